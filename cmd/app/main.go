@@ -91,13 +91,21 @@ func main() {
 	topicRepo := mysql.NewTopicRepository(db)
 	chatRepo := mysql.NewChatRepository(db)
 	relationshipRepo := mysql.NewRelationshipRepository(db)
+	adminRepo := mysql.NewAdminRepository(db)
+	reportRepo := mysql.NewReportRepository(db)
+	activityRepo := mysql.NewActivityRepository(db)
 
 	// 8. 初始化服务层
 	storageService := storage.GetStorage()
-	userService := service.NewUserService(userRepo, storageService)
-	chatService := service.NewChatService(chatRepo, userRepo, relationshipRepo, storageService)
+	userService := service.NewUserService(userRepo, storageService, cfg.Nearby, cfg.Upload, cfg.Profile)
+	chatService := service.NewChatService(chatRepo, topicRepo, userRepo, relationshipRepo, storageService, cfg.Upload, cfg.Chat)
 	relationshipService := service.NewRelationshipService(relationshipRepo, userRepo, chatService)
-	topicService := service.NewTopicService(topicRepo, userRepo, relationshipRepo, storageService)
+	topicService := service.NewTopicService(topicRepo, userRepo, relationshipRepo, storageService, cfg.Topic, cfg.Nearby, cfg.Upload)
+	adminService := service.NewAdminService(adminRepo, topicRepo)
+	uploadService := service.NewUploadService(storageService)
+	meService := service.NewMeService(userRepo, topicRepo, chatRepo, relationshipRepo, cfg.Features)
+	reportService := service.NewReportService(reportRepo, userRepo, topicRepo, chatRepo, chatService)
+	activityService := service.NewActivityService(activityRepo, topicRepo, userRepo)
 
 	// 9. 初始化处理器
 	h := handler.NewHandler(
@@ -105,10 +113,15 @@ func main() {
 		topicService,
 		chatService,
 		relationshipService,
+		adminService,
+		uploadService,
+		meService,
+		reportService,
+		activityService,
 	)
 
 	// 10. 初始化路由
-	r := router.SetupRouter(h)
+	r := router.SetupRouter(h, cfg)
 
 	// 11. 创建HTTP服务器
 	srv := &http.Server{
@@ -131,17 +144,53 @@ func main() {
 		}
 	}()
 
-	// 13. 优雅关闭
+	// 13. 定期清理未完成的分片上传及过期话题
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(service.UploadCleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cleanupCtx.Done():
+				return
+			case <-ticker.C:
+				if n, err := uploadService.CleanupExpiredSessions(cleanupCtx); err != nil {
+					logger.Error("Failed to clean up upload sessions", logger.Any("error", err))
+				} else if n > 0 {
+					logger.Info("Cleaned up expired upload sessions", logger.Int("count", n))
+				}
+			}
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(service.TopicExpiryCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cleanupCtx.Done():
+				return
+			case <-ticker.C:
+				if n, err := topicService.CleanExpiredTopics(cleanupCtx); err != nil {
+					logger.Error("Failed to close expired topics", logger.Any("error", err))
+				} else if n > 0 {
+					logger.Info("Closed expired topics", logger.Int64("count", n))
+				}
+			}
+		}
+	}()
+
+	// 14. 优雅关闭
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logger.Info("Shutting down server...")
+	stopCleanup()
 
-	// 14. 设置关闭超时
+	// 15. 设置关闭超时
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// 15. 关闭服务器
+	// 16. 关闭服务器
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Error("Server forced to shutdown", logger.Any("error", err))
 	}
@@ -9,11 +9,19 @@ import (
 )
 
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	MySQL    MySQLConfig    `mapstructure:"mysql"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	ES       ESConfig       `mapstructure:"elasticsearch"`
-	Firebase FirebaseConfig `mapstructure:"firebase"`
+	App      AppConfig       `mapstructure:"app"`
+	MySQL    MySQLConfig     `mapstructure:"mysql"`
+	Redis    RedisConfig     `mapstructure:"redis"`
+	ES       ESConfig        `mapstructure:"elasticsearch"`
+	Firebase FirebaseConfig  `mapstructure:"firebase"`
+	Topic    TopicConfig     `mapstructure:"topic"`
+	Nearby   NearbyConfig    `mapstructure:"nearby"`
+	Upload   UploadConfig    `mapstructure:"upload"`
+	Profile  ProfileConfig   `mapstructure:"profile"`
+	Chat     ChatConfig      `mapstructure:"chat"`
+	Auth     AuthConfig      `mapstructure:"auth"`
+	Client   ClientConfig    `mapstructure:"client"`
+	Features map[string]bool `mapstructure:"features"` // 下发给客户端的功能开关
 }
 
 type AppConfig struct {
@@ -23,6 +31,12 @@ type AppConfig struct {
 	ReadTimeout    time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout   time.Duration `mapstructure:"write_timeout"`
 	MaxHeaderBytes int           `mapstructure:"max_header_bytes"`
+	// MaxBodySize 全局请求体上限，具体上传接口另有更小的限制
+	MaxBodySize int64 `mapstructure:"max_body_size"`
+	// MaxMultipartMemory multipart 表单在内存中缓冲的上限，超出部分写入临时文件
+	MaxMultipartMemory int64 `mapstructure:"max_multipart_memory"`
+	// TrustedProxies 可信反向代理的 IP 或网段，只采信其转发的 X-Forwarded-For，为空时使用连接地址
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type MySQLConfig struct {
@@ -40,6 +54,8 @@ type RedisConfig struct {
 	Port     int    `mapstructure:"port"`
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
+	// TombstoneTTL 缓存失效后禁止读路径回填的时长，0 表示关闭
+	TombstoneTTL time.Duration `mapstructure:"tombstone_ttl"`
 }
 
 type ESConfig struct {
@@ -52,12 +68,145 @@ type FirebaseConfig struct {
 	CredentialsFile string `mapstructure:"credentials_file"`
 	ProjectID       string `mapstructure:"project_id"`
 	StorageBucket   string `mapstructure:"storage_bucket"`
+	// DirectoryVisibility 存储目录可见性(public/private)，未配置的目录公开
+	DirectoryVisibility map[string]string `mapstructure:"directory_visibility"`
+}
+
+type TopicConfig struct {
+	DefaultSort TopicSortConfig  `mapstructure:"default_sort"`
+	CreateLimit TopicLimitConfig `mapstructure:"create_limit"`
+	// DefaultExpiration 未指定过期时间时的默认有效期
+	DefaultExpiration time.Duration `mapstructure:"default_expiration"`
+	// MaxExpiration 普通用户可设置的最长有效期
+	MaxExpiration time.Duration `mapstructure:"max_expiration"`
+	// PermanentUserTypes 允许发布永久话题的用户类型
+	PermanentUserTypes []string `mapstructure:"permanent_user_types"`
+	// ViewMode 浏览计数方式
+	// explicit: 仅客户端调用浏览接口时计数; auto: 获取话题详情时自动计数
+	ViewMode string `mapstructure:"view_mode"`
+	// ViewDedupWindow 同一用户在该时长内重复浏览只计一次，0 表示不去重
+	ViewDedupWindow time.Duration `mapstructure:"view_dedup_window"`
+	// MaxProfilePins 每个用户最多置顶到主页的话题数量，0 表示不限制
+	MaxProfilePins int `mapstructure:"max_profile_pins"`
+	// RestoreWindow 删除后可恢复的时长，0 表示不允许恢复
+	RestoreWindow time.Duration `mapstructure:"restore_window"`
+	// Dedup 近似重复话题检测
+	Dedup TopicDedupConfig `mapstructure:"dedup"`
+}
+
+// TopicDedupConfig 近似重复话题检测配置
+// 同一作者在 Window 内、Radius 米范围内发布内容相似度不低于 Similarity 的话题时拒绝发布
+type TopicDedupConfig struct {
+	Window     time.Duration `mapstructure:"window"`     // 0 表示不检测
+	Radius     float64       `mapstructure:"radius"`     // 米
+	Similarity float64       `mapstructure:"similarity"` // 0~1
+}
+
+// TopicLimitConfig 发布话题频率限制
+type TopicLimitConfig struct {
+	Window        time.Duration `mapstructure:"window"`
+	Limit         int           `mapstructure:"limit"`          // 普通用户窗口内最多发布数
+	VerifiedLimit int           `mapstructure:"verified_limit"` // 商家/官方账号窗口内最多发布数
+}
+
+// TopicSortConfig 各列表接口的默认排序(recent/popular)
+type TopicSortConfig struct {
+	List   string `mapstructure:"list"`
+	User   string `mapstructure:"user"`
+	Nearby string `mapstructure:"nearby"`
+}
+
+// NearbyConfig 附近用户/话题查询配置
+type NearbyConfig struct {
+	// ActiveWithin 未指定 active_within 时只返回该时长内活跃过的用户，0 表示不过滤
+	ActiveWithin time.Duration `mapstructure:"active_within"`
+}
+
+// UploadConfig 上传配置
+type UploadConfig struct {
+	// MediaFailurePolicy 发消息/发话题时部分文件上传失败的处理方式
+	// strict: 整个请求失败并删除已上传的文件; partial: 保留成功的文件并返回失败列表
+	MediaFailurePolicy string `mapstructure:"media_failure_policy"`
+	// AnimatedAvatarPolicy 动图头像的处理方式
+	// reject: 拒绝上传; flatten: 只保留第一帧，无法提取首帧的格式仍会被拒绝
+	AnimatedAvatarPolicy string `mapstructure:"animated_avatar_policy"`
+	// AvatarShapePolicy 非正方形头像的处理方式
+	// crop: 居中裁剪为正方形; reject: 长宽比超过 AvatarMaxAspectRatio 时拒绝
+	AvatarShapePolicy    string  `mapstructure:"avatar_shape_policy"`
+	AvatarMaxAspectRatio float64 `mapstructure:"avatar_max_aspect_ratio"`
+	// AvatarMaxDimension 头像最大边长，超出时等比缩小，0 表示不限制
+	AvatarMaxDimension uint `mapstructure:"avatar_max_dimension"`
+	// MaxConcurrentUploads 单个请求内媒体文件的最大并发上传数
+	MaxConcurrentUploads int `mapstructure:"max_concurrent_uploads"`
+}
+
+// ProfileConfig 用户资料配置
+type ProfileConfig struct {
+	// UniqueNickname 是否要求昵称唯一，关闭时允许重名
+	UniqueNickname bool `mapstructure:"unique_nickname"`
+}
+
+// ChatConfig 聊天配置
+type ChatConfig struct {
+	// MaxPinnedRooms 每个用户最多置顶的聊天室数量，0 表示不限制
+	MaxPinnedRooms int `mapstructure:"max_pinned_rooms"`
+}
+
+// AuthConfig 登录认证配置
+type AuthConfig struct {
+	// LoginFailureIPLimit 同一 IP 在 LoginFailureWindow 内允许的登录失败次数，达到后暂时拒绝该 IP 登录
+	LoginFailureIPLimit int `mapstructure:"login_failure_ip_limit"`
+	// LoginFailureLimit 同一用户在 LoginFailureWindow 内使用已撤销令牌登录的次数，达到后暂时拒绝该用户登录
+	LoginFailureLimit  int           `mapstructure:"login_failure_limit"`
+	LoginFailureWindow time.Duration `mapstructure:"login_failure_window"`
+}
+
+// ClientConfig 客户端版本配置
+type ClientConfig struct {
+	// MinVersions 各平台（ios/android/web）允许访问的最低版本，未配置的平台不限制
+	MinVersions map[string]string `mapstructure:"min_versions"`
+}
+
+// setDefaults 设置配置默认值
+func setDefaults() {
+	viper.SetDefault("app.max_body_size", 100<<20)
+	viper.SetDefault("redis.tombstone_ttl", 2*time.Second)
+	viper.SetDefault("app.max_multipart_memory", 8<<20)
+	viper.SetDefault("topic.default_sort.list", "recent")
+	viper.SetDefault("topic.default_sort.user", "recent")
+	viper.SetDefault("topic.default_sort.nearby", "recent")
+	viper.SetDefault("topic.create_limit.window", time.Hour)
+	viper.SetDefault("topic.create_limit.limit", 10)
+	viper.SetDefault("topic.create_limit.verified_limit", 50)
+	viper.SetDefault("topic.default_expiration", 24*time.Hour)
+	viper.SetDefault("topic.max_expiration", 7*24*time.Hour)
+	viper.SetDefault("topic.permanent_user_types", []string{"merchant", "official", "admin"})
+	viper.SetDefault("topic.view_mode", "explicit")
+	viper.SetDefault("topic.view_dedup_window", 30*time.Minute)
+	viper.SetDefault("topic.max_profile_pins", 3)
+	viper.SetDefault("topic.restore_window", 24*time.Hour)
+	viper.SetDefault("topic.dedup.window", 30*time.Minute)
+	viper.SetDefault("topic.dedup.radius", 1000)
+	viper.SetDefault("topic.dedup.similarity", 0.9)
+	viper.SetDefault("nearby.active_within", 7*24*time.Hour)
+	viper.SetDefault("upload.media_failure_policy", "partial")
+	viper.SetDefault("upload.animated_avatar_policy", "flatten")
+	viper.SetDefault("upload.avatar_shape_policy", "crop")
+	viper.SetDefault("upload.avatar_max_aspect_ratio", 1.25)
+	viper.SetDefault("upload.avatar_max_dimension", 1024)
+	viper.SetDefault("upload.max_concurrent_uploads", 4)
+	viper.SetDefault("profile.unique_nickname", false)
+	viper.SetDefault("chat.max_pinned_rooms", 10)
+	viper.SetDefault("auth.login_failure_ip_limit", 50)
+	viper.SetDefault("auth.login_failure_limit", 10)
+	viper.SetDefault("auth.login_failure_window", 15*time.Minute)
 }
 
 // LoadConfig 加载配置
 func LoadConfig(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
 	viper.AutomaticEnv()
+	setDefaults()
 
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -0,0 +1,3 @@
+ALTER TABLE chat_rooms
+    DROP INDEX uk_pair_key,
+    DROP COLUMN pair_key;
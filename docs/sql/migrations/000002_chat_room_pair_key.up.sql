@@ -0,0 +1,21 @@
+-- 私聊房间唯一键：每对用户只能有一个私聊房间
+ALTER TABLE chat_rooms
+    ADD COLUMN pair_key VARCHAR(41) NULL COMMENT '私聊双方用户ID（较小ID:较大ID），群聊为空' AFTER topic_id;
+
+-- 回填已有私聊房间，同一对用户存在多个房间时只保留最早创建的房间
+UPDATE chat_rooms r
+JOIN (
+    SELECT MIN(p.chat_room_id) AS chat_room_id, p.pair_key
+    FROM (
+        SELECT m.chat_room_id, CONCAT(MIN(m.user_id), ':', MAX(m.user_id)) AS pair_key
+        FROM chat_room_members m
+        JOIN chat_rooms c ON c.id = m.chat_room_id AND c.type = 'individual'
+        GROUP BY m.chat_room_id
+        HAVING COUNT(*) = 2
+    ) p
+    GROUP BY p.pair_key
+) k ON k.chat_room_id = r.id
+SET r.pair_key = k.pair_key;
+
+ALTER TABLE chat_rooms
+    ADD UNIQUE INDEX uk_pair_key (pair_key);
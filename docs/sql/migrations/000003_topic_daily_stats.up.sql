@@ -0,0 +1,9 @@
+-- 话题每日统计表
+CREATE TABLE topic_daily_stats (
+    topic_id BIGINT UNSIGNED NOT NULL COMMENT '话题ID',
+    stat_date DATE NOT NULL COMMENT '统计日期',
+    views INT UNSIGNED DEFAULT 0 COMMENT '当日浏览数',
+    likes INT UNSIGNED DEFAULT 0 COMMENT '当日点赞数',
+    PRIMARY KEY (topic_id, stat_date),
+    FOREIGN KEY (topic_id) REFERENCES topics(id) ON DELETE CASCADE
+)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '话题每日统计表，按日累加浏览和点赞';
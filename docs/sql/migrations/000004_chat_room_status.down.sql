@@ -0,0 +1,2 @@
+ALTER TABLE chat_rooms
+    DROP COLUMN status;
@@ -0,0 +1,9 @@
+-- 聊天室状态：最后一名成员退出后关闭
+ALTER TABLE chat_rooms
+    ADD COLUMN status ENUM('active', 'closed') NOT NULL DEFAULT 'active' COMMENT '聊天室状态：active-正常, closed-已关闭' AFTER announcement;
+
+-- 已没有成员的群聊视为已关闭
+UPDATE chat_rooms r
+SET r.status = 'closed'
+WHERE r.type = 'group'
+  AND NOT EXISTS (SELECT 1 FROM chat_room_members m WHERE m.chat_room_id = r.id);
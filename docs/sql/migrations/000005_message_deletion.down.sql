@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS hidden_messages;
+
+ALTER TABLE messages
+    DROP COLUMN deleted_at;
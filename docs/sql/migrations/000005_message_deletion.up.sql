@@ -0,0 +1,13 @@
+-- 消息删除：对所有人删除保留墓碑，仅对自己删除记录到隐藏表
+ALTER TABLE messages
+    ADD COLUMN deleted_at TIMESTAMP NULL COMMENT '对所有人删除(撤回)的时间，删除后内容清空' AFTER content;
+
+-- 用户仅对自己删除的消息
+CREATE TABLE hidden_messages (
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    message_id BIGINT UNSIGNED NOT NULL COMMENT '消息ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '删除时间',
+    PRIMARY KEY (user_id, message_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '隐藏消息表，记录用户仅对自己删除的消息';
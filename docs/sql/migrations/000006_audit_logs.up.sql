@@ -0,0 +1,16 @@
+-- 管理操作审计日志表
+CREATE TABLE audit_logs (
+    id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+    operator_id BIGINT UNSIGNED NOT NULL COMMENT '操作人ID',
+    action VARCHAR(50) NOT NULL COMMENT '操作类型',
+    target_type VARCHAR(20) NOT NULL COMMENT '操作对象类型',
+    target_id BIGINT UNSIGNED NOT NULL COMMENT '操作对象ID',
+    detail TEXT COMMENT '操作详情(JSON)',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (operator_id) REFERENCES users(id),
+    INDEX idx_operator_id (operator_id),
+    INDEX idx_action (action),
+    INDEX idx_audit_target (target_type, target_id),
+    INDEX idx_created_at (created_at)
+)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '管理操作审计日志表';
@@ -0,0 +1,4 @@
+DELETE FROM messages WHERE content_type = 'topic';
+
+ALTER TABLE messages
+    MODIFY COLUMN content_type ENUM('text', 'image', 'file', 'system') DEFAULT 'text' NOT NULL COMMENT '消息类型：text-文本, image-图片, file-文件, system-系统消息';
@@ -0,0 +1,3 @@
+-- 新增分享话题消息类型，内容为话题ID
+ALTER TABLE messages
+    MODIFY COLUMN content_type ENUM('text', 'image', 'file', 'system', 'topic') DEFAULT 'text' NOT NULL COMMENT '消息类型：text-文本, image-图片, file-文件, system-系统消息, topic-分享话题';
@@ -0,0 +1,9 @@
+-- 个人主页置顶话题表
+CREATE TABLE profile_pinned_topics (
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    topic_id BIGINT UNSIGNED NOT NULL COMMENT '话题ID',
+    pinned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '置顶时间',
+    PRIMARY KEY (user_id, topic_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (topic_id) REFERENCES topics(id) ON DELETE CASCADE
+)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '个人主页置顶话题表';
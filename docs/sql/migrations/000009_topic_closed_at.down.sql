@@ -0,0 +1,2 @@
+ALTER TABLE topics
+    DROP COLUMN closed_at;
@@ -0,0 +1,5 @@
+-- 记录话题关闭时间，删除后在宽限期内可恢复
+ALTER TABLE topics
+    ADD COLUMN closed_at TIMESTAMP NULL DEFAULT NULL COMMENT '关闭时间' AFTER status;
+
+UPDATE topics SET closed_at = updated_at WHERE status = 'closed';
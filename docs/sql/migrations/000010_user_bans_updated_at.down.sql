@@ -0,0 +1,3 @@
+ALTER TABLE user_bans
+    DROP COLUMN updated_at,
+    MODIFY COLUMN ban_end TIMESTAMP COMMENT '封禁结束时间';
@@ -0,0 +1,4 @@
+-- 封禁记录需要记录取消时间，结束时间为空表示永久封禁
+ALTER TABLE user_bans
+    MODIFY COLUMN ban_end TIMESTAMP NULL DEFAULT NULL COMMENT '封禁结束时间，为空表示永久封禁',
+    ADD COLUMN updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP AFTER created_at;
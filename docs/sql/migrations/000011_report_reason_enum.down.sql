@@ -0,0 +1,2 @@
+ALTER TABLE reports
+    MODIFY COLUMN reason_type VARCHAR(50) NOT NULL COMMENT '举报原因类型';
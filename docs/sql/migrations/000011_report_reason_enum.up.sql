@@ -0,0 +1,10 @@
+-- 举报原因统一为 spam/harassment/nudity/other，与提交举报时可选的原因列表一致
+-- 旧的 abuse 归入 harassment，其余不在列表中的原因归入 other，原值保留在说明中
+UPDATE reports SET reason_type = 'harassment' WHERE reason_type = 'abuse';
+UPDATE reports
+SET reason_detail = CONCAT_WS(' ', CONCAT('[', reason_type, ']'), reason_detail),
+    reason_type = 'other'
+WHERE reason_type NOT IN ('spam', 'harassment', 'nudity', 'other');
+
+ALTER TABLE reports
+    MODIFY COLUMN reason_type ENUM('spam', 'harassment', 'nudity', 'other') NOT NULL COMMENT '举报原因类型';
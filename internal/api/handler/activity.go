@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"DistanceBack_v1/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrackLastActive 记录当前用户的最后活跃时间，需放在 AuthRequired 之后
+// 写入按用户节流，失败只记录日志，不影响请求本身
+func (h *Handler) TrackLastActive() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID := h.GetCurrentUserID(c); userID != 0 {
+			if err := h.userService.TouchLastActive(c, userID); err != nil {
+				logger.Warn("更新用户最后活跃时间失败",
+					logger.Any("error", err),
+					logger.Uint64("user_id", userID))
+			}
+		}
+
+		c.Next()
+	}
+}
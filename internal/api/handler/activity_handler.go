@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"DistanceBack_v1/internal/api/request"
+	"DistanceBack_v1/internal/api/response"
+	"DistanceBack_v1/internal/service"
+	"DistanceBack_v1/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetActivityTimeline 获取当前用户动态
+// @Summary 获取我的动态
+// @Description 按时间倒序游标分页获取当前用户发布的话题、话题互动和关注记录
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20" minimum(1) maximum(100)
+// @Success 200 {object} response.Response{data=response.ActivityTimelineResponse} "用户动态"
+// @Failure 400,401 {object} response.Response "错误详情"
+// @Router /api/v1/users/activity [get]
+func (h *Handler) GetActivityTimeline(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 获取参数
+	var req request.ActivityTimelineRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 3. 获取动态
+	page, err := h.activityService.GetTimeline(c, userID, req.Cursor, req.Limit)
+	if err != nil {
+		logger.Error("获取用户动态失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+			logger.String("cursor", req.Cursor))
+		Error(c, err)
+		return
+	}
+
+	Success(c, response.ToActivityTimelineResponse(page.Activities, page.NextCursor, page.HasMore))
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newActivityFixture() (*fakeUserRepo, *Handler) {
+	stale := time.Now().Add(-30 * 24 * time.Hour)
+	active := &model.User{Nickname: "active", Status: model.UserStatusActive, LastActiveAt: &stale}
+	active.ID = 1
+	dormant := &model.User{Nickname: "dormant", Status: model.UserStatusActive, LastActiveAt: &stale}
+	dormant.ID = 2
+	userRepo := newFakeUserRepo(active, dormant)
+	userRepo.firebase["uid-1"] = 1
+
+	userService := service.NewUserService(userRepo, nil, config.NearbyConfig{ActiveWithin: 7 * 24 * time.Hour},
+		config.UploadConfig{}, config.ProfileConfig{})
+	return userRepo, NewHandler(userService, nil, nil, nil, nil, nil, nil, nil, nil)
+}
+
+func TestTrackLastActiveThrottlesWrites(t *testing.T) {
+	resetCache(t)
+	userRepo, h := newActivityFixture()
+	r := gin.New()
+	r.GET("/ping", withFirebaseUID("uid-1"), h.TrackLastActive(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for i := 0; i < 3; i++ {
+		if w := serve(r, httptest.NewRequest(http.MethodGet, "/ping", nil)); w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+	}
+	if userRepo.activeUpdates != 1 {
+		t.Fatalf("updates = %d, want 1 within the throttle window", userRepo.activeUpdates)
+	}
+
+	testRedis.FastForward(10 * time.Minute)
+	serve(r, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if userRepo.activeUpdates != 2 {
+		t.Errorf("updates = %d, want 2 after the throttle window", userRepo.activeUpdates)
+	}
+}
+
+func TestTrackLastActiveSkipsWhenCacheDown(t *testing.T) {
+	resetCache(t)
+	userRepo, h := newActivityFixture()
+	// 预先解析用户ID，只让节流键写入失败
+	r := gin.New()
+	r.GET("/ping", func(c *gin.Context) {
+		c.Set(currentUserIDKey, uint64(1))
+		c.Next()
+	}, h.TrackLastActive(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	testRedis.SetError("connection refused")
+	defer testRedis.SetError("")
+
+	if w := serve(r, httptest.NewRequest(http.MethodGet, "/ping", nil)); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if userRepo.activeUpdates != 0 {
+		t.Errorf("updates = %d, want 0 while the cache is down", userRepo.activeUpdates)
+	}
+}
+
+func TestRecentlyActiveUserAppearsNearby(t *testing.T) {
+	resetCache(t)
+	_, h := newActivityFixture()
+	r := gin.New()
+	r.GET("/users/nearby", withFirebaseUID("uid-1"), h.TrackLastActive(), h.GetNearbyUsers)
+
+	w := serve(r, httptest.NewRequest(http.MethodGet,
+		"/users/nearby?latitude=35.6&longitude=139.7&radius=1000&page=1&page_size=10", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", w.Code, w.Body.String())
+	}
+
+	var page struct {
+		List []struct {
+			ID uint64 `json:"id"`
+		} `json:"list"`
+	}
+	decodeData(t, w, &page)
+	if len(page.List) != 1 || page.List[0].ID != 1 {
+		t.Errorf("nearby = %+v, want only the recently active user", page.List)
+	}
+}
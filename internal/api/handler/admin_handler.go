@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"time"
+
+	"DistanceBack_v1/internal/api/request"
+	"DistanceBack_v1/internal/service"
+	"DistanceBack_v1/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRequired 管理员权限中间件(需在认证中间件之后使用)
+func (h *Handler) AdminRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := h.GetCurrentUserID(c)
+		if userID == 0 {
+			Error(c, service.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		isAdmin, err := h.userService.IsAdmin(c, userID)
+		if err != nil {
+			logger.Error("检查管理员权限失败",
+				logger.Any("error", err),
+				logger.Uint64("user_id", userID))
+			Error(c, err)
+			c.Abort()
+			return
+		}
+		if !isAdmin {
+			Error(c, service.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetDashboardStats 获取管理后台概览数据
+func (h *Handler) GetDashboardStats(c *gin.Context) {
+	stats, err := h.adminService.GetDashboardStats(c)
+	if err != nil {
+		logger.Error("获取统计数据失败", logger.Any("error", err))
+		Error(c, err)
+		return
+	}
+
+	Success(c, stats)
+}
+
+// RemoveTopic 管理员下架违规话题
+func (h *Handler) RemoveTopic(c *gin.Context) {
+	operatorID := h.GetCurrentUserID(c)
+
+	topicID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	var req request.RemoveTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+
+	if err := h.adminService.RemoveTopic(c, operatorID, topicID, req.Reason, req.NotifyAuthor); err != nil {
+		logger.Error("下架话题失败",
+			logger.Any("error", err),
+			logger.Uint64("operator_id", operatorID),
+			logger.Uint64("topic_id", topicID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+// BanUser 管理员封禁用户
+func (h *Handler) BanUser(c *gin.Context) {
+	operatorID := h.GetCurrentUserID(c)
+
+	userID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	var req request.BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+
+	var until *time.Time
+	if req.DurationHours > 0 {
+		end := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+		until = &end
+	}
+
+	if err := h.userService.BanUser(c, operatorID, userID, req.Reason, until); err != nil {
+		logger.Error("封禁用户失败",
+			logger.Any("error", err),
+			logger.Uint64("operator_id", operatorID),
+			logger.Uint64("user_id", userID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+// UnbanUser 管理员解除用户封禁
+func (h *Handler) UnbanUser(c *gin.Context) {
+	userID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.userService.UnbanUser(c, userID); err != nil {
+		logger.Error("解除封禁失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
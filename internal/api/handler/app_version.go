@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"DistanceBack_v1/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisteredAppVersion 查找当前用户注册设备上报的平台和版本，需放在认证中间件之后
+// 供客户端未携带版本号时检查最低版本，未登录或查询失败时返回空字符串
+func (h *Handler) RegisteredAppVersion(c *gin.Context, platform string) (string, string) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		return "", ""
+	}
+
+	device, err := h.userService.GetLatestDevice(c, userID, platform)
+	if err != nil {
+		logger.Warn("获取用户设备版本失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID))
+		return "", ""
+	}
+	if device == nil {
+		return "", ""
+	}
+	return device.DeviceType, device.AppVersion
+}
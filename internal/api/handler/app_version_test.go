@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRegisteredAppVersion(t *testing.T) {
+	resetCache(t)
+	userRepo, topicRepo := newTopicFixture()
+	now := time.Now()
+	userRepo.devices = []*model.UserDevice{
+		{UserID: 7, DeviceType: "android", AppVersion: "3.0.0", IsActive: true, LastActiveAt: now.Add(-time.Hour)},
+		{UserID: 7, DeviceType: "ios", AppVersion: "1.4.0", IsActive: true, LastActiveAt: now},
+		{UserID: 7, DeviceType: "ios", AppVersion: "9.9.9", IsActive: false, LastActiveAt: now}, // 已退出
+	}
+	h := newTopicTestHandler(userRepo, topicRepo, config.TopicConfig{})
+
+	tests := []struct {
+		name         string
+		uid          string
+		platform     string
+		wantPlatform string
+		wantVersion  string
+	}{
+		{"latest device", "fb-viewer", "", "ios", "1.4.0"},
+		{"requested platform", "fb-viewer", "android", "android", "3.0.0"},
+		{"no device for platform", "fb-viewer", "web", "", ""},
+		{"anonymous", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var platform, version string
+			r := gin.New()
+			r.GET("/ping", withFirebaseUID(tt.uid), func(c *gin.Context) {
+				platform, version = h.RegisteredAppVersion(c, tt.platform)
+			})
+			serve(r, httptest.NewRequest("GET", "/ping", nil))
+			if platform != tt.wantPlatform || version != tt.wantVersion {
+				t.Errorf("got %q %q, want %q %q", platform, version, tt.wantPlatform, tt.wantVersion)
+			}
+		})
+	}
+}
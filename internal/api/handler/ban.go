@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"time"
+
+	"DistanceBack_v1/internal/service"
+	"DistanceBack_v1/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// banExemptRoutes 封禁用户仍可访问的路由，便于客户端展示账号状态
+var banExemptRoutes = map[string]bool{
+	"/api/v1/me": true,
+}
+
+// RejectBannedUsers 拒绝被封禁用户的请求，需放在 AuthRequired 或 OptionalAuth 之后
+// 未登录及尚未注册的用户交由具体接口处理；查询封禁状态失败时拒绝请求，与 AdminRequired 一致
+// 缓存不可用时会回源数据库，只有数据库也失败时才拒绝，此时后续接口通常同样无法工作
+func (h *Handler) RejectBannedUsers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if banExemptRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		userID := h.GetCurrentUserID(c)
+		if userID == 0 {
+			c.Next()
+			return
+		}
+
+		status, err := h.userService.GetBanStatus(c, userID)
+		if err != nil {
+			logger.Error("获取用户封禁状态失败",
+				logger.Any("error", err),
+				logger.Uint64("user_id", userID))
+			Error(c, err)
+			c.Abort()
+			return
+		}
+
+		if status.Active(time.Now()) {
+			Error(c, service.NewError(service.CodeUserBanned, service.ErrUserBanned.Message).
+				WithStatus(service.ErrUserBanned.HTTPStatus).
+				WithDetails(status))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
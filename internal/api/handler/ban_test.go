@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newBanRouter 按路由中的顺序挂载封禁检查，/api/v1/me 为豁免路由
+func newBanRouter(userRepo *fakeUserRepo) *gin.Engine {
+	userService := service.NewUserService(userRepo, nil, config.NearbyConfig{},
+		config.UploadConfig{}, config.ProfileConfig{})
+	h := NewHandler(userService, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	r := gin.New()
+	group := r.Group("/api/v1", withFirebaseUID("uid-7"), h.RejectBannedUsers())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	group.GET("/me", ok)
+	group.GET("/topics", ok)
+	return r
+}
+
+func newBanUser(status string) *fakeUserRepo {
+	user := &model.User{Nickname: "kay", Status: status}
+	user.ID = 7
+	userRepo := newFakeUserRepo(user)
+	userRepo.firebase["uid-7"] = 7
+	return userRepo
+}
+
+func TestRejectBannedUsersAllowsExemptRoute(t *testing.T) {
+	resetCache(t)
+	userRepo := newBanUser(model.UserStatusActive)
+	banEnd := time.Now().Add(time.Hour)
+	userRepo.bans = []*model.UserBan{{UserID: 7, BanStart: time.Now().Add(-time.Hour), BanEnd: &banEnd, Status: "active"}}
+	r := newBanRouter(userRepo)
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/api/v1/topics", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("banned user status = %d, want 403", w.Code)
+	}
+	var status service.BanStatus
+	decodeData(t, w, &status)
+	if !status.Banned || status.Until == nil {
+		t.Errorf("details = %+v, want ban with end time", status)
+	}
+
+	if w := serve(r, httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)); w.Code != http.StatusOK {
+		t.Errorf("exempt route status = %d, want 200", w.Code)
+	}
+}
+
+func TestRejectBannedUsersAllowsActiveUser(t *testing.T) {
+	resetCache(t)
+	r := newBanRouter(newBanUser(model.UserStatusActive))
+
+	if w := serve(r, httptest.NewRequest(http.MethodGet, "/api/v1/topics", nil)); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
+
+func TestRejectBannedUsersFailsClosed(t *testing.T) {
+	resetCache(t)
+	userRepo := newBanUser(model.UserStatusActive)
+	userRepo.banErr = errors.New("connection refused")
+	r := newBanRouter(userRepo)
+
+	if w := serve(r, httptest.NewRequest(http.MethodGet, "/api/v1/topics", nil)); w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500 when ban status is unavailable", w.Code)
+	}
+}
+
+func TestRejectBannedUsersOnOptionalAuthRoutes(t *testing.T) {
+	resetCache(t)
+	userRepo := newBanUser(model.UserStatusBanned)
+	userService := service.NewUserService(userRepo, nil, config.NearbyConfig{},
+		config.UploadConfig{}, config.ProfileConfig{})
+	h := NewHandler(userService, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	// 可选认证的公开路由，只有解析出用户时才检查封禁
+	r := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/public/topics", h.RejectBannedUsers(), ok)
+	r.GET("/topics", withFirebaseUID("uid-7"), h.RejectBannedUsers(), ok)
+
+	if w := serve(r, httptest.NewRequest(http.MethodGet, "/public/topics", nil)); w.Code != http.StatusOK {
+		t.Errorf("anonymous status = %d, want 200", w.Code)
+	}
+	if w := serve(r, httptest.NewRequest(http.MethodGet, "/topics", nil)); w.Code != http.StatusForbidden {
+		t.Errorf("banned user status = %d, want 403", w.Code)
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	stderrors "errors"
 	"net/http"
 	"strconv"
 
@@ -29,6 +30,11 @@ type Handler struct {
 	topicService        *service.TopicService
 	chatService         *service.ChatService
 	relationshipService *service.RelationshipService
+	adminService        *service.AdminService
+	uploadService       *service.UploadService
+	meService           *service.MeService
+	reportService       *service.ReportService
+	activityService     *service.ActivityService
 }
 
 // NewHandler 创建处理器实例
@@ -37,12 +43,22 @@ func NewHandler(
 	topicService *service.TopicService,
 	chatService *service.ChatService,
 	relationshipService *service.RelationshipService,
+	adminService *service.AdminService,
+	uploadService *service.UploadService,
+	meService *service.MeService,
+	reportService *service.ReportService,
+	activityService *service.ActivityService,
 ) *Handler {
 	return &Handler{
 		userService:         userService,
 		topicService:        topicService,
 		chatService:         chatService,
 		relationshipService: relationshipService,
+		adminService:        adminService,
+		uploadService:       uploadService,
+		meService:           meService,
+		reportService:       reportService,
+		activityService:     activityService,
 	}
 }
 
@@ -55,10 +71,30 @@ func Success(c *gin.Context, data interface{}) {
 	})
 }
 
+// bodyError 转换读取请求体时的错误：超过 MaxBodySize 限制时返回 413，
+// 包括未声明 Content-Length 的分块请求；其他错误返回 fallback
+func bodyError(err error, fallback error) error {
+	var maxBytesErr *http.MaxBytesError
+	if stderrors.As(err, &maxBytesErr) {
+		return errors.NewRequestTooLarge(maxBytesErr.Limit)
+	}
+	return fallback
+}
+
 // Error 返回错误响应
 func Error(c *gin.Context, err error) {
+	// 读取请求体超限的错误可能由服务层原样返回
+	var maxBytesErr *http.MaxBytesError
+	if stderrors.As(err, &maxBytesErr) {
+		err = errors.NewRequestTooLarge(maxBytesErr.Limit)
+	}
+
 	// 处理应用错误
 	if e, ok := err.(*errors.AppError); ok { // 修改这里
+		// 限流错误附带 Retry-After 头
+		if seconds, ok := errors.RetryAfterSeconds(e); ok {
+			c.Header("Retry-After", strconv.Itoa(seconds))
+		}
 		c.JSON(e.HTTPStatus, Response{
 			Code:    e.Code,
 			Message: e.Message,
@@ -67,6 +103,20 @@ func Error(c *gin.Context, err error) {
 		return
 	}
 
+	// 处理服务层错误
+	if e, ok := err.(*service.Error); ok {
+		status := e.HTTPStatus
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, Response{
+			Code:    e.Code,
+			Message: e.Message,
+			Data:    e.Details,
+		})
+		return
+	}
+
 	// 处理其他错误
 	c.JSON(http.StatusInternalServerError, Response{
 		Code:    500,
@@ -74,13 +124,28 @@ func Error(c *gin.Context, err error) {
 	})
 }
 
+// currentUserIDKey 上下文中缓存的内部用户ID
+const currentUserIDKey = "current_user_id"
+
 // GetCurrentUserID 获取当前用户ID
+// 中间件写入的是 Firebase UID，这里解析为内部用户ID；匿名访问时返回0
 func (h *Handler) GetCurrentUserID(c *gin.Context) uint64 {
-	userID, exists := c.Get("user_id")
-	if !exists {
+	if userID, exists := c.Get(currentUserIDKey); exists {
+		return userID.(uint64)
+	}
+
+	firebaseUID := c.GetString("user_id")
+	if firebaseUID == "" {
 		return 0
 	}
-	return userID.(uint64)
+
+	userID, err := h.userService.GetUserIDByFirebaseUID(c, firebaseUID)
+	if err != nil || userID == 0 {
+		return 0
+	}
+
+	c.Set(currentUserIDKey, userID)
+	return userID
 }
 
 // ParseUint64Param 解析uint64类型的路径参数
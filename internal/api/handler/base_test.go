@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/internal/service"
+	"DistanceBack_v1/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorSetsRetryAfterForRateLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantHeader string
+	}{
+		{"rate limited", errors.NewRateLimited(2500 * time.Millisecond), http.StatusTooManyRequests, "3"},
+		{"other app error", errors.ErrNotFound, http.StatusOK, ""},
+		{"service error", service.ErrTopicNotFound, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			Error(c, tt.err)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantHeader {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestErrorMapsServiceErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    int
+		wantDetails string
+	}{
+		{
+			"conflict with details",
+			service.NewError(service.CodeConflict, "resource already exists").
+				WithStatus(http.StatusConflict).
+				WithDetails(map[string]uint64{"topic_id": 42}),
+			http.StatusConflict, service.CodeConflict, `{"topic_id":42}`,
+		},
+		{"forbidden", service.ErrForbidden, http.StatusForbidden, service.CodeForbidden, ""},
+		{"no status", &service.Error{Code: service.CodeOperationFailed}, http.StatusInternalServerError, service.CodeOperationFailed, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			Error(c, tt.err)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var resp struct {
+				Code int             `json:"code"`
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", resp.Code, tt.wantCode)
+			}
+			if got := string(resp.Data); got != tt.wantDetails {
+				t.Errorf("data = %s, want %s", got, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestBodyErrorMapsMaxBytesError(t *testing.T) {
+	r := gin.New()
+	r.POST("/", func(c *gin.Context) {
+		// 模拟 MaxBodySize 中间件
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 16)
+		var req struct {
+			Title string `json:"title"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Error(c, bodyError(err, service.ErrInvalidRequest))
+			return
+		}
+		Success(c, nil)
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"chunked body over limit", `{"title":"` + strings.Repeat("x", 32) + `"}`, http.StatusRequestEntityTooLarge},
+		{"malformed body", `{"title":`, http.StatusBadRequest},
+		{"small body", `{}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.ContentLength = -1
+			req.Header.Set("Content-Type", "application/json")
+			w := serve(r, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"strings"
+
 	"DistanceBack_v1/internal/model"
 	"DistanceBack_v1/internal/service"
 
@@ -10,13 +12,27 @@ import (
 // CreateGroupRequest 创建群聊请求
 type CreateGroupRequest struct {
 	Name           string   `json:"name" binding:"required,min=1,max=100"`
+	TopicID        uint64   `json:"topic_id"` // 关联话题，为空时不关联
 	InitialMembers []uint64 `json:"initial_members" binding:"required,min=1,dive,min=1"`
 }
 
 // SendMessageRequest 发送消息请求
 type SendMessageRequest struct {
 	ContentType string `json:"content_type" binding:"required,oneof=text image file system"`
-	Content     string `json:"content" binding:"required"`
+	// Content 文本和系统消息必填，图片和文件消息可以只有媒体
+	Content string `json:"content"`
+	// Attachments 通过上传接口获得的文件句柄，提供时不再读取 multipart 文件
+	Attachments []string `json:"attachments" binding:"omitempty,max=9,dive,required"`
+}
+
+// LinkPreviewRequest 链接预览请求
+type LinkPreviewRequest struct {
+	URL string `json:"url" binding:"required,url,max=2048"`
+}
+
+// ShareTopicRequest 分享话题请求
+type ShareTopicRequest struct {
+	TopicID uint64 `json:"topic_id" binding:"required"`
 }
 
 // CreatePrivateRoom 创建私聊
@@ -63,11 +79,11 @@ func (h *Handler) CreateGroupRoom(c *gin.Context) {
 
 	var req CreateGroupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
-	room, err := h.chatService.CreateGroupRoom(c, userID, req.Name, req.InitialMembers)
+	room, err := h.chatService.CreateGroupRoom(c, userID, req.Name, req.TopicID, req.InitialMembers)
 	if err != nil {
 		Error(c, err)
 		return
@@ -92,16 +108,40 @@ func (h *Handler) SendMessage(c *gin.Context) {
 
 	var req SendMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+	isMedia := req.ContentType == "image" || req.ContentType == "file"
+	if !isMedia && req.Content == "" {
 		Error(c, service.ErrInvalidRequest)
 		return
 	}
 
+	// 处理引用的已上传文件
+	// 先校验消息类型和成员身份，避免发送失败时句柄已被消费
+	var attachments []*service.UploadHandle
+	if len(req.Attachments) > 0 {
+		if !isMedia {
+			Error(c, service.ErrInvalidRequest)
+			return
+		}
+		if err := h.chatService.CheckRoomMember(c, userID, roomID); err != nil {
+			Error(c, err)
+			return
+		}
+		attachments, err = h.uploadService.ClaimHandles(c, userID, req.Attachments)
+		if err != nil {
+			Error(c, err)
+			return
+		}
+	}
+
 	// 处理媒体文件
 	var files []*model.File
-	if req.ContentType == "image" || req.ContentType == "file" {
+	if len(attachments) == 0 && isMedia {
 		form, err := c.MultipartForm()
 		if err != nil {
-			Error(c, service.ErrInvalidRequest)
+			Error(c, bodyError(err, service.ErrInvalidRequest))
 			return
 		}
 
@@ -122,7 +162,37 @@ func (h *Handler) SendMessage(c *gin.Context) {
 		}
 	}
 
-	message, err := h.chatService.SendMessage(c, userID, roomID, req.ContentType, req.Content, files)
+	message, err := h.chatService.SendMessage(c, userID, roomID, req.ContentType, req.Content, files, attachments)
+	if err != nil {
+		h.uploadService.ReleaseHandles(c, attachments)
+		Error(c, err)
+		return
+	}
+
+	Success(c, message)
+}
+
+// ShareTopic 分享话题到聊天室
+func (h *Handler) ShareTopic(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	roomID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	var req ShareTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+
+	message, err := h.chatService.ShareTopic(c, userID, roomID, req.TopicID)
 	if err != nil {
 		Error(c, err)
 		return
@@ -147,6 +217,7 @@ func (h *Handler) GetMessages(c *gin.Context) {
 
 	var query struct {
 		BeforeID uint64 `form:"before_id"`
+		AfterID  uint64 `form:"after_id"`
 		Limit    int    `form:"limit,default=20" binding:"min=1,max=50"`
 	}
 	if err := c.ShouldBindQuery(&query); err != nil {
@@ -154,7 +225,7 @@ func (h *Handler) GetMessages(c *gin.Context) {
 		return
 	}
 
-	messages, err := h.chatService.GetMessages(c, userID, roomID, query.BeforeID, query.Limit)
+	messages, err := h.chatService.GetMessages(c, userID, roomID, query.BeforeID, query.AfterID, query.Limit)
 	if err != nil {
 		Error(c, err)
 		return
@@ -163,6 +234,80 @@ func (h *Handler) GetMessages(c *gin.Context) {
 	Success(c, messages)
 }
 
+// GetMessageContext 获取指定消息前后的消息
+func (h *Handler) GetMessageContext(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	roomID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	messageID, err := ParseUint64Param(c, "message_id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	var query struct {
+		Before int `form:"before,default=20" binding:"min=0,max=50"`
+		After  int `form:"after,default=20" binding:"min=0,max=50"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	result, err := h.chatService.GetMessageContext(c, userID, roomID, messageID, query.Before, query.After)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, result)
+}
+
+// DeleteMessage 删除消息，scope=me 仅对自己删除，scope=everyone 对所有人删除
+func (h *Handler) DeleteMessage(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	roomID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	messageID, err := ParseUint64Param(c, "message_id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	var query struct {
+		Scope string `form:"scope" binding:"required,oneof=me everyone"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.chatService.DeleteMessage(c, userID, roomID, messageID, query.Scope); err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
 // MarkMessagesAsRead 标记消息为已读
 func (h *Handler) MarkMessagesAsRead(c *gin.Context) {
 	userID := h.GetCurrentUserID(c)
@@ -181,7 +326,7 @@ func (h *Handler) MarkMessagesAsRead(c *gin.Context) {
 		MessageID uint64 `json:"message_id" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
@@ -223,13 +368,19 @@ func (h *Handler) ListRooms(c *gin.Context) {
 
 // GetRoomInfo 获取聊天室信息
 func (h *Handler) GetRoomInfo(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
 	roomID, err := ParseUint64Param(c, "id")
 	if err != nil {
 		Error(c, service.ErrInvalidRequest)
 		return
 	}
 
-	room, err := h.chatService.GetRoomInfo(c, roomID)
+	room, err := h.chatService.GetRoomInfo(c, userID, roomID)
 	if err != nil {
 		Error(c, err)
 		return
@@ -256,7 +407,7 @@ func (h *Handler) AddMember(c *gin.Context) {
 		UserID uint64 `json:"user_id" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
@@ -320,7 +471,7 @@ func (h *Handler) UpdateMemberRole(c *gin.Context) {
 		Role string `json:"role" binding:"required,oneof=owner admin member"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
@@ -332,6 +483,37 @@ func (h *Handler) UpdateMemberRole(c *gin.Context) {
 	Success(c, nil)
 }
 
+// UpdateMyMember 更新自己在群内的昵称，为空时恢复显示用户昵称
+func (h *Handler) UpdateMyMember(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	roomID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	var req struct {
+		Nickname string `json:"nickname" binding:"max=50"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+
+	nickname := strings.TrimSpace(req.Nickname)
+	if err := h.chatService.UpdateMemberNickname(c, roomID, userID, nickname); err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, gin.H{"nickname": nickname})
+}
+
 // PinRoom 置顶聊天室
 func (h *Handler) PinRoom(c *gin.Context) {
 	userID := h.GetCurrentUserID(c)
@@ -398,3 +580,199 @@ func (h *Handler) GetUnreadCount(c *gin.Context) {
 
 	Success(c, gin.H{"unread_count": count})
 }
+
+// LeaveRoom 退出群聊
+func (h *Handler) LeaveRoom(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	roomID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.chatService.LeaveRoom(c, userID, roomID); err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+// LeaveAllGroups 退出所有群聊
+func (h *Handler) LeaveAllGroups(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	result, err := h.chatService.LeaveAllGroups(c, userID)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, result)
+}
+
+// GetLinkPreview 获取链接预览
+func (h *Handler) GetLinkPreview(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	var req LinkPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+
+	preview, err := h.chatService.GetLinkPreview(c, req.URL)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, preview)
+}
+
+// GetSharedRooms 获取与指定用户共同加入的聊天室
+func (h *Handler) GetSharedRooms(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	targetID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 仅好友之间可以查看
+	isFriend, err := h.relationshipService.IsFriend(c, userID, targetID)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+	if !isFriend {
+		Error(c, service.ErrForbidden)
+		return
+	}
+
+	rooms, err := h.chatService.GetSharedRooms(c, userID, targetID)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, gin.H{
+		"rooms": rooms,
+		"total": len(rooms),
+	})
+}
+
+// EnterRoom 进入聊天室(心跳)
+func (h *Handler) EnterRoom(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	roomID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.chatService.EnterRoom(c, userID, roomID); err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, gin.H{"ttl_seconds": int(service.RoomPresenceTTL.Seconds())})
+}
+
+// ExitRoom 离开聊天室页面
+func (h *Handler) ExitRoom(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	roomID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.chatService.ExitRoom(c, userID, roomID); err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+// SetTyping 更新正在输入状态
+func (h *Handler) SetTyping(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	roomID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	var req struct {
+		Typing *bool `json:"typing" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+
+	if err := h.chatService.SetTyping(c, userID, roomID, *req.Typing); err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+// GetRoomPresence 获取聊天室实时状态
+func (h *Handler) GetRoomPresence(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	roomID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	presence, err := h.chatService.GetRoomPresence(c, userID, roomID)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, presence)
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/service"
+	"DistanceBack_v1/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testHandleID = "0b6f6f1e-7d3c-4c3a-9a53-1f2d5c6b7a80"
+
+func newChatTestHandler(userRepo *fakeUserRepo, chatRepo *fakeChatRepo) *Handler {
+	userService := service.NewUserService(userRepo, nil, config.NearbyConfig{}, config.UploadConfig{}, config.ProfileConfig{})
+	chatService := service.NewChatService(chatRepo, nil, userRepo, nil, nil, config.UploadConfig{}, config.ChatConfig{})
+	uploadService := service.NewUploadService(nil)
+	return NewHandler(userService, nil, chatService, nil, nil, uploadService, nil, nil, nil)
+}
+
+// storeHandle 写入属于 userID 的上传句柄
+func storeHandle(t *testing.T, userID uint64) {
+	t.Helper()
+	handle := &service.UploadHandle{
+		ID:        testHandleID,
+		UserID:    userID,
+		MediaType: "image",
+		URL:       "https://example.com/a.jpg",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := cache.Set(cache.UploadHandleKey(handle.ID), handle, time.Hour); err != nil {
+		t.Fatalf("store handle: %v", err)
+	}
+}
+
+func TestSendMessageKeepsHandlesWhenRejected(t *testing.T) {
+	sender := &model.User{Nickname: "sender", Status: model.UserStatusActive}
+	sender.ID = 7
+	userRepo := newFakeUserRepo(sender)
+	userRepo.firebase["fb-sender"] = sender.ID
+
+	tests := []struct {
+		name        string
+		contentType string
+		members     []*model.ChatRoomMember
+		wantStatus  int
+	}{
+		{"text with attachments", "text", []*model.ChatRoomMember{{UserID: 7}}, 400},
+		{"not a member", "image", []*model.ChatRoomMember{{UserID: 8}}, 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCache(t)
+			storeHandle(t, sender.ID)
+			chatRepo := newFakeChatRepo()
+			chatRepo.members[5] = tt.members
+			h := newChatTestHandler(userRepo, chatRepo)
+
+			r := gin.New()
+			r.POST("/chats/:id/messages", withFirebaseUID("fb-sender"), h.SendMessage)
+			body := `{"content_type":"` + tt.contentType + `","content":"hi","attachments":["` + testHandleID + `"]}`
+			req := httptest.NewRequest("POST", "/chats/5/messages", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := serve(r, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			exists, err := cache.Exists(cache.UploadHandleKey(testHandleID))
+			if err != nil || !exists {
+				t.Errorf("handle consumed by rejected send (exists=%v, err=%v)", exists, err)
+			}
+		})
+	}
+}
+
+func TestSendMessageContentRequiredOnlyForText(t *testing.T) {
+	sender := &model.User{Nickname: "sender", Status: model.UserStatusActive}
+	sender.ID = 7
+	userRepo := newFakeUserRepo(sender)
+	userRepo.firebase["fb-sender"] = sender.ID
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"text without content", `{"content_type":"text"}`, 400},
+		{"system without content", `{"content_type":"system","content":""}`, 400},
+		// 通过内容校验后因不是成员被拒绝
+		{"image without content", `{"content_type":"image","attachments":["` + testHandleID + `"]}`, 403},
+		{"file without content", `{"content_type":"file","attachments":["` + testHandleID + `"]}`, 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCache(t)
+			chatRepo := newFakeChatRepo()
+			chatRepo.members[5] = []*model.ChatRoomMember{{UserID: 8}}
+			h := newChatTestHandler(userRepo, chatRepo)
+
+			r := gin.New()
+			r.POST("/chats/:id/messages", withFirebaseUID("fb-sender"), h.SendMessage)
+			req := httptest.NewRequest("POST", "/chats/5/messages", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if w := serve(r, req); w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetSharedRoomsOnlyBetweenFriends(t *testing.T) {
+	viewer := &model.User{Nickname: "viewer", Status: model.UserStatusActive}
+	viewer.ID = 7
+	userRepo := newFakeUserRepo(viewer)
+	userRepo.firebase["fb-viewer"] = viewer.ID
+
+	shared := &model.ChatRoom{Name: "hiking", Type: "group"}
+	shared.ID = 3
+
+	tests := []struct {
+		name       string
+		relations  map[[2]uint64]string
+		wantStatus int
+	}{
+		{"friends", map[[2]uint64]string{{7, 8}: "accepted", {8, 7}: "accepted"}, 200},
+		{"one-way follow", map[[2]uint64]string{{7, 8}: "accepted"}, 403},
+		{"pending follow back", map[[2]uint64]string{{7, 8}: "accepted", {8, 7}: "pending"}, 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCache(t)
+			chatRepo := newFakeChatRepo()
+			chatRepo.shared = []*model.ChatRoom{shared}
+			relationRepo := newFakeRelationRepo()
+			relationRepo.relations = tt.relations
+
+			h := newChatTestHandler(userRepo, chatRepo)
+			h.relationshipService = service.NewRelationshipService(relationRepo, userRepo, h.chatService)
+
+			r := gin.New()
+			r.GET("/users/:id/shared-rooms", withFirebaseUID("fb-viewer"), h.GetSharedRooms)
+			w := serve(r, httptest.NewRequest("GET", "/users/8/shared-rooms", nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+			var data struct {
+				Rooms []model.ChatRoom `json:"rooms"`
+				Total int              `json:"total"`
+			}
+			decodeData(t, w, &data)
+			if data.Total != 1 || data.Rooms[0].ID != shared.ID {
+				t.Errorf("shared rooms = %+v", data)
+			}
+		})
+	}
+}
+
+func TestUpdateMyMemberChangesOnlyOwnNickname(t *testing.T) {
+	me := &model.User{Nickname: "me", Status: model.UserStatusActive}
+	me.ID = 8
+	userRepo := newFakeUserRepo(me)
+	userRepo.firebase["fb-me"] = me.ID
+
+	tests := []struct {
+		name       string
+		members    []*model.ChatRoomMember
+		body       string
+		wantStatus int
+	}{
+		{"member sets nickname", []*model.ChatRoomMember{{UserID: 7, Role: "owner"}, {UserID: 8, Role: "member"}}, `{"nickname":"  Kay  "}`, 200},
+		{"nickname too long", []*model.ChatRoomMember{{UserID: 8, Role: "member"}}, `{"nickname":"` + strings.Repeat("a", 51) + `"}`, 400},
+		{"not a member", []*model.ChatRoomMember{{UserID: 7, Role: "owner"}}, `{"nickname":"Kay"}`, 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCache(t)
+			chatRepo := newFakeChatRepo()
+			chatRepo.members[5] = tt.members
+			h := newChatTestHandler(userRepo, chatRepo)
+
+			r := gin.New()
+			r.PUT("/chats/:id/members/me", withFirebaseUID("fb-me"), h.UpdateMyMember)
+			req := httptest.NewRequest("PUT", "/chats/5/members/me", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := serve(r, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != 200 {
+				if len(chatRepo.updated) != 0 {
+					t.Errorf("updated = %+v, want no change", chatRepo.updated)
+				}
+				return
+			}
+
+			if len(chatRepo.updated) != 1 {
+				t.Fatalf("updated = %+v, want one member saved", chatRepo.updated)
+			}
+			saved := chatRepo.updated[0]
+			if saved.UserID != 8 || saved.Nickname != "Kay" || saved.Role != "member" {
+				t.Errorf("saved = %+v, want own nickname changed and role kept", saved)
+			}
+		})
+	}
+}
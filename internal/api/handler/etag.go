@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SuccessWithETag 返回带 ETag 的成功响应
+// ETag 由响应内容哈希得出，请求头 If-None-Match 匹配时返回 304 且不带响应体
+// 供频繁轮询的读接口按需使用
+func SuccessWithETag(c *gin.Context, data interface{}) {
+	body, err := json.Marshal(Response{
+		Code:    0,
+		Message: "success",
+		Data:    data,
+	})
+	if err != nil {
+		Success(c, data)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// etagMatches 检查 If-None-Match 是否包含指定 ETag，按弱比较忽略 W/ 前缀
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	target := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == target {
+			return true
+		}
+	}
+	return false
+}
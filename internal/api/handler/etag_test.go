@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"DistanceBack_v1/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetTopicNotModifiedOnMatchingETag(t *testing.T) {
+	resetCache(t)
+	userRepo, topicRepo := newTopicFixture()
+	h := newTopicTestHandler(userRepo, topicRepo, config.TopicConfig{})
+
+	r := gin.New()
+	r.GET("/topics/:id", withFirebaseUID("fb-viewer"), h.GetTopic)
+
+	first := serve(r, httptest.NewRequest("GET", "/topics/100", nil))
+	etag := first.Header().Get("ETag")
+	if first.Code != 200 || etag == "" {
+		t.Fatalf("status = %d, etag = %q", first.Code, etag)
+	}
+
+	req := httptest.NewRequest("GET", "/topics/100", nil)
+	req.Header.Set("If-None-Match", etag)
+	w := serve(r, req)
+	if w.Code != 304 {
+		t.Fatalf("status = %d, want 304", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("304 response has body: %s", w.Body.String())
+	}
+	if w.Header().Get("ETag") != etag {
+		t.Errorf("304 etag = %q, want %q", w.Header().Get("ETag"), etag)
+	}
+
+	// 话题内容变化后旧 ETag 失效
+	topicRepo.mu.Lock()
+	topicRepo.topics[100].Title = "updated"
+	topicRepo.mu.Unlock()
+	resetCache(t)
+	req = httptest.NewRequest("GET", "/topics/100", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = serve(r, req)
+	if w.Code != 200 || w.Header().Get("ETag") == etag {
+		t.Errorf("after update status = %d, etag = %q; want 200 with a new etag", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	const etag = `W/"abc"`
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{`W/"abc"`, true},
+		{`"abc"`, true},
+		{`"xyz", W/"abc"`, true},
+		{`"xyz"`, false},
+		{"*", true},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, etag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+)
+
+// fakeUserRepo 内存用户仓储，只实现测试用到的方法
+type fakeUserRepo struct {
+	repository.UserRepository
+	users         map[uint64]*model.User
+	firebase      map[string]uint64
+	activeUpdates int
+	bans          []*model.UserBan
+	banErr        error // GetActiveBan 返回的错误
+	devices       []*model.UserDevice
+	idsQueries    int // GetByIDs 调用次数
+}
+
+func newFakeUserRepo(users ...*model.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: map[uint64]*model.User{}, firebase: map[string]uint64{}}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uint64) (*model.User, error) {
+	if u, ok := r.users[id]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetByIDs(ctx context.Context, ids []uint64) ([]*model.User, error) {
+	r.idsQueries++
+	var users []*model.User
+	for _, id := range ids {
+		if u, ok := r.users[id]; ok {
+			copied := *u
+			users = append(users, &copied)
+		}
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepo) GetByFirebaseUID(ctx context.Context, uid string) (*model.User, error) {
+	if id, ok := r.firebase[uid]; ok {
+		return r.GetByID(ctx, id)
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetActiveBan(ctx context.Context, userID uint64, now time.Time) (*model.UserBan, error) {
+	if r.banErr != nil {
+		return nil, r.banErr
+	}
+	for _, ban := range r.bans {
+		if ban.UserID == userID && ban.Status == "active" && !ban.BanStart.After(now) &&
+			ban.BanEnd != nil && ban.BanEnd.After(now) {
+			return ban, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) UpdateLastActive(ctx context.Context, userID uint64) error {
+	if u, ok := r.users[userID]; ok {
+		now := time.Now()
+		u.LastActiveAt = &now
+		r.activeUpdates++
+	}
+	return nil
+}
+
+// ListActiveDevices 按最近活跃时间倒序返回已登录的设备
+func (r *fakeUserRepo) ListActiveDevices(ctx context.Context, userID uint64) ([]*model.UserDevice, error) {
+	var devices []*model.UserDevice
+	for _, d := range r.devices {
+		if d.UserID == userID && d.IsActive {
+			devices = append(devices, d)
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].LastActiveAt.After(devices[j].LastActiveAt) })
+	return devices, nil
+}
+
+// GetNearbyUsers 忽略距离，只按最后活跃时间过滤
+func (r *fakeUserRepo) GetNearbyUsers(ctx context.Context, lat, lng float64, radius float64, activeSince time.Time, offset, limit int) ([]*model.User, int64, error) {
+	var users []*model.User
+	for _, u := range r.users {
+		if !activeSince.IsZero() && (u.LastActiveAt == nil || u.LastActiveAt.Before(activeSince)) {
+			continue
+		}
+		copied := *u
+		users = append(users, &copied)
+	}
+	return users, int64(len(users)), nil
+}
+
+// fakeTopicRepo 内存话题仓储，只实现测试用到的方法
+type fakeTopicRepo struct {
+	repository.TopicRepository
+	mu           sync.Mutex
+	topics       map[uint64]*model.Topic
+	interactions []*model.TopicInteraction
+	views        map[uint64]int
+	viewCtxErrs  []error
+}
+
+func newFakeTopicRepo(topics ...*model.Topic) *fakeTopicRepo {
+	r := &fakeTopicRepo{topics: map[uint64]*model.Topic{}, views: map[uint64]int{}}
+	for _, t := range topics {
+		r.topics[t.ID] = t
+	}
+	return r
+}
+
+func (r *fakeTopicRepo) GetByID(ctx context.Context, id uint64) (*model.Topic, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.topics[id]; ok {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeTopicRepo) GetUserInteractions(ctx context.Context, topicID, userID uint64) ([]*model.TopicInteraction, error) {
+	var result []*model.TopicInteraction
+	for _, i := range r.interactions {
+		if i.TopicID == topicID && i.UserID == userID && i.InteractionStatus == model.InteractionStatusActive {
+			result = append(result, i)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeTopicRepo) ListRoomParticipants(ctx context.Context, topicID, viewerID uint64, limit int) ([]*model.User, error) {
+	return nil, nil
+}
+
+func (r *fakeTopicRepo) ListRecentInteractors(ctx context.Context, topicID uint64, limit int) ([]*model.User, error) {
+	return nil, nil
+}
+
+func (r *fakeTopicRepo) IncrementViewCount(ctx context.Context, topicID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.views[topicID]++
+	r.viewCtxErrs = append(r.viewCtxErrs, ctx.Err())
+	return nil
+}
+
+func (r *fakeTopicRepo) viewCount(topicID uint64) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.views[topicID]
+}
+
+// fakeChatRepo 内存聊天仓储，只实现测试用到的方法
+type fakeChatRepo struct {
+	repository.ChatRepository
+	members map[uint64][]*model.ChatRoomMember
+	shared  []*model.ChatRoom
+	updated []model.ChatRoomMember // 保存过的成员记录
+}
+
+func newFakeChatRepo() *fakeChatRepo {
+	return &fakeChatRepo{members: map[uint64][]*model.ChatRoomMember{}}
+}
+
+func (r *fakeChatRepo) GetRoomMembers(ctx context.Context, roomID uint64) ([]*model.ChatRoomMember, error) {
+	return r.members[roomID], nil
+}
+
+func (r *fakeChatRepo) UpdateMember(ctx context.Context, member *model.ChatRoomMember) error {
+	r.updated = append(r.updated, *member)
+	return nil
+}
+
+func (r *fakeChatRepo) GetSharedRooms(ctx context.Context, userA, userB uint64) ([]*model.ChatRoom, error) {
+	return r.shared, nil
+}
+
+// fakeRelationRepo 内存关注关系仓储，键为 {关注者, 被关注者}
+type fakeRelationRepo struct {
+	repository.RelationshipRepository
+	relations map[[2]uint64]string
+
+	countQueries int
+}
+
+func newFakeRelationRepo() *fakeRelationRepo {
+	return &fakeRelationRepo{relations: map[[2]uint64]string{}}
+}
+
+func (r *fakeRelationRepo) GetRelationship(ctx context.Context, followerID, followingID uint64) (*model.UserRelationship, error) {
+	status, ok := r.relations[[2]uint64{followerID, followingID}]
+	if !ok {
+		return nil, nil
+	}
+	return &model.UserRelationship{FollowerID: followerID, FollowingID: followingID, Status: status}, nil
+}
+
+func (r *fakeRelationRepo) ListBetween(ctx context.Context, userID uint64, targetIDs []uint64) ([]*model.UserRelationship, error) {
+	var relationships []*model.UserRelationship
+	for _, targetID := range targetIDs {
+		for _, key := range [][2]uint64{{userID, targetID}, {targetID, userID}} {
+			if status, ok := r.relations[key]; ok {
+				relationships = append(relationships, &model.UserRelationship{FollowerID: key[0], FollowingID: key[1], Status: status})
+			}
+		}
+	}
+	return relationships, nil
+}
+
+// GetFollowers 返回关注 userID 的关系，status 为空时不过滤
+func (r *fakeRelationRepo) GetFollowers(ctx context.Context, userID uint64, status string, offset, limit int) ([]*model.UserRelationship, int64, error) {
+	var result []*model.UserRelationship
+	for key, s := range r.relations {
+		if key[1] == userID && (status == "" || s == status) {
+			result = append(result, &model.UserRelationship{FollowerID: key[0], FollowingID: key[1], Status: s})
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+// GetFollowings 返回 userID 关注的关系，status 为空时不过滤
+func (r *fakeRelationRepo) GetFollowings(ctx context.Context, userID uint64, status string, offset, limit int) ([]*model.UserRelationship, int64, error) {
+	var result []*model.UserRelationship
+	for key, s := range r.relations {
+		if key[0] == userID && (status == "" || s == status) {
+			result = append(result, &model.UserRelationship{FollowerID: key[0], FollowingID: key[1], Status: s})
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+// GetCountsByStatus 按关系表统计两个方向各状态的关系数
+func (r *fakeRelationRepo) GetCountsByStatus(ctx context.Context, userID uint64) (*repository.RelationshipCountsByStatus, error) {
+	r.countQueries++
+	var counts repository.RelationshipCountsByStatus
+	for key, status := range r.relations {
+		var target *repository.RelationshipStatusCounts
+		switch userID {
+		case key[1]:
+			target = &counts.Followers
+		case key[0]:
+			target = &counts.Followings
+		default:
+			continue
+		}
+		switch status {
+		case "pending":
+			target.Pending++
+		case "accepted":
+			target.Accepted++
+		case "blocked":
+			target.Blocked++
+		}
+	}
+	return &counts, nil
+}
+
+// GetCounts 按关系表统计已通过的粉丝、关注、好友数
+func (r *fakeRelationRepo) GetCounts(ctx context.Context, userID uint64) (*repository.RelationshipCounts, error) {
+	r.countQueries++
+	var counts repository.RelationshipCounts
+	for key, status := range r.relations {
+		if status != "accepted" {
+			continue
+		}
+		switch userID {
+		case key[1]:
+			counts.Followers++
+		case key[0]:
+			counts.Following++
+			if r.relations[[2]uint64{key[1], key[0]}] == "accepted" {
+				counts.Friends++
+			}
+		}
+	}
+	return &counts, nil
+}
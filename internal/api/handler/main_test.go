@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+var testRedis *miniredis.Miniredis
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.Log = zap.NewNop()
+
+	var err error
+	testRedis, err = miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	cache.RedisClient = redis.NewClient(&redis.Options{Addr: testRedis.Addr()})
+
+	code := m.Run()
+	testRedis.Close()
+	os.Exit(code)
+}
+
+// resetCache 清空测试用 Redis
+func resetCache(t *testing.T) {
+	t.Helper()
+	testRedis.FlushAll()
+}
+
+// withFirebaseUID 模拟认证中间件写入 Firebase UID，uid 为空时视为匿名访问
+func withFirebaseUID(uid string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if uid != "" {
+			c.Set("user_id", uid)
+		}
+		c.Next()
+	}
+}
+
+// serve 发送请求并返回响应
+func serve(r *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// decodeData 解析标准响应中的 data 字段
+func decodeData(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	var resp struct {
+		Code int             `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v, body=%s", err, w.Body.String())
+	}
+	if err := json.Unmarshal(resp.Data, v); err != nil {
+		t.Fatalf("decode data: %v, body=%s", err, w.Body.String())
+	}
+}
@@ -26,12 +26,13 @@ func (h *Handler) Follow(c *gin.Context) {
 		return
 	}
 
-	if err := h.relationshipService.Follow(c, userID, targetID); err != nil {
+	result, err := h.relationshipService.Follow(c, userID, targetID)
+	if err != nil {
 		Error(c, err)
 		return
 	}
 
-	Success(c, nil)
+	Success(c, result)
 }
 
 // Unfollow 取消关注
@@ -70,12 +71,13 @@ func (h *Handler) AcceptFollow(c *gin.Context) {
 		return
 	}
 
-	if err := h.relationshipService.AcceptFollow(c, userID, followerID); err != nil {
+	result, err := h.relationshipService.AcceptFollow(c, userID, followerID)
+	if err != nil {
 		Error(c, err)
 		return
 	}
 
-	Success(c, nil)
+	Success(c, result)
 }
 
 // RejectFollow 拒绝关注请求
@@ -92,22 +94,56 @@ func (h *Handler) RejectFollow(c *gin.Context) {
 		return
 	}
 
-	if err := h.relationshipService.RejectFollow(c, userID, followerID); err != nil {
+	result, err := h.relationshipService.RejectFollow(c, userID, followerID)
+	if err != nil {
 		Error(c, err)
 		return
 	}
 
-	Success(c, nil)
+	Success(c, result)
 }
 
-// GetFollowers 获取粉丝列表
-func (h *Handler) GetFollowers(c *gin.Context) {
-	targetID, err := ParseUint64Param(c, "id")
+// RejectAndBlock 拒绝关注请求并拉黑
+func (h *Handler) RejectAndBlock(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	followerID, err := ParseUint64Param(c, "id")
 	if err != nil {
 		Error(c, service.ErrInvalidRequest)
 		return
 	}
 
+	result, err := h.relationshipService.RejectAndBlock(c, userID, followerID)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, result)
+}
+
+// GetFollowers 获取粉丝列表
+func (h *Handler) GetFollowers(c *gin.Context) {
+	// 未指定用户时查询当前用户
+	currentUserID := h.GetCurrentUserID(c)
+	targetID := currentUserID
+	if c.Param("id") != "" {
+		id, err := ParseUint64Param(c, "id")
+		if err != nil {
+			Error(c, service.ErrInvalidRequest)
+			return
+		}
+		targetID = id
+	}
+	if targetID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
 	var query struct {
 		PaginationQuery
 		Status string `form:"status" binding:"omitempty,oneof=pending accepted"`
@@ -123,19 +159,41 @@ func (h *Handler) GetFollowers(c *gin.Context) {
 		return
 	}
 
-	Success(c, gin.H{
+	result := gin.H{
 		"followers": followers,
 		"total":     total,
 		"page":      query.Page,
 		"size":      query.PageSize,
-	})
+	}
+
+	// 仅本人可查看各状态计数，避免泄露待处理请求和拉黑关系
+	if targetID == currentUserID {
+		counts, err := h.relationshipService.GetRelationshipCountsByStatus(c, targetID)
+		if err != nil {
+			Error(c, err)
+			return
+		}
+		result["status_counts"] = counts.Followers
+	}
+
+	Success(c, result)
 }
 
 // GetFollowings 获取关注列表
 func (h *Handler) GetFollowings(c *gin.Context) {
-	targetID, err := ParseUint64Param(c, "id")
-	if err != nil {
-		Error(c, service.ErrInvalidRequest)
+	// 未指定用户时查询当前用户
+	currentUserID := h.GetCurrentUserID(c)
+	targetID := currentUserID
+	if c.Param("id") != "" {
+		id, err := ParseUint64Param(c, "id")
+		if err != nil {
+			Error(c, service.ErrInvalidRequest)
+			return
+		}
+		targetID = id
+	}
+	if targetID == 0 {
+		Error(c, service.ErrUnauthorized)
 		return
 	}
 
@@ -154,12 +212,24 @@ func (h *Handler) GetFollowings(c *gin.Context) {
 		return
 	}
 
-	Success(c, gin.H{
+	result := gin.H{
 		"followings": followings,
 		"total":      total,
 		"page":       query.Page,
 		"size":       query.PageSize,
-	})
+	}
+
+	// 仅本人可查看各状态计数，避免泄露待处理请求和拉黑关系
+	if targetID == currentUserID {
+		counts, err := h.relationshipService.GetRelationshipCountsByStatus(c, targetID)
+		if err != nil {
+			Error(c, err)
+			return
+		}
+		result["status_counts"] = counts.Followings
+	}
+
+	Success(c, result)
 }
 
 // GetFriends 获取好友列表
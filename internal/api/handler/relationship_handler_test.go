@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newRelationshipTestHandler 用户 7 有各状态的粉丝与关注
+func newRelationshipTestHandler() (*Handler, *fakeRelationRepo) {
+	viewer := &model.User{Nickname: "viewer", Status: model.UserStatusActive}
+	viewer.ID = 7
+	other := &model.User{Nickname: "other", Status: model.UserStatusActive}
+	other.ID = 8
+	userRepo := newFakeUserRepo(viewer, other)
+	userRepo.firebase["fb-viewer"] = viewer.ID
+
+	relationRepo := newFakeRelationRepo()
+	relationRepo.relations = map[[2]uint64]string{
+		{8, 7}:  "accepted",
+		{9, 7}:  "accepted",
+		{10, 7}: "pending",
+		{11, 7}: "blocked",
+		{7, 8}:  "accepted",
+		{7, 12}: "pending",
+		{7, 13}: "blocked",
+		{7, 14}: "blocked",
+	}
+
+	h := newChatTestHandler(userRepo, newFakeChatRepo())
+	h.relationshipService = service.NewRelationshipService(relationRepo, userRepo, h.chatService)
+	return h, relationRepo
+}
+
+type statusCounts struct {
+	Pending  int64 `json:"pending"`
+	Accepted int64 `json:"accepted"`
+	Blocked  int64 `json:"blocked"`
+}
+
+func TestFollowListsIncludeStatusCountsForOwner(t *testing.T) {
+	resetCache(t)
+	h, relationRepo := newRelationshipTestHandler()
+
+	r := gin.New()
+	r.GET("/followers", withFirebaseUID("fb-viewer"), h.GetFollowers)
+	r.GET("/followings", withFirebaseUID("fb-viewer"), h.GetFollowings)
+
+	tests := []struct {
+		path string
+		want statusCounts
+	}{
+		{"/followers?page=1&page_size=20", statusCounts{Pending: 1, Accepted: 2, Blocked: 1}},
+		{"/followings?page=1&page_size=20", statusCounts{Pending: 1, Accepted: 1, Blocked: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			before := relationRepo.countQueries
+			w := serve(r, httptest.NewRequest("GET", tt.path, nil))
+			if w.Code != 200 {
+				t.Fatalf("status = %d, body=%s", w.Code, w.Body.String())
+			}
+			var data struct {
+				StatusCounts *statusCounts `json:"status_counts"`
+			}
+			decodeData(t, w, &data)
+			if data.StatusCounts == nil || *data.StatusCounts != tt.want {
+				t.Errorf("status_counts = %+v, want %+v", data.StatusCounts, tt.want)
+			}
+			if n := relationRepo.countQueries - before; n != 1 {
+				t.Errorf("count queries = %d, want 1", n)
+			}
+		})
+	}
+}
+
+func TestFollowListsHideStatusCountsFromOthers(t *testing.T) {
+	resetCache(t)
+	h, _ := newRelationshipTestHandler()
+
+	r := gin.New()
+	r.GET("/users/:id/followers", withFirebaseUID("fb-viewer"), h.GetFollowers)
+	w := serve(r, httptest.NewRequest("GET", "/users/8/followers?page=1&page_size=20", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d, body=%s", w.Code, w.Body.String())
+	}
+	var data map[string]json.RawMessage
+	decodeData(t, w, &data)
+	if _, ok := data["status_counts"]; ok {
+		t.Errorf("other user's list exposes status_counts: %s", w.Body.String())
+	}
+}
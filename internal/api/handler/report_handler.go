@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"DistanceBack_v1/internal/api/request"
+	"DistanceBack_v1/internal/api/response"
+	"DistanceBack_v1/internal/service"
+	"DistanceBack_v1/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReportReasons 获取可选的举报原因
+// @Summary 获取举报原因
+// @Description 返回客户端可展示的举报原因，requires_detail 为 true 时必须填写说明
+// @Tags 举报
+// @Produce json
+// @Success 200 {object} response.Response{data=[]service.ReportReason}
+// @Router /api/v1/config/report-reasons [get]
+func (h *Handler) GetReportReasons(c *gin.Context) {
+	Success(c, h.reportService.ReportReasons())
+}
+
+// SubmitReport 提交举报
+// @Summary 提交举报
+// @Description 举报用户、话题或消息，原因必须来自举报原因列表
+// @Tags 举报
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param request body request.SubmitReportRequest true "举报内容"
+// @Success 200 {object} response.Response{data=response.ReportResponse}
+// @Failure 400,401,404 {object} response.Response "错误详情"
+// @Router /api/v1/reports [post]
+func (h *Handler) SubmitReport(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req request.SubmitReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+
+	// 3. 提交举报
+	report, err := h.reportService.SubmitReport(c, userID, service.SubmitReportInput{
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+		Detail:     req.Detail,
+	})
+	if err != nil {
+		logger.Error("提交举报失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+			logger.String("target_type", req.TargetType),
+			logger.Uint64("target_id", req.TargetID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, response.ToReportResponse(report))
+}
@@ -1,15 +1,23 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"net/http"
+
 	"DistanceBack_v1/internal/api/request"
 	"DistanceBack_v1/internal/api/response"
 	"DistanceBack_v1/internal/model"
 	"DistanceBack_v1/internal/service"
 	"DistanceBack_v1/pkg/logger"
+	"DistanceBack_v1/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// topicParticipantsPreview 话题详情中返回的参与者数量
+const topicParticipantsPreview = 8
+
 // CreateTopic 创建新话题
 // @Summary 创建话题
 // @Description 创建一个新的话题,支持添加图片和标签
@@ -42,10 +50,14 @@ func (h *Handler) CreateTopic(c *gin.Context) {
 		return
 	}
 
-	// 3. 处理上传的图片
+	// 3. 处理上传的图片，非 multipart 请求视为没有图片
 	var images []*model.File
 	form, err := c.MultipartForm()
-	if err == nil && form != nil && form.File["images"] != nil {
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+	if form != nil && form.File["images"] != nil {
 		files := form.File["images"]
 		images = make([]*model.File, 0, len(files))
 		for _, file := range files {
@@ -70,7 +82,7 @@ func (h *Handler) CreateTopic(c *gin.Context) {
 	}
 
 	// 5. 调用服务创建话题
-	createdTopic, err := h.topicService.CreateTopic(c, userID, topic, images)
+	createdTopic, err := h.topicService.CreateTopic(c, userID, topic, req.Permanent, images)
 	if err != nil {
 		logger.Error("创建话题失败",
 			logger.Any("error", err),
@@ -128,7 +140,7 @@ func (h *Handler) UpdateTopic(c *gin.Context) {
 		logger.Error("更新参数解析失败",
 			logger.Any("error", err),
 			logger.Uint64("topic_id", topicID))
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
@@ -141,7 +153,7 @@ func (h *Handler) UpdateTopic(c *gin.Context) {
 	}
 
 	// 5. 执行更新
-	if err := h.topicService.UpdateTopic(c, userID, topic); err != nil {
+	if err := h.topicService.UpdateTopic(c, userID, topic, req.Permanent); err != nil {
 		logger.Error("更新话题失败",
 			logger.Any("error", err),
 			logger.Uint64("user_id", userID),
@@ -155,7 +167,7 @@ func (h *Handler) UpdateTopic(c *gin.Context) {
 
 // DeleteTopic 删除话题
 // @Summary 删除话题
-// @Description 删除指定的话题(仅话题创建者可操作)
+// @Description 删除指定的话题(仅话题创建者可操作)，宽限期内可恢复
 // @Tags 话题
 // @Accept json
 // @Produce json
@@ -195,6 +207,127 @@ func (h *Handler) DeleteTopic(c *gin.Context) {
 	Success(c, nil)
 }
 
+// RestoreTopic 恢复已删除的话题
+// @Summary 恢复话题
+// @Description 作者在宽限期内恢复已删除的话题，已过期的话题不能恢复
+// @Tags 话题
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path uint64 true "话题ID"
+// @Success 200 {object} response.Response{data=response.TopicResponse} "恢复后的话题"
+// @Failure 400,401,403,404 {object} response.Response "错误详情"
+// @Router /api/v1/topics/{id}/restore [post]
+func (h *Handler) RestoreTopic(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 获取话题ID
+	topicID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 3. 恢复话题
+	topic, err := h.topicService.RestoreTopic(c, userID, topicID)
+	if err != nil {
+		logger.Error("恢复话题失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+			logger.Uint64("topic_id", topicID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, response.ToTopicResponse(topic))
+}
+
+// TransferTopic 转移话题作者
+// @Summary 转移话题
+// @Description 将话题转移给其他用户(仅管理员可操作)，操作记入审计日志
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path uint64 true "话题ID"
+// @Param request body request.TransferTopicRequest true "新作者"
+// @Success 200 {object} response.Response "转移成功"
+// @Failure 400,401,403,404 {object} response.Response "错误详情"
+// @Router /api/v1/admin/topics/{id}/transfer [post]
+func (h *Handler) TransferTopic(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 获取参数
+	topicID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		logger.Error("话题ID解析失败",
+			logger.Any("error", err),
+			logger.String("id", c.Param("id")))
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	var req request.TransferTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+
+	// 3. 执行转移
+	if err := h.topicService.TransferTopic(c, userID, topicID, req.NewOwnerID); err != nil {
+		logger.Error("转移话题失败",
+			logger.Any("error", err),
+			logger.Uint64("operator_id", userID),
+			logger.Uint64("topic_id", topicID),
+			logger.Uint64("new_owner_id", req.NewOwnerID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+// PurgeClosedTopics 批量删除自己已关闭的话题
+// @Summary 清理已关闭话题
+// @Description 彻底删除当前用户关闭时间已超过恢复宽限期的话题(含图片、标签关联与互动)，过期话题被关闭后同样要等宽限期结束
+// @Tags 话题
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} response.Response "删除数量"
+// @Failure 401,500 {object} response.Response "错误详情"
+// @Router /api/v1/topics/closed [delete]
+func (h *Handler) PurgeClosedTopics(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 执行清理
+	purged, err := h.topicService.PurgeMyClosedTopics(c, userID)
+	if err != nil {
+		logger.Error("清理已关闭话题失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, gin.H{"purged": purged})
+}
+
 // GetTopic 获取话题详情
 // @Summary 获取话题详情
 // @Description 获取指定话题的详细信息
@@ -214,8 +347,19 @@ func (h *Handler) GetTopic(c *gin.Context) {
 		return
 	}
 
-	// 2. 异步增加浏览次数
-	go h.topicService.ViewTopic(c, topicID)
+	// 2. 自动计数模式下异步增加浏览次数，否则由客户端调用浏览接口
+	// gin.Context 在请求结束后会被复用，异步任务使用不随请求取消的独立上下文
+	if h.topicService.AutoCountViews() {
+		viewerID := h.GetCurrentUserID(c)
+		ctx := context.WithoutCancel(c.Request.Context())
+		go func() {
+			if _, err := h.topicService.ViewTopic(ctx, viewerID, topicID); err != nil {
+				logger.Warn("增加话题浏览次数失败",
+					logger.Any("error", err),
+					logger.Uint64("topic_id", topicID))
+			}
+		}()
+	}
 
 	// 3. 获取话题信息
 	topic, err := h.topicService.GetTopicByID(c, topicID)
@@ -232,18 +376,195 @@ func (h *Handler) GetTopic(c *gin.Context) {
 		return
 	}
 
-	// 4. 获取当前用户的互动状态(如果已登录)
-	userID := h.GetCurrentUserID(c)
-	var interaction *model.TopicInteraction
-	if userID != 0 {
-		interactions, _ := h.topicService.GetInteractions(c, topicID, "")
-		if len(interactions) > 0 {
-			interaction = interactions[0]
+	// 4. 获取当前用户的互动状态(仅登录用户)
+	var interactions []*model.TopicInteraction
+	if userID := h.GetCurrentUserID(c); userID != 0 {
+		interactions, err = h.topicService.GetUserInteractions(c, userID, topicID)
+		if err != nil {
+			logger.Warn("获取用户互动状态失败",
+				logger.Any("error", err),
+				logger.Uint64("topic_id", topicID),
+				logger.Uint64("user_id", userID))
+			interactions = nil
+		} else if interactions == nil {
+			interactions = []*model.TopicInteraction{}
 		}
 	}
 
-	// 5. 转换并返回响应
-	Success(c, response.ToTopicDetailResponse(topic, interaction))
+	// 5. 获取参与者预览，只有群成员能看到群成员列表，失败时不影响话题详情
+	participants, err := h.topicService.GetTopicParticipants(c, topicID, h.GetCurrentUserID(c), topicParticipantsPreview)
+	if err != nil {
+		logger.Warn("获取话题参与者失败",
+			logger.Any("error", err),
+			logger.Uint64("topic_id", topicID))
+	}
+
+	// 6. 转换并返回响应，私有目录的图片返回签名地址
+	h.topicService.ResolveTopicImages(c, topic)
+	SuccessWithETag(c, response.ToTopicDetailResponse(topic, interactions, participants))
+}
+
+// ViewTopic 记录话题浏览
+// @Summary 记录话题浏览
+// @Description 客户端实际展示话题时调用，同一用户在去重窗口内只计一次
+// @Tags 话题
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path uint64 true "话题ID"
+// @Success 200 {object} response.Response "是否计数"
+// @Failure 400,401,404 {object} response.Response "错误详情"
+// @Router /api/v1/topics/{id}/view [post]
+func (h *Handler) ViewTopic(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 获取话题ID
+	topicID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 3. 检查话题是否存在
+	topic, err := h.topicService.GetTopicByID(c, topicID)
+	if err != nil {
+		logger.Error("获取话题失败",
+			logger.Any("error", err),
+			logger.Uint64("topic_id", topicID))
+		Error(c, err)
+		return
+	}
+	if topic == nil {
+		Error(c, service.ErrTopicNotFound)
+		return
+	}
+
+	// 4. 记录浏览
+	counted, err := h.topicService.ViewTopic(c, userID, topicID)
+	if err != nil {
+		logger.Error("记录话题浏览失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+			logger.Uint64("topic_id", topicID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, gin.H{"counted": counted})
+}
+
+// PinTopic 置顶话题到个人主页
+// @Summary 置顶话题到个人主页
+// @Description 只能置顶自己发布的有效话题，数量有上限
+// @Tags 话题
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path uint64 true "话题ID"
+// @Success 200 {object} response.Response "置顶成功"
+// @Failure 400,401,403,404 {object} response.Response "错误详情"
+// @Router /api/v1/topics/{id}/pin [post]
+func (h *Handler) PinTopic(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 获取话题ID
+	topicID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 3. 置顶话题
+	if err := h.topicService.PinTopicToProfile(c, userID, topicID); err != nil {
+		logger.Error("置顶话题失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+			logger.Uint64("topic_id", topicID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+// UnpinTopic 取消话题的主页置顶
+// @Summary 取消话题的主页置顶
+// @Tags 话题
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path uint64 true "话题ID"
+// @Success 200 {object} response.Response "取消成功"
+// @Failure 400,401 {object} response.Response "错误详情"
+// @Router /api/v1/topics/{id}/pin [delete]
+func (h *Handler) UnpinTopic(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 获取话题ID
+	topicID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 3. 取消置顶
+	if err := h.topicService.UnpinTopicFromProfile(c, userID, topicID); err != nil {
+		logger.Error("取消置顶话题失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+			logger.Uint64("topic_id", topicID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+// GetPinnedTopics 获取用户主页置顶的话题
+// @Summary 获取用户主页置顶的话题
+// @Description 按置顶时间倒序返回，已关闭或过期的话题不展示
+// @Tags 话题
+// @Accept json
+// @Produce json
+// @Param id path uint64 true "用户ID"
+// @Success 200 {object} response.Response{data=[]response.TopicResponse} "置顶话题"
+// @Failure 400 {object} response.Response "错误详情"
+// @Router /api/v1/users/{id}/pinned-topics [get]
+func (h *Handler) GetPinnedTopics(c *gin.Context) {
+	// 1. 获取目标用户ID
+	targetUserID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 2. 获取置顶话题
+	topics, err := h.topicService.GetProfilePinnedTopics(c, targetUserID)
+	if err != nil {
+		logger.Error("获取置顶话题失败",
+			logger.Any("error", err),
+			logger.Uint64("target_user_id", targetUserID))
+		Error(c, err)
+		return
+	}
+
+	// 3. 转换并返回响应
+	Success(c, response.ToTopicResponses(topics))
 }
 
 // ListTopics 获取话题列表
@@ -254,8 +575,11 @@ func (h *Handler) GetTopic(c *gin.Context) {
 // @Produce json
 // @Param page query int true "页码" minimum(1)
 // @Param page_size query int true "每页大小" minimum(1) maximum(100)
+// @Param sort_by query string false "排序方式(recent/popular)"
 // @Param tag_id query uint64 false "标签ID"
 // @Param user_id query uint64 false "用户ID"
+// @Param start_date query string false "创建日期起(YYYY-MM-DD，含)"
+// @Param end_date query string false "创建日期止(YYYY-MM-DD，含)"
 // @Success 200 {object} response.Response{data=response.TopicListResponse} "话题列表"
 // @Failure 400 {object} response.Response "错误详情"
 // @Router /api/v1/topics [get]
@@ -267,8 +591,19 @@ func (h *Handler) ListTopics(c *gin.Context) {
 		return
 	}
 
+	createdFrom, createdTo, err := utils.ParseDateRange(query.StartDate, query.EndDate)
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
 	// 2. 获取话题列表
-	topics, total, err := h.topicService.ListTopics(c, query.Page, query.PageSize)
+	filter := service.TopicListFilter{
+		SortBy:      query.SortBy,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+	}
+	topics, total, err := h.topicService.ListTopics(c, filter, query.Page, query.PageSize)
 	if err != nil {
 		logger.Error("获取话题列表失败",
 			logger.Any("error", err),
@@ -281,6 +616,41 @@ func (h *Handler) ListTopics(c *gin.Context) {
 	Success(c, response.ToTopicListResponse(topics, total, query.Page, query.PageSize))
 }
 
+// SearchTopics 搜索话题
+// @Summary 搜索话题
+// @Description 按标题/内容或作者昵称搜索进行中的话题
+// @Tags 话题
+// @Accept json
+// @Produce json
+// @Param keyword query string true "关键词"
+// @Param by query string false "搜索范围(content/author)"
+// @Param page query int true "页码" minimum(1)
+// @Param page_size query int true "每页大小" minimum(1) maximum(100)
+// @Success 200 {object} response.Response{data=response.TopicListResponse} "话题列表"
+// @Failure 400 {object} response.Response "错误详情"
+// @Router /api/v1/topics/search [get]
+func (h *Handler) SearchTopics(c *gin.Context) {
+	// 1. 获取查询参数
+	var query request.TopicSearchRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 2. 搜索话题
+	topics, total, err := h.topicService.SearchTopics(c, query.Keyword, query.By, query.Page, query.PageSize)
+	if err != nil {
+		logger.Error("搜索话题失败",
+			logger.Any("error", err),
+			logger.Any("query", query))
+		Error(c, err)
+		return
+	}
+
+	// 3. 转换并返回响应
+	Success(c, response.ToTopicListResponse(topics, total, query.Page, query.PageSize))
+}
+
 // ListUserTopics 获取用户的话题列表
 // @Summary 获取用户话题列表
 // @Description 分页获取指定用户发布的所有话题
@@ -288,6 +658,9 @@ func (h *Handler) ListTopics(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint64 true "用户ID"
+// @Param sort_by query string false "排序方式(recent/popular)"
+// @Param start_date query string false "创建日期起(YYYY-MM-DD，含)"
+// @Param end_date query string false "创建日期止(YYYY-MM-DD，含)"
 // @Param page query int true "页码" minimum(1)
 // @Param page_size query int true "每页大小" minimum(1) maximum(100)
 // @Success 200 {object} response.Response{data=response.TopicListResponse} "话题列表"
@@ -314,8 +687,38 @@ func (h *Handler) ListUserTopics(c *gin.Context) {
 		return
 	}
 
+	var query struct {
+		request.TopicSort
+		request.CreatedBetween
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	createdFrom, createdTo, err := utils.ParseDateRange(query.StartDate, query.EndDate)
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	filter := service.TopicListFilter{
+		SortBy:      query.SortBy,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+	}
+
+	// 作者本人和管理员可以看到已关闭和已过期的话题
+	if currentUserID := h.GetCurrentUserID(c); currentUserID != 0 {
+		if currentUserID == targetUserID {
+			filter.IncludeInactive = true
+		} else if isAdmin, err := h.userService.IsAdmin(c, currentUserID); err == nil && isAdmin {
+			filter.IncludeInactive = true
+		}
+	}
+
 	// 3. 获取用户话题列表
-	topics, total, err := h.topicService.ListUserTopics(c, targetUserID, pagination.Page, pagination.PageSize)
+	topics, total, err := h.topicService.ListUserTopics(c, targetUserID, filter, pagination.Page, pagination.PageSize)
 	if err != nil {
 		logger.Error("获取用户话题列表失败",
 			logger.Any("error", err),
@@ -340,6 +743,7 @@ func (h *Handler) ListUserTopics(c *gin.Context) {
 // @Param latitude query number true "纬度" minimum(-90) maximum(90)
 // @Param longitude query number true "经度" minimum(-180) maximum(180)
 // @Param radius query number true "范围(米)" minimum(0) maximum(50000)
+// @Param sort_by query string false "排序方式(recent/popular)"
 // @Param page query int true "页码" minimum(1)
 // @Param page_size query int true "每页大小" minimum(1) maximum(100)
 // @Success 200 {object} response.Response{data=response.TopicListResponse} "话题列表"
@@ -359,6 +763,8 @@ func (h *Handler) GetNearbyTopics(c *gin.Context) {
 		query.Latitude,
 		query.Longitude,
 		query.Radius,
+		query.SortBy,
+		query.ActiveWithin,
 		query.Page,
 		query.PageSize,
 	)
@@ -407,7 +813,7 @@ func (h *Handler) AddTopicImage(c *gin.Context) {
 		logger.Error("获取上传文件失败",
 			logger.Any("error", err),
 			logger.Uint64("topic_id", topicID))
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
@@ -563,6 +969,177 @@ func (h *Handler) GetTopicInteractions(c *gin.Context) {
 	Success(c, response.ToTopicInteractionsResponse(interactions))
 }
 
+// ListInteractedTopics 获取当前用户互动过的话题
+// @Summary 获取互动过的话题
+// @Description 按互动时间倒序游标分页获取当前用户点赞、收藏或分享过的有效话题
+// @Tags 话题
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param type query string true "互动类型(like/favorite/share)"
+// @Param cursor query uint64 false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20" minimum(1) maximum(100)
+// @Success 200 {object} response.Response{data=response.TopicCursorListResponse} "话题列表"
+// @Failure 400,401 {object} response.Response "错误详情"
+// @Router /api/v1/topics/interacted [get]
+func (h *Handler) ListInteractedTopics(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 获取参数
+	var req request.InteractedTopicsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 3. 获取话题列表
+	page, err := h.topicService.ListInteractedTopics(c, userID, req.Type, req.Cursor, req.Limit)
+	if err != nil {
+		logger.Error("获取互动话题失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+			logger.String("type", req.Type))
+		Error(c, err)
+		return
+	}
+
+	Success(c, response.ToTopicCursorListResponse(page.Topics, page.NextCursor, page.HasMore))
+}
+
+// GetTopicLikers 获取话题点赞用户
+// @Summary 获取话题点赞用户
+// @Description 按点赞时间倒序游标分页获取点赞用户，不返回与当前用户存在拉黑关系的用户
+// @Tags 话题
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path uint64 true "话题ID"
+// @Param cursor query uint64 false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20" minimum(1) maximum(100)
+// @Success 200 {object} response.Response{data=response.TopicLikersResponse} "点赞用户"
+// @Failure 400,401,404 {object} response.Response "错误详情"
+// @Router /api/v1/topics/{id}/likers [get]
+func (h *Handler) GetTopicLikers(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 获取参数
+	topicID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		logger.Error("解析话题ID失败",
+			logger.Any("error", err),
+			logger.String("id", c.Param("id")))
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	var req request.TopicLikersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 3. 获取点赞用户
+	page, err := h.topicService.GetLikers(c, userID, topicID, req.Cursor, req.Limit)
+	if err != nil {
+		logger.Error("获取话题点赞用户失败",
+			logger.Any("error", err),
+			logger.Uint64("topic_id", topicID),
+			logger.Uint64("cursor", req.Cursor))
+		Error(c, err)
+		return
+	}
+
+	Success(c, response.ToTopicLikersResponse(page.Users, page.NextCursor, page.HasMore))
+}
+
+// GetTopicStats 获取话题统计
+// @Summary 获取话题统计
+// @Description 获取话题最近若干天的每日浏览数和点赞数，仅话题作者和管理员可查看
+// @Tags 话题
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path uint64 true "话题ID"
+// @Param days query int false "统计天数，默认7" minimum(1) maximum(90)
+// @Success 200 {object} response.Response{data=response.TopicStatsResponse} "每日统计"
+// @Failure 400,401,403,404 {object} response.Response "错误详情"
+// @Router /api/v1/topics/{id}/stats [get]
+func (h *Handler) GetTopicStats(c *gin.Context) {
+	// 1. 身份验证
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 获取参数
+	topicID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		logger.Error("解析话题ID失败",
+			logger.Any("error", err),
+			logger.String("id", c.Param("id")))
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	var req request.TopicStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	// 3. 检查权限，仅作者和管理员可查看
+	topic, err := h.topicService.GetTopicByID(c, topicID)
+	if err != nil {
+		logger.Error("获取话题失败",
+			logger.Any("error", err),
+			logger.Uint64("topic_id", topicID))
+		Error(c, err)
+		return
+	}
+	if topic == nil {
+		Error(c, service.ErrTopicNotFound)
+		return
+	}
+	if topic.UserID != userID {
+		isAdmin, err := h.userService.IsAdmin(c, userID)
+		if err != nil {
+			logger.Error("检查管理员权限失败",
+				logger.Any("error", err),
+				logger.Uint64("user_id", userID))
+			Error(c, err)
+			return
+		}
+		if !isAdmin {
+			Error(c, service.ErrForbidden)
+			return
+		}
+	}
+
+	// 4. 获取统计数据
+	stats, err := h.topicService.GetTopicStats(c, topicID, req.Days)
+	if err != nil {
+		logger.Error("获取话题统计失败",
+			logger.Any("error", err),
+			logger.Uint64("topic_id", topicID),
+			logger.Int("days", req.Days))
+		Error(c, err)
+		return
+	}
+
+	Success(c, response.ToTopicStatsResponse(topicID, stats))
+}
+
 // isValidInteractionType 验证互动类型是否有效
 func isValidInteractionType(t string) bool {
 	return t == "like" || t == "favorite" || t == "share"
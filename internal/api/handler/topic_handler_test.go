@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTopicTestHandler(userRepo *fakeUserRepo, topicRepo *fakeTopicRepo, cfg config.TopicConfig) *Handler {
+	userService := service.NewUserService(userRepo, nil, config.NearbyConfig{}, config.UploadConfig{}, config.ProfileConfig{})
+	topicService := service.NewTopicService(topicRepo, userRepo, nil, nil, cfg, config.NearbyConfig{}, config.UploadConfig{})
+	return NewHandler(userService, topicService, nil, nil, nil, nil, nil, nil, nil)
+}
+
+func newTopicFixture() (*fakeUserRepo, *fakeTopicRepo) {
+	author := &model.User{Nickname: "author", Status: model.UserStatusActive}
+	author.ID = 1
+	viewer := &model.User{Nickname: "viewer", Status: model.UserStatusActive}
+	viewer.ID = 7
+	userRepo := newFakeUserRepo(author, viewer)
+	userRepo.firebase["fb-viewer"] = viewer.ID
+
+	topic := &model.Topic{UserID: author.ID, Title: "hello", Status: model.TopicStatusActive, User: *author}
+	topic.ID = 100
+	topicRepo := newFakeTopicRepo(topic)
+	like := &model.TopicInteraction{
+		TopicID:           topic.ID,
+		UserID:            viewer.ID,
+		InteractionType:   model.InteractionTypeLike,
+		InteractionStatus: model.InteractionStatusActive,
+	}
+	topicRepo.interactions = append(topicRepo.interactions, like)
+	return userRepo, topicRepo
+}
+
+func TestGetTopicAnonymousOmitsUserInteraction(t *testing.T) {
+	resetCache(t)
+	userRepo, topicRepo := newTopicFixture()
+	h := newTopicTestHandler(userRepo, topicRepo, config.TopicConfig{})
+
+	r := gin.New()
+	r.GET("/topics/:id", withFirebaseUID(""), h.GetTopic)
+	w := serve(r, httptest.NewRequest("GET", "/topics/100", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d, body=%s", w.Code, w.Body.String())
+	}
+
+	var data map[string]json.RawMessage
+	decodeData(t, w, &data)
+	if _, ok := data["user_interaction"]; ok {
+		t.Errorf("anonymous response should not contain user_interaction: %s", w.Body.String())
+	}
+	if string(data["title"]) != `"hello"` {
+		t.Errorf("title = %s", data["title"])
+	}
+}
+
+func TestGetTopicLoggedInIncludesUserInteraction(t *testing.T) {
+	resetCache(t)
+	userRepo, topicRepo := newTopicFixture()
+	h := newTopicTestHandler(userRepo, topicRepo, config.TopicConfig{})
+
+	r := gin.New()
+	r.GET("/topics/:id", withFirebaseUID("fb-viewer"), h.GetTopic)
+	w := serve(r, httptest.NewRequest("GET", "/topics/100", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d, body=%s", w.Code, w.Body.String())
+	}
+
+	var data struct {
+		HasLiked        bool `json:"has_liked"`
+		UserInteraction *struct {
+			IsLiked     bool `json:"is_liked"`
+			IsFavorited bool `json:"is_favorited"`
+		} `json:"user_interaction"`
+	}
+	decodeData(t, w, &data)
+	if data.UserInteraction == nil {
+		t.Fatalf("logged-in response should contain user_interaction: %s", w.Body.String())
+	}
+	if !data.UserInteraction.IsLiked || !data.HasLiked || data.UserInteraction.IsFavorited {
+		t.Errorf("unexpected interaction state: %s", w.Body.String())
+	}
+}
+
+func TestGetTopicNotFound(t *testing.T) {
+	resetCache(t)
+	userRepo, topicRepo := newTopicFixture()
+	h := newTopicTestHandler(userRepo, topicRepo, config.TopicConfig{})
+
+	r := gin.New()
+	r.GET("/topics/:id", withFirebaseUID(""), h.GetTopic)
+	w := serve(r, httptest.NewRequest("GET", "/topics/404", nil))
+	if w.Code != 404 {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestGetTopicExplicitModeDoesNotCountView(t *testing.T) {
+	resetCache(t)
+	userRepo, topicRepo := newTopicFixture()
+	h := newTopicTestHandler(userRepo, topicRepo, config.TopicConfig{ViewMode: service.TopicViewModeExplicit})
+
+	r := gin.New()
+	r.GET("/topics/:id", withFirebaseUID("fb-viewer"), h.GetTopic)
+	r.POST("/topics/:id/view", withFirebaseUID("fb-viewer"), h.ViewTopic)
+	for i := 0; i < 2; i++ {
+		if w := serve(r, httptest.NewRequest("GET", "/topics/100", nil)); w.Code != 200 {
+			t.Fatalf("status = %d, body=%s", w.Code, w.Body.String())
+		}
+	}
+	if got := topicRepo.viewCount(100); got != 0 {
+		t.Fatalf("GET alone counted %d views, want 0", got)
+	}
+
+	if w := serve(r, httptest.NewRequest("POST", "/topics/100/view", nil)); w.Code != 200 {
+		t.Fatalf("view status = %d, body=%s", w.Code, w.Body.String())
+	}
+	if got := topicRepo.viewCount(100); got != 1 {
+		t.Errorf("explicit view counted %d, want 1", got)
+	}
+}
+
+func TestGetTopicAutoModeCountsViewAfterRequestEnds(t *testing.T) {
+	resetCache(t)
+	userRepo, topicRepo := newTopicFixture()
+	h := newTopicTestHandler(userRepo, topicRepo, config.TopicConfig{ViewMode: service.TopicViewModeAuto})
+
+	r := gin.New()
+	r.GET("/topics/:id", withFirebaseUID("fb-viewer"), h.GetTopic)
+	ctx, cancel := context.WithCancel(context.Background())
+	w := serve(r, httptest.NewRequest("GET", "/topics/100", nil).WithContext(ctx))
+	cancel()
+	if w.Code != 200 {
+		t.Fatalf("status = %d, body=%s", w.Code, w.Body.String())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for topicRepo.viewCount(100) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := topicRepo.viewCount(100); got != 1 {
+		t.Fatalf("auto mode counted %d views, want 1", got)
+	}
+	topicRepo.mu.Lock()
+	defer topicRepo.mu.Unlock()
+	if err := topicRepo.viewCtxErrs[0]; err != nil {
+		t.Errorf("view counted on a cancelled context: %v", err)
+	}
+}
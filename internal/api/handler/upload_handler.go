@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"net/http"
+
+	"DistanceBack_v1/internal/api/request"
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/service"
+	"DistanceBack_v1/pkg/logger"
+	"DistanceBack_v1/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadFile 上传文件
+// @Summary 上传文件
+// @Description 上传文件并返回临时句柄，发送消息时可通过 attachments 引用
+// @Tags 上传
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "文件"
+// @Param type formData string true "文件类型(image/file)"
+// @Success 200 {object} Response{data=service.UploadHandle}
+// @Router /uploads [post]
+func (h *Handler) UploadFile(c *gin.Context) {
+	// 1. 获取当前用户
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 读取文件
+	mediaType := c.PostForm("type")
+	if mediaType != "image" && mediaType != "file" {
+		Error(c, service.ErrInvalidFileType)
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		Error(c, bodyError(err, service.ErrInvalidFile))
+		return
+	}
+
+	// 3. 上传并生成句柄
+	handle, err := h.uploadService.Upload(c, userID, &model.File{
+		File: file,
+		Type: mediaType,
+		Name: file.Filename,
+		Size: uint(file.Size),
+	})
+	if err != nil {
+		logger.Error("上传文件失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, handle)
+}
+
+// InitChunkedUpload 创建分片上传
+// @Summary 创建分片上传
+// @Description 创建分片上传会话，会话在有效期内未完成会被清理
+// @Tags 上传
+// @Accept json
+// @Produce json
+// @Param request body request.InitChunkedUploadRequest true "文件信息"
+// @Success 200 {object} Response{data=service.UploadSession}
+// @Router /uploads/init [post]
+func (h *Handler) InitChunkedUpload(c *gin.Context) {
+	// 1. 获取当前用户
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req request.InitChunkedUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+
+	// 3. 创建上传会话
+	session, err := h.uploadService.InitChunkedUpload(c, userID, req.Type, req.FileName, req.TotalSize, req.ChunkSize)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, session)
+}
+
+// UploadChunk 上传分片
+// @Summary 上传分片
+// @Description 请求体为分片原始内容，同一分片可重复上传
+// @Tags 上传
+// @Accept application/octet-stream
+// @Produce json
+// @Param id path string true "上传会话ID"
+// @Param index query int true "分片序号(从0开始)"
+// @Success 200 {object} Response
+// @Router /uploads/{id}/chunk [put]
+func (h *Handler) UploadChunk(c *gin.Context) {
+	// 1. 获取当前用户
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req request.UploadChunkRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	size := c.Request.ContentLength
+	if size <= 0 {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+	if size > storage.MaxChunkSize {
+		Error(c, service.ErrFileTooLarge)
+		return
+	}
+
+	// 3. 写入分片
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, storage.MaxChunkSize)
+	if err := h.uploadService.UploadChunk(c, userID, c.Param("id"), *req.Index, size, body); err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+// CompleteChunkedUpload 完成分片上传
+// @Summary 完成分片上传
+// @Description 合并全部分片，返回可在发送消息时引用的上传句柄
+// @Tags 上传
+// @Produce json
+// @Param id path string true "上传会话ID"
+// @Success 200 {object} Response{data=service.UploadHandle}
+// @Router /uploads/{id}/complete [post]
+func (h *Handler) CompleteChunkedUpload(c *gin.Context) {
+	// 1. 获取当前用户
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	// 2. 合并分片
+	handle, err := h.uploadService.CompleteChunkedUpload(c, userID, c.Param("id"))
+	if err != nil {
+		logger.Error("完成分片上传失败",
+			logger.Any("error", err),
+			logger.String("session_id", c.Param("id")))
+		Error(c, err)
+		return
+	}
+
+	Success(c, handle)
+}
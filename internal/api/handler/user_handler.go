@@ -27,7 +27,7 @@ func (h *Handler) RegisterUser(c *gin.Context) {
 	var req request.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Error("请求数据绑定失败", logger.Any("error", err))
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
@@ -79,7 +79,44 @@ func (h *Handler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	Success(c, response.ToResponse(user))
+	SuccessWithETag(c, response.ToResponse(user))
+}
+
+// GetMe 获取当前用户概览
+// @Summary 获取当前用户概览
+// @Description 一次返回个人资料、统计数据、未读消息总数、待处理关注请求数及功能开关
+// @Tags 用户管理
+// @Produce json
+// @Success 200 {object} response.Response{data=response.MeResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Router /api/v1/me [get]
+func (h *Handler) GetMe(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	overview, err := h.meService.GetOverview(c, userID)
+	if err != nil {
+		logger.Error("获取用户概览失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID))
+		Error(c, err)
+		return
+	}
+
+	Success(c, &response.MeResponse{
+		Profile: response.ToResponse(overview.Profile),
+		Stats: response.MeStats{
+			TopicsCount:    overview.Stats.TopicsCount,
+			FollowersCount: overview.Stats.FollowersCount,
+			FollowingCount: overview.Stats.FollowingCount,
+		},
+		TotalUnread:           overview.TotalUnread,
+		PendingFollowRequests: overview.PendingFollowRequests,
+		Features:              overview.Features,
+	})
 }
 
 // UpdateProfile 更新用户个人资料
@@ -101,7 +138,7 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 
 	var req request.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
@@ -137,7 +174,7 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 // @Accept multipart/form-data
 // @Produce json
 // @Param avatar formData file true "头像文件"
-// @Success 200 {object} response.Response{data=response.UserResponse}
+// @Success 200 {object} response.Response{data=response.AvatarUpdateResponse}
 // @Failure 400,401 {object} response.ErrorResponse
 // @Router /api/v1/users/avatar [put]
 func (h *Handler) UpdateAvatar(c *gin.Context) {
@@ -149,7 +186,7 @@ func (h *Handler) UpdateAvatar(c *gin.Context) {
 
 	file, err := c.FormFile("avatar")
 	if err != nil {
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
@@ -158,7 +195,8 @@ func (h *Handler) UpdateAvatar(c *gin.Context) {
 		Type: "avatar",
 	}
 
-	if err := h.userService.UpdateAvatar(c, userID, avatarFile); err != nil {
+	avatar, err := h.userService.UpdateAvatar(c, userID, avatarFile)
+	if err != nil {
 		Error(c, err)
 		return
 	}
@@ -170,7 +208,11 @@ func (h *Handler) UpdateAvatar(c *gin.Context) {
 		return
 	}
 
-	Success(c, response.ToResponse(updatedUser))
+	Success(c, &response.AvatarUpdateResponse{
+		UserResponse: response.ToResponse(updatedUser),
+		AvatarWidth:  avatar.Width,
+		AvatarHeight: avatar.Height,
+	})
 }
 
 // UpdateLocation 更新用户位置信息
@@ -192,7 +234,7 @@ func (h *Handler) UpdateLocation(c *gin.Context) {
 
 	var req request.UpdateLocationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
@@ -249,16 +291,114 @@ func (h *Handler) GetUserProfile(c *gin.Context) {
 		}
 	}
 
-	Success(c, response.ToResponse(user))
+	SuccessWithETag(c, response.ToResponse(user))
+}
+
+// GetRelationshipCounts 获取用户的粉丝、关注及好友数
+// @Summary 获取关系计数
+// @Tags 用户管理
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=response.RelationshipCountsResponse}
+// @Failure 400,401,403,404 {object} response.ErrorResponse
+// @Router /api/v1/users/{id}/relationship-counts [get]
+func (h *Handler) GetRelationshipCounts(c *gin.Context) {
+	targetID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	currentUserID := h.GetCurrentUserID(c)
+	user, err := h.userService.GetUserByID(c, targetID)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+	if user == nil {
+		Error(c, service.ErrUserNotFound)
+		return
+	}
+
+	// 检查隐私设置，与查看资料的规则一致
+	if user.PrivacyLevel != "public" && currentUserID != targetID {
+		isFriend, err := h.relationshipService.IsFriend(c, currentUserID, targetID)
+		if err != nil || !isFriend {
+			Error(c, service.ErrForbidden)
+			return
+		}
+	}
+
+	counts, err := h.relationshipService.GetCounts(c, targetID)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, &response.RelationshipCountsResponse{
+		FollowersCount: counts.Followers,
+		FollowingCount: counts.Following,
+		FriendsCount:   counts.Friends,
+	})
+}
+
+// GetUserBriefs 批量获取用户简要信息
+// @Summary 批量获取用户简要信息
+// @Description 用于渲染成员列表、点赞者和提及，按请求顺序返回，不存在及存在拉黑关系的用户会被忽略
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body request.UserBriefsRequest true "用户ID列表"
+// @Success 200 {object} response.Response{data=[]response.UserBrief}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/v1/users/batch [post]
+func (h *Handler) GetUserBriefs(c *gin.Context) {
+	var req request.UserBriefsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, bodyError(err, service.ErrInvalidRequest))
+		return
+	}
+
+	// 过滤与当前用户存在拉黑关系的用户
+	userIDs := req.UserIDs
+	if currentUserID := h.GetCurrentUserID(c); currentUserID != 0 {
+		blocked, err := h.relationshipService.GetBlockedIDs(c, currentUserID, userIDs)
+		if err != nil {
+			logger.Error("获取拉黑关系失败",
+				logger.Any("error", err),
+				logger.Uint64("user_id", currentUserID))
+			Error(c, err)
+			return
+		}
+		if len(blocked) > 0 {
+			userIDs = make([]uint64, 0, len(req.UserIDs))
+			for _, id := range req.UserIDs {
+				if !blocked[id] {
+					userIDs = append(userIDs, id)
+				}
+			}
+		}
+	}
+
+	users, err := h.userService.GetBriefs(c, userIDs)
+	if err != nil {
+		logger.Error("批量获取用户信息失败",
+			logger.Any("error", err),
+			logger.Int("count", len(req.UserIDs)))
+		Error(c, err)
+		return
+	}
+
+	Success(c, response.ToUserBriefs(users))
 }
 
 // SearchUsers 搜索用户
 // @Summary 搜索用户
-// @Description 根据关键词搜索用户
+// @Description 根据关键词搜索用户，结果附带与当前用户的关系和已存在的私聊房间
 // @Tags 用户管理
 // @Produce json
 // @Param request query request.SearchUserRequest true "搜索请求"
-// @Success 200 {object} response.Response{data=response.PaginatedResponse{list=[]response.UserResponse}}
+// @Success 200 {object} response.Response{data=response.PaginatedResponse{list=[]response.UserSearchResult}}
 // @Failure 400 {object} response.ErrorResponse
 // @Router /api/v1/users/search [get]
 func (h *Handler) SearchUsers(c *gin.Context) {
@@ -274,13 +414,41 @@ func (h *Handler) SearchUsers(c *gin.Context) {
 		return
 	}
 
+	// 批量获取与当前用户的关系和私聊房间
+	var statuses map[uint64]*service.UserRelationStatus
+	if currentUserID := h.GetCurrentUserID(c); currentUserID != 0 && len(users) > 0 {
+		userIDs := make([]uint64, len(users))
+		for i, user := range users {
+			userIDs[i] = user.ID
+		}
+		statuses, err = h.relationshipService.GetRelationStatuses(c, currentUserID, userIDs)
+		if err != nil {
+			logger.Error("获取搜索结果关系状态失败",
+				logger.Any("error", err),
+				logger.Uint64("user_id", currentUserID))
+			Error(c, err)
+			return
+		}
+	}
+
 	// 转换为响应格式
-	userResponses := make([]*response.UserResponse, len(users))
+	results := make([]*response.UserSearchResult, len(users))
 	for i, user := range users {
-		userResponses[i] = response.ToResponse(user)
+		result := &response.UserSearchResult{UserResponse: *response.ToResponse(user)}
+		if status, ok := statuses[user.ID]; ok {
+			result.Relationship = &response.Relationship{
+				IsFollowing: status.IsFollowing,
+				IsFollowed:  status.IsFollowed,
+				IsFriend:    status.IsFriend,
+				IsBlocked:   status.IsBlocked,
+				IsPending:   status.IsPending,
+			}
+			result.PrivateRoomID = status.PrivateRoomID
+		}
+		results[i] = result
 	}
 
-	Success(c, response.NewPaginated(userResponses, total, req.Page, req.PageSize))
+	Success(c, response.NewPaginated(results, total, req.Page, req.PageSize))
 }
 
 // GetNearbyUsers 获取附近的用户
@@ -299,7 +467,7 @@ func (h *Handler) GetNearbyUsers(c *gin.Context) {
 		return
 	}
 
-	users, total, err := h.userService.GetNearbyUsers(c, req.Latitude, req.Longitude, req.Radius, req.Page, req.PageSize)
+	users, total, err := h.userService.GetNearbyUsers(c, req.Latitude, req.Longitude, req.Radius, req.ActiveWithin, req.Page, req.PageSize)
 	if err != nil {
 		Error(c, err)
 		return
@@ -314,6 +482,83 @@ func (h *Handler) GetNearbyUsers(c *gin.Context) {
 	Success(c, response.NewPaginated(userResponses, total, req.Page, req.PageSize))
 }
 
+// ListSessions 获取登录设备列表
+// @Summary 获取登录设备
+// @Description 获取当前用户已登录的设备，按最近活跃时间倒序
+// @Tags 用户管理
+// @Produce json
+// @Success 200 {object} response.Response{data=[]response.SessionResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Router /api/v1/users/sessions [get]
+func (h *Handler) ListSessions(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	devices, err := h.userService.ListSessions(c, userID)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, response.ToSessionResponses(devices))
+}
+
+// RevokeSession 退出指定设备
+// @Summary 退出登录设备
+// @Description 将指定设备标记为已退出并停止向其推送，同时撤销 Firebase 令牌，所有设备需重新登录
+// @Tags 用户管理
+// @Produce json
+// @Param id path uint64 true "设备ID"
+// @Success 200 {object} response.Response
+// @Failure 400,401,404 {object} response.ErrorResponse
+// @Router /api/v1/users/sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	deviceID, err := ParseUint64Param(c, "id")
+	if err != nil {
+		Error(c, service.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.userService.RevokeSession(c, userID, deviceID); err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+// DeleteAccount 注销当前账号
+// @Summary 注销账号
+// @Description 解除当前 Firebase 账号与用户的绑定并停用用户，已发布的内容保留
+// @Tags 用户管理
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 401,404 {object} response.ErrorResponse
+// @Router /api/v1/users/me [delete]
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	userID := h.GetCurrentUserID(c)
+	if userID == 0 {
+		Error(c, service.ErrUnauthorized)
+		return
+	}
+
+	if err := h.userService.DeleteAccount(c, userID); err != nil {
+		Error(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
 // RegisterDevice 注册用户设备
 // @Summary 注册设备
 // @Description 注册用户的设备信息用于消息推送
@@ -333,7 +578,7 @@ func (h *Handler) RegisterDevice(c *gin.Context) {
 
 	var device model.UserDevice
 	if err := c.ShouldBindJSON(&device); err != nil {
-		Error(c, service.ErrInvalidRequest)
+		Error(c, bodyError(err, service.ErrInvalidRequest))
 		return
 	}
 
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetRelationshipCounts(t *testing.T) {
+	viewer := &model.User{Nickname: "viewer", Status: model.UserStatusActive}
+	viewer.ID = 7
+
+	tests := []struct {
+		name       string
+		privacy    string
+		relations  map[[2]uint64]string
+		wantStatus int
+		followers  int64
+	}{
+		{"public", model.PrivacyPublic, map[[2]uint64]string{{9, 8}: "accepted", {8, 9}: "accepted", {10, 8}: "pending"}, 200, 1},
+		{"private to stranger", model.PrivacyPrivate, map[[2]uint64]string{{9, 8}: "accepted"}, 403, 0},
+		{"private to friend", model.PrivacyPrivate, map[[2]uint64]string{{7, 8}: "accepted", {8, 7}: "accepted", {9, 8}: "accepted"}, 200, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCache(t)
+			target := &model.User{Nickname: "target", Status: model.UserStatusActive, PrivacyLevel: tt.privacy}
+			target.ID = 8
+			userRepo := newFakeUserRepo(viewer, target)
+			userRepo.firebase["fb-viewer"] = viewer.ID
+			relationRepo := newFakeRelationRepo()
+			relationRepo.relations = tt.relations
+
+			h := newChatTestHandler(userRepo, newFakeChatRepo())
+			h.relationshipService = service.NewRelationshipService(relationRepo, userRepo, h.chatService)
+
+			r := gin.New()
+			r.GET("/users/:id/relationship-counts", withFirebaseUID("fb-viewer"), h.GetRelationshipCounts)
+			for i := 0; i < 2; i++ {
+				w := serve(r, httptest.NewRequest("GET", "/users/8/relationship-counts", nil))
+				if w.Code != tt.wantStatus {
+					t.Fatalf("status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+				}
+				if tt.wantStatus != 200 {
+					return
+				}
+
+				var counts struct {
+					Followers int64 `json:"followers_count"`
+					Following int64 `json:"following_count"`
+					Friends   int64 `json:"friends_count"`
+				}
+				decodeData(t, w, &counts)
+				if counts.Followers != tt.followers || counts.Following != 1 || counts.Friends != 1 {
+					t.Errorf("counts = %+v, want %d followers, 1 following, 1 friend", counts, tt.followers)
+				}
+			}
+			// 第二次请求读取缓存
+			if relationRepo.countQueries != 1 {
+				t.Errorf("count queries = %d, want 1", relationRepo.countQueries)
+			}
+		})
+	}
+}
+
+func TestGetUserBriefsSkipsBlockedUsers(t *testing.T) {
+	resetCache(t)
+	var users []*model.User
+	for _, id := range []uint64{7, 8, 9, 10} {
+		user := &model.User{Nickname: "user", Status: model.UserStatusActive}
+		user.ID = id
+		users = append(users, user)
+	}
+	userRepo := newFakeUserRepo(users...)
+	userRepo.firebase["fb-viewer"] = 7
+	relationRepo := newFakeRelationRepo()
+	relationRepo.relations = map[[2]uint64]string{
+		{7, 8}:  "blocked",
+		{9, 7}:  "blocked",
+		{10, 7}: "accepted",
+	}
+
+	h := newChatTestHandler(userRepo, newFakeChatRepo())
+	h.relationshipService = service.NewRelationshipService(relationRepo, userRepo, h.chatService)
+
+	r := gin.New()
+	r.POST("/users/batch", withFirebaseUID("fb-viewer"), h.GetUserBriefs)
+	body := strings.NewReader(`{"user_ids":[8,9,10]}`)
+	w := serve(r, httptest.NewRequest("POST", "/users/batch", body))
+	if w.Code != 200 {
+		t.Fatalf("status = %d, body=%s", w.Code, w.Body.String())
+	}
+
+	var briefs []struct {
+		ID uint64 `json:"id"`
+	}
+	decodeData(t, w, &briefs)
+	// 双向拉黑的用户都被过滤
+	if len(briefs) != 1 || briefs[0].ID != 10 {
+		t.Errorf("briefs = %+v, want only user 10", briefs)
+	}
+	if userRepo.idsQueries != 1 {
+		t.Errorf("GetByIDs calls = %d, want 1", userRepo.idsQueries)
+	}
+}
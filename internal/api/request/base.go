@@ -27,6 +27,11 @@ type Location struct {
 	Radius    float64 `json:"radius" form:"radius" binding:"required,min=0,max=50000"` // 米为单位，最大50km
 }
 
+// ActiveFilter 活跃度过滤参数
+type ActiveFilter struct {
+	ActiveWithin int `json:"active_within" form:"active_within" binding:"omitempty,min=1,max=365"` // 天为单位，不传使用默认配置
+}
+
 // DateRange 日期范围
 type DateRange struct {
 	StartDate string `json:"start_date" form:"start_date" binding:"required,datetime=2006-01-02"`
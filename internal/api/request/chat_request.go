@@ -3,13 +3,15 @@ package request
 // CreateGroupRequest 创建群聊请求
 type CreateGroupRequest struct {
 	Name           string   `json:"name" binding:"required,min=1,max=100"`
+	TopicID        uint64   `json:"topic_id"` // 关联话题，为空时不关联
 	InitialMembers []uint64 `json:"initial_members" binding:"required,min=1,dive,min=1"`
 }
 
 // SendMessageRequest 发送消息请求
 type SendMessageRequest struct {
 	ContentType string `json:"content_type" binding:"required,oneof=text image file system"`
-	Content     string `json:"content" binding:"required"`
+	// Content 文本和系统消息必填，图片和文件消息可以只有媒体
+	Content string `json:"content"`
 }
 
 // UpdateRoomRequest 更新聊天室请求
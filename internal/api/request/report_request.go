@@ -0,0 +1,9 @@
+package request
+
+// SubmitReportRequest 提交举报请求
+type SubmitReportRequest struct {
+	TargetType string `json:"target_type" binding:"required,oneof=user topic message"`
+	TargetID   uint64 `json:"target_id" binding:"required"`
+	Reason     string `json:"reason" binding:"required,max=50"`
+	Detail     string `json:"detail" binding:"max=1000"`
+}
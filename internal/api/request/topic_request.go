@@ -8,29 +8,70 @@ type CreateTopicRequest struct {
 	Title   string `json:"title" binding:"required,min=1,max=255"`
 	Content string `json:"content" binding:"required,min=1"`
 	Location
-	ExpiresAt time.Time `json:"expires_at" binding:"required,gtfield=time.Now"`
-	Tags      []string  `json:"tags" binding:"omitempty,dive,min=1,max=50"`
+	ExpiresAt *time.Time `json:"expires_at"` // 为空时使用默认有效期
+	Permanent bool       `json:"permanent"`  // 永久话题，仅商家/官方账号可用
+	Tags      []string   `json:"tags" binding:"omitempty,dive,min=1,max=50"`
 }
 
 // UpdateTopicRequest 更新话题请求
 type UpdateTopicRequest struct {
-	Title     string    `json:"title" binding:"required,min=1,max=255"`
-	Content   string    `json:"content" binding:"required,min=1"`
-	ExpiresAt time.Time `json:"expires_at" binding:"required,gtfield=time.Now"`
+	Title     string     `json:"title" binding:"required,min=1,max=255"`
+	Content   string     `json:"content" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"` // 为空时保持原过期时间
+	Permanent bool       `json:"permanent"`
+}
+
+// TopicSort 话题排序参数，为空时使用接口默认排序
+type TopicSort struct {
+	SortBy string `json:"sort_by" form:"sort_by" binding:"omitempty,oneof=recent popular"`
+}
+
+// CreatedBetween 按创建日期过滤，两端日期均包含在内
+type CreatedBetween struct {
+	StartDate string `json:"start_date" form:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" form:"end_date" binding:"omitempty,datetime=2006-01-02"`
 }
 
 // TopicListRequest 话题列表请求
 type TopicListRequest struct {
 	Pagination
-	Sort
+	TopicSort
+	CreatedBetween
 	TagID  uint64 `form:"tag_id" binding:"omitempty,min=1"`
 	UserID uint64 `form:"user_id" binding:"omitempty,min=1"`
 }
 
+// TopicSearchRequest 话题搜索请求
+type TopicSearchRequest struct {
+	Pagination
+	Keyword string `form:"keyword" binding:"required,min=1,max=50"`
+	By      string `form:"by" binding:"omitempty,oneof=content author"` // content: 标题/内容, author: 作者昵称
+}
+
 // NearbyTopicsRequest 附近话题请求
 type NearbyTopicsRequest struct {
 	Pagination
 	Location
+	TopicSort
+	ActiveFilter
+}
+
+// TopicStatsRequest 话题统计请求
+type TopicStatsRequest struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=90"` // 统计天数，默认 7
+}
+
+// TopicLikersRequest 点赞用户列表请求
+type TopicLikersRequest struct {
+	Cursor uint64 `form:"cursor"`                                  // 上一页返回的 next_cursor，首页不传
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"` // 每页数量，默认 20
+}
+
+// InteractedTopicsRequest 互动过的话题列表请求
+type InteractedTopicsRequest struct {
+	Type   string `form:"type" binding:"required,oneof=like favorite share"` // 互动类型
+	Cursor uint64 `form:"cursor"`                                            // 上一页返回的 next_cursor，首页不传
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`           // 每页数量，默认 20
 }
 
 // TopicInteractionRequest 话题互动请求
@@ -47,3 +88,14 @@ type AddTagsRequest struct {
 type RemoveTagsRequest struct {
 	TagIDs []uint64 `json:"tag_ids" binding:"required,min=1"`
 }
+
+// RemoveTopicRequest 管理员下架话题请求
+type RemoveTopicRequest struct {
+	Reason       string `json:"reason" binding:"required,max=500"`
+	NotifyAuthor bool   `json:"notify_author"`
+}
+
+// TransferTopicRequest 转移话题请求
+type TransferTopicRequest struct {
+	NewOwnerID uint64 `json:"new_owner_id" binding:"required"`
+}
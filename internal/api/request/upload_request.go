@@ -0,0 +1,14 @@
+package request
+
+// InitChunkedUploadRequest 创建分片上传请求
+type InitChunkedUploadRequest struct {
+	Type      string `json:"type" binding:"required,oneof=image file"`
+	FileName  string `json:"file_name" binding:"required,max=255"`
+	TotalSize int64  `json:"total_size" binding:"required,min=1"`
+	ChunkSize int64  `json:"chunk_size" binding:"required,min=1"`
+}
+
+// UploadChunkRequest 上传分片请求
+type UploadChunkRequest struct {
+	Index *int `form:"index" binding:"required,min=0"`
+}
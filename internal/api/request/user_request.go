@@ -34,8 +34,26 @@ type SearchUserRequest struct {
 	Keyword string `json:"keyword" form:"keyword" binding:"required,min=1,max=50"`
 }
 
+// UserBriefsRequest 批量获取用户简要信息请求
+type UserBriefsRequest struct {
+	UserIDs []uint64 `json:"user_ids" binding:"required,min=1,max=100,dive,gt=0"`
+}
+
 // NearbyUsersRequest 查询附近用户请求
 type NearbyUsersRequest struct {
 	Pagination
 	Location
+	ActiveFilter
+}
+
+// ActivityTimelineRequest 用户动态列表请求
+type ActivityTimelineRequest struct {
+	Cursor string `form:"cursor"`                                  // 上一页返回的 next_cursor，首页不传
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"` // 每页数量，默认 20
+}
+
+// BanUserRequest 管理员封禁用户请求
+type BanUserRequest struct {
+	Reason        string `json:"reason" binding:"required,max=500"`
+	DurationHours int    `json:"duration_hours" binding:"min=0"` // 封禁时长（小时），0 表示永久封禁
 }
@@ -0,0 +1,59 @@
+package response
+
+import (
+	"DistanceBack_v1/internal/model"
+	"time"
+)
+
+// ActivityResponse 用户动态，按 type 区分携带的内容
+// topic_created、interaction 携带 topic，interaction 额外携带 interaction_type，follow 携带 user
+type ActivityResponse struct {
+	Type            string         `json:"type"`
+	ID              uint64         `json:"id"`
+	OccurredAt      time.Time      `json:"occurred_at"`
+	Topic           *TopicResponse `json:"topic,omitempty"`
+	InteractionType string         `json:"interaction_type,omitempty"`
+	User            *UserBrief     `json:"user,omitempty"`
+}
+
+// ActivityTimelineResponse 用户动态列表响应
+type ActivityTimelineResponse struct {
+	Activities []*ActivityResponse `json:"activities"`
+	NextCursor string              `json:"next_cursor"` // 没有更多时为空
+	HasMore    bool                `json:"has_more"`
+}
+
+// ToActivityTimelineResponse 转换用户动态列表响应
+func ToActivityTimelineResponse(activities []*model.Activity, nextCursor string, hasMore bool) *ActivityTimelineResponse {
+	resp := &ActivityTimelineResponse{
+		Activities: make([]*ActivityResponse, 0, len(activities)),
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}
+	for _, activity := range activities {
+		item := &ActivityResponse{
+			Type:       activity.Type,
+			ID:         activity.ID,
+			OccurredAt: activity.OccurredAt,
+		}
+		switch activity.Type {
+		case model.ActivityFollow:
+			if activity.User != nil {
+				item.User = &UserBrief{
+					ID:        activity.User.ID,
+					Nickname:  activity.User.Nickname,
+					AvatarURL: activity.User.AvatarURL,
+				}
+			}
+		case model.ActivityInteraction:
+			item.InteractionType = activity.InteractionType
+			fallthrough
+		default:
+			if activity.Topic != nil {
+				item.Topic = ToTopicResponse(activity.Topic)
+			}
+		}
+		resp.Activities = append(resp.Activities, item)
+	}
+	return resp
+}
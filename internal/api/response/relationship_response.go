@@ -19,6 +19,13 @@ type RelationshipStatsResponse struct {
 	BlockedUsersCount int64 `json:"blocked_users_count"`
 }
 
+// RelationshipCountsResponse 关系计数响应
+type RelationshipCountsResponse struct {
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+	FriendsCount   int64 `json:"friends_count"`
+}
+
 // RelationshipStatusResponse 关系状态响应
 type RelationshipStatusResponse struct {
 	IsFollowing bool `json:"is_following"`
@@ -0,0 +1,30 @@
+package response
+
+import (
+	"DistanceBack_v1/internal/model"
+	"time"
+)
+
+// ReportResponse 举报响应
+type ReportResponse struct {
+	ID         uint64    `json:"id"`
+	TargetType string    `json:"target_type"`
+	TargetID   uint64    `json:"target_id"`
+	Reason     string    `json:"reason"`
+	Detail     string    `json:"detail,omitempty"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ToReportResponse 转换举报响应
+func ToReportResponse(report *model.Report) *ReportResponse {
+	return &ReportResponse{
+		ID:         report.ID,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		Reason:     report.ReasonType,
+		Detail:     report.ReasonDetail,
+		Status:     report.Status,
+		CreatedAt:  report.CreatedAt,
+	}
+}
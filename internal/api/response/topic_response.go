@@ -41,12 +41,14 @@ type TopicResponse struct {
 	ViewsCount        uint         `json:"views_count"`
 	SharesCount       uint         `json:"shares_count"`
 	ParticipantsCount uint         `json:"participants_count"`
-	ExpiresAt         time.Time    `json:"expires_at"`
+	ExpiresAt         *time.Time   `json:"expires_at"`
 	Status            string       `json:"status"`
 	CreatedAt         time.Time    `json:"created_at"`
 	HasLiked          bool         `json:"has_liked"`
 	HasFavorited      bool         `json:"has_favorited"`
 	Distance          float64      `json:"distance,omitempty"`
+	// FailedImages 创建话题时上传失败的图片
+	FailedImages []model.FileUploadFailure `json:"failed_images,omitempty"`
 }
 
 // TopicInteractionListResponse 话题互动列表响应
@@ -61,6 +63,7 @@ type TopicInteractionListResponse struct {
 type TopicDetailResponse struct {
 	TopicResponse
 	UserInteraction *UserInteraction `json:"user_interaction,omitempty"`
+	Participants    []*UserBrief     `json:"participants,omitempty"` // 参与者预览
 }
 
 // UserInteraction 用户与话题的互动状态
@@ -107,6 +110,7 @@ func ToTopicResponse(topic *model.Topic) *TopicResponse {
 		Status:            topic.Status,
 		ExpiresAt:         topic.ExpiresAt,
 		CreatedAt:         topic.CreatedAt,
+		FailedImages:      topic.FailedImages,
 	}
 
 	// 位置信息
@@ -126,11 +130,23 @@ func ToTopicResponse(topic *model.Topic) *TopicResponse {
 		}
 	}
 
+	// 图片信息
+	for _, image := range topic.TopicImages {
+		resp.Images = append(resp.Images, TopicImage{
+			ID:     image.ID,
+			URL:    image.ImageURL,
+			Width:  image.ImageWidth,
+			Height: image.ImageHeight,
+			Size:   image.FileSize,
+		})
+	}
+
 	return resp
 }
 
 // ToTopicDetailResponse 将话题模型转换为详情响应
-func ToTopicDetailResponse(topic *model.Topic, interaction *model.TopicInteraction) *TopicDetailResponse {
+// interactions 为 nil 时(匿名访问)不返回用户互动状态
+func ToTopicDetailResponse(topic *model.Topic, interactions []*model.TopicInteraction, participants []*model.User) *TopicDetailResponse {
 	if topic == nil {
 		return nil
 	}
@@ -139,12 +155,31 @@ func ToTopicDetailResponse(topic *model.Topic, interaction *model.TopicInteracti
 		TopicResponse: *ToTopicResponse(topic),
 	}
 
-	if interaction != nil {
-		detail.UserInteraction = &UserInteraction{
-			IsLiked:     interaction.InteractionType == "like" && interaction.InteractionStatus == "active",
-			IsFavorited: interaction.InteractionType == "favorite" && interaction.InteractionStatus == "active",
-			IsShared:    interaction.InteractionType == "share" && interaction.InteractionStatus == "active",
+	for _, user := range participants {
+		detail.Participants = append(detail.Participants, &UserBrief{
+			ID:        user.ID,
+			Nickname:  user.Nickname,
+			AvatarURL: user.AvatarURL,
+		})
+	}
+
+	if interactions != nil {
+		detail.UserInteraction = &UserInteraction{}
+		for _, interaction := range interactions {
+			if interaction.InteractionStatus != "active" {
+				continue
+			}
+			switch interaction.InteractionType {
+			case "like":
+				detail.UserInteraction.IsLiked = true
+			case "favorite":
+				detail.UserInteraction.IsFavorited = true
+			case "share":
+				detail.UserInteraction.IsShared = true
+			}
 		}
+		detail.HasLiked = detail.UserInteraction.IsLiked
+		detail.HasFavorited = detail.UserInteraction.IsFavorited
 	}
 
 	return detail
@@ -171,6 +206,15 @@ func ToTopicListResponse(topics []*model.Topic, total int64, page, pageSize int)
 	}
 }
 
+// ToTopicResponses 转换不分页的话题列表
+func ToTopicResponses(topics []*model.Topic) []*TopicResponse {
+	responses := make([]*TopicResponse, 0, len(topics))
+	for _, topic := range topics {
+		responses = append(responses, ToTopicResponse(topic))
+	}
+	return responses
+}
+
 // ToTopicInteractionResponse 将互动模型转换为响应
 func ToTopicInteractionResponse(interaction *model.TopicInteraction) *TopicInteractionResponse {
 	if interaction == nil {
@@ -252,3 +296,78 @@ func NewTopicInteractionListResponse(interactions []*model.TopicInteraction, tot
 		PageSize:     pageSize,
 	}
 }
+
+// TopicStatPoint 话题单日统计
+type TopicStatPoint struct {
+	Date  string `json:"date"`
+	Views uint   `json:"views"`
+	Likes uint   `json:"likes"`
+}
+
+// TopicStatsResponse 话题统计响应
+type TopicStatsResponse struct {
+	TopicID uint64           `json:"topic_id"`
+	Days    int              `json:"days"`
+	Series  []TopicStatPoint `json:"series"`
+}
+
+// ToTopicStatsResponse 将每日统计转换为时间序列响应
+func ToTopicStatsResponse(topicID uint64, stats []*model.TopicDailyStat) *TopicStatsResponse {
+	series := make([]TopicStatPoint, 0, len(stats))
+	for _, stat := range stats {
+		series = append(series, TopicStatPoint{
+			Date:  stat.StatDate.Format("2006-01-02"),
+			Views: stat.Views,
+			Likes: stat.Likes,
+		})
+	}
+	return &TopicStatsResponse{
+		TopicID: topicID,
+		Days:    len(series),
+		Series:  series,
+	}
+}
+
+// TopicCursorListResponse 游标分页的话题列表响应
+type TopicCursorListResponse struct {
+	Topics     []*TopicResponse `json:"topics"`
+	NextCursor uint64           `json:"next_cursor"` // 没有更多时为 0
+	HasMore    bool             `json:"has_more"`
+}
+
+// ToTopicCursorListResponse 转换游标分页的话题列表响应
+func ToTopicCursorListResponse(topics []*model.Topic, nextCursor uint64, hasMore bool) *TopicCursorListResponse {
+	resp := &TopicCursorListResponse{
+		Topics:     make([]*TopicResponse, 0, len(topics)),
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}
+	for _, topic := range topics {
+		resp.Topics = append(resp.Topics, ToTopicResponse(topic))
+	}
+	return resp
+}
+
+// TopicLikersResponse 点赞用户列表响应
+type TopicLikersResponse struct {
+	Users      []*UserBrief `json:"users"`
+	NextCursor uint64       `json:"next_cursor"` // 没有更多时为 0
+	HasMore    bool         `json:"has_more"`
+}
+
+// ToTopicLikersResponse 转换点赞用户列表响应
+func ToTopicLikersResponse(users []*model.User, nextCursor uint64, hasMore bool) *TopicLikersResponse {
+	resp := &TopicLikersResponse{
+		Users:      make([]*UserBrief, 0, len(users)),
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}
+	for _, user := range users {
+		resp.Users = append(resp.Users, &UserBrief{
+			ID:        user.ID,
+			Nickname:  user.Nickname,
+			AvatarURL: user.AvatarURL,
+		})
+	}
+	return resp
+}
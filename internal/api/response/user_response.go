@@ -42,6 +42,21 @@ type Relationship struct {
 	IsFollowed  bool `json:"is_followed"`
 	IsFriend    bool `json:"is_friend"`
 	IsBlocked   bool `json:"is_blocked"`
+	IsPending   bool `json:"is_pending"`
+}
+
+// UserSearchResult 用户搜索结果，附带与当前用户的关系和私聊房间
+type UserSearchResult struct {
+	UserResponse
+	Relationship  *Relationship `json:"relationship,omitempty"`
+	PrivateRoomID *uint64       `json:"private_room_id,omitempty"`
+}
+
+// AvatarUpdateResponse 更新头像响应，附带处理后的头像尺寸
+type AvatarUpdateResponse struct {
+	*UserResponse
+	AvatarWidth  int `json:"avatar_width"`
+	AvatarHeight int `json:"avatar_height"`
 }
 
 // Location 位置信息
@@ -83,3 +98,64 @@ func ToResponse(user *model.User) *UserResponse {
 
 	return resp
 }
+
+// ToUserBriefs 转换用户简要信息列表
+func ToUserBriefs(users []*model.User) []*UserBrief {
+	briefs := make([]*UserBrief, 0, len(users))
+	for _, user := range users {
+		briefs = append(briefs, &UserBrief{
+			ID:        user.ID,
+			Nickname:  user.Nickname,
+			AvatarURL: user.AvatarURL,
+		})
+	}
+	return briefs
+}
+
+// SessionResponse 登录设备响应，不返回推送令牌
+type SessionResponse struct {
+	ID           uint64    `json:"id"`
+	DeviceType   string    `json:"device_type"`
+	DeviceName   string    `json:"device_name"`
+	DeviceModel  string    `json:"device_model"`
+	OSVersion    string    `json:"os_version"`
+	AppVersion   string    `json:"app_version"`
+	BrowserInfo  string    `json:"browser_info"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ToSessionResponses 转换登录设备列表
+func ToSessionResponses(devices []*model.UserDevice) []*SessionResponse {
+	sessions := make([]*SessionResponse, 0, len(devices))
+	for _, device := range devices {
+		sessions = append(sessions, &SessionResponse{
+			ID:           device.ID,
+			DeviceType:   device.DeviceType,
+			DeviceName:   device.DeviceName,
+			DeviceModel:  device.DeviceModel,
+			OSVersion:    device.OSVersion,
+			AppVersion:   device.AppVersion,
+			BrowserInfo:  device.BrowserInfo,
+			LastActiveAt: device.LastActiveAt,
+			CreatedAt:    device.CreatedAt,
+		})
+	}
+	return sessions
+}
+
+// MeResponse 当前用户概览响应
+type MeResponse struct {
+	Profile               *UserResponse   `json:"profile"`
+	Stats                 MeStats         `json:"stats"`
+	TotalUnread           int64           `json:"total_unread"`
+	PendingFollowRequests int64           `json:"pending_follow_requests"`
+	Features              map[string]bool `json:"features"`
+}
+
+// MeStats 当前用户统计
+type MeStats struct {
+	TopicsCount    int64 `json:"topics_count"`
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+}
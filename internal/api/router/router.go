@@ -1,8 +1,12 @@
 package router
 
 import (
+	"DistanceBack_v1/config"
 	"DistanceBack_v1/internal/api/handler"
 	"DistanceBack_v1/internal/middleware"
+	"DistanceBack_v1/internal/service"
+	"DistanceBack_v1/pkg/logger"
+	"DistanceBack_v1/pkg/storage"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -10,19 +14,35 @@ import (
 )
 
 // SetupRouter 配置路由
-func SetupRouter(h *handler.Handler) *gin.Engine {
+func SetupRouter(h *handler.Handler, cfg *config.Config) *gin.Engine {
 	r := gin.New()
+	r.MaxMultipartMemory = cfg.App.MaxMultipartMemory
+
+	// 只采信可信代理转发的客户端 IP，防止伪造 X-Forwarded-For 绕过按 IP 的限流
+	if err := r.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		logger.Error("可信代理配置无效，不采信转发的客户端 IP", logger.Any("error", err))
+		r.SetTrustedProxies(nil)
+	}
 
 	// 使用日志和恢复中间件
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 
+	// 请求体大小限制
+	r.Use(middleware.MaxBodySize(cfg.App.MaxBodySize))
+
+	// 各上传接口的请求体上限，与单文件大小限制保持一致
+	singleFileLimit := middleware.MaxBodySize(storage.MaxFileSize + storage.MultipartOverhead)
+	topicImagesLimit := middleware.MaxBodySize(storage.MaxFileSize*storage.MaxTopicImages + storage.MultipartOverhead)
+	messageFilesLimit := middleware.MaxBodySize(storage.MaxFileSize*service.MaxMessageAttachments + storage.MultipartOverhead)
+	chunkLimit := middleware.MaxBodySize(storage.MaxChunkSize)
+
 	// CORS 配置
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-None-Match", middleware.HeaderAppVersion, middleware.HeaderPlatform},
+		ExposeHeaders:    []string{"Content-Length", "ETag", "Upgrade"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -37,39 +57,69 @@ func SetupRouter(h *handler.Handler) *gin.Engine {
 	// API 版本组
 	v1 := r.Group("/api/v1")
 
+	// 客户端最低版本限制，认证和健康检查不受限制
+	// 放在认证之后，未携带版本号时按用户注册设备上报的版本检查
+	minAppVersion := middleware.MinAppVersion(cfg.Client.MinVersions, h.RegisteredAppVersion)
+
 	// 认证相关路由
 	auth := v1.Group("/auth")
 	{
-		auth.POST("/register", middleware.AuthRequired(), h.RegisterUser)
+		loginLimit := middleware.LoginFailureLimit(cfg.Auth.LoginFailureIPLimit, cfg.Auth.LoginFailureLimit, cfg.Auth.LoginFailureWindow)
+		auth.POST("/register", loginLimit, middleware.AuthRequired(), h.RegisterUser)
+	}
+
+	// 客户端配置
+	v1.GET("/config/report-reasons", h.GetReportReasons) // 获取举报原因
+
+	// 公开浏览的话题路由(登录后返回个人互动状态，已登录的封禁用户被拒绝)
+	publicTopics := v1.Group("/topics")
+	publicTopics.Use(middleware.OptionalAuth(), minAppVersion, h.RejectBannedUsers())
+	{
+		publicTopics.GET("", h.ListTopics)             // 获取话题列表
+		publicTopics.GET("/nearby", h.GetNearbyTopics) // 获取附近话题
+		publicTopics.GET("/search", h.SearchTopics)    // 搜索话题
+		publicTopics.GET("/:id", h.GetTopic)           // 获取话题详情
 	}
 
 	// 需要认证的路由组
 	authenticated := v1.Group("")
-	authenticated.Use(middleware.AuthRequired())
+	authenticated.Use(middleware.AuthRequired(), minAppVersion, h.RejectBannedUsers(), h.TrackLastActive())
 	{
+		// 当前用户概览
+		authenticated.GET("/me", h.GetMe)
+
 		// 用户相关路由
 		users := authenticated.Group("/users")
 		{
-			users.GET("/profile", h.GetProfile)      // 获取个人资料
-			users.PUT("/profile", h.UpdateProfile)   // 更新个人资料
-			users.PUT("/avatar", h.UpdateAvatar)     // 更新头像
-			users.PUT("/location", h.UpdateLocation) // 更新位置
-			users.GET("/nearby", h.GetNearbyUsers)   // 获取附近用户
-			users.POST("/devices", h.RegisterDevice) // 注册设备
+			users.GET("/profile", h.GetProfile)                   // 获取个人资料
+			users.PUT("/profile", h.UpdateProfile)                // 更新个人资料
+			users.PUT("/avatar", singleFileLimit, h.UpdateAvatar) // 更新头像
+			users.PUT("/location", h.UpdateLocation)              // 更新位置
+			users.GET("/nearby", h.GetNearbyUsers)                // 获取附近用户
+			users.POST("/devices", h.RegisterDevice)              // 注册设备
+			users.GET("/sessions", h.ListSessions)                // 获取登录设备
+			users.DELETE("/sessions/:id", h.RevokeSession)        // 退出指定设备
+			users.GET("/activity", h.GetActivityTimeline)         // 获取我的动态
+			users.DELETE("/me", h.DeleteAccount)                  // 注销账号
 
 			// 用户查询
-			users.GET("/search", h.SearchUsers) // 搜索用户
-			users.GET("/:id", h.GetUserProfile) // 获取用户资料
+			users.GET("/search", h.SearchUsers)                            // 搜索用户
+			users.POST("/batch", h.GetUserBriefs)                          // 批量获取用户简要信息
+			users.GET("/:id", h.GetUserProfile)                            // 获取用户资料
+			users.GET("/:id/shared-rooms", h.GetSharedRooms)               // 获取共同加入的聊天室
+			users.GET("/:id/pinned-topics", h.GetPinnedTopics)             // 获取主页置顶话题
+			users.GET("/:id/relationship-counts", h.GetRelationshipCounts) // 获取关系计数
 		}
 
 		// 关系相关路由
 		relationship := authenticated.Group("/relationships")
 		{
 			// 关注相关
-			relationship.POST("/users/:id/follow", h.Follow)           // 关注用户
-			relationship.DELETE("/users/:id/follow", h.Unfollow)       // 取消关注
-			relationship.POST("/followers/:id/accept", h.AcceptFollow) // 接受关注请求
-			relationship.POST("/followers/:id/reject", h.RejectFollow) // 拒绝关注请求
+			relationship.POST("/users/:id/follow", h.Follow)                   // 关注用户
+			relationship.DELETE("/users/:id/follow", h.Unfollow)               // 取消关注
+			relationship.POST("/followers/:id/accept", h.AcceptFollow)         // 接受关注请求
+			relationship.POST("/followers/:id/reject", h.RejectFollow)         // 拒绝关注请求
+			relationship.POST("/followers/:id/reject-block", h.RejectAndBlock) // 拒绝并拉黑
 
 			// 查询关系
 			relationship.GET("/users/:id/status", h.CheckRelationship) // 检查与用户的关系
@@ -83,23 +133,30 @@ func SetupRouter(h *handler.Handler) *gin.Engine {
 		topics := authenticated.Group("/topics")
 		{
 			// 基础操作
-			topics.POST("", h.CreateTopic)       // 创建话题
-			topics.PUT("/:id", h.UpdateTopic)    // 更新话题
-			topics.DELETE("/:id", h.DeleteTopic) // 删除话题
-			topics.GET("/:id", h.GetTopic)       // 获取话题详情
+			topics.POST("", topicImagesLimit, h.CreateTopic) // 创建话题
+			topics.PUT("/:id", h.UpdateTopic)                // 更新话题
+			topics.DELETE("/:id", h.DeleteTopic)             // 删除话题
+			topics.POST("/:id/restore", h.RestoreTopic)      // 恢复已删除的话题
+			topics.DELETE("/closed", h.PurgeClosedTopics)    // 清理已关闭/过期话题
+			topics.POST("/:id/view", h.ViewTopic)            // 记录话题浏览
+			topics.POST("/:id/pin", h.PinTopic)              // 置顶到个人主页
+			topics.DELETE("/:id/pin", h.UnpinTopic)          // 取消主页置顶
 
 			// 列表查询
-			topics.GET("", h.ListTopics)               // 获取话题列表
-			topics.GET("/users/:id", h.ListUserTopics) // 获取用户的话题
-			topics.GET("/nearby", h.GetNearbyTopics)   // 获取附近话题
+			topics.GET("/users/:id", h.ListUserTopics)        // 获取用户的话题
+			topics.GET("/interacted", h.ListInteractedTopics) // 获取互动过的话题
 
 			// 图片管理
-			topics.POST("/:id/images", h.AddTopicImage) // 添加话题图片
+			topics.POST("/:id/images", singleFileLimit, h.AddTopicImage) // 添加话题图片
 
 			// 互动相关
 			topics.POST("/:id/interactions/:type", h.AddTopicInteraction)      // 添加互动
 			topics.DELETE("/:id/interactions/:type", h.RemoveTopicInteraction) // 移除互动
 			topics.GET("/:id/interactions/:type", h.GetTopicInteractions)      // 获取互动列表
+			topics.GET("/:id/likers", h.GetTopicLikers)                        // 获取点赞用户
+
+			// 统计
+			topics.GET("/:id/stats", h.GetTopicStats) // 获取话题每日统计
 
 			// 标签相关路由
 			topics.GET("/:id/tags", h.GetTopicTags)
@@ -115,28 +172,64 @@ func SetupRouter(h *handler.Handler) *gin.Engine {
 			chats.POST("/groups", h.CreateGroupRoom)               // 创建群聊
 			chats.GET("", h.ListRooms)                             // 获取聊天室列表
 			chats.GET("/:id", h.GetRoomInfo)                       // 获取聊天室信息
+			chats.POST("/leave-all", h.LeaveAllGroups)             // 退出所有群聊
+			chats.POST("/link-preview", h.GetLinkPreview)          // 获取链接预览
+			chats.POST("/:id/leave", h.LeaveRoom)                  // 退出群聊
 
 			// 成员管理
 			chats.POST("/:id/members", h.AddMember)                       // 添加成员
 			chats.DELETE("/:id/members/:member_id", h.RemoveMember)       // 移除成员
 			chats.PUT("/:id/members/:member_id/role", h.UpdateMemberRole) // 更新成员角色
+			chats.PUT("/:id/members/me", h.UpdateMyMember)                // 更新自己的群昵称
 
 			// 消息管理
-			chats.POST("/:id/messages", h.SendMessage)             // 发送消息
-			chats.GET("/:id/messages", h.GetMessages)              // 获取消息历史
-			chats.POST("/:id/messages/read", h.MarkMessagesAsRead) // 标记消息已读
-			chats.GET("/:id/unread", h.GetUnreadCount)             // 获取未读数
+			chats.POST("/:id/messages", messageFilesLimit, h.SendMessage)       // 发送消息
+			chats.GET("/:id/messages", h.GetMessages)                           // 获取消息历史
+			chats.POST("/:id/share-topic", h.ShareTopic)                        // 分享话题
+			chats.GET("/:id/messages/:message_id/context", h.GetMessageContext) // 获取消息上下文
+			chats.DELETE("/:id/messages/:message_id", h.DeleteMessage)          // 删除消息(仅自己/所有人)
+			chats.POST("/:id/messages/read", h.MarkMessagesAsRead)              // 标记消息已读
+			chats.GET("/:id/unread", h.GetUnreadCount)                          // 获取未读数
+
+			// 实时状态
+			chats.POST("/:id/presence", h.EnterRoom)      // 进入聊天室(心跳)
+			chats.DELETE("/:id/presence", h.ExitRoom)     // 离开聊天室
+			chats.GET("/:id/presence", h.GetRoomPresence) // 获取查看及输入状态
+			chats.POST("/:id/typing", h.SetTyping)        // 更新正在输入状态
 
 			// 其他功能
 			chats.POST("/:id/pin", h.PinRoom)     // 置顶聊天室
 			chats.DELETE("/:id/pin", h.UnpinRoom) // 取消置顶
 		}
 
+		// 上传相关路由
+		uploads := authenticated.Group("/uploads")
+		{
+			uploads.POST("", singleFileLimit, h.UploadFile)        // 上传文件，返回临时句柄
+			uploads.POST("/init", h.InitChunkedUpload)             // 创建分片上传
+			uploads.PUT("/:id/chunk", chunkLimit, h.UploadChunk)   // 上传分片
+			uploads.POST("/:id/complete", h.CompleteChunkedUpload) // 完成分片上传
+		}
+
 		// 添加独立的标签路由组
 		tags := authenticated.Group("/tags")
 		{
 			tags.GET("/popular", h.GetPopularTags)
 		}
+
+		// 举报
+		authenticated.POST("/reports", h.SubmitReport)
+
+		// 管理后台路由
+		admin := authenticated.Group("/admin")
+		admin.Use(h.AdminRequired())
+		{
+			admin.GET("/stats", h.GetDashboardStats)            // 获取概览统计
+			admin.POST("/topics/:id/transfer", h.TransferTopic) // 转移话题作者
+			admin.POST("/topics/:id/remove", h.RemoveTopic)     // 下架违规话题
+			admin.POST("/users/:id/ban", h.BanUser)             // 封禁用户
+			admin.POST("/users/:id/unban", h.UnbanUser)         // 解除封禁
+		}
 	}
 
 	return r
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"DistanceBack_v1/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 客户端版本请求头
+const (
+	HeaderAppVersion = "X-App-Version"
+	HeaderPlatform   = "X-Platform"
+)
+
+// AppVersionLookup 请求未携带版本号时查找客户端的平台和版本
+// platform 为请求头中的平台，可能为空；找不到时返回空字符串
+type AppVersionLookup func(c *gin.Context, platform string) (string, string)
+
+// MinAppVersion 客户端最低版本中间件
+// 按 X-Platform 查找该平台的最低版本，X-App-Version 低于它时返回 426 及最低版本
+// 未携带版本时使用 fallback 查找的版本，依赖登录用户时需放在认证中间件之后
+// 仍找不到版本、平台未配置或版本号无法解析时放行
+func MinAppVersion(minVersions map[string]string, fallback AppVersionLookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		platform := strings.ToLower(strings.TrimSpace(c.GetHeader(HeaderPlatform)))
+		version := strings.TrimSpace(c.GetHeader(HeaderAppVersion))
+		if version == "" && fallback != nil {
+			platform, version = fallback(c, platform)
+			platform = strings.ToLower(platform)
+		}
+		minVersion := minVersions[platform]
+		if version == "" || minVersion == "" {
+			c.Next()
+			return
+		}
+
+		cmp, ok := compareVersions(version, minVersion)
+		if ok && cmp < 0 {
+			// 426 响应须携带 Upgrade 头，说明需要升级到的客户端版本
+			c.Header("Upgrade", platform+"/"+minVersion)
+			e := errors.NewUpgradeRequired(platform, minVersion)
+			c.AbortWithStatusJSON(e.HTTPStatus, gin.H{
+				"code":    e.Code,
+				"message": e.Message,
+				"data":    e.Details,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// compareVersions 按数字段比较 a、b 两个版本号，缺少的段视为 0
+// 忽略 "v" 前缀及 "-"、"+" 之后的预发布和构建信息
+func compareVersions(a, b string) (int, bool) {
+	as, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	bs, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if x != y {
+			if x < y {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+func parseVersion(v string) ([]int, bool) {
+	v = strings.TrimPrefix(strings.ToLower(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	parts := strings.Split(v, ".")
+	segments := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		segments[i] = n
+	}
+	return segments, true
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"1.2.3", "1.3.0", -1, true}, // 低于
+		{"1.2", "1.2.0", 0, true},    // 等于，缺少的段视为 0
+		{"v2.0.0", "1.9.9", 1, true}, // 高于
+		{"1.10.0", "1.9.0", 1, true}, // 按数字而非字符串比较
+		{"1.2.0-beta+5", "1.2.0", 0, true},
+		{"1.x", "1.0", 0, false},
+		{"", "1.0", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := compareVersions(tt.a, tt.b)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("compareVersions(%q, %q) = %d, %v; want %d, %v", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestMinAppVersion(t *testing.T) {
+	// 注册设备上报的版本
+	registered := func(version string) AppVersionLookup {
+		return func(c *gin.Context, platform string) (string, string) {
+			return "ios", version
+		}
+	}
+	tests := []struct {
+		name     string
+		header   string // X-App-Version，空表示未携带
+		fallback AppVersionLookup
+		want     int
+	}{
+		{"below", "1.9.9", nil, http.StatusUpgradeRequired},
+		{"at", "2.0.0", nil, http.StatusOK},
+		{"above", "2.1.0", nil, http.StatusOK},
+		{"missing without fallback", "", nil, http.StatusOK},
+		{"missing falls back below", "", registered("1.0.0"), http.StatusUpgradeRequired},
+		{"missing falls back at", "", registered("2.0.0"), http.StatusOK},
+		{"header wins over fallback", "2.0.0", registered("1.0.0"), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(MinAppVersion(map[string]string{"ios": "2.0.0"}, tt.fallback))
+			r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest("GET", "/ping", nil)
+			req.Header.Set(HeaderPlatform, "iOS")
+			if tt.header != "" {
+				req.Header.Set(HeaderAppVersion, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if w.Code == http.StatusUpgradeRequired && w.Header().Get("Upgrade") != "ios/2.0.0" {
+				t.Errorf("Upgrade = %q, want ios/2.0.0", w.Header().Get("Upgrade"))
+			}
+		})
+	}
+}
@@ -38,6 +38,10 @@ func AuthRequired() gin.HandlerFunc {
 		// 验证 Firebase token
 		firebaseToken, err := auth.VerifyIDToken(context.Background(), token)
 		if err != nil {
+			// 签名有效但已撤销的令牌可以确定所属用户，供登录失败限流按用户计数
+			if uid, ok := auth.RevokedTokenUID(err); ok {
+				c.Set(rejectedUIDKey, uid)
+			}
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
 				"message": "invalid token",
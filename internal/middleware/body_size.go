@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"DistanceBack_v1/internal/api/handler"
+	"DistanceBack_v1/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBodySize 限制请求体大小
+// 声明的 Content-Length 超限时直接返回 413，未声明长度的请求在读取超限时由 handler 返回 413
+func MaxBodySize(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			handler.Error(c, errors.NewRequestTooLarge(limit))
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
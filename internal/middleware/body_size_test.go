@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"DistanceBack_v1/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaxBodySizeRejectsDeclaredLength(t *testing.T) {
+	r := gin.New()
+	r.POST("/", MaxBodySize(8), func(c *gin.Context) {
+		t.Error("handler should not run")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(strings.Repeat("x", 32))))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", w.Code)
+	}
+
+	var resp struct {
+		Code int `json:"code"`
+		Data struct {
+			MaxBytes int64 `json:"max_bytes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != errors.CodeTooLarge || resp.Data.MaxBytes != 8 {
+		t.Errorf("response = %s", w.Body.String())
+	}
+}
+
+func TestMaxBodySizeLimitsChunkedBody(t *testing.T) {
+	var readErr error
+	r := gin.New()
+	r.POST("/", MaxBodySize(8), func(c *gin.Context) {
+		_, readErr = io.ReadAll(c.Request.Body)
+	})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(strings.Repeat("x", 32)))
+	req.ContentLength = -1
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var maxBytesErr *http.MaxBytesError
+	if !stderrors.As(readErr, &maxBytesErr) || maxBytesErr.Limit != 8 {
+		t.Errorf("read err = %v, want *http.MaxBytesError with limit 8", readErr)
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"DistanceBack_v1/pkg/auth"
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/logger"
+	"DistanceBack_v1/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rejectedUIDKey 上下文中签名有效但被拒绝的令牌所属 UID
+const rejectedUIDKey = "rejected_uid"
+
+// LoginFailureLimit 登录失败限流中间件，需放在认证中间件之前
+// 同一 IP 在 window 内验证失败达到 ipLimit 次后暂时拒绝该 IP 登录，轮换令牌中的 UID 无法绕过
+// 同一 UID 的令牌签名有效但被拒绝（已撤销）达到 uidLimit 次后暂时拒绝该 UID 登录
+// 签名无效的令牌不计入 UID，他人伪造的失败请求不会锁住用户
+// SDK 先校验过期时间再校验签名，过期令牌无法确认所属用户，只计入 IP
+// 客户端 IP 依赖路由配置的可信代理，限流存储不可用时放行
+func LoginFailureLimit(ipLimit, uidLimit int, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ipKey := cache.LoginFailureIPKey(c.ClientIP())
+		if !peekLoginFailures(c, ipKey, ipLimit, window) {
+			return
+		}
+		token, _ := auth.TokenFromHeader(c)
+		if uid := auth.UnverifiedUID(token); uid != "" {
+			if !peekLoginFailures(c, cache.LoginFailureUIDKey(uid), uidLimit, window) {
+				return
+			}
+		}
+
+		c.Next()
+
+		// 只记录令牌验证失败
+		if c.Writer.Status() != http.StatusUnauthorized {
+			return
+		}
+		recordLoginFailure(c, ipKey, ipLimit, window)
+		if uid := c.GetString(rejectedUIDKey); uid != "" {
+			recordLoginFailure(c, cache.LoginFailureUIDKey(uid), uidLimit, window)
+		}
+	}
+}
+
+// peekLoginFailures 检查失败次数是否已达上限，达到时中止请求并返回 false
+func peekLoginFailures(c *gin.Context, key string, limit int, window time.Duration) bool {
+	if limit <= 0 {
+		return true
+	}
+	allowed, retryAfter, err := ratelimit.Peek(c, key, limit, window)
+	if err != nil {
+		logger.Warn("failed to check login failure limit", logger.Any("error", err))
+		return true
+	}
+	if !allowed {
+		AbortRateLimited(c, retryAfter)
+		return false
+	}
+	return true
+}
+
+// recordLoginFailure 记录一次登录失败
+func recordLoginFailure(c *gin.Context, key string, limit int, window time.Duration) {
+	if limit <= 0 {
+		return
+	}
+	if _, _, err := ratelimit.Allow(c, key, limit, window); err != nil {
+		logger.Warn("failed to record login failure", logger.Any("error", err))
+	}
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/pkg/auth"
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// useTestRedis 将限流存储指向测试用 Redis
+func useTestRedis(t *testing.T) {
+	t.Helper()
+	logger.Log = zap.NewNop()
+	mr := miniredis.RunT(t)
+	prev := cache.RedisClient
+	cache.RedisClient = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { cache.RedisClient = prev })
+}
+
+// 令牌签名状态
+const (
+	tokenValid   = "valid"
+	tokenForged  = "forged"
+	tokenRevoked = "revoked"
+)
+
+// idToken 构造声明指定 UID 的令牌，signature 表示签名状态
+func idToken(uid, signature string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"` + uid + `"}`))
+	return "header." + payload + "." + signature
+}
+
+// newLoginRouter 只信任连接地址，签名无效或已撤销的令牌返回 401
+func newLoginRouter(ipLimit, uidLimit int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.SetTrustedProxies(nil)
+	r.POST("/login", LoginFailureLimit(ipLimit, uidLimit, time.Minute), func(c *gin.Context) {
+		token, _ := auth.TokenFromHeader(c)
+		switch {
+		case strings.HasSuffix(token, "."+tokenValid):
+			c.Status(http.StatusOK)
+		case strings.HasSuffix(token, "."+tokenRevoked):
+			c.Set(rejectedUIDKey, auth.UnverifiedUID(token))
+			c.Status(http.StatusUnauthorized)
+		default:
+			c.Status(http.StatusUnauthorized)
+		}
+	})
+	return r
+}
+
+// login 从 remoteAddr 以 uid 的身份登录并返回状态码
+func login(r *gin.Engine, remoteAddr, forwardedFor, uid, signature string) int {
+	req := httptest.NewRequest("POST", "/login", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	req.Header.Set("Authorization", "Bearer "+idToken(uid, signature))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestLoginFailureLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	useTestRedis(t)
+	r := newLoginRouter(2, 2)
+
+	// 每次伪造不同的 X-Forwarded-For，仍按连接地址计数
+	for i, spoofed := range []string{"1.1.1.1", "2.2.2.2"} {
+		if code := login(r, "10.0.0.1:1234", spoofed, "alice", tokenForged); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d status = %d, want 401", i, code)
+		}
+	}
+	if code := login(r, "10.0.0.1:1234", "3.3.3.3", "alice", tokenForged); code != http.StatusTooManyRequests {
+		t.Fatalf("status after limit = %d, want 429", code)
+	}
+}
+
+func TestLoginFailureLimitRotatingUIDsFromOneIP(t *testing.T) {
+	useTestRedis(t)
+	r := newLoginRouter(3, 2)
+
+	// 每次声明不同的 UID，仍按 IP 计数
+	for i, uid := range []string{"uid-1", "uid-2", "uid-3"} {
+		if code := login(r, "10.0.0.1:1234", "", uid, tokenForged); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d status = %d, want 401", i, code)
+		}
+	}
+	if code := login(r, "10.0.0.1:1234", "", "uid-4", tokenForged); code != http.StatusTooManyRequests {
+		t.Fatalf("rotated uid status = %d, want 429", code)
+	}
+	if code := login(r, "10.0.0.2:1234", "", "uid-4", tokenValid); code != http.StatusOK {
+		t.Errorf("other ip status = %d, want 200", code)
+	}
+}
+
+func TestLoginFailureLimitDoesNotLockOutVictim(t *testing.T) {
+	useTestRedis(t)
+	r := newLoginRouter(2, 2)
+
+	// 攻击者用声明受害者 UID 的伪造令牌触发限流
+	for i := 0; i < 2; i++ {
+		login(r, "10.0.0.1:1234", "", "victim", tokenForged)
+	}
+	if code := login(r, "10.0.0.1:1234", "", "victim", tokenValid); code != http.StatusTooManyRequests {
+		t.Fatalf("attacker status = %d, want 429", code)
+	}
+
+	// 伪造令牌不计入受害者的 UID，受害者从自己的 IP 正常登录
+	if code := login(r, "10.0.0.2:1234", "", "victim", tokenValid); code != http.StatusOK {
+		t.Errorf("victim status = %d, want 200", code)
+	}
+}
+
+func TestLoginFailureLimitCountsRevokedTokensPerUID(t *testing.T) {
+	useTestRedis(t)
+	r := newLoginRouter(10, 2)
+
+	// 已撤销的令牌从不同 IP 登录，按 UID 累计
+	for i, addr := range []string{"10.0.0.1:1234", "10.0.0.2:1234"} {
+		if code := login(r, addr, "", "alice", tokenRevoked); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d status = %d, want 401", i, code)
+		}
+	}
+	if code := login(r, "10.0.0.3:1234", "", "alice", tokenValid); code != http.StatusTooManyRequests {
+		t.Fatalf("alice status after limit = %d, want 429", code)
+	}
+	if code := login(r, "10.0.0.3:1234", "", "bob", tokenValid); code != http.StatusOK {
+		t.Errorf("bob status = %d, want 200", code)
+	}
+}
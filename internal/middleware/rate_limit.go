@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"strconv"
 	"sync"
+	"time"
+
+	"DistanceBack_v1/pkg/errors"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
@@ -44,13 +48,30 @@ func RateLimit(r rate.Limit, b int) gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 		ip := c.ClientIP()
-		if !limiter.getLimiter(ip).Allow() {
-			c.AbortWithStatusJSON(429, gin.H{
-				"code":    429,
-				"message": "too many requests",
-			})
+		reservation := limiter.getLimiter(ip).Reserve()
+		if !reservation.OK() {
+			AbortRateLimited(c, time.Second)
+			return
+		}
+		if delay := reservation.Delay(); delay > 0 {
+			// 归还令牌，只用于计算重试时间
+			reservation.Cancel()
+			AbortRateLimited(c, delay)
 			return
 		}
 		c.Next()
 	}
 }
+
+// AbortRateLimited 以统一格式返回限流响应
+func AbortRateLimited(c *gin.Context, retryAfter time.Duration) {
+	e := errors.NewRateLimited(retryAfter)
+	if seconds, ok := errors.RetryAfterSeconds(e); ok {
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+	c.AbortWithStatusJSON(e.HTTPStatus, gin.H{
+		"code":    e.Code,
+		"message": e.Message,
+		"data":    e.Details,
+	})
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitReturnsRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RateLimit(0.5, 1)) // 每 2 秒一个令牌
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("first request status = %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want 429", w.Code)
+	}
+
+	header, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	if err != nil || header < 1 || header > 2 {
+		t.Fatalf("Retry-After = %q", w.Header().Get("Retry-After"))
+	}
+
+	var body struct {
+		Data struct {
+			RetryAfterSeconds int `json:"retry_after_seconds"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Data.RetryAfterSeconds != header {
+		t.Errorf("retry_after_seconds = %d, header = %d", body.Data.RetryAfterSeconds, header)
+	}
+}
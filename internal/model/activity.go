@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// 动态类型
+const (
+	ActivityTopicCreated = "topic_created" // 发布话题
+	ActivityInteraction  = "interaction"   // 点赞、收藏或分享话题
+	ActivityFollow       = "follow"        // 关注用户
+)
+
+// Activity 用户动态，由话题、互动和关注关系汇总而来，不对应数据表
+type Activity struct {
+	Type            string    `json:"type"`
+	ID              uint64    `json:"id"` // 来源记录的ID
+	OccurredAt      time.Time `json:"occurred_at"`
+	TargetID        uint64    `json:"target_id"`                  // 话题ID或被关注用户ID
+	InteractionType string    `json:"interaction_type,omitempty"` // 仅互动动态
+	Topic           *Topic    `gorm:"-" json:"topic,omitempty"`
+	User            *User     `gorm:"-" json:"user,omitempty"`
+}
@@ -0,0 +1,22 @@
+package model
+
+// 审计操作类型
+const (
+	AuditActionTopicTransfer = "topic_transfer"
+	AuditActionTopicRemove   = "topic_remove"
+)
+
+// 审计对象类型
+const (
+	AuditTargetTopic = "topic"
+)
+
+// AuditLog 管理操作审计日志
+type AuditLog struct {
+	BaseModel
+	OperatorID uint64 `gorm:"index" json:"operator_id"`
+	Action     string `gorm:"size:50;index" json:"action"`
+	TargetType string `gorm:"size:20;index:idx_audit_target" json:"target_type"`
+	TargetID   uint64 `gorm:"index:idx_audit_target" json:"target_id"`
+	Detail     string `gorm:"type:text" json:"detail"`
+}
@@ -34,3 +34,11 @@ type File struct {
 	Width  int                   `json:"width"`  // 图片宽度（仅图片类型）
 	Height int                   `json:"height"` // 图片高度（仅图片类型）
 }
+
+// FileUploadFailure 文件上传失败信息
+type FileUploadFailure struct {
+	Index    int    `json:"index"`     // 文件在请求中的序号
+	FileName string `json:"file_name"` // 文件名
+	Code     int    `json:"code"`      // 失败错误码
+	Reason   string `json:"reason"`    // 失败原因
+}
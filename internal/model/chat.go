@@ -1,6 +1,18 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// MessageContentTopic 分享话题消息，内容为话题ID
+const MessageContentTopic = "topic"
+
+// 聊天室状态
+const (
+	ChatRoomStatusActive = "active"
+	ChatRoomStatusClosed = "closed" // 最后一名成员退出后关闭，不再出现在列表和详情中
+)
 
 // ChatRoom 聊天室模型
 type ChatRoom struct {
@@ -8,9 +20,22 @@ type ChatRoom struct {
 	Name         string  `gorm:"size:100" json:"name"`
 	Type         string  `gorm:"type:enum('individual','group','merchant','official')" json:"type"`
 	TopicID      *uint64 `json:"topic_id"`
+	PairKey      *string `gorm:"size:41;uniqueIndex:uk_pair_key" json:"-"` // 私聊双方用户ID，群聊为空
 	AvatarURL    string  `gorm:"size:255" json:"avatar_url"`
 	Announcement string  `gorm:"type:text" json:"announcement"`
+	Status       string  `gorm:"type:enum('active','closed');default:'active'" json:"status"`
 	Topic        *Topic  `gorm:"foreignKey:TopicID" json:"topic"`
+	ViewerCount  *int64  `gorm:"-" json:"viewer_count,omitempty"` // 当前正在查看的成员数，不持久化
+
+	ChatRoomMembers []ChatRoomMember `gorm:"foreignKey:ChatRoomID" json:"chat_room_members,omitempty"`
+}
+
+// PrivatePairKey 生成私聊房间的唯一键，格式为 "较小用户ID:较大用户ID"
+func PrivatePairKey(userID1, userID2 uint64) string {
+	if userID1 > userID2 {
+		userID1, userID2 = userID2, userID1
+	}
+	return fmt.Sprintf("%d:%d", userID1, userID2)
 }
 
 // ChatRoomMember 聊天室成员模型
@@ -29,12 +54,63 @@ type ChatRoomMember struct {
 // Message 消息模型
 type Message struct {
 	BaseModel
-	ChatRoomID  uint64   `gorm:"index:idx_chat_room_time" json:"chat_room_id"`
-	SenderID    uint64   `json:"sender_id"`
-	ContentType string   `gorm:"type:enum('text','image','file','system');default:'text'" json:"content_type"`
-	Content     string   `gorm:"type:text" json:"content"`
-	ChatRoom    ChatRoom `gorm:"foreignKey:ChatRoomID" json:"chat_room"`
-	Sender      User     `gorm:"foreignKey:SenderID" json:"sender"`
+	ChatRoomID  uint64 `gorm:"index:idx_chat_room_time" json:"chat_room_id"`
+	SenderID    uint64 `json:"sender_id"`
+	ContentType string `gorm:"type:enum('text','image','file','system','topic');default:'text'" json:"content_type"`
+	Content     string `gorm:"type:text" json:"content"`
+	// DeletedAt 对所有人删除（撤回）的时间，删除后保留记录作为墓碑，内容和媒体被清空
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ChatRoom  ChatRoom   `gorm:"foreignKey:ChatRoomID" json:"chat_room"`
+	Sender    User       `gorm:"foreignKey:SenderID" json:"sender"`
+	// MessageMedia 消息附带的图片和文件
+	MessageMedia []MessageMedia `gorm:"foreignKey:MessageID" json:"message_media,omitempty"`
+	// FailedMedia 部分成功策略下上传失败的文件，不持久化
+	FailedMedia []FileUploadFailure `gorm:"-" json:"failed_media,omitempty"`
+	// SharedTopic 分享话题消息的话题预览，话题已删除时为空，不持久化
+	SharedTopic *SharedTopic `gorm:"-" json:"shared_topic,omitempty"`
+}
+
+// sharedTopicSummaryLength 话题预览摘要的最大长度（按字符）
+const sharedTopicSummaryLength = 100
+
+// SharedTopic 聊天中分享的话题预览
+type SharedTopic struct {
+	ID             uint64 `json:"id"`
+	Title          string `json:"title"`
+	Summary        string `json:"summary"`
+	AuthorID       uint64 `json:"author_id"`
+	AuthorNickname string `json:"author_nickname"`
+	Status         string `json:"status"`
+	SharesCount    uint   `json:"shares_count"`
+}
+
+// NewSharedTopic 根据话题生成预览
+func NewSharedTopic(topic *Topic) *SharedTopic {
+	summary := []rune(topic.Content)
+	if len(summary) > sharedTopicSummaryLength {
+		summary = summary[:sharedTopicSummaryLength]
+	}
+	return &SharedTopic{
+		ID:             topic.ID,
+		Title:          topic.Title,
+		Summary:        string(summary),
+		AuthorID:       topic.UserID,
+		AuthorNickname: topic.User.Nickname,
+		Status:         topic.Status,
+		SharesCount:    topic.SharesCount,
+	}
+}
+
+// IsDeleted 消息是否已对所有人删除
+func (m *Message) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+// HiddenMessage 用户仅对自己删除的消息
+type HiddenMessage struct {
+	UserID    uint64    `gorm:"primaryKey" json:"user_id"`
+	MessageID uint64    `gorm:"primaryKey" json:"message_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // MessageMedia 消息媒体模型
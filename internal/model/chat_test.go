@@ -0,0 +1,29 @@
+package model
+
+import "testing"
+
+func TestPrivatePairKey(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want string
+	}{
+		{1, 2, "1:2"},
+		{2, 1, "1:2"},
+		{10, 9, "9:10"},
+		{7, 7, "7:7"},
+		{18446744073709551615, 1, "1:18446744073709551615"},
+	}
+	for _, tt := range tests {
+		if got := PrivatePairKey(tt.a, tt.b); got != tt.want {
+			t.Errorf("PrivatePairKey(%d, %d) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPrivatePairKeyFitsColumn(t *testing.T) {
+	// pair_key 列长度为 41，两个最大的 uint64 加分隔符正好放下
+	key := PrivatePairKey(18446744073709551615, 18446744073709551614)
+	if len(key) > 41 {
+		t.Errorf("len(%q) = %d, exceeds column size 41", key, len(key))
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+// 举报处理状态
+const (
+	ReportStatusPending    = "pending"
+	ReportStatusProcessing = "processing"
+	ReportStatusResolved   = "resolved"
+	ReportStatusRejected   = "rejected"
+)
+
+// 举报对象类型
+const (
+	ReportTargetUser    = "user"
+	ReportTargetTopic   = "topic"
+	ReportTargetComment = "comment"
+	ReportTargetMessage = "message"
+)
+
+// 举报原因，other 需要填写详细说明
+const (
+	ReportReasonSpam       = "spam"
+	ReportReasonHarassment = "harassment"
+	ReportReasonNudity     = "nudity"
+	ReportReasonOther      = "other"
+)
+
+// Report 举报记录模型
+type Report struct {
+	BaseModel
+	ReporterID     uint64  `gorm:"index" json:"reporter_id"`
+	TargetType     string  `gorm:"type:enum('user','topic','comment','message');index:idx_target" json:"target_type"`
+	TargetID       uint64  `gorm:"index:idx_target" json:"target_id"`
+	ReasonType     string  `gorm:"type:enum('spam','harassment','nudity','other')" json:"reason_type"` // 举报原因，取值见 ReportReason*
+	ReasonDetail   string  `gorm:"type:text" json:"reason_detail"`
+	Status         string  `gorm:"type:enum('pending','processing','resolved','rejected');default:'pending';index:idx_status" json:"status"`
+	HandlerID      *uint64 `json:"handler_id"`
+	HandlingResult string  `gorm:"type:text" json:"handling_result"`
+	Reporter       User    `gorm:"foreignKey:ReporterID" json:"reporter"`
+}
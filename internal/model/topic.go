@@ -16,23 +16,55 @@ const (
 	// 互动状态
 	InteractionStatusActive    = "active"
 	InteractionStatusCancelled = "cancelled"
+
+	// 排序方式
+	TopicSortRecent  = "recent"
+	TopicSortPopular = "popular"
+
+	// 搜索范围
+	TopicSearchByContent = "content"
+	TopicSearchByAuthor  = "author"
 )
 
 // Topic 话题模型
 type Topic struct {
 	BaseModel
-	UserID            uint64    `gorm:"index:idx_user_time" json:"user_id"`
-	Title             string    `gorm:"size:255" json:"title"`
-	Content           string    `gorm:"type:text" json:"content"`
-	LocationLatitude  float64   `gorm:"type:decimal(10,8)" json:"location_latitude"`
-	LocationLongitude float64   `gorm:"type:decimal(11,8)" json:"location_longitude"`
-	LikesCount        uint      `gorm:"default:0" json:"likes_count"`        // 点赞数
-	ParticipantsCount uint      `gorm:"default:0" json:"participants_count"` // 参与人数
-	ViewsCount        uint      `gorm:"default:0" json:"views_count"`        // 浏览数
-	SharesCount       uint      `gorm:"default:0" json:"shares_count"`       // 分享数
-	ExpiresAt         time.Time `json:"expires_at"`                          // 过期时间
-	Status            string    `gorm:"type:enum('active','closed','cancelled');default:'active'" json:"status"`
-	User              User      `gorm:"foreignKey:UserID" json:"user"`
+	UserID            uint64     `gorm:"index:idx_user_time" json:"user_id"`
+	Title             string     `gorm:"size:255" json:"title"`
+	Content           string     `gorm:"type:text" json:"content"`
+	LocationLatitude  float64    `gorm:"type:decimal(10,8)" json:"location_latitude"`
+	LocationLongitude float64    `gorm:"type:decimal(11,8)" json:"location_longitude"`
+	LikesCount        uint       `gorm:"default:0" json:"likes_count"`        // 点赞数
+	ParticipantsCount uint       `gorm:"default:0" json:"participants_count"` // 参与人数
+	ViewsCount        uint       `gorm:"default:0" json:"views_count"`        // 浏览数
+	SharesCount       uint       `gorm:"default:0" json:"shares_count"`       // 分享数
+	ExpiresAt         *time.Time `json:"expires_at"`                          // 过期时间，为空表示永久有效
+	Status            string     `gorm:"type:enum('active','closed','cancelled');default:'active'" json:"status"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"` // 关闭时间，用于判断是否还能恢复
+	User              User       `gorm:"foreignKey:UserID" json:"user"`
+	// TopicImages 话题图片，查询时按 sort_order 排序
+	TopicImages []TopicImage `gorm:"foreignKey:TopicID" json:"topic_images,omitempty"`
+	// FailedImages 部分成功策略下上传失败的图片，不持久化
+	FailedImages []FileUploadFailure `gorm:"-" json:"failed_images,omitempty"`
+}
+
+// IsExpired 检查话题是否已过期，永久话题不会过期
+func (t *Topic) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !t.ExpiresAt.After(now)
+}
+
+// TopicDailyStat 话题每日统计，按 (话题, 日期) 累加浏览与点赞
+type TopicDailyStat struct {
+	TopicID  uint64    `gorm:"primaryKey" json:"topic_id"`
+	StatDate time.Time `gorm:"primaryKey;type:date" json:"stat_date"`
+	Views    uint      `gorm:"default:0" json:"views"`
+	Likes    uint      `gorm:"default:0" json:"likes"`
+}
+
+// StatDay 返回时间所在日期的零点（本地时区），用作每日统计的日期键
+func StatDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
 }
 
 // TopicImage 话题图片模型
@@ -74,12 +106,21 @@ type TopicInteraction struct {
 	User              User   `gorm:"foreignKey:UserID" json:"user"`
 }
 
+// ProfilePinnedTopic 个人主页置顶话题模型
+type ProfilePinnedTopic struct {
+	UserID   uint64    `gorm:"primaryKey" json:"user_id"`
+	TopicID  uint64    `gorm:"primaryKey" json:"topic_id"`
+	PinnedAt time.Time `json:"pinned_at"`
+	User     User      `gorm:"foreignKey:UserID" json:"user"`
+	Topic    Topic     `gorm:"foreignKey:TopicID" json:"topic"`
+}
+
 // TopicReport 话题举报模型
 type TopicReport struct {
 	BaseModel
 	TopicID      uint64 `json:"topic_id"`
 	ReporterID   uint64 `json:"reporter_id"`
-	ReasonType   string `gorm:"type:enum('spam','abuse','copyright','other')" json:"reason_type"`
+	ReasonType   string `gorm:"type:enum('spam','harassment','nudity','other')" json:"reason_type"` // 与 Report 相同，取值见 ReportReason*
 	ReasonDetail string `gorm:"type:text" json:"reason_detail"`
 	Status       string `gorm:"type:enum('pending','processing','resolved','rejected');default:'pending'" json:"status"`
 	HandlerID    uint64 `json:"handler_id"`                     // 处理人ID
@@ -89,12 +89,12 @@ type AdminPermission struct {
 // UserBan 用户封禁记录模型
 type UserBan struct {
 	BaseModel
-	UserID     uint64    `gorm:"index:idx_user_status" json:"user_id"`
-	OperatorID uint64    `json:"operator_id"`
-	Reason     string    `gorm:"type:text" json:"reason"`
-	BanStart   time.Time `json:"ban_start"`
-	BanEnd     time.Time `json:"ban_end"`
-	Status     string    `gorm:"type:enum('active','expired','cancelled');default:'active';index:idx_user_status" json:"status"`
-	User       User      `gorm:"foreignKey:UserID" json:"user"`
-	Operator   User      `gorm:"foreignKey:OperatorID" json:"operator"`
+	UserID     uint64     `gorm:"index:idx_user_status" json:"user_id"`
+	OperatorID uint64     `json:"operator_id"`
+	Reason     string     `gorm:"type:text" json:"reason"`
+	BanStart   time.Time  `json:"ban_start"`
+	BanEnd     *time.Time `json:"ban_end"` // 为空表示永久封禁
+	Status     string     `gorm:"type:enum('active','expired','cancelled');default:'active';index:idx_user_status" json:"status"`
+	User       User       `gorm:"foreignKey:UserID" json:"user"`
+	Operator   User       `gorm:"foreignKey:OperatorID" json:"operator"`
 }
@@ -0,0 +1,98 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type activityRepository struct {
+	db *gorm.DB
+}
+
+// NewActivityRepository 创建用户动态仓储实例
+func NewActivityRepository(db *gorm.DB) repository.ActivityRepository {
+	return &activityRepository{db: db}
+}
+
+// activitySource 动态来源表的查询定义
+type activitySource struct {
+	activityType string
+	table        string
+	timeExpr     string // 动态发生时间
+	targetExpr   string
+	detailExpr   string
+	where        string // 以用户ID为唯一参数的过滤条件
+}
+
+var activitySources = []activitySource{
+	{
+		activityType: model.ActivityTopicCreated,
+		table:        "topics",
+		timeExpr:     "created_at",
+		targetExpr:   "id",
+		detailExpr:   "''",
+		where:        "user_id = ?",
+	},
+	{
+		activityType: model.ActivityInteraction,
+		table:        "topic_interactions",
+		timeExpr:     "created_at",
+		targetExpr:   "topic_id",
+		detailExpr:   "interaction_type",
+		where:        "user_id = ? AND interaction_status = '" + model.InteractionStatusActive + "'",
+	},
+	{
+		activityType: model.ActivityFollow,
+		table:        "user_relationships",
+		timeExpr:     "COALESCE(accepted_at, created_at)",
+		targetExpr:   "following_id",
+		detailExpr:   "''",
+		where:        "follower_id = ? AND status = 'accepted'",
+	},
+}
+
+// ListByUser 按时间倒序合并用户的各类动态，cursor 为上一页最后一条，零值表示首页
+// 排序键为 (发生时间, 类型, ID)，每个来源先各取 limit 条再合并
+func (r *activityRepository) ListByUser(ctx context.Context, userID uint64, cursor repository.ActivityCursor, limit int) ([]*model.Activity, error) {
+	branches := make([]string, 0, len(activitySources))
+	var args []interface{}
+	for _, src := range activitySources {
+		where := src.where
+		branchArgs := []interface{}{userID}
+		if !cursor.OccurredAt.IsZero() {
+			// 类型是排序的第二键，同一时间点下只有类型更小或同类型 ID 更小的记录排在游标之后
+			switch {
+			case src.activityType < cursor.Type:
+				where += fmt.Sprintf(" AND %s <= ?", src.timeExpr)
+				branchArgs = append(branchArgs, cursor.OccurredAt)
+			case src.activityType == cursor.Type:
+				where += fmt.Sprintf(" AND (%s < ? OR (%s = ? AND id < ?))", src.timeExpr, src.timeExpr)
+				branchArgs = append(branchArgs, cursor.OccurredAt, cursor.OccurredAt, cursor.ID)
+			default:
+				where += fmt.Sprintf(" AND %s < ?", src.timeExpr)
+				branchArgs = append(branchArgs, cursor.OccurredAt)
+			}
+		}
+
+		branches = append(branches, fmt.Sprintf(
+			"(SELECT '%s' AS type, id, %s AS occurred_at, %s AS target_id, %s AS interaction_type FROM %s WHERE %s ORDER BY occurred_at DESC, id DESC LIMIT ?)",
+			src.activityType, src.timeExpr, src.targetExpr, src.detailExpr, src.table, where))
+		args = append(args, branchArgs...)
+		args = append(args, limit)
+	}
+
+	query := strings.Join(branches, " UNION ALL ") + " ORDER BY occurred_at DESC, type DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	var activities []*model.Activity
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&activities).Error; err != nil {
+		return nil, err
+	}
+	return activities, nil
+}
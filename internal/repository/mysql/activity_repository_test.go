@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestActivityListByUserFirstPage(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewActivityRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"(SELECT 'topic_created' AS type, id, created_at AS occurred_at, id AS target_id, '' AS interaction_type FROM topics WHERE user_id = ? ORDER BY occurred_at DESC, id DESC LIMIT ?) UNION ALL "+
+			"(SELECT 'interaction' AS type, id, created_at AS occurred_at, topic_id AS target_id, interaction_type AS interaction_type FROM topic_interactions WHERE user_id = ? AND interaction_status = 'active' ORDER BY occurred_at DESC, id DESC LIMIT ?) UNION ALL "+
+			"(SELECT 'follow' AS type, id, COALESCE(accepted_at, created_at) AS occurred_at, following_id AS target_id, '' AS interaction_type FROM user_relationships WHERE follower_id = ? AND status = 'accepted' ORDER BY occurred_at DESC, id DESC LIMIT ?) "+
+			"ORDER BY occurred_at DESC, type DESC, id DESC LIMIT ?")).
+		WithArgs(7, 21, 7, 21, 7, 21, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "id", "occurred_at", "target_id", "interaction_type"}).
+			AddRow(model.ActivityFollow, 3, time.Now(), 8, ""))
+
+	activities, err := repo.ListByUser(context.Background(), 7, repository.ActivityCursor{}, 21)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(activities) != 1 || activities[0].Type != model.ActivityFollow || activities[0].TargetID != 8 {
+		t.Errorf("activities = %+v, want one follow of user 8", activities)
+	}
+}
+
+func TestActivityListByUserKeysetAfterCursor(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewActivityRepository(db)
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// 游标类型为 interaction：同一时刻类型更大的 topic_created 已读完，类型更小的 follow 还没有读取
+	mock.ExpectQuery(regexp.QuoteMeta("FROM topics WHERE user_id = ? AND created_at < ? ORDER BY")+".*"+
+		regexp.QuoteMeta("FROM topic_interactions WHERE user_id = ? AND interaction_status = 'active' AND (created_at < ? OR (created_at = ? AND id < ?)) ORDER BY")+".*"+
+		regexp.QuoteMeta("FROM user_relationships WHERE follower_id = ? AND status = 'accepted' AND COALESCE(accepted_at, created_at) <= ? ORDER BY")).
+		WithArgs(7, at, 11, 7, at, at, 50, 11, 7, at, 11, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "id", "occurred_at", "target_id", "interaction_type"}))
+
+	cursor := repository.ActivityCursor{OccurredAt: at, Type: model.ActivityInteraction, ID: 50}
+	if _, err := repo.ListByUser(context.Background(), 7, cursor, 11); err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+}
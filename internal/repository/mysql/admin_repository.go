@@ -0,0 +1,115 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type adminRepository struct {
+	db *gorm.DB
+}
+
+// NewAdminRepository 创建管理后台统计仓储实例
+func NewAdminRepository(db *gorm.DB) repository.AdminRepository {
+	return &adminRepository{db: db}
+}
+
+// CountUsers 统计用户总数
+func (r *adminRepository) CountUsers(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Count(&count).Error
+	return count, err
+}
+
+// CountActiveUsers 统计指定时间之后活跃过的用户数
+func (r *adminRepository) CountActiveUsers(ctx context.Context, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("last_active_at >= ?", since).
+		Count(&count).Error
+	return count, err
+}
+
+// CountTopicsSince 统计指定时间之后创建的话题数
+func (r *adminRepository) CountTopicsSince(ctx context.Context, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.Topic{}).
+		Where("created_at >= ?", since).
+		Count(&count).Error
+	return count, err
+}
+
+// CountMessagesSince 统计指定时间之后发送的消息数
+func (r *adminRepository) CountMessagesSince(ctx context.Context, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.Message{}).
+		Where("created_at >= ?", since).
+		Count(&count).Error
+	return count, err
+}
+
+// CountOpenReports 统计待处理的举报数
+func (r *adminRepository) CountOpenReports(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.Report{}).
+		Where("status IN ?", []string{model.ReportStatusPending, model.ReportStatusProcessing}).
+		Count(&count).Error
+	return count, err
+}
+
+// RemoveTopic 管理员下架违规话题，在同一事务中扣减标签使用次数、取消全部互动、
+// 移除主页置顶并写入审计日志，话题数据保留以便复核
+func (r *adminRepository) RemoveTopic(ctx context.Context, topicID uint64, audit *model.AuditLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tagIDs []uint64
+		if err := tx.Model(&model.TopicTag{}).
+			Where("topic_id = ?", topicID).
+			Pluck("tag_id", &tagIDs).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) > 0 {
+			if err := tx.Model(&model.Tag{}).
+				Where("id IN ? AND use_count > 0", tagIDs).
+				UpdateColumn("use_count", gorm.Expr("use_count - ?", 1)).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("topic_id = ?", topicID).Delete(&model.TopicTag{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.TopicInteraction{}).
+			Where("topic_id = ? AND interaction_status = ?", topicID, model.InteractionStatusActive).
+			Update("interaction_status", model.InteractionStatusCancelled).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("topic_id = ?", topicID).Delete(&model.ProfilePinnedTopic{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.Topic{}).
+			Where("id = ?", topicID).
+			Updates(map[string]interface{}{
+				"status":             model.TopicStatusCancelled,
+				"closed_at":          time.Now(),
+				"likes_count":        0,
+				"shares_count":       0,
+				"participants_count": 0,
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(audit).Error
+	})
+}
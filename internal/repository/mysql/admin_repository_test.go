@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRemoveTopicDecrementsTagsAndCancelsInteractions(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewAdminRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `tag_id` FROM `topic_tags` WHERE topic_id = ?")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"tag_id"}).AddRow(1).AddRow(2))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `tags` SET `use_count`=use_count - ? WHERE id IN (?,?) AND use_count > 0")).
+		WithArgs(1, 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `topic_tags` WHERE topic_id = ?")).
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `topic_interactions` SET `interaction_status`=?,`updated_at`=? WHERE topic_id = ? AND interaction_status = ?")).
+		WithArgs("cancelled", sqlmock.AnyArg(), 5, "active").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `profile_pinned_topics` WHERE topic_id = ?")).
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `topics` SET")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `audit_logs`")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	audit := &model.AuditLog{OperatorID: 99, Action: model.AuditActionTopicRemove, TargetType: model.AuditTargetTopic, TargetID: 5}
+	if err := repo.RemoveTopic(context.Background(), 5, audit); err != nil {
+		t.Fatalf("RemoveTopic: %v", err)
+	}
+}
+
+func TestAdminCountQueries(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		query string
+		args  []driver.Value
+		count func(repo *adminRepository) (int64, error)
+	}{
+		{
+			"users", "SELECT count(*) FROM `users`", nil,
+			func(repo *adminRepository) (int64, error) { return repo.CountUsers(context.Background()) },
+		},
+		{
+			"active users", "SELECT count(*) FROM `users` WHERE last_active_at >= ?", []driver.Value{since},
+			func(repo *adminRepository) (int64, error) { return repo.CountActiveUsers(context.Background(), since) },
+		},
+		{
+			"topics", "SELECT count(*) FROM `topics` WHERE created_at >= ?", []driver.Value{since},
+			func(repo *adminRepository) (int64, error) { return repo.CountTopicsSince(context.Background(), since) },
+		},
+		{
+			"messages", "SELECT count(*) FROM `messages` WHERE created_at >= ?", []driver.Value{since},
+			func(repo *adminRepository) (int64, error) {
+				return repo.CountMessagesSince(context.Background(), since)
+			},
+		},
+		{
+			"open reports", "SELECT count(*) FROM `reports` WHERE status IN (?,?)",
+			[]driver.Value{model.ReportStatusPending, model.ReportStatusProcessing},
+			func(repo *adminRepository) (int64, error) { return repo.CountOpenReports(context.Background()) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			repo := NewAdminRepository(db).(*adminRepository)
+
+			mock.ExpectQuery("^" + regexp.QuoteMeta(tt.query) + "$").
+				WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(42))
+
+			count, err := tt.count(repo)
+			if err != nil {
+				t.Fatalf("count: %v", err)
+			}
+			if count != 42 {
+				t.Errorf("count = %d, want 42", count)
+			}
+		})
+	}
+}
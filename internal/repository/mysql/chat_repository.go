@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type chatRepository struct {
@@ -29,15 +30,49 @@ func (r *chatRepository) CreateRoom(ctx context.Context, room *model.ChatRoom) e
 	})
 }
 
+// ListPrivateRooms 根据一批私聊唯一键获取已存在的私聊房间
+func (r *chatRepository) ListPrivateRooms(ctx context.Context, pairKeys []string) ([]*model.ChatRoom, error) {
+	var rooms []*model.ChatRoom
+	if len(pairKeys) == 0 {
+		return rooms, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("pair_key IN ?", pairKeys).
+		Find(&rooms).Error
+	if err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// CreateRoomWithMembers 在同一事务中创建聊天室并添加成员
+func (r *chatRepository) CreateRoomWithMembers(ctx context.Context, room *model.ChatRoom, members []*model.ChatRoomMember) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(room).Error; err != nil {
+			return err
+		}
+		for _, member := range members {
+			member.ChatRoomID = room.ID
+		}
+		return tx.Create(&members).Error
+	})
+}
+
 // UpdateRoom 更新聊天室信息
 func (r *chatRepository) UpdateRoom(ctx context.Context, room *model.ChatRoom) error {
 	return r.db.WithContext(ctx).Save(room).Error
 }
 
-// GetRoomByID 获取聊天室信息
+// roomActive 过滤已关闭的聊天室
+func roomActive(db *gorm.DB) *gorm.DB {
+	return db.Where("chat_rooms.status = ?", model.ChatRoomStatusActive)
+}
+
+// GetRoomByID 获取聊天室信息，已关闭的聊天室视为不存在
 func (r *chatRepository) GetRoomByID(ctx context.Context, id uint64) (*model.ChatRoom, error) {
 	var room model.ChatRoom
 	err := r.db.WithContext(ctx).
+		Scopes(roomActive).
 		First(&room, id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -48,6 +83,21 @@ func (r *chatRepository) GetRoomByID(ctx context.Context, id uint64) (*model.Cha
 	return &room, nil
 }
 
+// GetPrivateRoom 根据私聊唯一键获取私聊房间
+func (r *chatRepository) GetPrivateRoom(ctx context.Context, pairKey string) (*model.ChatRoom, error) {
+	var room model.ChatRoom
+	err := r.db.WithContext(ctx).
+		Where("pair_key = ?", pairKey).
+		First(&room).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
 // ListUserRooms 获取用户的聊天室列表
 func (r *chatRepository) ListUserRooms(ctx context.Context, userID uint64, offset, limit int) ([]*model.ChatRoom, int64, error) {
 	var rooms []*model.ChatRoom
@@ -58,6 +108,7 @@ func (r *chatRepository) ListUserRooms(ctx context.Context, userID uint64, offse
 		Where("user_id = ?", userID)
 
 	db := r.db.WithContext(ctx).
+		Scopes(roomActive).
 		Where("id IN (?)", subQuery)
 
 	// 获取总数
@@ -114,7 +165,9 @@ func (r *chatRepository) RemoveMember(ctx context.Context, roomID, userID uint64
 			return err
 		}
 
-		return nil
+		// 取消该成员的置顶
+		return tx.Where("chat_room_id = ? AND user_id = ?", roomID, userID).
+			Delete(&model.PinnedChatRoom{}).Error
 	})
 }
 
@@ -137,6 +190,108 @@ func (r *chatRepository) GetRoomMembers(ctx context.Context, roomID uint64) ([]*
 	return members, nil
 }
 
+// TransferOwnership 转让群主，原群主降为管理员
+func (r *chatRepository) TransferOwnership(ctx context.Context, roomID, fromUserID, toUserID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.ChatRoomMember{}).
+			Where("chat_room_id = ? AND user_id = ?", roomID, fromUserID).
+			Update("role", "admin").Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.ChatRoomMember{}).
+			Where("chat_room_id = ? AND user_id = ?", roomID, toUserID).
+			Update("role", "owner").Error
+	})
+}
+
+// LeaveRoom 成员退出聊天室
+// newOwnerID 不为 0 时将群主转让给该成员；退出后房间无剩余成员时关闭关联话题
+func (r *chatRepository) LeaveRoom(ctx context.Context, roomID, userID, newOwnerID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 移除成员及其置顶
+		if err := tx.Where("chat_room_id = ? AND user_id = ?", roomID, userID).
+			Delete(&model.ChatRoomMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("chat_room_id = ? AND user_id = ?", roomID, userID).
+			Delete(&model.PinnedChatRoom{}).Error; err != nil {
+			return err
+		}
+
+		// 转让群主
+		if newOwnerID != 0 {
+			if err := tx.Model(&model.ChatRoomMember{}).
+				Where("chat_room_id = ? AND user_id = ?", roomID, newOwnerID).
+				Update("role", "owner").Error; err != nil {
+				return err
+			}
+		}
+
+		// 检查剩余成员
+		var remaining int64
+		if err := tx.Model(&model.ChatRoomMember{}).
+			Where("chat_room_id = ?", roomID).
+			Count(&remaining).Error; err != nil {
+			return err
+		}
+		if remaining > 0 {
+			return nil
+		}
+
+		// 无剩余成员时关闭聊天室及关联话题
+		if err := tx.Model(&model.ChatRoom{}).
+			Where("id = ?", roomID).
+			Update("status", model.ChatRoomStatusClosed).Error; err != nil {
+			return err
+		}
+		var room model.ChatRoom
+		if err := tx.Select("id", "topic_id").First(&room, roomID).Error; err != nil {
+			return err
+		}
+		if room.TopicID == nil {
+			return nil
+		}
+		return tx.Model(&model.Topic{}).
+			Where("id = ? AND status = ?", *room.TopicID, model.TopicStatusActive).
+			Update("status", model.TopicStatusClosed).Error
+	})
+}
+
+// ListUserRoomsByType 获取用户加入的指定类型聊天室
+func (r *chatRepository) ListUserRoomsByType(ctx context.Context, userID uint64, roomType string) ([]*model.ChatRoom, error) {
+	var rooms []*model.ChatRoom
+
+	subQuery := r.db.Model(&model.ChatRoomMember{}).
+		Select("chat_room_id").
+		Where("user_id = ?", userID)
+
+	err := r.db.WithContext(ctx).
+		Scopes(roomActive).
+		Where("id IN (?) AND type = ?", subQuery, roomType).
+		Order("id ASC").
+		Find(&rooms).Error
+	if err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// GetSharedRooms 获取两个用户共同加入的聊天室(不含私聊)
+func (r *chatRepository) GetSharedRooms(ctx context.Context, userA, userB uint64) ([]*model.ChatRoom, error) {
+	var rooms []*model.ChatRoom
+	err := r.db.WithContext(ctx).
+		Joins("JOIN chat_room_members ma ON ma.chat_room_id = chat_rooms.id AND ma.user_id = ?", userA).
+		Joins("JOIN chat_room_members mb ON mb.chat_room_id = chat_rooms.id AND mb.user_id = ?", userB).
+		Where("chat_rooms.type <> ?", "individual").
+		Scopes(roomActive).
+		Order("chat_rooms.updated_at DESC").
+		Find(&rooms).Error
+	if err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
 // CreateMessage 创建消息
 func (r *chatRepository) CreateMessage(ctx context.Context, message *model.Message) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -156,11 +311,49 @@ func (r *chatRepository) CreateMessage(ctx context.Context, message *model.Messa
 	})
 }
 
-// GetMessagesByRoom 获取聊天室消息列表（向前加载）
-func (r *chatRepository) GetMessagesByRoom(ctx context.Context, roomID uint64, beforeID uint64, limit int) ([]*model.Message, error) {
+// CreateTopicShareMessage 创建分享话题消息，并在同一事务中记录分享互动、更新话题分享数
+// 同一用户重复分享只保留一条互动记录
+func (r *chatRepository) CreateTopicShareMessage(ctx context.Context, message *model.Message, share *model.TopicInteraction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.ChatRoom{}).
+			Where("id = ?", message.ChatRoomID).
+			UpdateColumn("updated_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		// 已有分享记录时恢复为有效状态
+		if err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"interaction_status": model.InteractionStatusActive,
+				"updated_at":         time.Now(),
+			}),
+		}).Create(share).Error; err != nil {
+			return err
+		}
+
+		return updateTopicCounts(tx, share.TopicID)
+	})
+}
+
+// notHiddenFor 过滤用户仅对自己删除的消息
+func (r *chatRepository) notHiddenFor(viewerID uint64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("id NOT IN (?)", r.db.Model(&model.HiddenMessage{}).
+			Select("message_id").
+			Where("user_id = ?", viewerID))
+	}
+}
+
+// GetMessagesByRoom 获取聊天室消息列表（向前加载），不含 viewerID 隐藏的消息
+func (r *chatRepository) GetMessagesByRoom(ctx context.Context, roomID, viewerID uint64, beforeID uint64, limit int) ([]*model.Message, error) {
 	var messages []*model.Message
 	query := r.db.WithContext(ctx).
 		Where("chat_room_id = ?", roomID).
+		Scopes(r.notHiddenFor(viewerID)).
 		Preload("Sender").
 		Preload("MessageMedia").
 		Order("id DESC").
@@ -182,11 +375,112 @@ func (r *chatRepository) GetMessagesByRoom(ctx context.Context, roomID uint64, b
 	return messages, nil
 }
 
-// GetLatestMessages 获取聊天室最新消息
-func (r *chatRepository) GetLatestMessages(ctx context.Context, roomID uint64, limit int) ([]*model.Message, error) {
+// GetMessagesAfter 获取指定消息之后的消息（向后加载），不含 viewerID 隐藏的消息
+func (r *chatRepository) GetMessagesAfter(ctx context.Context, roomID, viewerID uint64, afterID uint64, limit int) ([]*model.Message, error) {
+	var messages []*model.Message
+	err := r.db.WithContext(ctx).
+		Where("chat_room_id = ? AND id > ?", roomID, afterID).
+		Scopes(r.notHiddenFor(viewerID)).
+		Preload("Sender").
+		Preload("MessageMedia").
+		Order("id ASC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetMessagesAround 获取指定消息前后的消息（按时间正序，包含该消息），不含 viewerID 隐藏的消息
+func (r *chatRepository) GetMessagesAround(ctx context.Context, roomID, viewerID, messageID uint64, beforeN, afterN int) ([]*model.Message, error) {
+	var before []*model.Message
+	if beforeN > 0 {
+		if err := r.db.WithContext(ctx).
+			Where("chat_room_id = ? AND id < ?", roomID, messageID).
+			Scopes(r.notHiddenFor(viewerID)).
+			Preload("Sender").
+			Preload("MessageMedia").
+			Order("id DESC").
+			Limit(beforeN).
+			Find(&before).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var after []*model.Message
+	if err := r.db.WithContext(ctx).
+		Where("chat_room_id = ? AND id >= ?", roomID, messageID).
+		Scopes(r.notHiddenFor(viewerID)).
+		Preload("Sender").
+		Preload("MessageMedia").
+		Order("id ASC").
+		Limit(afterN + 1).
+		Find(&after).Error; err != nil {
+		return nil, err
+	}
+
+	messages := make([]*model.Message, 0, len(before)+len(after))
+	for i := len(before) - 1; i >= 0; i-- {
+		messages = append(messages, before[i])
+	}
+	return append(messages, after...), nil
+}
+
+// GetMessageByID 根据ID获取消息
+func (r *chatRepository) GetMessageByID(ctx context.Context, id uint64) (*model.Message, error) {
+	var message model.Message
+	if err := r.db.WithContext(ctx).First(&message, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+// DeleteMessageForEveryone 对所有人删除消息，清空内容和媒体并记录删除时间作为墓碑
+func (r *chatRepository) DeleteMessageForEveryone(ctx context.Context, messageID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Message{}).
+			Where("id = ? AND deleted_at IS NULL", messageID).
+			Updates(map[string]interface{}{
+				"content":    "",
+				"deleted_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Where("message_id = ?", messageID).
+			Delete(&model.MessageMedia{}).Error
+	})
+}
+
+// HideMessage 对用户隐藏消息，重复隐藏不报错
+func (r *chatRepository) HideMessage(ctx context.Context, userID, messageID uint64) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.HiddenMessage{UserID: userID, MessageID: messageID}).Error
+}
+
+// IsMessageHidden 检查消息是否已被用户隐藏
+func (r *chatRepository) IsMessageHidden(ctx context.Context, userID, messageID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.HiddenMessage{}).
+		Where("user_id = ? AND message_id = ?", userID, messageID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetLatestMessages 获取聊天室最新消息，不含 viewerID 隐藏的消息
+func (r *chatRepository) GetLatestMessages(ctx context.Context, roomID, viewerID uint64, limit int) ([]*model.Message, error) {
 	var messages []*model.Message
 	err := r.db.WithContext(ctx).
 		Where("chat_room_id = ?", roomID).
+		Scopes(r.notHiddenFor(viewerID)).
 		Preload("Sender").
 		Preload("MessageMedia").
 		Order("id DESC").
@@ -204,6 +498,19 @@ func (r *chatRepository) GetLatestMessages(ctx context.Context, roomID uint64, l
 	return messages, nil
 }
 
+// GetTotalUnread 统计用户所有聊天室的未读消息总数
+func (r *chatRepository) GetTotalUnread(ctx context.Context, userID uint64) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).
+		Table("messages").
+		Joins("JOIN chat_room_members ON chat_room_members.chat_room_id = messages.chat_room_id").
+		Where("chat_room_members.user_id = ?", userID).
+		Where("messages.id > chat_room_members.last_read_message_id").
+		Where("messages.sender_id <> ?", userID).
+		Count(&total).Error
+	return total, err
+}
+
 // AddMessageMedia 添加消息媒体
 func (r *chatRepository) AddMessageMedia(ctx context.Context, media *model.MessageMedia) error {
 	return r.db.WithContext(ctx).Create(media).Error
@@ -243,7 +550,9 @@ func (r *chatRepository) GetPinnedRooms(ctx context.Context, userID uint64) ([]*
 	var rooms []*model.ChatRoom
 	err := r.db.WithContext(ctx).
 		Joins("JOIN pinned_chat_rooms ON pinned_chat_rooms.chat_room_id = chat_rooms.id").
+		Joins("JOIN chat_room_members ON chat_room_members.chat_room_id = chat_rooms.id AND chat_room_members.user_id = pinned_chat_rooms.user_id").
 		Where("pinned_chat_rooms.user_id = ?", userID).
+		Scopes(roomActive).
 		Preload("ChatRoomMembers", func(db *gorm.DB) *gorm.DB {
 			return db.Order("joined_at DESC")
 		}).
@@ -0,0 +1,257 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"DistanceBack_v1/internal/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectMessagePage 期望一次消息查询及其媒体预加载
+func expectMessagePage(mock sqlmock.Sqlmock, messageID uint64) {
+	mock.ExpectQuery("SELECT \\* FROM `messages` WHERE \\(?chat_room_id = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chat_room_id", "sender_id", "content_type"}).
+			AddRow(messageID, 1, 0, "image"))
+	mock.ExpectQuery("SELECT \\* FROM `message_media` WHERE `message_media`.`message_id` = \\?").
+		WithArgs(messageID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "message_id", "media_type", "media_url"}).
+			AddRow(messageID*10, messageID, "image", "https://example.com/a.jpg"))
+}
+
+func TestMessagePaginatorsPreloadMedia(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		pages []uint64
+		fetch func(repo *chatRepository) ([]*model.Message, error)
+	}{
+		{
+			name:  "before",
+			pages: []uint64{5},
+			fetch: func(repo *chatRepository) ([]*model.Message, error) {
+				return repo.GetMessagesByRoom(ctx, 1, 7, 10, 20)
+			},
+		},
+		{
+			name:  "after",
+			pages: []uint64{5},
+			fetch: func(repo *chatRepository) ([]*model.Message, error) {
+				return repo.GetMessagesAfter(ctx, 1, 7, 4, 20)
+			},
+		},
+		{
+			name:  "around",
+			pages: []uint64{4, 5},
+			fetch: func(repo *chatRepository) ([]*model.Message, error) {
+				return repo.GetMessagesAround(ctx, 1, 7, 5, 1, 0)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			for _, id := range tt.pages {
+				expectMessagePage(mock, id)
+			}
+
+			messages, err := tt.fetch(&chatRepository{db: db})
+			if err != nil {
+				t.Fatalf("fetch: %v", err)
+			}
+			if len(messages) != len(tt.pages) {
+				t.Fatalf("len(messages) = %d, want %d", len(messages), len(tt.pages))
+			}
+			for _, m := range messages {
+				if len(m.MessageMedia) != 1 || m.MessageMedia[0].MessageID != m.ID {
+					t.Errorf("message %d media = %+v", m.ID, m.MessageMedia)
+				}
+			}
+		})
+	}
+}
+
+func TestGetSharedRoomsRequiresBothMembers(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewChatRepository(db)
+
+	mock.ExpectQuery("FROM `chat_rooms` "+regexp.QuoteMeta(
+		"JOIN chat_room_members ma ON ma.chat_room_id = chat_rooms.id AND ma.user_id = ? "+
+			"JOIN chat_room_members mb ON mb.chat_room_id = chat_rooms.id AND mb.user_id = ? "+
+			"WHERE chat_rooms.type <> ? AND chat_rooms.status = ? ORDER BY chat_rooms.updated_at DESC")).
+		WithArgs(7, 8, "individual", "active").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).AddRow(3, "group"))
+
+	rooms, err := repo.GetSharedRooms(context.Background(), 7, 8)
+	if err != nil {
+		t.Fatalf("GetSharedRooms: %v", err)
+	}
+	if len(rooms) != 1 || rooms[0].ID != 3 {
+		t.Errorf("rooms = %+v", rooms)
+	}
+}
+
+func TestCreateMessageRollsBackWhenMediaFails(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &chatRepository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `messages`").WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectExec("INSERT INTO `message_media`").WillReturnError(errors.New("deadlock found"))
+	mock.ExpectRollback()
+
+	msg := &model.Message{
+		ChatRoomID:   1,
+		SenderID:     7,
+		ContentType:  "image",
+		MessageMedia: []model.MessageMedia{{MediaType: "image", MediaURL: "https://example.com/a.jpg"}},
+	}
+	if err := repo.CreateMessage(context.Background(), msg); err == nil {
+		t.Fatal("CreateMessage succeeded, want media insert error")
+	}
+}
+
+func TestLeaveRoomRemovesPin(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &chatRepository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `chat_room_members` WHERE chat_room_id = \\? AND user_id = \\?").
+		WithArgs(1, 8).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM `pinned_chat_rooms` WHERE chat_room_id = \\? AND user_id = \\?").
+		WithArgs(1, 8).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `chat_room_members`").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectCommit()
+
+	if err := repo.LeaveRoom(context.Background(), 1, 8, 0); err != nil {
+		t.Fatalf("LeaveRoom: %v", err)
+	}
+}
+
+func TestGetRoomByIDHidesClosedRoom(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewChatRepository(db)
+
+	mock.ExpectQuery("SELECT \\* FROM `chat_rooms` WHERE `chat_rooms`.`id` = \\? AND chat_rooms.status = \\?").
+		WithArgs(3, "active", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "status"}))
+
+	room, err := repo.GetRoomByID(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("GetRoomByID: %v", err)
+	}
+	if room != nil {
+		t.Errorf("room = %+v, want nil for closed room", room)
+	}
+}
+
+func TestListUserRoomsExcludesClosedRooms(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewChatRepository(db)
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `chat_rooms` WHERE id IN \\(SELECT `chat_room_id` FROM `chat_room_members` WHERE user_id = \\?\\) AND chat_rooms.status = \\?").
+		WithArgs(7, "active").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT \\* FROM `chat_rooms` WHERE id IN .* AND chat_rooms.status = \\?").
+		WithArgs(7, "active", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	rooms, total, err := repo.ListUserRooms(context.Background(), 7, 0, 20)
+	if err != nil {
+		t.Fatalf("ListUserRooms: %v", err)
+	}
+	if total != 0 || len(rooms) != 0 {
+		t.Errorf("rooms = %+v, total = %d, want none", rooms, total)
+	}
+}
+
+func TestLeaveRoomClosesEmptiedRoom(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &chatRepository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `chat_room_members`").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM `pinned_chat_rooms`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `chat_room_members`").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("UPDATE `chat_rooms` SET `status`=\\?").
+		WithArgs("closed", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT `id`,`topic_id` FROM `chat_rooms`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic_id"}).AddRow(1, 9))
+	mock.ExpectExec("UPDATE `topics` SET `status`=\\?").
+		WithArgs("closed", sqlmock.AnyArg(), 9, "active").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.LeaveRoom(context.Background(), 1, 8, 0); err != nil {
+		t.Fatalf("LeaveRoom: %v", err)
+	}
+}
+
+func TestGetLatestMessagesSkipsHiddenMessages(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewChatRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE chat_room_id = ? AND "+
+		"id NOT IN (SELECT `message_id` FROM `hidden_messages` WHERE user_id = ?) ORDER BY id DESC LIMIT ?")).
+		WithArgs(1, 8, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, err := repo.GetLatestMessages(context.Background(), 1, 8, 1); err != nil {
+		t.Fatalf("GetLatestMessages: %v", err)
+	}
+}
+
+func TestCreateTopicShareMessageUpsertsShareInTransaction(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &chatRepository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `messages`").WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectExec("UPDATE `chat_rooms` SET `updated_at`=\\? WHERE id = \\?").
+		WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `topic_interactions` .* ON DUPLICATE KEY UPDATE `interaction_status`=\\?,`updated_at`=\\?").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	for _, count := range []int{0, 1, 1} {
+		mock.ExpectQuery("(?i)SELECT count\\(.*\\) FROM `topic_interactions`").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+	}
+	mock.ExpectExec("UPDATE `topics` SET .*`shares_count`=\\?").
+		WithArgs(0, 1, 1, sqlmock.AnyArg(), 100).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	msg := &model.Message{ChatRoomID: 1, SenderID: 7, ContentType: model.MessageContentTopic, Content: "100"}
+	share := &model.TopicInteraction{
+		TopicID:           100,
+		UserID:            7,
+		InteractionType:   model.InteractionTypeShare,
+		InteractionStatus: model.InteractionStatusActive,
+	}
+	if err := repo.CreateTopicShareMessage(context.Background(), msg, share); err != nil {
+		t.Fatalf("CreateTopicShareMessage: %v", err)
+	}
+}
+
+func TestCreateTopicShareMessageRollsBackWhenShareFails(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &chatRepository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `messages`").WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectExec("UPDATE `chat_rooms`").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `topic_interactions`").WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	msg := &model.Message{ChatRoomID: 1, SenderID: 7, ContentType: model.MessageContentTopic, Content: "100"}
+	share := &model.TopicInteraction{TopicID: 100, UserID: 7, InteractionType: model.InteractionTypeShare}
+	if err := repo.CreateTopicShareMessage(context.Background(), msg, share); err == nil {
+		t.Fatal("CreateTopicShareMessage succeeded, want share insert error")
+	}
+}
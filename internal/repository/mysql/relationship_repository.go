@@ -64,6 +64,17 @@ func (r *relationshipRepository) Delete(ctx context.Context, followerID, followi
 		Delete(&model.UserRelationship{}).Error
 }
 
+// DeletePending 删除待处理的关注请求，已接受或已拉黑的关系不受影响
+func (r *relationshipRepository) DeletePending(ctx context.Context, followerID, followingID uint64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("follower_id = ? AND following_id = ? AND status = ?", followerID, followingID, "pending").
+		Delete(&model.UserRelationship{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // GetRelationship 获取两个用户之间的关系
 func (r *relationshipRepository) GetRelationship(ctx context.Context, followerID, followingID uint64) (*model.UserRelationship, error) {
 	var relationship model.UserRelationship
@@ -80,6 +91,22 @@ func (r *relationshipRepository) GetRelationship(ctx context.Context, followerID
 	return &relationship, nil
 }
 
+// ListBetween 获取用户与一批目标用户之间双向的关系记录
+func (r *relationshipRepository) ListBetween(ctx context.Context, userID uint64, targetIDs []uint64) ([]*model.UserRelationship, error) {
+	var relationships []*model.UserRelationship
+	if len(targetIDs) == 0 {
+		return relationships, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("(follower_id = ? AND following_id IN ?) OR (following_id = ? AND follower_id IN ?)",
+			userID, targetIDs, userID, targetIDs).
+		Find(&relationships).Error
+	if err != nil {
+		return nil, err
+	}
+	return relationships, nil
+}
+
 // GetFollowers 获取用户的粉丝列表
 func (r *relationshipRepository) GetFollowers(ctx context.Context, userID uint64, status string, offset, limit int) ([]*model.UserRelationship, int64, error) {
 	var relationships []*model.UserRelationship
@@ -173,6 +200,24 @@ func (r *relationshipRepository) ExistsRelationship(ctx context.Context, followe
 	return count > 0, nil
 }
 
+// RejectAndBlock 在同一事务中拒绝关注请求并拉黑请求者
+// 删除双方之间的所有关系后创建拉黑关系
+func (r *relationshipRepository) RejectAndBlock(ctx context.Context, userID, followerID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)",
+			followerID, userID, userID, followerID).
+			Delete(&model.UserRelationship{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&model.UserRelationship{
+			FollowerID:  userID,
+			FollowingID: followerID,
+			Status:      "blocked",
+		}).Error
+	})
+}
+
 // // GetMutualFollowers 获取共同关注者（好友）
 // func (r *relationshipRepository) GetMutualFollowers(ctx context.Context, userID1, userID2 uint64, offset, limit int) ([]*model.User, int64, error) {
 // 	var users []*model.User
@@ -290,3 +335,64 @@ func (r *relationshipRepository) ExistsRelationship(ctx context.Context, followe
 
 // 	return relationships, total, nil
 // }
+
+// GetCounts 一次查询统计粉丝、关注、好友及待处理请求数
+func (r *relationshipRepository) GetCounts(ctx context.Context, userID uint64) (*repository.RelationshipCounts, error) {
+	var counts repository.RelationshipCounts
+	err := r.db.WithContext(ctx).
+		Model(&model.UserRelationship{}).
+		Select(`COALESCE(SUM(following_id = ? AND status = 'accepted'), 0) AS followers,
+			COALESCE(SUM(follower_id = ? AND status = 'accepted'), 0) AS following,
+			COALESCE(SUM(follower_id = ? AND status = 'accepted' AND EXISTS (
+				SELECT 1 FROM user_relationships AS r2
+				WHERE r2.follower_id = user_relationships.following_id
+					AND r2.following_id = user_relationships.follower_id
+					AND r2.status = 'accepted')), 0) AS friends,
+			COALESCE(SUM(following_id = ? AND status = 'pending'), 0) AS pending`,
+			userID, userID, userID, userID).
+		Where("follower_id = ? OR following_id = ?", userID, userID).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// GetCountsByStatus 一次查询统计粉丝与关注两个方向各状态的关系数
+func (r *relationshipRepository) GetCountsByStatus(ctx context.Context, userID uint64) (*repository.RelationshipCountsByStatus, error) {
+	var row struct {
+		FollowersPending   int64
+		FollowersAccepted  int64
+		FollowersBlocked   int64
+		FollowingsPending  int64
+		FollowingsAccepted int64
+		FollowingsBlocked  int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.UserRelationship{}).
+		Select(`COALESCE(SUM(following_id = ? AND status = 'pending'), 0) AS followers_pending,
+			COALESCE(SUM(following_id = ? AND status = 'accepted'), 0) AS followers_accepted,
+			COALESCE(SUM(following_id = ? AND status = 'blocked'), 0) AS followers_blocked,
+			COALESCE(SUM(follower_id = ? AND status = 'pending'), 0) AS followings_pending,
+			COALESCE(SUM(follower_id = ? AND status = 'accepted'), 0) AS followings_accepted,
+			COALESCE(SUM(follower_id = ? AND status = 'blocked'), 0) AS followings_blocked`,
+			userID, userID, userID, userID, userID, userID).
+		Where("follower_id = ? OR following_id = ?", userID, userID).
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.RelationshipCountsByStatus{
+		Followers: repository.RelationshipStatusCounts{
+			Pending:  row.FollowersPending,
+			Accepted: row.FollowersAccepted,
+			Blocked:  row.FollowersBlocked,
+		},
+		Followings: repository.RelationshipStatusCounts{
+			Pending:  row.FollowingsPending,
+			Accepted: row.FollowingsAccepted,
+			Blocked:  row.FollowingsBlocked,
+		},
+	}, nil
+}
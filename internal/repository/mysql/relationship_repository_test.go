@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"DistanceBack_v1/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestGetCountsByStatusUsesOneGroupedQuery(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &relationshipRepository{db: db}
+
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(following_id = \\? AND status = 'pending'\\), 0\\) AS followers_pending,.* "+
+		"FROM `user_relationships` WHERE follower_id = \\? OR following_id = \\?").
+		WithArgs(7, 7, 7, 7, 7, 7, 7, 7).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"followers_pending", "followers_accepted", "followers_blocked",
+			"followings_pending", "followings_accepted", "followings_blocked",
+		}).AddRow(1, 2, 3, 4, 5, 6))
+
+	counts, err := repo.GetCountsByStatus(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetCountsByStatus: %v", err)
+	}
+	want := repository.RelationshipCountsByStatus{
+		Followers:  repository.RelationshipStatusCounts{Pending: 1, Accepted: 2, Blocked: 3},
+		Followings: repository.RelationshipStatusCounts{Pending: 4, Accepted: 5, Blocked: 6},
+	}
+	if *counts != want {
+		t.Errorf("counts = %+v, want %+v", *counts, want)
+	}
+}
+
+func TestRejectAndBlockInOneTransaction(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &relationshipRepository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `user_relationships` WHERE "+
+		"(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)")).
+		WithArgs(8, 7, 7, 8).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO `user_relationships`").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 7, 8, "blocked", nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.RejectAndBlock(context.Background(), 7, 8); err != nil {
+		t.Fatalf("RejectAndBlock: %v", err)
+	}
+}
+
+func TestRejectAndBlockRollsBackWhenBlockFails(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &relationshipRepository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `user_relationships`").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `user_relationships`").WillReturnError(errors.New("deadlock found"))
+	mock.ExpectRollback()
+
+	if err := repo.RejectAndBlock(context.Background(), 7, 8); err == nil {
+		t.Fatal("RejectAndBlock succeeded, want insert error")
+	}
+}
@@ -0,0 +1,24 @@
+package mysql
+
+import (
+	"context"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+// NewReportRepository 创建举报仓储实例
+func NewReportRepository(db *gorm.DB) repository.ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// Create 创建举报记录
+func (r *reportRepository) Create(ctx context.Context, report *model.Report) error {
+	return r.db.WithContext(ctx).Create(report).Error
+}
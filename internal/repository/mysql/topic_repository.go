@@ -5,8 +5,11 @@ import (
 	"DistanceBack_v1/internal/repository"
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type topicRepository struct {
@@ -35,20 +38,126 @@ func (r *topicRepository) Update(ctx context.Context, topic *model.Topic) error
 	return r.db.WithContext(ctx).Save(topic).Error
 }
 
-// Delete 删除话题
+// ListByIDs 批量获取话题，不存在的ID会被忽略
+func (r *topicRepository) ListByIDs(ctx context.Context, ids []uint64) ([]*model.Topic, error) {
+	var topics []*model.Topic
+	if len(ids) == 0 {
+		return topics, nil
+	}
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("id IN ?", ids).
+		Find(&topics).Error
+	if err != nil {
+		return nil, err
+	}
+	return topics, nil
+}
+
+// TransferOwner 转移话题作者，并在同一事务中写入审计日志
+func (r *topicRepository) TransferOwner(ctx context.Context, topicID, newOwnerID uint64, audit *model.AuditLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Topic{}).
+			Where("id = ?", topicID).
+			Update("user_id", newOwnerID).Error; err != nil {
+			return err
+		}
+		return tx.Create(audit).Error
+	})
+}
+
+// Delete 关闭话题并记录关闭时间，数据保留以便在宽限期内恢复，由清理接口彻底删除
+// 只关闭有效的话题，重复删除不会刷新关闭时间而延长宽限期
 func (r *topicRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Topic{}).
+		Where("id = ? AND status = ?", id, model.TopicStatusActive).
+		Updates(map[string]interface{}{
+			"status":    model.TopicStatusClosed,
+			"closed_at": time.Now(),
+		}).Error
+}
+
+// Restore 重新开放 closedSince 之后关闭的话题，返回是否恢复成功
+func (r *topicRepository) Restore(ctx context.Context, id uint64, closedSince time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Topic{}).
+		Where("id = ? AND status = ? AND closed_at >= ?", id, model.TopicStatusClosed, closedSince).
+		Updates(map[string]interface{}{
+			"status":    model.TopicStatusActive,
+			"closed_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// notExpired 过滤已过期的话题，过期时间为空表示永久有效
+func notExpired(db *gorm.DB) *gorm.DB {
+	return db.Where("topics.expires_at IS NULL OR topics.expires_at > ?", time.Now())
+}
+
+// likeEscaper 转义 LIKE 通配符，使关键词按字面匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern 构造包含关键词的 LIKE 模式
+func containsPattern(keyword string) string {
+	return "%" + likeEscaper.Replace(keyword) + "%"
+}
+
+// createdBetween 按创建时间范围过滤
+func createdBetween(opts repository.TopicListOptions) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !opts.CreatedFrom.IsZero() {
+			db = db.Where("topics.created_at >= ?", opts.CreatedFrom)
+		}
+		if !opts.CreatedTo.IsZero() {
+			db = db.Where("topics.created_at < ?", opts.CreatedTo)
+		}
+		return db
+	}
+}
+
+// topicOrder 返回话题排序子句，以 id 作为次级排序保证分页稳定
+func topicOrder(sortBy string) string {
+	if sortBy == model.TopicSortPopular {
+		return "topics.likes_count DESC, topics.views_count DESC, topics.created_at DESC, topics.id DESC"
+	}
+	return "topics.created_at DESC, topics.id DESC"
+}
+
+// HardDelete 彻底删除话题及其关联数据，并扣减标签使用次数
+// 关联的聊天室保留，只解除与话题的关联
+func (r *topicRepository) HardDelete(ctx context.Context, id uint64) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 删除话题相关的所有数据
-		if err := tx.Where("topic_id = ?", id).Delete(&model.TopicImage{}).Error; err != nil {
+		var tagIDs []uint64
+		if err := tx.Model(&model.TopicTag{}).
+			Where("topic_id = ?", id).
+			Pluck("tag_id", &tagIDs).Error; err != nil {
 			return err
 		}
+		if len(tagIDs) > 0 {
+			if err := tx.Model(&model.Tag{}).
+				Where("id IN ? AND use_count > 0", tagIDs).
+				UpdateColumn("use_count", gorm.Expr("use_count - ?", 1)).Error; err != nil {
+				return err
+			}
+		}
+
+		// chat_rooms.topic_id 的外键没有级联，需先解除关联才能删除话题
+		if err := tx.Model(&model.ChatRoom{}).
+			Where("topic_id = ?", id).
+			UpdateColumn("topic_id", nil).Error; err != nil {
+			return err
+		}
+
 		if err := tx.Where("topic_id = ?", id).Delete(&model.TopicTag{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("topic_id = ?", id).Delete(&model.TopicImage{}).Error; err != nil {
+			return err
+		}
 		if err := tx.Where("topic_id = ?", id).Delete(&model.TopicInteraction{}).Error; err != nil {
 			return err
 		}
-		// 删除话题
 		return tx.Delete(&model.Topic{}, id).Error
 	})
 }
@@ -73,11 +182,11 @@ func (r *topicRepository) GetByID(ctx context.Context, id uint64) (*model.Topic,
 }
 
 // List 获取话题列表
-func (r *topicRepository) List(ctx context.Context, offset, limit int) ([]*model.Topic, int64, error) {
+func (r *topicRepository) List(ctx context.Context, opts repository.TopicListOptions, offset, limit int) ([]*model.Topic, int64, error) {
 	var topics []*model.Topic
 	var total int64
 
-	db := r.db.WithContext(ctx).Where("status = ?", "active")
+	db := r.db.WithContext(ctx).Where("status = ?", "active").Scopes(notExpired, createdBetween(opts))
 
 	if err := db.Model(&model.Topic{}).Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -87,7 +196,7 @@ func (r *topicRepository) List(ctx context.Context, offset, limit int) ([]*model
 		Preload("TopicImages", func(db *gorm.DB) *gorm.DB {
 			return db.Order("sort_order ASC")
 		}).
-		Order("created_at DESC").
+		Order(topicOrder(opts.SortBy)).
 		Offset(offset).
 		Limit(limit).
 		Find(&topics).Error
@@ -100,11 +209,14 @@ func (r *topicRepository) List(ctx context.Context, offset, limit int) ([]*model
 }
 
 // ListByUser 获取用户的话题列表
-func (r *topicRepository) ListByUser(ctx context.Context, userID uint64, offset, limit int) ([]*model.Topic, int64, error) {
+func (r *topicRepository) ListByUser(ctx context.Context, userID uint64, opts repository.TopicListOptions, offset, limit int) ([]*model.Topic, int64, error) {
 	var topics []*model.Topic
 	var total int64
 
-	db := r.db.WithContext(ctx).Where("user_id = ? AND status = ?", userID, "active")
+	db := r.db.WithContext(ctx).Where("user_id = ?", userID).Scopes(createdBetween(opts))
+	if !opts.IncludeInactive {
+		db = db.Where("status = ?", "active").Scopes(notExpired)
+	}
 
 	if err := db.Model(&model.Topic{}).Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -114,7 +226,7 @@ func (r *topicRepository) ListByUser(ctx context.Context, userID uint64, offset,
 		Preload("TopicImages", func(db *gorm.DB) *gorm.DB {
 			return db.Order("sort_order ASC")
 		}).
-		Order("created_at DESC").
+		Order(topicOrder(opts.SortBy)).
 		Offset(offset).
 		Limit(limit).
 		Find(&topics).Error
@@ -126,8 +238,131 @@ func (r *topicRepository) ListByUser(ctx context.Context, userID uint64, offset,
 	return topics, total, nil
 }
 
+// PinToProfile 将话题置顶到用户主页，重复置顶只更新置顶时间
+func (r *topicRepository) PinToProfile(ctx context.Context, userID, topicID uint64) error {
+	pinned := &model.ProfilePinnedTopic{
+		UserID:   userID,
+		TopicID:  topicID,
+		PinnedAt: time.Now(),
+	}
+	return r.db.WithContext(ctx).Save(pinned).Error
+}
+
+// UnpinFromProfile 取消主页置顶
+func (r *topicRepository) UnpinFromProfile(ctx context.Context, userID, topicID uint64) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND topic_id = ?", userID, topicID).
+		Delete(&model.ProfilePinnedTopic{}).Error
+}
+
+// ListProfilePinned 获取用户主页置顶的话题，按置顶时间倒序
+// 只返回仍属于该用户且有效的话题，已关闭、过期或转移的话题不再展示
+func (r *topicRepository) ListProfilePinned(ctx context.Context, userID uint64) ([]*model.Topic, error) {
+	var topics []*model.Topic
+	err := r.db.WithContext(ctx).
+		Joins("JOIN profile_pinned_topics ON profile_pinned_topics.topic_id = topics.id AND profile_pinned_topics.user_id = topics.user_id").
+		Where("profile_pinned_topics.user_id = ? AND topics.status = ?", userID, "active").
+		Scopes(notExpired).
+		Preload("User").
+		Preload("TopicImages", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC")
+		}).
+		Order("profile_pinned_topics.pinned_at DESC").
+		Find(&topics).Error
+	if err != nil {
+		return nil, err
+	}
+	return topics, nil
+}
+
+// ListClosedByUser 获取用户在 closedBefore 之前关闭的话题
+// 仍在恢复宽限期内的话题不返回，避免被彻底删除；过期话题由定时任务关闭后同样要等宽限期结束
+// 管理员下架(cancelled)的话题保留以便复核，不返回
+func (r *topicRepository) ListClosedByUser(ctx context.Context, userID uint64, closedBefore time.Time) ([]*model.Topic, error) {
+	var topics []*model.Topic
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("status = ? AND closed_at < ?", model.TopicStatusClosed, closedBefore).
+		Order("id ASC").
+		Find(&topics).Error
+	if err != nil {
+		return nil, err
+	}
+	return topics, nil
+}
+
+// ListRecentByUser 获取用户在 since 之后发布且仍有效的话题，按创建时间倒序
+// 已关闭或被下架的话题不返回，删除后重新发布不算重复
+func (r *topicRepository) ListRecentByUser(ctx context.Context, userID uint64, since time.Time, limit int) ([]*model.Topic, error) {
+	var topics []*model.Topic
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status = ? AND created_at >= ?", userID, model.TopicStatusActive, since).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&topics).Error
+	if err != nil {
+		return nil, err
+	}
+	return topics, nil
+}
+
+// CloseExpired 关闭已过期的有效话题，过期时间为空的永久话题不受影响
+func (r *topicRepository) CloseExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Topic{}).
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", model.TopicStatusActive, now).
+		Updates(map[string]interface{}{
+			"status":    model.TopicStatusClosed,
+			"closed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// CountByUser 统计用户发布的话题数
+func (r *topicRepository) CountByUser(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.Topic{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// Search 搜索话题，by 为 author 时按作者昵称匹配，否则匹配标题和内容
+func (r *topicRepository) Search(ctx context.Context, keyword, by string, offset, limit int) ([]*model.Topic, int64, error) {
+	var topics []*model.Topic
+	var total int64
+
+	pattern := containsPattern(keyword)
+	db := r.db.WithContext(ctx).Model(&model.Topic{}).
+		Where("topics.status = ?", model.TopicStatusActive).
+		Scopes(notExpired)
+	if by == model.TopicSearchByAuthor {
+		db = db.Joins("JOIN users ON users.id = topics.user_id").
+			Where("users.nickname LIKE ?", pattern)
+	} else {
+		db = db.Where("topics.title LIKE ? OR topics.content LIKE ?", pattern, pattern)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("User").
+		Select("topics.*").
+		Order(topicOrder(model.TopicSortRecent)).
+		Offset(offset).
+		Limit(limit).
+		Find(&topics).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return topics, total, nil
+}
+
 // GetNearbyTopics 获取附近的话题
-func (r *topicRepository) GetNearbyTopics(ctx context.Context, lat, lng float64, radius float64, offset, limit int) ([]*model.Topic, int64, error) {
+func (r *topicRepository) GetNearbyTopics(ctx context.Context, lat, lng float64, radius float64, opts repository.TopicListOptions, offset, limit int) ([]*model.Topic, int64, error) {
 	var topics []*model.Topic
 	var total int64
 
@@ -135,7 +370,12 @@ func (r *topicRepository) GetNearbyTopics(ctx context.Context, lat, lng float64,
 	distanceSQL := "ST_Distance_Sphere(POINT(location_longitude, location_latitude), POINT(?, ?))"
 	db := r.db.WithContext(ctx).
 		Where(fmt.Sprintf("%s <= ?", distanceSQL), lng, lat, radius).
-		Where("status = ?", "active")
+		Where("status = ?", "active").
+		Scopes(notExpired)
+	if !opts.ActiveSince.IsZero() {
+		db = db.Where("user_id IN (?)",
+			r.db.Model(&model.User{}).Select("id").Where("last_active_at >= ?", opts.ActiveSince))
+	}
 
 	if err := db.Model(&model.Topic{}).Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -146,7 +386,7 @@ func (r *topicRepository) GetNearbyTopics(ctx context.Context, lat, lng float64,
 			return db.Order("sort_order ASC")
 		}).
 		Select("*, "+distanceSQL+" as distance", lng, lat).
-		Order("created_at DESC").
+		Order(topicOrder(opts.SortBy)).
 		Offset(offset).
 		Limit(limit).
 		Find(&topics).Error
@@ -158,6 +398,52 @@ func (r *topicRepository) GetNearbyTopics(ctx context.Context, lat, lng float64,
 	return topics, total, nil
 }
 
+// ListRoomParticipants 获取话题关联群聊的成员，按加入时间倒序，每个用户只返回一次
+// 只返回 viewerID 所在的有效群聊中状态正常的成员，非成员查询结果为空
+func (r *topicRepository) ListRoomParticipants(ctx context.Context, topicID, viewerID uint64, limit int) ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.WithContext(ctx).
+		Select("users.*").
+		Joins("JOIN (?) AS rm ON rm.user_id = users.id",
+			r.db.Model(&model.ChatRoomMember{}).
+				Select("chat_room_members.user_id, MAX(chat_room_members.joined_at) AS joined_at").
+				Joins("JOIN chat_rooms ON chat_rooms.id = chat_room_members.chat_room_id").
+				Where("chat_rooms.topic_id = ? AND chat_rooms.type = ? AND chat_rooms.status = ?",
+					topicID, "group", model.ChatRoomStatusActive).
+				Where("chat_room_members.chat_room_id IN (?)",
+					r.db.Model(&model.ChatRoomMember{}).
+						Select("chat_room_id").
+						Where("user_id = ?", viewerID)).
+				Group("chat_room_members.user_id")).
+		Where("users.status = ?", model.UserStatusActive).
+		Order("rm.joined_at DESC").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ListRecentInteractors 获取最近与话题互动的用户，每个用户只返回一次
+func (r *topicRepository) ListRecentInteractors(ctx context.Context, topicID uint64, limit int) ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.WithContext(ctx).
+		Select("users.*").
+		Joins("JOIN (?) AS ti ON ti.user_id = users.id",
+			r.db.Model(&model.TopicInteraction{}).
+				Select("user_id, MAX(created_at) AS last_at").
+				Where("topic_id = ? AND interaction_status = ?", topicID, "active").
+				Group("user_id")).
+		Order("ti.last_at DESC").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // AddImages 添加话题图片
 func (r *topicRepository) AddImages(ctx context.Context, topicID uint64, images []*model.TopicImage) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -288,13 +574,14 @@ func (r *topicRepository) ListPopular(ctx context.Context, limit int) ([]*model.
 }
 
 // ListByTag 获取带有特定标签的话题列表
-func (r *topicRepository) ListByTag(ctx context.Context, tagID uint64, offset, limit int) ([]*model.Topic, int64, error) {
+func (r *topicRepository) ListByTag(ctx context.Context, tagID uint64, opts repository.TopicListOptions, offset, limit int) ([]*model.Topic, int64, error) {
 	var topics []*model.Topic
 	var total int64
 
 	db := r.db.WithContext(ctx).
 		Joins("JOIN topic_tags ON topic_tags.topic_id = topics.id").
-		Where("topic_tags.tag_id = ? AND topics.status = ?", tagID, "active")
+		Where("topic_tags.tag_id = ? AND topics.status = ?", tagID, "active").
+		Scopes(notExpired)
 
 	if err := db.Model(&model.Topic{}).Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -304,7 +591,7 @@ func (r *topicRepository) ListByTag(ctx context.Context, tagID uint64, offset, l
 		Preload("TopicImages", func(db *gorm.DB) *gorm.DB {
 			return db.Order("sort_order ASC")
 		}).
-		Order("topics.created_at DESC").
+		Order(topicOrder(opts.SortBy)).
 		Offset(offset).
 		Limit(limit).
 		Find(&topics).Error
@@ -322,6 +609,12 @@ func (r *topicRepository) AddInteraction(ctx context.Context, interaction *model
 		if err := tx.Create(interaction).Error; err != nil {
 			return err
 		}
+		// 记录每日点赞
+		if interaction.InteractionType == model.InteractionTypeLike {
+			if err := incrementDailyStat(tx, interaction.TopicID, "likes", time.Now()); err != nil {
+				return err
+			}
+		}
 		// 更新计数
 		return r.UpdateCounts(ctx, interaction.TopicID)
 	})
@@ -354,52 +647,163 @@ func (r *topicRepository) GetInteractions(ctx context.Context, topicID uint64, i
 	return interactions, nil
 }
 
-// IncrementViewCount 增加话题浏览次数
-func (r *topicRepository) IncrementViewCount(ctx context.Context, topicID uint64) error {
-	return r.db.WithContext(ctx).
-		Model(&model.Topic{}).
-		Where("id = ?", topicID).
-		UpdateColumn("views_count", gorm.Expr("views_count + ?", 1)).
-		Error
+// GetUserInteractions 获取用户在话题上的有效互动
+func (r *topicRepository) GetUserInteractions(ctx context.Context, topicID, userID uint64) ([]*model.TopicInteraction, error) {
+	var interactions []*model.TopicInteraction
+	err := r.db.WithContext(ctx).
+		Where("topic_id = ? AND user_id = ? AND interaction_status = ?",
+			topicID, userID, "active").
+		Find(&interactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return interactions, nil
 }
 
-// UpdateCounts 更新话题的各种计数
-func (r *topicRepository) UpdateCounts(ctx context.Context, topicID uint64) error {
+// ListLikers 按点赞记录ID倒序获取有效点赞及用户信息
+// beforeID 为上一页最后一条记录的ID，排除与 viewerID 之间存在拉黑关系的用户
+func (r *topicRepository) ListLikers(ctx context.Context, topicID, viewerID, beforeID uint64, limit int) ([]*model.TopicInteraction, error) {
+	var interactions []*model.TopicInteraction
+	query := r.db.WithContext(ctx).
+		Where("topic_id = ? AND interaction_type = ? AND interaction_status = ?",
+			topicID, model.InteractionTypeLike, model.InteractionStatusActive)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	if viewerID > 0 {
+		query = query.
+			Where("user_id NOT IN (?)", r.db.Model(&model.UserRelationship{}).
+				Select("following_id").
+				Where("follower_id = ? AND status = ?", viewerID, "blocked")).
+			Where("user_id NOT IN (?)", r.db.Model(&model.UserRelationship{}).
+				Select("follower_id").
+				Where("following_id = ? AND status = ?", viewerID, "blocked"))
+	}
+
+	err := query.
+		Preload("User").
+		Order("id DESC").
+		Limit(limit).
+		Find(&interactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+// ListInteractedByUser 按互动时间倒序获取用户互动过的有效话题
+// beforeID 为互动记录ID游标，返回的互动记录已预加载话题及作者
+func (r *topicRepository) ListInteractedByUser(ctx context.Context, userID uint64, interactionType string, beforeID uint64, limit int) ([]*model.TopicInteraction, error) {
+	var interactions []*model.TopicInteraction
+	query := r.db.WithContext(ctx).
+		Joins("JOIN topics ON topics.id = topic_interactions.topic_id").
+		Where("topic_interactions.user_id = ? AND topic_interactions.interaction_type = ? AND topic_interactions.interaction_status = ?",
+			userID, interactionType, model.InteractionStatusActive).
+		Where("topics.status = ?", model.TopicStatusActive).
+		Scopes(notExpired)
+	if beforeID > 0 {
+		query = query.Where("topic_interactions.id < ?", beforeID)
+	}
+
+	err := query.
+		Preload("Topic").
+		Preload("Topic.User").
+		Order("topic_interactions.id DESC").
+		Limit(limit).
+		Find(&interactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+// IncrementViewCount 增加话题浏览次数，同时累加当日浏览统计
+func (r *topicRepository) IncrementViewCount(ctx context.Context, topicID uint64) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 更新点赞数
-		var likesCount int64
-		if err := tx.Model(&model.TopicInteraction{}).
-			Where("topic_id = ? AND interaction_type = ? AND interaction_status = ?",
-				topicID, "like", "active").
-			Count(&likesCount).Error; err != nil {
+		if err := tx.Model(&model.Topic{}).
+			Where("id = ?", topicID).
+			UpdateColumn("views_count", gorm.Expr("views_count + ?", 1)).
+			Error; err != nil {
 			return err
 		}
+		return incrementDailyStat(tx, topicID, "views", time.Now())
+	})
+}
 
-		// 更新分享数
-		var sharesCount int64
-		if err := tx.Model(&model.TopicInteraction{}).
-			Where("topic_id = ? AND interaction_type = ? AND interaction_status = ?",
-				topicID, "share", "active").
-			Count(&sharesCount).Error; err != nil {
-			return err
-		}
+// GetDailyStats 获取话题从 since 所在日期起的每日统计，按日期升序
+func (r *topicRepository) GetDailyStats(ctx context.Context, topicID uint64, since time.Time) ([]*model.TopicDailyStat, error) {
+	var stats []*model.TopicDailyStat
+	err := r.db.WithContext(ctx).
+		Where("topic_id = ? AND stat_date >= ?", topicID, model.StatDay(since)).
+		Order("stat_date ASC").
+		Find(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
 
-		// 更新参与人数（去重的互动用户数）
-		var participantsCount int64
-		if err := tx.Model(&model.TopicInteraction{}).
-			Where("topic_id = ? AND interaction_status = ?", topicID, "active").
-			Distinct("user_id").
-			Count(&participantsCount).Error; err != nil {
-			return err
-		}
+// incrementDailyStat 当日统计行不存在时插入，存在时对指定列加一
+func incrementDailyStat(tx *gorm.DB, topicID uint64, column string, now time.Time) error {
+	stat := &model.TopicDailyStat{
+		TopicID:  topicID,
+		StatDate: model.StatDay(now),
+	}
+	switch column {
+	case "views":
+		stat.Views = 1
+	case "likes":
+		stat.Likes = 1
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			column: gorm.Expr(column + " + 1"),
+		}),
+	}).Create(stat).Error
+}
 
-		// 更新话题统计数据
-		return tx.Model(&model.Topic{}).
-			Where("id = ?", topicID).
-			Updates(map[string]interface{}{
-				"likes_count":        likesCount,
-				"shares_count":       sharesCount,
-				"participants_count": participantsCount,
-			}).Error
+// UpdateCounts 更新话题的各种计数
+func (r *topicRepository) UpdateCounts(ctx context.Context, topicID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateTopicCounts(tx, topicID)
 	})
 }
+
+// updateTopicCounts 在给定事务中按互动记录重新计算话题计数
+func updateTopicCounts(tx *gorm.DB, topicID uint64) error {
+	// 更新点赞数
+	var likesCount int64
+	if err := tx.Model(&model.TopicInteraction{}).
+		Where("topic_id = ? AND interaction_type = ? AND interaction_status = ?",
+			topicID, "like", "active").
+		Count(&likesCount).Error; err != nil {
+		return err
+	}
+
+	// 更新分享数
+	var sharesCount int64
+	if err := tx.Model(&model.TopicInteraction{}).
+		Where("topic_id = ? AND interaction_type = ? AND interaction_status = ?",
+			topicID, "share", "active").
+		Count(&sharesCount).Error; err != nil {
+		return err
+	}
+
+	// 更新参与人数（去重的互动用户数）
+	var participantsCount int64
+	if err := tx.Model(&model.TopicInteraction{}).
+		Where("topic_id = ? AND interaction_status = ?", topicID, "active").
+		Distinct("user_id").
+		Count(&participantsCount).Error; err != nil {
+		return err
+	}
+
+	// 更新话题统计数据
+	return tx.Model(&model.Topic{}).
+		Where("id = ?", topicID).
+		Updates(map[string]interface{}{
+			"likes_count":        likesCount,
+			"shares_count":       sharesCount,
+			"participants_count": participantsCount,
+		}).Error
+}
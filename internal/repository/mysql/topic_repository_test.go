@@ -0,0 +1,266 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestListClosedByUserSkipsTopicsInsideRestoreWindow(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewTopicRepository(db)
+	closedBefore := time.Now().Add(-24 * time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `topics` WHERE user_id = ? AND (status = ? AND closed_at < ?) ORDER BY id ASC")).
+		WithArgs(7, "closed", closedBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status"}).AddRow(3, 7, "closed"))
+
+	topics, err := repo.ListClosedByUser(context.Background(), 7, closedBefore)
+	if err != nil {
+		t.Fatalf("ListClosedByUser: %v", err)
+	}
+	if len(topics) != 1 || topics[0].ID != 3 {
+		t.Errorf("topics = %+v", topics)
+	}
+}
+
+func TestDeleteOnlyClosesActiveTopics(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewTopicRepository(db)
+
+	// 已关闭的话题不匹配，不会刷新关闭时间
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `topics` SET `closed_at`=?,`status`=?,`updated_at`=? WHERE id = ? AND status = ?")).
+		WithArgs(sqlmock.AnyArg(), "closed", sqlmock.AnyArg(), 3, "active").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	if err := repo.Delete(context.Background(), 3); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+}
+
+func TestContainsPatternEscapesWildcards(t *testing.T) {
+	tests := []struct {
+		keyword string
+		want    string
+	}{
+		{"go", "%go%"},
+		{"100%", `%100\%%`},
+		{"a_b", `%a\_b%`},
+		{`c:\tmp`, `%c:\\tmp%`},
+		{"", "%%"},
+	}
+	for _, tt := range tests {
+		if got := containsPattern(tt.keyword); got != tt.want {
+			t.Errorf("containsPattern(%q) = %q, want %q", tt.keyword, got, tt.want)
+		}
+	}
+}
+
+func TestSearchByAuthorUsesEscapedPattern(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewTopicRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `topics` JOIN users ON users.id = topics.user_id WHERE")).
+		WithArgs("active", `%50\%\_off%`, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT topics.* FROM `topics` JOIN users ON users.id = topics.user_id WHERE")).
+		WithArgs("active", `%50\%\_off%`, sqlmock.AnyArg(), 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, _, err := repo.Search(context.Background(), "50%_off", "author", 0, 20); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+}
+
+func TestTopicOrderBreaksTiesByID(t *testing.T) {
+	for _, sortBy := range []string{"", "recent", "popular"} {
+		order := topicOrder(sortBy)
+		if !strings.HasSuffix(order, "topics.created_at DESC, topics.id DESC") {
+			t.Errorf("topicOrder(%q) = %q, want created_at/id tie-break", sortBy, order)
+		}
+	}
+}
+
+func TestListRoomParticipantsSingleGatedQuery(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &topicRepository{db: db}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT users.* FROM `users` JOIN (SELECT chat_room_members.user_id, MAX(chat_room_members.joined_at) AS joined_at FROM `chat_room_members` JOIN chat_rooms ON chat_rooms.id = chat_room_members.chat_room_id WHERE (chat_rooms.topic_id = ? AND chat_rooms.type = ? AND chat_rooms.status = ?) AND chat_room_members.chat_room_id IN (SELECT `chat_room_id` FROM `chat_room_members` WHERE user_id = ?) GROUP BY `chat_room_members`.`user_id`) AS rm ON rm.user_id = users.id WHERE users.status = ? ORDER BY rm.joined_at DESC LIMIT ?")).
+		WithArgs(5, "group", model.ChatRoomStatusActive, 7, model.UserStatusActive, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nickname"}).AddRow(20, "member"))
+
+	users, err := repo.ListRoomParticipants(context.Background(), 5, 7, 8)
+	if err != nil {
+		t.Fatalf("ListRoomParticipants: %v", err)
+	}
+	if len(users) != 1 || users[0].ID != 20 {
+		t.Errorf("users = %+v, want user 20", users)
+	}
+}
+
+func TestListLikersExcludesBlockedUsers(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &topicRepository{db: db}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `topic_interactions` WHERE "+
+		"(topic_id = ? AND interaction_type = ? AND interaction_status = ?) AND id < ? "+
+		"AND user_id NOT IN (SELECT `following_id` FROM `user_relationships` WHERE follower_id = ? AND status = ?) "+
+		"AND user_id NOT IN (SELECT `follower_id` FROM `user_relationships` WHERE following_id = ? AND status = ?) "+
+		"ORDER BY id DESC LIMIT ?")).
+		WithArgs(1, "like", "active", 50, 7, "blocked", 7, "blocked", 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic_id", "user_id"}))
+
+	if _, err := repo.ListLikers(context.Background(), 1, 7, 50, 21); err != nil {
+		t.Fatalf("ListLikers: %v", err)
+	}
+}
+
+func TestListInteractedByUserOnlyReturnsOpenTopics(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &topicRepository{db: db}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `topic_interactions`.`id`")+".*"+
+		regexp.QuoteMeta("FROM `topic_interactions` JOIN topics ON topics.id = topic_interactions.topic_id "+
+			"WHERE (topic_interactions.user_id = ? AND topic_interactions.interaction_type = ? AND topic_interactions.interaction_status = ?) "+
+			"AND topics.status = ? AND topic_interactions.id < ? AND (topics.expires_at IS NULL OR topics.expires_at > ?) "+
+			"ORDER BY topic_interactions.id DESC LIMIT ?")).
+		WithArgs(7, "favorite", "active", "active", 50, sqlmock.AnyArg(), 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic_id", "user_id"}))
+
+	if _, err := repo.ListInteractedByUser(context.Background(), 7, "favorite", 50, 21); err != nil {
+		t.Fatalf("ListInteractedByUser: %v", err)
+	}
+}
+
+func TestListProfilePinnedPreloadsImages(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &topicRepository{db: db}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `topics`.`id`")+".*"+
+		regexp.QuoteMeta("FROM `topics` JOIN profile_pinned_topics ON profile_pinned_topics.topic_id = topics.id AND profile_pinned_topics.user_id = topics.user_id "+
+			"WHERE (profile_pinned_topics.user_id = ? AND topics.status = ?) AND (topics.expires_at IS NULL OR topics.expires_at > ?) "+
+			"ORDER BY profile_pinned_topics.pinned_at DESC")).
+		WithArgs(7, "active", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status"}).AddRow(3, 7, "active"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `topic_images` WHERE `topic_images`.`topic_id` = ? ORDER BY sort_order ASC")).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic_id", "image_url", "sort_order"}).
+			AddRow(11, 3, "topics/a.jpg", 0).
+			AddRow(12, 3, "topics/b.jpg", 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ?")).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nickname"}).AddRow(7, "author"))
+
+	topics, err := repo.ListProfilePinned(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("ListProfilePinned: %v", err)
+	}
+	if len(topics) != 1 || len(topics[0].TopicImages) != 2 || topics[0].TopicImages[0].ImageURL != "topics/a.jpg" {
+		t.Errorf("topics = %+v, want topic 3 with two ordered images", topics)
+	}
+}
+
+func TestListOrdersBySort(t *testing.T) {
+	tests := []struct {
+		sortBy string
+		order  string
+	}{
+		{model.TopicSortRecent, "ORDER BY topics.created_at DESC, topics.id DESC"},
+		{model.TopicSortPopular, "ORDER BY topics.likes_count DESC, topics.views_count DESC, topics.created_at DESC, topics.id DESC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			repo := &topicRepository{db: db}
+
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `topics` WHERE status = ?")).
+				WithArgs("active", sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `topics` WHERE status = ?")+".*"+
+				regexp.QuoteMeta(tt.order+" LIMIT ?")).
+				WithArgs("active", sqlmock.AnyArg(), 20).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			if _, _, err := repo.List(context.Background(), repository.TopicListOptions{SortBy: tt.sortBy}, 0, 20); err != nil {
+				t.Fatalf("List: %v", err)
+			}
+		})
+	}
+}
+
+func TestListRecentByUserOnlyReturnsActiveTopics(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := &topicRepository{db: db}
+	since := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `topics` WHERE user_id = ? AND status = ? AND created_at >= ? ORDER BY created_at DESC LIMIT ?")).
+		WithArgs(7, "active", since, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status"}).AddRow(3, 7, "active"))
+
+	topics, err := repo.ListRecentByUser(context.Background(), 7, since, 20)
+	if err != nil {
+		t.Fatalf("ListRecentByUser: %v", err)
+	}
+	if len(topics) != 1 || topics[0].ID != 3 {
+		t.Errorf("topics = %+v, want topic 3", topics)
+	}
+}
+
+func TestHardDeleteReleasesTagsAndChatRooms(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewTopicRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `tag_id` FROM `topic_tags` WHERE topic_id = ?")).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"tag_id"}).AddRow(1).AddRow(2))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `tags` SET `use_count`=use_count - ? WHERE id IN (?,?) AND use_count > 0")).
+		WithArgs(1, 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `chat_rooms` SET `topic_id`=? WHERE topic_id = ?")).
+		WithArgs(nil, 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, table := range []string{"topic_tags", "topic_images", "topic_interactions"} {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `" + table + "` WHERE topic_id = ?")).
+			WithArgs(9).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `topics` WHERE `topics`.`id` = ?")).
+		WithArgs(9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.HardDelete(context.Background(), 9); err != nil {
+		t.Fatalf("HardDelete: %v", err)
+	}
+}
+
+func TestHardDeleteRollsBackOnFailure(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewTopicRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `tag_id` FROM `topic_tags` WHERE topic_id = ?")).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"tag_id"}))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `chat_rooms` SET `topic_id`=? WHERE topic_id = ?")).
+		WithArgs(nil, 9).
+		WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
+
+	if err := repo.HardDelete(context.Background(), 9); err == nil {
+		t.Fatal("HardDelete succeeded, want error")
+	}
+}
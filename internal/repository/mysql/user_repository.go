@@ -47,6 +47,80 @@ func (r *userRepository) GetByID(ctx context.Context, id uint64) (*model.User, e
 	return &user, nil
 }
 
+// GetByIDs 批量获取用户，不存在的ID会被忽略
+func (r *userRepository) GetByIDs(ctx context.Context, ids []uint64) ([]*model.User, error) {
+	var users []*model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetActiveBan 获取 now 时正在生效的限期封禁中结束时间最晚的一条
+// 永久封禁以账号状态为准，不在此查询
+func (r *userRepository) GetActiveBan(ctx context.Context, userID uint64, now time.Time) (*model.UserBan, error) {
+	var ban model.UserBan
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status = ?", userID, "active").
+		Where("ban_start <= ? AND ban_end > ?", now, now).
+		Order("ban_end DESC").
+		First(&ban).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ban, nil
+}
+
+// CreateBan 写入封禁记录，永久封禁（BanEnd 为空）同时将账号状态设为 banned
+func (r *userRepository) CreateBan(ctx context.Context, ban *model.UserBan) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(ban).Error; err != nil {
+			return err
+		}
+		if ban.BanEnd != nil {
+			return nil
+		}
+		return tx.Model(&model.User{}).
+			Where("id = ?", ban.UserID).
+			Update("status", model.UserStatusBanned).Error
+	})
+}
+
+// CancelBans 取消用户所有有效的封禁记录，并将 banned 状态的账号恢复为 active
+func (r *userRepository) CancelBans(ctx context.Context, userID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.UserBan{}).
+			Where("user_id = ? AND status = ?", userID, "active").
+			Update("status", "cancelled").Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).
+			Where("id = ? AND status = ?", userID, model.UserStatusBanned).
+			Update("status", model.UserStatusActive).Error
+	})
+}
+
+// NicknameExists 检查昵称是否已被其他用户使用，excludeID 为当前用户
+// 比较规则跟随列排序规则（utf8mb4_unicode_ci 下不区分大小写）
+func (r *userRepository) NicknameExists(ctx context.Context, nickname string, excludeID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("nickname = ? AND id <> ?", nickname, excludeID).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // GetByFirebaseUID 根据Firebase UID获取用户
 func (r *userRepository) GetByFirebaseUID(ctx context.Context, firebaseUID string) (*model.User, error) {
 	var auth model.UserAuthentication
@@ -86,11 +160,8 @@ func (r *userRepository) Search(ctx context.Context, keyword string, offset, lim
 	var users []*model.User
 	var total int64
 
-	db := r.db.WithContext(ctx).Where(
-		"nickname LIKE ? OR bio LIKE ?",
-		fmt.Sprintf("%%%s%%", keyword),
-		fmt.Sprintf("%%%s%%", keyword),
-	)
+	pattern := containsPattern(keyword)
+	db := r.db.WithContext(ctx).Where("nickname LIKE ? OR bio LIKE ?", pattern, pattern)
 
 	if err := db.Model(&model.User{}).Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -104,7 +175,8 @@ func (r *userRepository) Search(ctx context.Context, keyword string, offset, lim
 }
 
 // GetNearbyUsers 获取附近的用户
-func (r *userRepository) GetNearbyUsers(ctx context.Context, lat, lng float64, radius float64, offset, limit int) ([]*model.User, int64, error) {
+// activeSince 非零时只返回该时间后活跃过的用户
+func (r *userRepository) GetNearbyUsers(ctx context.Context, lat, lng float64, radius float64, activeSince time.Time, offset, limit int) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
 
@@ -113,6 +185,9 @@ func (r *userRepository) GetNearbyUsers(ctx context.Context, lat, lng float64, r
 	db := r.db.WithContext(ctx).
 		Where(fmt.Sprintf("%s <= ?", distanceSQL), lng, lat, radius).
 		Where("location_sharing = ?", true)
+	if !activeSince.IsZero() {
+		db = db.Where("last_active_at >= ?", activeSince)
+	}
 
 	if err := db.Model(&model.User{}).Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -147,6 +222,16 @@ func (r *userRepository) UpdateLastActive(ctx context.Context, userID uint64) er
 		Error
 }
 
+// HasAdminPermission 检查用户是否拥有有效的管理员权限
+func (r *userRepository) HasAdminPermission(ctx context.Context, userID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.AdminPermission{}).
+		Where("user_id = ? AND status = ?", userID, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
 // CreateAuthentication 创建用户认证信息
 func (r *userRepository) CreateAuthentication(ctx context.Context, auth *model.UserAuthentication) error {
 	return r.db.WithContext(ctx).Create(auth).Error
@@ -157,6 +242,30 @@ func (r *userRepository) UpdateAuthentication(ctx context.Context, auth *model.U
 	return r.db.WithContext(ctx).Save(auth).Error
 }
 
+// GetAuthentication 获取用户的认证信息
+func (r *userRepository) GetAuthentication(ctx context.Context, userID uint64) (*model.UserAuthentication, error) {
+	var auth model.UserAuthentication
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&auth).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &auth, nil
+}
+
+// DeactivateAccount 注销账号：删除认证信息解除 Firebase 绑定，用户记录置为停用以保留其发布的内容
+func (r *userRepository) DeactivateAccount(ctx context.Context, userID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserAuthentication{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).
+			Where("id = ?", userID).
+			Update("status", model.UserStatusInactive).Error
+	})
+}
+
 // CreateDevice 创建用户设备
 func (r *userRepository) CreateDevice(ctx context.Context, device *model.UserDevice) error {
 	return r.db.WithContext(ctx).Create(device).Error
@@ -187,3 +296,31 @@ func (r *userRepository) GetUserDevices(ctx context.Context, userID uint64) ([]*
 	}
 	return devices, nil
 }
+
+// ListActiveDevices 获取用户已登录的设备，按最近活跃时间倒序
+func (r *userRepository) ListActiveDevices(ctx context.Context, userID uint64) ([]*model.UserDevice, error) {
+	var devices []*model.UserDevice
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Order("last_active_at DESC").
+		Find(&devices).Error
+	if err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// DeactivateDevice 将用户的设备标记为已退出并停止推送，返回是否有设备被更新
+func (r *userRepository) DeactivateDevice(ctx context.Context, userID, deviceID uint64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserDevice{}).
+		Where("id = ? AND user_id = ? AND is_active = ?", deviceID, userID, true).
+		Updates(map[string]interface{}{
+			"is_active":    false,
+			"push_enabled": false,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"DistanceBack_v1/internal/model"
 	"context"
+	"time"
 )
 
 // UserRepository 用户仓储接口
@@ -12,26 +13,69 @@ type UserRepository interface {
 	Update(ctx context.Context, user *model.User) error
 	Delete(ctx context.Context, id uint64) error
 	GetByID(ctx context.Context, id uint64) (*model.User, error)
+	GetByIDs(ctx context.Context, ids []uint64) ([]*model.User, error)
+	NicknameExists(ctx context.Context, nickname string, excludeID uint64) (bool, error)
 
 	// 认证相关
 	GetByFirebaseUID(ctx context.Context, firebaseUID string) (*model.User, error)
 	CreateAuthentication(ctx context.Context, auth *model.UserAuthentication) error
 	UpdateAuthentication(ctx context.Context, auth *model.UserAuthentication) error
+	GetAuthentication(ctx context.Context, userID uint64) (*model.UserAuthentication, error)
+	DeactivateAccount(ctx context.Context, userID uint64) error
 
 	// 设备相关
 	CreateDevice(ctx context.Context, device *model.UserDevice) error
 	UpdateDevice(ctx context.Context, device *model.UserDevice) error
 	GetDeviceByToken(ctx context.Context, token string) (*model.UserDevice, error)
 	GetUserDevices(ctx context.Context, userID uint64) ([]*model.UserDevice, error)
+	ListActiveDevices(ctx context.Context, userID uint64) ([]*model.UserDevice, error)
+	DeactivateDevice(ctx context.Context, userID, deviceID uint64) (bool, error)
 
 	// 查询操作
 	List(ctx context.Context, offset, limit int) ([]*model.User, int64, error)
 	Search(ctx context.Context, keyword string, offset, limit int) ([]*model.User, int64, error)
-	GetNearbyUsers(ctx context.Context, lat, lng float64, radius float64, offset, limit int) ([]*model.User, int64, error)
+	GetNearbyUsers(ctx context.Context, lat, lng float64, radius float64, activeSince time.Time, offset, limit int) ([]*model.User, int64, error)
 
 	// 状态操作
 	UpdateStatus(ctx context.Context, userID uint64, status string) error
 	UpdateLastActive(ctx context.Context, userID uint64) error
+	GetActiveBan(ctx context.Context, userID uint64, now time.Time) (*model.UserBan, error)
+	CreateBan(ctx context.Context, ban *model.UserBan) error
+	CancelBans(ctx context.Context, userID uint64) error
+
+	// 权限相关
+	HasAdminPermission(ctx context.Context, userID uint64) (bool, error)
+}
+
+// TopicListOptions 话题列表查询选项
+type TopicListOptions struct {
+	SortBy      string    // 排序方式: recent/popular
+	ActiveSince time.Time // 只返回该时间后活跃过的作者的话题，零值不过滤
+	CreatedFrom time.Time // 创建时间下限(含)，零值不过滤
+	CreatedTo   time.Time // 创建时间上限(不含)，零值不过滤
+	// IncludeInactive 包含已关闭和已过期的话题，仅用于 ListByUser
+	IncludeInactive bool
+}
+
+// RelationshipStatusCounts 按状态统计的关系数
+type RelationshipStatusCounts struct {
+	Pending  int64 `json:"pending"`
+	Accepted int64 `json:"accepted"`
+	Blocked  int64 `json:"blocked"`
+}
+
+// RelationshipCountsByStatus 粉丝与关注两个方向按状态统计的关系数
+type RelationshipCountsByStatus struct {
+	Followers  RelationshipStatusCounts `json:"followers"`
+	Followings RelationshipStatusCounts `json:"followings"`
+}
+
+// RelationshipCounts 用户关系计数
+type RelationshipCounts struct {
+	Followers int64 // 已通过的粉丝数
+	Following int64 // 已通过的关注数
+	Friends   int64 // 互相关注的好友数
+	Pending   int64 // 待处理的关注请求数
 }
 
 // TopicRepository 话题仓储接口
@@ -40,7 +84,11 @@ type TopicRepository interface {
 	Create(ctx context.Context, topic *model.Topic) error
 	Update(ctx context.Context, topic *model.Topic) error
 	Delete(ctx context.Context, id uint64) error
+	HardDelete(ctx context.Context, id uint64) error
+	Restore(ctx context.Context, id uint64, closedSince time.Time) (bool, error)
 	GetByID(ctx context.Context, id uint64) (*model.Topic, error)
+	ListByIDs(ctx context.Context, ids []uint64) ([]*model.Topic, error)
+	TransferOwner(ctx context.Context, topicID, newOwnerID uint64, audit *model.AuditLog) error
 
 	// 图片相关
 	AddImages(ctx context.Context, topicID uint64, images []*model.TopicImage) error
@@ -54,19 +102,35 @@ type TopicRepository interface {
 	ListPopular(ctx context.Context, limit int) ([]*model.Tag, error)
 
 	// 查询操作
-	List(ctx context.Context, offset, limit int) ([]*model.Topic, int64, error)
-	ListByUser(ctx context.Context, userID uint64, offset, limit int) ([]*model.Topic, int64, error)
-	ListByTag(ctx context.Context, tagID uint64, offset, limit int) ([]*model.Topic, int64, error)
-	GetNearbyTopics(ctx context.Context, lat, lng float64, radius float64, offset, limit int) ([]*model.Topic, int64, error)
+	List(ctx context.Context, opts TopicListOptions, offset, limit int) ([]*model.Topic, int64, error)
+	ListByUser(ctx context.Context, userID uint64, opts TopicListOptions, offset, limit int) ([]*model.Topic, int64, error)
+	ListByTag(ctx context.Context, tagID uint64, opts TopicListOptions, offset, limit int) ([]*model.Topic, int64, error)
+	ListClosedByUser(ctx context.Context, userID uint64, closedBefore time.Time) ([]*model.Topic, error)
+	ListRecentByUser(ctx context.Context, userID uint64, since time.Time, limit int) ([]*model.Topic, error)
+	CloseExpired(ctx context.Context, now time.Time) (int64, error)
+	CountByUser(ctx context.Context, userID uint64) (int64, error)
+	Search(ctx context.Context, keyword, by string, offset, limit int) ([]*model.Topic, int64, error)
+	GetNearbyTopics(ctx context.Context, lat, lng float64, radius float64, opts TopicListOptions, offset, limit int) ([]*model.Topic, int64, error)
+	ListRoomParticipants(ctx context.Context, topicID, viewerID uint64, limit int) ([]*model.User, error)
+	ListRecentInteractors(ctx context.Context, topicID uint64, limit int) ([]*model.User, error)
 
 	// 互动操作
 	AddInteraction(ctx context.Context, interaction *model.TopicInteraction) error
 	RemoveInteraction(ctx context.Context, topicID, userID uint64, interactionType string) error
 	GetInteractions(ctx context.Context, topicID uint64, interactionType string) ([]*model.TopicInteraction, error)
+	GetUserInteractions(ctx context.Context, topicID, userID uint64) ([]*model.TopicInteraction, error)
+	ListLikers(ctx context.Context, topicID, viewerID, beforeID uint64, limit int) ([]*model.TopicInteraction, error)
+	ListInteractedByUser(ctx context.Context, userID uint64, interactionType string, beforeID uint64, limit int) ([]*model.TopicInteraction, error)
+
+	// 主页置顶
+	PinToProfile(ctx context.Context, userID, topicID uint64) error
+	UnpinFromProfile(ctx context.Context, userID, topicID uint64) error
+	ListProfilePinned(ctx context.Context, userID uint64) ([]*model.Topic, error)
 
 	// 计数操作
 	IncrementViewCount(ctx context.Context, topicID uint64) error
 	UpdateCounts(ctx context.Context, topicID uint64) error
+	GetDailyStats(ctx context.Context, topicID uint64, since time.Time) ([]*model.TopicDailyStat, error)
 }
 
 // ChatRepository 聊天仓储接口
@@ -75,6 +139,9 @@ type ChatRepository interface {
 	CreateRoom(ctx context.Context, room *model.ChatRoom) error
 	UpdateRoom(ctx context.Context, room *model.ChatRoom) error
 	GetRoomByID(ctx context.Context, id uint64) (*model.ChatRoom, error)
+	GetPrivateRoom(ctx context.Context, pairKey string) (*model.ChatRoom, error)
+	ListPrivateRooms(ctx context.Context, pairKeys []string) ([]*model.ChatRoom, error)
+	CreateRoomWithMembers(ctx context.Context, room *model.ChatRoom, members []*model.ChatRoomMember) error
 	ListUserRooms(ctx context.Context, userID uint64, offset, limit int) ([]*model.ChatRoom, int64, error)
 
 	// 成员操作
@@ -82,11 +149,23 @@ type ChatRepository interface {
 	RemoveMember(ctx context.Context, roomID, userID uint64) error
 	UpdateMember(ctx context.Context, member *model.ChatRoomMember) error
 	GetRoomMembers(ctx context.Context, roomID uint64) ([]*model.ChatRoomMember, error)
+	LeaveRoom(ctx context.Context, roomID, userID, newOwnerID uint64) error
+	TransferOwnership(ctx context.Context, roomID, fromUserID, toUserID uint64) error
+	ListUserRoomsByType(ctx context.Context, userID uint64, roomType string) ([]*model.ChatRoom, error)
+	GetSharedRooms(ctx context.Context, userA, userB uint64) ([]*model.ChatRoom, error)
 
 	// 消息操作
 	CreateMessage(ctx context.Context, message *model.Message) error
-	GetMessagesByRoom(ctx context.Context, roomID uint64, beforeID uint64, limit int) ([]*model.Message, error)
-	GetLatestMessages(ctx context.Context, roomID uint64, limit int) ([]*model.Message, error)
+	CreateTopicShareMessage(ctx context.Context, message *model.Message, share *model.TopicInteraction) error
+	GetMessagesByRoom(ctx context.Context, roomID, viewerID uint64, beforeID uint64, limit int) ([]*model.Message, error)
+	GetMessagesAfter(ctx context.Context, roomID, viewerID uint64, afterID uint64, limit int) ([]*model.Message, error)
+	GetMessagesAround(ctx context.Context, roomID, viewerID, messageID uint64, beforeN, afterN int) ([]*model.Message, error)
+	GetMessageByID(ctx context.Context, id uint64) (*model.Message, error)
+	DeleteMessageForEveryone(ctx context.Context, messageID uint64) error
+	HideMessage(ctx context.Context, userID, messageID uint64) error
+	IsMessageHidden(ctx context.Context, userID, messageID uint64) (bool, error)
+	GetLatestMessages(ctx context.Context, roomID, viewerID uint64, limit int) ([]*model.Message, error)
+	GetTotalUnread(ctx context.Context, userID uint64) (int64, error)
 
 	// 媒体操作
 	AddMessageMedia(ctx context.Context, media *model.MessageMedia) error
@@ -104,15 +183,21 @@ type RelationshipRepository interface {
 	Create(ctx context.Context, relationship *model.UserRelationship) error
 	Update(ctx context.Context, relationship *model.UserRelationship) error
 	Delete(ctx context.Context, followerID, followingID uint64) error
+	// DeletePending 仅删除待处理的关注请求，返回是否删除了记录
+	DeletePending(ctx context.Context, followerID, followingID uint64) (bool, error)
 
 	// 查询操作
 	GetRelationship(ctx context.Context, followerID, followingID uint64) (*model.UserRelationship, error)
+	ListBetween(ctx context.Context, userID uint64, targetIDs []uint64) ([]*model.UserRelationship, error)
 	GetFollowers(ctx context.Context, userID uint64, status string, offset, limit int) ([]*model.UserRelationship, int64, error)
 	GetFollowings(ctx context.Context, userID uint64, status string, offset, limit int) ([]*model.UserRelationship, int64, error)
 
 	// 状态操作
 	UpdateStatus(ctx context.Context, followerID, followingID uint64, status string) error
 	ExistsRelationship(ctx context.Context, followerID, followingID uint64) (bool, error)
+	GetCounts(ctx context.Context, userID uint64) (*RelationshipCounts, error)
+	GetCountsByStatus(ctx context.Context, userID uint64) (*RelationshipCountsByStatus, error)
+	RejectAndBlock(ctx context.Context, userID, followerID uint64) error
 }
 
 // TagRepository 标签仓储接口
@@ -128,3 +213,30 @@ type TagRepository interface {
 	Delete(ctx context.Context, id uint64) error
 	BatchCreate(ctx context.Context, tags []string) ([]uint64, error)
 }
+
+// AdminRepository 管理后台统计仓储接口
+type AdminRepository interface {
+	CountUsers(ctx context.Context) (int64, error)
+	CountActiveUsers(ctx context.Context, since time.Time) (int64, error)
+	CountTopicsSince(ctx context.Context, since time.Time) (int64, error)
+	CountMessagesSince(ctx context.Context, since time.Time) (int64, error)
+	CountOpenReports(ctx context.Context) (int64, error)
+	RemoveTopic(ctx context.Context, topicID uint64, audit *model.AuditLog) error
+}
+
+// ReportRepository 举报仓储接口
+type ReportRepository interface {
+	Create(ctx context.Context, report *model.Report) error
+}
+
+// ActivityCursor 用户动态分页游标，零值表示首页
+type ActivityCursor struct {
+	OccurredAt time.Time
+	Type       string
+	ID         uint64
+}
+
+// ActivityRepository 用户动态仓储接口
+type ActivityRepository interface {
+	ListByUser(ctx context.Context, userID uint64, cursor ActivityCursor, limit int) ([]*model.Activity, error)
+}
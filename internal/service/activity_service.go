@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+)
+
+const (
+	// DefaultActivityLimit 动态列表默认每页数量
+	DefaultActivityLimit = 20
+	// MaxActivityLimit 动态列表每页最大数量
+	MaxActivityLimit = 100
+)
+
+// ActivityPage 动态游标分页结果
+type ActivityPage struct {
+	Activities []*model.Activity
+	NextCursor string // 继续加载时作为 cursor，没有更多时为空
+	HasMore    bool
+}
+
+type ActivityService struct {
+	activityRepo repository.ActivityRepository
+	topicRepo    repository.TopicRepository
+	userRepo     repository.UserRepository
+}
+
+// NewActivityService 创建用户动态服务实例
+func NewActivityService(
+	activityRepo repository.ActivityRepository,
+	topicRepo repository.TopicRepository,
+	userRepo repository.UserRepository,
+) *ActivityService {
+	return &ActivityService{
+		activityRepo: activityRepo,
+		topicRepo:    topicRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// GetTimeline 按时间倒序获取用户发布话题、互动和关注的动态
+// cursor 为上一页返回的 NextCursor，首页传空
+func (s *ActivityService) GetTimeline(ctx context.Context, userID uint64, cursor string, limit int) (*ActivityPage, error) {
+	if limit <= 0 {
+		limit = DefaultActivityLimit
+	}
+	if limit > MaxActivityLimit {
+		limit = MaxActivityLimit
+	}
+
+	after, err := decodeActivityCursor(cursor)
+	if err != nil {
+		return nil, ErrInvalidRequest
+	}
+
+	// 多取一条判断是否还有更多
+	activities, err := s.activityRepo.ListByUser(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activities: %w", err)
+	}
+
+	page := &ActivityPage{}
+	if len(activities) > limit {
+		activities = activities[:limit]
+		last := activities[len(activities)-1]
+		page.HasMore = true
+		page.NextCursor = encodeActivityCursor(repository.ActivityCursor{
+			OccurredAt: last.OccurredAt,
+			Type:       last.Type,
+			ID:         last.ID,
+		})
+	}
+
+	if err := s.attachTargets(ctx, activities); err != nil {
+		return nil, err
+	}
+
+	// 目标已被删除的动态不再展示
+	page.Activities = make([]*model.Activity, 0, len(activities))
+	for _, activity := range activities {
+		if activity.Topic != nil || activity.User != nil {
+			page.Activities = append(page.Activities, activity)
+		}
+	}
+	return page, nil
+}
+
+// attachTargets 批量加载动态关联的话题和用户
+func (s *ActivityService) attachTargets(ctx context.Context, activities []*model.Activity) error {
+	var topicIDs, userIDs []uint64
+	for _, activity := range activities {
+		if activity.Type == model.ActivityFollow {
+			userIDs = append(userIDs, activity.TargetID)
+		} else {
+			topicIDs = append(topicIDs, activity.TargetID)
+		}
+	}
+
+	topics := make(map[uint64]*model.Topic, len(topicIDs))
+	if len(topicIDs) > 0 {
+		list, err := s.topicRepo.ListByIDs(ctx, topicIDs)
+		if err != nil {
+			return fmt.Errorf("failed to get topics: %w", err)
+		}
+		for _, topic := range list {
+			// 被管理员下架的话题不出现在动态中
+			if topic.Status != model.TopicStatusCancelled {
+				topics[topic.ID] = topic
+			}
+		}
+	}
+
+	users := make(map[uint64]*model.User, len(userIDs))
+	if len(userIDs) > 0 {
+		list, err := s.userRepo.GetByIDs(ctx, userIDs)
+		if err != nil {
+			return fmt.Errorf("failed to get users: %w", err)
+		}
+		for _, user := range list {
+			users[user.ID] = user
+		}
+	}
+
+	for _, activity := range activities {
+		if activity.Type == model.ActivityFollow {
+			activity.User = users[activity.TargetID]
+		} else {
+			activity.Topic = topics[activity.TargetID]
+		}
+	}
+	return nil
+}
+
+// encodeActivityCursor 将游标编码为 "纳秒时间戳:类型:ID" 的 base64 字符串
+func encodeActivityCursor(c repository.ActivityCursor) string {
+	raw := fmt.Sprintf("%d:%s:%d", c.OccurredAt.UnixNano(), c.Type, c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeActivityCursor 解析游标，空字符串返回零值表示首页
+func decodeActivityCursor(cursor string) (repository.ActivityCursor, error) {
+	var c repository.ActivityCursor
+	if cursor == "" {
+		return c, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, err
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return c, fmt.Errorf("malformed activity cursor")
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return c, err
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return c, err
+	}
+
+	switch parts[1] {
+	case model.ActivityTopicCreated, model.ActivityInteraction, model.ActivityFollow:
+	default:
+		return c, fmt.Errorf("unknown activity type in cursor")
+	}
+
+	c.OccurredAt = time.Unix(0, nanos)
+	c.Type = parts[1]
+	c.ID = id
+	return c, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+)
+
+func TestActivityCursorRoundTrip(t *testing.T) {
+	want := repository.ActivityCursor{
+		OccurredAt: time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC),
+		Type:       model.ActivityInteraction,
+		ID:         42,
+	}
+	got, err := decodeActivityCursor(encodeActivityCursor(want))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !got.OccurredAt.Equal(want.OccurredAt) || got.Type != want.Type || got.ID != want.ID {
+		t.Errorf("cursor = %+v, want %+v", got, want)
+	}
+
+	// 空游标表示首页
+	if first, err := decodeActivityCursor(""); err != nil || !first.OccurredAt.IsZero() {
+		t.Errorf("decode empty = %+v, %v, want zero cursor", first, err)
+	}
+}
+
+func TestDecodeActivityCursorRejectsMalformed(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	for _, cursor := range []string{
+		"not base64!",
+		encode("1714566600:interaction"),
+		encode("soon:interaction:1"),
+		encode("1714566600:interaction:-1"),
+		encode("1714566600:comment:1"),
+		encode("1714566600:interaction:1:2"),
+	} {
+		if _, err := decodeActivityCursor(cursor); err == nil {
+			t.Errorf("decodeActivityCursor(%q) succeeded, want error", cursor)
+		}
+	}
+}
+
+// newActivityTestService 用户 7 发布话题 1、2，点赞话题 3（已下架），关注用户 8 和已注销的用户 9
+// 话题 2 的发布、点赞和关注用户 8 发生在同一时刻，用来检查同一时间点按类型排序
+func newActivityTestService() *ActivityService {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	activityRepo := &fakeActivityRepo{activities: []*model.Activity{
+		{Type: model.ActivityTopicCreated, ID: 1, OccurredAt: base, TargetID: 1},
+		{Type: model.ActivityTopicCreated, ID: 2, OccurredAt: base.Add(time.Hour), TargetID: 2},
+		{Type: model.ActivityInteraction, ID: 10, OccurredAt: base.Add(time.Hour), TargetID: 2, InteractionType: model.InteractionTypeLike},
+		{Type: model.ActivityFollow, ID: 20, OccurredAt: base.Add(time.Hour), TargetID: 8},
+		{Type: model.ActivityInteraction, ID: 11, OccurredAt: base.Add(2 * time.Hour), TargetID: 3, InteractionType: model.InteractionTypeLike},
+		{Type: model.ActivityFollow, ID: 21, OccurredAt: base.Add(3 * time.Hour), TargetID: 9},
+	}}
+	topicRepo := newFakeTopicRepo(
+		newTestTopic(1, 7, model.TopicStatusActive),
+		newTestTopic(2, 7, model.TopicStatusActive),
+		newTestTopic(3, 8, model.TopicStatusCancelled),
+	)
+	followed := &model.User{Nickname: "followed", Status: model.UserStatusActive}
+	followed.ID = 8
+	return NewActivityService(activityRepo, topicRepo, newFakeUserRepo(followed))
+}
+
+func TestGetTimelinePagesWithoutGapsOrDuplicates(t *testing.T) {
+	svc := newActivityTestService()
+
+	var seen []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		page, err := svc.GetTimeline(context.Background(), 7, cursor, 2)
+		if err != nil {
+			t.Fatalf("GetTimeline: %v", err)
+		}
+		for _, activity := range page.Activities {
+			seen = append(seen, fmt.Sprintf("%s:%d", activity.Type, activity.ID))
+		}
+		if !page.HasMore {
+			if page.NextCursor != "" {
+				t.Errorf("last page has cursor %q", page.NextCursor)
+			}
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	// 已下架的话题和已不存在的用户被过滤，同一时刻按类型倒序
+	want := "topic_created:2,interaction:10,follow:20,topic_created:1"
+	if got := strings.Join(seen, ","); got != want {
+		t.Errorf("timeline = %s, want %s", got, want)
+	}
+}
+
+func TestGetTimelineAttachesTargets(t *testing.T) {
+	svc := newActivityTestService()
+
+	page, err := svc.GetTimeline(context.Background(), 7, "", 10)
+	if err != nil {
+		t.Fatalf("GetTimeline: %v", err)
+	}
+	if page.HasMore {
+		t.Error("HasMore = true, want false")
+	}
+	for _, activity := range page.Activities {
+		switch activity.Type {
+		case model.ActivityFollow:
+			if activity.User == nil || activity.User.ID != activity.TargetID || activity.Topic != nil {
+				t.Errorf("follow %d: user = %+v, topic = %+v", activity.ID, activity.User, activity.Topic)
+			}
+		default:
+			if activity.Topic == nil || activity.Topic.ID != activity.TargetID || activity.User != nil {
+				t.Errorf("%s %d: topic = %+v, user = %+v", activity.Type, activity.ID, activity.Topic, activity.User)
+			}
+		}
+	}
+}
+
+func TestGetTimelineRejectsBadCursor(t *testing.T) {
+	svc := newActivityTestService()
+	if _, err := svc.GetTimeline(context.Background(), 7, "%%%", 10); err != ErrInvalidRequest {
+		t.Errorf("err = %v, want ErrInvalidRequest", err)
+	}
+}
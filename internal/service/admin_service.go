@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/logger"
+	"DistanceBack_v1/pkg/utils"
+)
+
+const (
+	// ActiveUserWindow 活跃用户统计窗口
+	ActiveUserWindow = 24 * time.Hour
+	// DashboardStatsExpiration 统计数据缓存时间
+	DashboardStatsExpiration = time.Minute
+)
+
+// DashboardStats 管理后台概览数据
+type DashboardStats struct {
+	TotalUsers    int64     `json:"total_users"`
+	ActiveUsers   int64     `json:"active_users"`
+	TopicsToday   int64     `json:"topics_today"`
+	MessagesToday int64     `json:"messages_today"`
+	OpenReports   int64     `json:"open_reports"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
+
+type AdminService struct {
+	adminRepo repository.AdminRepository
+	topicRepo repository.TopicRepository
+}
+
+// NewAdminService 创建管理后台服务实例
+func NewAdminService(adminRepo repository.AdminRepository, topicRepo repository.TopicRepository) *AdminService {
+	return &AdminService{
+		adminRepo: adminRepo,
+		topicRepo: topicRepo,
+	}
+}
+
+// GetDashboardStats 获取管理后台概览数据
+func (s *AdminService) GetDashboardStats(ctx context.Context) (*DashboardStats, error) {
+	// 尝试从缓存获取
+	cacheKey := cache.AdminStatsKey()
+	var cachedStats DashboardStats
+	if err := cache.Get(cacheKey, &cachedStats); err == nil {
+		return &cachedStats, nil
+	}
+
+	now := time.Now()
+	startOfDay := utils.GetStartOfDay(now)
+	stats := &DashboardStats{GeneratedAt: now}
+
+	var err error
+	if stats.TotalUsers, err = s.adminRepo.CountUsers(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+	if stats.ActiveUsers, err = s.adminRepo.CountActiveUsers(ctx, now.Add(-ActiveUserWindow)); err != nil {
+		return nil, fmt.Errorf("failed to count active users: %w", err)
+	}
+	if stats.TopicsToday, err = s.adminRepo.CountTopicsSince(ctx, startOfDay); err != nil {
+		return nil, fmt.Errorf("failed to count topics: %w", err)
+	}
+	if stats.MessagesToday, err = s.adminRepo.CountMessagesSince(ctx, startOfDay); err != nil {
+		return nil, fmt.Errorf("failed to count messages: %w", err)
+	}
+	if stats.OpenReports, err = s.adminRepo.CountOpenReports(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count open reports: %w", err)
+	}
+
+	// 缓存统计数据
+	if err := cache.Set(cacheKey, stats, DashboardStatsExpiration); err != nil {
+		logger.Warn("failed to cache dashboard stats", logger.Any("error", err))
+	}
+
+	return stats, nil
+}
+
+// RemoveTopic 管理员因违规下架话题并记录审计日志
+// 与作者自行删除不同，下架后状态为 cancelled，作者不能恢复，标签计数和互动会立即清理
+func (s *AdminService) RemoveTopic(ctx context.Context, operatorID, topicID uint64, reason string, notifyAuthor bool) error {
+	topic, err := s.topicRepo.GetByID(ctx, topicID)
+	if err != nil {
+		return fmt.Errorf("failed to get topic: %w", err)
+	}
+	if topic == nil {
+		return ErrTopicNotFound
+	}
+	if topic.Status == model.TopicStatusCancelled {
+		return ErrInvalidTopicStatus
+	}
+
+	detail, err := json.Marshal(map[string]interface{}{
+		"author_id": topic.UserID,
+		"reason":    reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit detail: %w", err)
+	}
+	audit := &model.AuditLog{
+		OperatorID: operatorID,
+		Action:     model.AuditActionTopicRemove,
+		TargetType: model.AuditTargetTopic,
+		TargetID:   topicID,
+		Detail:     string(detail),
+	}
+
+	if err := s.adminRepo.RemoveTopic(ctx, topicID, audit); err != nil {
+		return fmt.Errorf("failed to remove topic: %w", err)
+	}
+
+	// 立即清除话题及标签缓存，墓碑防止旧数据被回填
+	if err := cache.Invalidate(cache.TopicKey(topicID)); err != nil {
+		logger.Warn("failed to delete topic cache", logger.Any("error", err))
+	}
+	for _, key := range []string{cache.TopicLikeKey(topicID), cache.TopicViewKey(topicID), cache.TopicTagsKey(topicID), cache.PopularTagsKey()} {
+		if err := cache.Delete(key); err != nil {
+			logger.Warn("failed to delete topic cache", logger.Any("error", err), logger.String("key", key))
+		}
+	}
+
+	if notifyAuthor {
+		s.notifyTopicRemoved(topic, reason)
+	}
+
+	return nil
+}
+
+// notifyTopicRemoved 通知作者话题已被下架
+// 这里应该通过推送通知用户，实际项目中应该通过消息队列处理
+func (s *AdminService) notifyTopicRemoved(topic *model.Topic, reason string) {
+	logger.Info("topic removed notification",
+		logger.Uint64("user_id", topic.UserID),
+		logger.Uint64("topic_id", topic.ID),
+		logger.String("reason", reason))
+}
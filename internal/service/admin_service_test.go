@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/utils"
+)
+
+func TestRemoveTopicEvictsCaches(t *testing.T) {
+	resetCache(t)
+	topic := newTestTopic(1, 7, model.TopicStatusActive)
+	topicRepo := newFakeTopicRepo(topic)
+	adminRepo := &fakeAdminRepo{topicRepo: topicRepo}
+	svc := NewAdminService(adminRepo, topicRepo)
+
+	keys := []string{cache.TopicKey(1), cache.TopicLikeKey(1), cache.TopicViewKey(1), cache.TopicTagsKey(1), cache.PopularTagsKey()}
+	for _, key := range keys {
+		if err := cache.Set(key, 1, time.Minute); err != nil {
+			t.Fatalf("set %s: %v", key, err)
+		}
+	}
+
+	if err := svc.RemoveTopic(context.Background(), 99, 1, "spam", false); err != nil {
+		t.Fatalf("RemoveTopic: %v", err)
+	}
+	for _, key := range keys {
+		if exists, _ := cache.Exists(key); exists {
+			t.Errorf("cache %s still exists", key)
+		}
+	}
+	// 墓碑阻止并发读取回填下架前的话题
+	if stored, err := cache.SetIfFresh(cache.TopicKey(1), topic, time.Minute); err != nil || stored {
+		t.Errorf("SetIfFresh = %v, %v, want blocked by tombstone", stored, err)
+	}
+	if len(adminRepo.audits) != 1 || adminRepo.audits[0].OperatorID != 99 || adminRepo.audits[0].TargetID != 1 {
+		t.Errorf("audits = %+v, want one entry for topic 1 by operator 99", adminRepo.audits)
+	}
+
+	// 已下架的话题不能重复下架
+	if err := svc.RemoveTopic(context.Background(), 99, 1, "spam", false); err != ErrInvalidTopicStatus {
+		t.Errorf("remove twice: err = %v, want ErrInvalidTopicStatus", err)
+	}
+}
+
+func TestGetDashboardStatsAggregatesAndCaches(t *testing.T) {
+	resetCache(t)
+	topicRepo := newFakeTopicRepo()
+	adminRepo := &fakeAdminRepo{topicRepo: topicRepo, stats: DashboardStats{
+		TotalUsers:    100,
+		ActiveUsers:   40,
+		TopicsToday:   12,
+		MessagesToday: 300,
+		OpenReports:   3,
+	}}
+	svc := NewAdminService(adminRepo, topicRepo)
+	ctx := context.Background()
+
+	before := time.Now()
+	stats, err := svc.GetDashboardStats(ctx)
+	if err != nil {
+		t.Fatalf("GetDashboardStats: %v", err)
+	}
+	if stats.TotalUsers != 100 || stats.ActiveUsers != 40 || stats.TopicsToday != 12 ||
+		stats.MessagesToday != 300 || stats.OpenReports != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if adminRepo.countQueries != 5 {
+		t.Errorf("count queries = %d, want 5", adminRepo.countQueries)
+	}
+	if d := before.Add(-ActiveUserWindow).Sub(adminRepo.activeSince); d > 0 || d < -time.Second {
+		t.Errorf("active since = %v, want %v ago", adminRepo.activeSince, ActiveUserWindow)
+	}
+	if !adminRepo.todaySince.Equal(utils.GetStartOfDay(stats.GeneratedAt)) {
+		t.Errorf("today since = %v, want start of day", adminRepo.todaySince)
+	}
+
+	// 缓存期内不再查询
+	adminRepo.stats.TotalUsers = 101
+	cached, err := svc.GetDashboardStats(ctx)
+	if err != nil {
+		t.Fatalf("cached GetDashboardStats: %v", err)
+	}
+	if adminRepo.countQueries != 5 || cached.TotalUsers != 100 {
+		t.Errorf("cached stats = %+v after %d queries, want cached values", cached, adminRepo.countQueries)
+	}
+
+	// 缓存过期后重新统计
+	testRedis.FastForward(DashboardStatsExpiration)
+	fresh, err := svc.GetDashboardStats(ctx)
+	if err != nil {
+		t.Fatalf("fresh GetDashboardStats: %v", err)
+	}
+	if adminRepo.countQueries != 10 || fresh.TotalUsers != 101 {
+		t.Errorf("fresh stats = %+v after %d queries, want recomputed", fresh, adminRepo.countQueries)
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/pkg/storage"
+)
+
+// 动图头像处理策略
+const (
+	AnimatedAvatarReject  = "reject"
+	AnimatedAvatarFlatten = "flatten"
+)
+
+// 非正方形头像处理策略
+const (
+	AvatarShapeCrop   = "crop"
+	AvatarShapeReject = "reject"
+)
+
+// AvatarUpload 头像上传结果，宽高为处理后的尺寸
+type AvatarUpload struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// uploadAvatar 处理并上传用户或聊天室头像
+// 动图按配置拒绝或只保留第一帧，非正方形图片按配置裁剪或拒绝，并限制最大边长
+func uploadAvatar(ctx context.Context, store storage.Storage, avatar *model.File, directory string, cfg config.UploadConfig) (*AvatarUpload, error) {
+	animated, err := storage.IsAnimated(avatar.File)
+	if err == storage.ErrImageTooLarge {
+		return nil, ErrImageDimensionsTooLarge
+	}
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+
+	opts := storage.AvatarOptions{
+		Crop:           cfg.AvatarShapePolicy != AvatarShapeReject,
+		MaxAspectRatio: cfg.AvatarMaxAspectRatio,
+		MaxDimension:   cfg.AvatarMaxDimension,
+	}
+
+	name := avatar.File.Filename
+	var processed *storage.AvatarImage
+	if animated {
+		if cfg.AnimatedAvatarPolicy == AnimatedAvatarReject {
+			return nil, ErrFileTypeNotSupported
+		}
+
+		frame, err := storage.FlattenAnimated(avatar.File)
+		if err != nil {
+			return nil, avatarProcessError(err)
+		}
+		// 首帧已重新编码，文件名随之更改以保证 Content-Type 正确
+		name = strings.TrimSuffix(name, path.Ext(name)) + storage.FlattenedExt
+		processed, err = storage.ProcessAvatar(frame, opts)
+		if err != nil {
+			return nil, avatarProcessError(err)
+		}
+	} else {
+		processed, err = storage.ProcessAvatarFile(avatar.File, opts)
+		if err != nil {
+			return nil, avatarProcessError(err)
+		}
+	}
+
+	if processed.Ext != "" {
+		name = strings.TrimSuffix(name, path.Ext(name)) + processed.Ext
+	}
+
+	fileURL, err := store.UploadBytes(ctx, processed.Data, name, directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload avatar: %w", err)
+	}
+	return &AvatarUpload{URL: fileURL, Width: processed.Width, Height: processed.Height}, nil
+}
+
+// avatarProcessError 将头像处理错误转换为业务错误
+func avatarProcessError(err error) error {
+	switch err {
+	case storage.ErrUnsupportedImage, storage.ErrCannotFlatten:
+		return ErrFileTypeNotSupported
+	case storage.ErrImageTooLarge:
+		return ErrImageDimensionsTooLarge
+	case storage.ErrAspectRatio:
+		return ErrInvalidAspectRatio
+	default:
+		return fmt.Errorf("failed to process avatar: %w", err)
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"mime/multipart"
+	"testing"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+)
+
+// animatedGIF 生成两帧的 GIF 头像
+func animatedGIF(t *testing.T) *model.File {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{Delay: []int{10, 10}}
+	for i := 0; i < 2; i++ {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, 8, 8), palette))
+	}
+	var data bytes.Buffer
+	if err := gif.EncodeAll(&data, anim); err != nil {
+		t.Fatalf("encode gif: %v", err)
+	}
+
+	return avatarFile(t, "me.gif", data.Bytes())
+}
+
+// avatarFile 构造上传的头像文件
+func avatarFile(t *testing.T, name string, data []byte) *model.File {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, _ := w.CreateFormFile("avatar", name)
+	part.Write(data)
+	w.Close()
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("read form: %v", err)
+	}
+	header := form.File["avatar"][0]
+	return &model.File{File: header, Type: "image", Name: header.Filename, Size: uint(header.Size)}
+}
+
+func TestUploadAvatarAnimated(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		wantURL string
+		wantErr error
+	}{
+		{"flatten renames to png", AnimatedAvatarFlatten, "avatars/me.png", nil},
+		{"reject", AnimatedAvatarReject, "", ErrFileTypeNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.UploadConfig{AnimatedAvatarPolicy: tt.policy, AvatarMaxAspectRatio: 2}
+			result, err := uploadAvatar(context.Background(), &fakeStorage{}, animatedGIF(t), "avatars", cfg)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && result.URL != tt.wantURL {
+				t.Errorf("url = %q, want %q", result.URL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestUploadAvatarWideImage(t *testing.T) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, image.NewGray(image.Rect(0, 0, 300, 100))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+
+	cfg := config.UploadConfig{AvatarShapePolicy: AvatarShapeCrop, AvatarMaxDimension: 64}
+	result, err := uploadAvatar(context.Background(), &fakeStorage{}, avatarFile(t, "wide.png", data.Bytes()), "avatars", cfg)
+	if err != nil {
+		t.Fatalf("crop: %v", err)
+	}
+	if result.Width != 64 || result.Height != 64 {
+		t.Errorf("size = %dx%d, want 64x64", result.Width, result.Height)
+	}
+
+	cfg = config.UploadConfig{AvatarShapePolicy: AvatarShapeReject, AvatarMaxAspectRatio: 2}
+	if _, err := uploadAvatar(context.Background(), &fakeStorage{}, avatarFile(t, "wide.png", data.Bytes()), "avatars", cfg); err != ErrInvalidAspectRatio {
+		t.Errorf("reject err = %v, want ErrInvalidAspectRatio", err)
+	}
+}
+
+func TestUploadAvatarRejectsOversizedDimensions(t *testing.T) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, image.NewGray(image.Rect(0, 0, 5000, 1))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	cfg := config.UploadConfig{AvatarShapePolicy: AvatarShapeCrop}
+	if _, err := uploadAvatar(context.Background(), &fakeStorage{}, avatarFile(t, "huge.png", data.Bytes()), "avatars", cfg); err != ErrImageDimensionsTooLarge {
+		t.Errorf("err = %v, want ErrImageDimensionsTooLarge", err)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"DistanceBack_v1/pkg/cache"
+)
+
+const (
+	// RoomPresenceTTL 房间在线状态有效期，客户端需在此时间内发送心跳
+	RoomPresenceTTL = 60 * time.Second
+	// RoomTypingTTL 正在输入状态有效期，超时视为停止输入
+	RoomTypingTTL = 6 * time.Second
+)
+
+// RoomPresence 聊天室实时状态
+type RoomPresence struct {
+	ViewerCount int64    `json:"viewer_count"`
+	Viewers     []uint64 `json:"viewers"`
+	Typing      []uint64 `json:"typing"`
+}
+
+// EnterRoom 标记用户正在查看聊天室，重复调用即为心跳
+func (s *ChatService) EnterRoom(ctx context.Context, userID, roomID uint64) error {
+	if !s.isRoomMember(ctx, roomID, userID) {
+		return ErrNotRoomMember
+	}
+	return touchPresence(cache.ChatPresenceKey(roomID), userID, RoomPresenceTTL)
+}
+
+// ExitRoom 清除用户在聊天室的查看及输入状态
+func (s *ChatService) ExitRoom(ctx context.Context, userID, roomID uint64) error {
+	if err := cache.ZRem(cache.ChatPresenceKey(roomID), userID); err != nil {
+		return err
+	}
+	return cache.ZRem(cache.ChatTypingKey(roomID), userID)
+}
+
+// SetTyping 设置或清除用户的正在输入状态
+func (s *ChatService) SetTyping(ctx context.Context, userID, roomID uint64, typing bool) error {
+	if !s.isRoomMember(ctx, roomID, userID) {
+		return ErrNotRoomMember
+	}
+	if !typing {
+		return cache.ZRem(cache.ChatTypingKey(roomID), userID)
+	}
+	// 输入时也视为正在查看
+	if err := touchPresence(cache.ChatPresenceKey(roomID), userID, RoomPresenceTTL); err != nil {
+		return err
+	}
+	return touchPresence(cache.ChatTypingKey(roomID), userID, RoomTypingTTL)
+}
+
+// GetRoomPresence 获取聊天室当前查看及输入的成员
+func (s *ChatService) GetRoomPresence(ctx context.Context, userID, roomID uint64) (*RoomPresence, error) {
+	if !s.isRoomMember(ctx, roomID, userID) {
+		return nil, ErrNotRoomMember
+	}
+
+	viewers, err := activeMembers(cache.ChatPresenceKey(roomID))
+	if err != nil {
+		return nil, err
+	}
+	typing, err := activeMembers(cache.ChatTypingKey(roomID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RoomPresence{
+		ViewerCount: int64(len(viewers)),
+		Viewers:     viewers,
+		Typing:      typing,
+	}, nil
+}
+
+// touchPresence 以过期时间为分数记录成员，断线未续期的成员会自然过期
+func touchPresence(key string, userID uint64, ttl time.Duration) error {
+	now := time.Now()
+	if err := cache.ZAdd(key, float64(now.Add(ttl).UnixMilli()), userID); err != nil {
+		return err
+	}
+	// 顺带清理过期成员，并让空闲房间的键自动过期
+	if err := cache.ZRemRangeByMaxScore(key, float64(now.UnixMilli())); err != nil {
+		return err
+	}
+	return cache.Expire(key, ttl)
+}
+
+// activeMembers 获取未过期的成员
+func activeMembers(key string) ([]uint64, error) {
+	members, err := cache.ZRangeByMinScore(key, float64(time.Now().UnixMilli()))
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, nil
+}
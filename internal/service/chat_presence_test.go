@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/pkg/cache"
+)
+
+func TestRoomPresence(t *testing.T) {
+	resetCache(t)
+	chatRepo := newFakeChatRepo()
+	chatRepo.addRoom(1, "group", member(7, "owner"), member(8, "member"), member(9, "member"))
+	svc := newTestChatService(chatRepo)
+	ctx := context.Background()
+
+	if err := svc.EnterRoom(ctx, 7, 1); err != nil {
+		t.Fatalf("EnterRoom: %v", err)
+	}
+	if err := svc.SetTyping(ctx, 8, 1, true); err != nil {
+		t.Fatalf("SetTyping: %v", err)
+	}
+	// 断线未续期的成员
+	if err := cache.ZAdd(cache.ChatPresenceKey(1), float64(time.Now().Add(-time.Second).UnixMilli()), uint64(9)); err != nil {
+		t.Fatalf("ZAdd: %v", err)
+	}
+
+	presence, err := svc.GetRoomPresence(ctx, 7, 1)
+	if err != nil {
+		t.Fatalf("GetRoomPresence: %v", err)
+	}
+	if presence.ViewerCount != 2 || !containsID(presence.Viewers, 7) || !containsID(presence.Viewers, 8) {
+		t.Errorf("viewers = %v (count %d), want 7 and 8", presence.Viewers, presence.ViewerCount)
+	}
+	if len(presence.Typing) != 1 || presence.Typing[0] != 8 {
+		t.Errorf("typing = %v, want [8]", presence.Typing)
+	}
+
+	if err := svc.ExitRoom(ctx, 8, 1); err != nil {
+		t.Fatalf("ExitRoom: %v", err)
+	}
+	presence, err = svc.GetRoomPresence(ctx, 7, 1)
+	if err != nil {
+		t.Fatalf("GetRoomPresence: %v", err)
+	}
+	if presence.ViewerCount != 1 || len(presence.Typing) != 0 {
+		t.Errorf("after exit presence = %+v, want only viewer 7", presence)
+	}
+}
+
+func TestRoomPresenceRequiresMembership(t *testing.T) {
+	resetCache(t)
+	chatRepo := newFakeChatRepo()
+	chatRepo.addRoom(1, "group", member(7, "owner"))
+	svc := newTestChatService(chatRepo)
+	ctx := context.Background()
+
+	if err := svc.EnterRoom(ctx, 8, 1); err != ErrNotRoomMember {
+		t.Errorf("EnterRoom err = %v, want ErrNotRoomMember", err)
+	}
+	if err := svc.SetTyping(ctx, 8, 1, true); err != ErrNotRoomMember {
+		t.Errorf("SetTyping err = %v, want ErrNotRoomMember", err)
+	}
+	if _, err := svc.GetRoomPresence(ctx, 8, 1); err != ErrNotRoomMember {
+		t.Errorf("GetRoomPresence err = %v, want ErrNotRoomMember", err)
+	}
+}
+
+func containsID(ids []uint64, id uint64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -3,19 +3,28 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"DistanceBack_v1/config"
 	"DistanceBack_v1/internal/model"
 	"DistanceBack_v1/internal/repository"
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/linkpreview"
 	"DistanceBack_v1/pkg/logger"
 	"DistanceBack_v1/pkg/storage"
 )
 
 type ChatService struct {
 	chatRepo       repository.ChatRepository
+	topicRepo      repository.TopicRepository
 	userRepo       repository.UserRepository
 	relationRepo   repository.RelationshipRepository
 	storage        storage.Storage
 	maxRoomMembers int
+	mediaPolicy    string // 媒体上传失败处理策略
+	upload         config.UploadConfig
+	maxPinnedRooms int // 每个用户最多置顶数量，0 表示不限制
+	linkFetcher    *linkpreview.Fetcher
 }
 
 const (
@@ -23,19 +32,50 @@ const (
 	DefaultMessageLimit   = 50
 )
 
+// MessageContext 指定消息前后的消息窗口
+type MessageContext struct {
+	Messages      []*model.Message `json:"messages"`
+	HasMoreBefore bool             `json:"has_more_before"`
+	HasMoreAfter  bool             `json:"has_more_after"`
+	BeforeCursor  uint64           `json:"before_cursor"` // 继续向前加载时作为 before_id
+	AfterCursor   uint64           `json:"after_cursor"`  // 继续向后加载时作为 after_id
+}
+
+// LeaveAllResult 批量退出群聊结果
+type LeaveAllResult struct {
+	Total  int                `json:"total"`
+	Left   []uint64           `json:"left"`
+	Failed []LeaveRoomFailure `json:"failed"`
+}
+
+// LeaveRoomFailure 退出失败的聊天室，Code 为错误码，Reason 为对应的固定错误信息
+type LeaveRoomFailure struct {
+	RoomID uint64 `json:"room_id"`
+	Code   int    `json:"code"`
+	Reason string `json:"reason"`
+}
+
 // NewChatService 创建聊天服务实例
 func NewChatService(
 	chatRepo repository.ChatRepository,
+	topicRepo repository.TopicRepository,
 	userRepo repository.UserRepository,
 	relationRepo repository.RelationshipRepository,
 	storage storage.Storage,
+	uploadCfg config.UploadConfig,
+	chatCfg config.ChatConfig,
 ) *ChatService {
 	return &ChatService{
 		chatRepo:       chatRepo,
+		topicRepo:      topicRepo,
 		userRepo:       userRepo,
 		relationRepo:   relationRepo,
 		storage:        storage,
 		maxRoomMembers: DefaultMaxRoomMembers,
+		mediaPolicy:    uploadCfg.MediaFailurePolicy,
+		upload:         uploadCfg,
+		maxPinnedRooms: chatCfg.MaxPinnedRooms,
+		linkFetcher:    linkpreview.NewFetcher(),
 	}
 }
 
@@ -52,7 +92,8 @@ func (s *ChatService) CreatePrivateRoom(ctx context.Context, userID1, userID2 ui
 	}
 
 	// 检查是否已经存在私聊房间
-	existingRoom, err := s.findPrivateRoom(ctx, userID1, userID2)
+	pairKey := model.PrivatePairKey(userID1, userID2)
+	existingRoom, err := s.chatRepo.GetPrivateRoom(ctx, pairKey)
 	if err != nil {
 		return nil, err
 	}
@@ -62,48 +103,86 @@ func (s *ChatService) CreatePrivateRoom(ctx context.Context, userID1, userID2 ui
 
 	// 创建新的私聊房间
 	room := &model.ChatRoom{
-		Name: fmt.Sprintf("%s & %s", user1.Nickname, user2.Nickname),
-		Type: "individual",
-	}
-
-	// 创建房间
-	if err := s.chatRepo.CreateRoom(ctx, room); err != nil {
-		return nil, fmt.Errorf("failed to create chat room: %w", err)
+		Name:    fmt.Sprintf("%s & %s", user1.Nickname, user2.Nickname),
+		Type:    "individual",
+		PairKey: &pairKey,
 	}
 
-	// 添加成员
 	members := []*model.ChatRoomMember{
 		{
-			ChatRoomID: room.ID,
-			UserID:     userID1,
-			Role:       "member",
-			Nickname:   user1.Nickname,
+			UserID:   userID1,
+			Role:     "member",
+			Nickname: user1.Nickname,
 		},
 		{
-			ChatRoomID: room.ID,
-			UserID:     userID2,
-			Role:       "member",
-			Nickname:   user2.Nickname,
+			UserID:   userID2,
+			Role:     "member",
+			Nickname: user2.Nickname,
 		},
 	}
 
-	for _, member := range members {
-		if err := s.chatRepo.AddMember(ctx, member); err != nil {
-			return nil, fmt.Errorf("failed to add member: %w", err)
+	// 创建房间和成员，pair_key 唯一索引冲突说明并发请求已创建，返回已有房间
+	if err := s.chatRepo.CreateRoomWithMembers(ctx, room, members); err != nil {
+		existingRoom, findErr := s.chatRepo.GetPrivateRoom(ctx, pairKey)
+		if findErr == nil && existingRoom != nil {
+			return existingRoom, nil
 		}
+		return nil, fmt.Errorf("failed to create chat room: %w", err)
 	}
 
 	return room, nil
 }
 
+// GetPrivateRoomIDs 批量获取用户与多个用户之间已存在的私聊房间ID
+// 返回 目标用户ID -> 房间ID，没有私聊的用户不在结果中
+func (s *ChatService) GetPrivateRoomIDs(ctx context.Context, userID uint64, targetIDs []uint64) (map[uint64]uint64, error) {
+	targetByKey := make(map[string]uint64, len(targetIDs))
+	pairKeys := make([]string, 0, len(targetIDs))
+	for _, targetID := range targetIDs {
+		if targetID == userID {
+			continue
+		}
+		key := model.PrivatePairKey(userID, targetID)
+		if _, ok := targetByKey[key]; ok {
+			continue
+		}
+		targetByKey[key] = targetID
+		pairKeys = append(pairKeys, key)
+	}
+
+	rooms, err := s.chatRepo.ListPrivateRooms(ctx, pairKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list private rooms: %w", err)
+	}
+
+	roomIDs := make(map[uint64]uint64, len(rooms))
+	for _, room := range rooms {
+		if room.PairKey == nil {
+			continue
+		}
+		if targetID, ok := targetByKey[*room.PairKey]; ok {
+			roomIDs[targetID] = room.ID
+		}
+	}
+	return roomIDs, nil
+}
+
 // CreateGroupRoom 创建群聊房间
-func (s *ChatService) CreateGroupRoom(ctx context.Context, creatorID uint64, name string, initialMembers []uint64) (*model.ChatRoom, error) {
+// topicID 为 0 时创建不关联话题的群聊，否则话题必须存在、有效且属于创建者
+func (s *ChatService) CreateGroupRoom(ctx context.Context, creatorID uint64, name string, topicID uint64, initialMembers []uint64) (*model.ChatRoom, error) {
 	// 验证创建者
 	creator, err := s.userRepo.GetByID(ctx, creatorID)
 	if err != nil || creator == nil {
 		return nil, ErrUserNotFound
 	}
 
+	// 验证关联话题
+	if topicID != 0 {
+		if err := s.validateRoomTopic(ctx, creatorID, topicID); err != nil {
+			return nil, err
+		}
+	}
+
 	// 验证初始成员数量
 	if len(initialMembers) > s.maxRoomMembers {
 		return nil, fmt.Errorf("number of members exceeds maximum limit of %d", s.maxRoomMembers)
@@ -114,6 +193,9 @@ func (s *ChatService) CreateGroupRoom(ctx context.Context, creatorID uint64, nam
 		Name: name,
 		Type: "group",
 	}
+	if topicID != 0 {
+		room.TopicID = &topicID
+	}
 
 	// 创建房间
 	if err := s.chatRepo.CreateRoom(ctx, room); err != nil {
@@ -160,53 +242,55 @@ func (s *ChatService) CreateGroupRoom(ctx context.Context, creatorID uint64, nam
 }
 
 // SendMessage 发送消息
-func (s *ChatService) SendMessage(ctx context.Context, userID uint64, roomID uint64, msgType string, content string, files []*model.File) (*model.Message, error) {
+// attachments 为已通过上传接口上传的文件，直接引用无需重新上传
+func (s *ChatService) SendMessage(ctx context.Context, userID uint64, roomID uint64, msgType string, content string, files []*model.File, attachments []*UploadHandle) (*model.Message, error) {
 	// 检查发送者是否是房间成员
 	if !s.isRoomMember(ctx, roomID, userID) {
 		return nil, ErrNotRoomMember
 	}
 
-	// 创建消息
+	// 先上传媒体文件，按配置的策略处理失败的文件
+	uploaded, failures, err := uploadMediaFiles(ctx, s.storage, files, storage.ChatDirectory, s.mediaPolicy, s.upload.MaxConcurrentUploads)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 && len(uploaded) == 0 && content == "" && len(attachments) == 0 {
+		// 消息只有媒体且全部上传失败
+		return nil, newMediaUploadError(failures)
+	}
+
+	// 创建消息，媒体记录随消息在同一事务中写入
 	msg := &model.Message{
 		ChatRoomID:  roomID,
 		SenderID:    userID,
 		ContentType: msgType,
 		Content:     content,
 	}
+	for _, m := range uploaded {
+		msg.MessageMedia = append(msg.MessageMedia, model.MessageMedia{
+			MediaType: m.File.Type,
+			MediaURL:  m.URL,
+			FileName:  m.File.Name,
+			FileSize:  m.File.Size,
+		})
+	}
+	// 引用的已上传文件
+	for _, attachment := range attachments {
+		msg.MessageMedia = append(msg.MessageMedia, model.MessageMedia{
+			MediaType: attachment.MediaType,
+			MediaURL:  attachment.URL,
+			FileName:  attachment.FileName,
+			FileSize:  attachment.FileSize,
+		})
+	}
 
 	// 发送消息
 	if err := s.chatRepo.CreateMessage(ctx, msg); err != nil {
+		deleteUploadedMedia(ctx, s.storage, uploaded)
 		return nil, fmt.Errorf("failed to create message: %w", err)
 	}
-
-	// 处理媒体文件
-	if len(files) > 0 {
-		for _, file := range files {
-			// 上传文件
-			fileURL, err := s.storage.UploadFile(ctx, file.File, storage.ChatDirectory)
-			if err != nil {
-				logger.Error("failed to upload message media",
-					logger.Any("error", err),
-					logger.Uint64("message_id", msg.ID))
-				continue
-			}
-
-			// 创建媒体记录
-			media := &model.MessageMedia{
-				MessageID: msg.ID,
-				MediaType: file.Type,
-				MediaURL:  fileURL,
-				FileName:  file.Name,
-				FileSize:  file.Size,
-			}
-
-			if err := s.chatRepo.AddMessageMedia(ctx, media); err != nil {
-				logger.Error("failed to save message media",
-					logger.Any("error", err),
-					logger.Uint64("message_id", msg.ID))
-			}
-		}
-	}
+	msg.FailedMedia = failures
+	resolveMessageMedia(ctx, s.storage, msg)
 
 	// 更新房间成员的未读消息状态
 	// 实际项目中，这里应该通过消息队列异步处理
@@ -215,8 +299,17 @@ func (s *ChatService) SendMessage(ctx context.Context, userID uint64, roomID uin
 	return msg, nil
 }
 
+// CheckRoomMember 检查用户是否是房间成员，不是时返回 ErrNotRoomMember
+func (s *ChatService) CheckRoomMember(ctx context.Context, userID, roomID uint64) error {
+	if !s.isRoomMember(ctx, roomID, userID) {
+		return ErrNotRoomMember
+	}
+	return nil
+}
+
 // GetMessages 获取消息历史
-func (s *ChatService) GetMessages(ctx context.Context, userID, roomID uint64, beforeID uint64, limit int) ([]*model.Message, error) {
+// 指定 afterID 时向后加载，否则按 beforeID 向前加载
+func (s *ChatService) GetMessages(ctx context.Context, userID, roomID uint64, beforeID, afterID uint64, limit int) ([]*model.Message, error) {
 	// 检查用户是否是房间成员
 	if !s.isRoomMember(ctx, roomID, userID) {
 		return nil, ErrNotRoomMember
@@ -226,7 +319,139 @@ func (s *ChatService) GetMessages(ctx context.Context, userID, roomID uint64, be
 		limit = DefaultMessageLimit
 	}
 
-	return s.chatRepo.GetMessagesByRoom(ctx, roomID, beforeID, limit)
+	var messages []*model.Message
+	var err error
+	if afterID > 0 {
+		messages, err = s.chatRepo.GetMessagesAfter(ctx, roomID, userID, afterID, limit)
+	} else {
+		messages, err = s.chatRepo.GetMessagesByRoom(ctx, roomID, userID, beforeID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.attachSharedTopics(ctx, messages)
+	resolveMessageMedia(ctx, s.storage, messages...)
+	return messages, nil
+}
+
+// GetMessageContext 获取指定消息前后的消息
+func (s *ChatService) GetMessageContext(ctx context.Context, userID, roomID, messageID uint64, before, after int) (*MessageContext, error) {
+	// 检查用户是否是房间成员
+	if !s.isRoomMember(ctx, roomID, userID) {
+		return nil, ErrNotRoomMember
+	}
+
+	// 检查消息是否属于该房间
+	message, err := s.chatRepo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil || message.ChatRoomID != roomID {
+		return nil, ErrMessageNotFound
+	}
+	hidden, err := s.chatRepo.IsMessageHidden(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		return nil, ErrMessageNotFound
+	}
+
+	if before < 0 || before > DefaultMessageLimit {
+		before = DefaultMessageLimit
+	}
+	if after < 0 || after > DefaultMessageLimit {
+		after = DefaultMessageLimit
+	}
+
+	// 两侧各多取一条用于判断是否还有更多
+	messages, err := s.chatRepo.GetMessagesAround(ctx, roomID, userID, messageID, before+1, after+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages around: %w", err)
+	}
+
+	result := &MessageContext{}
+	pivot := 0
+	for i, msg := range messages {
+		if msg.ID == messageID {
+			pivot = i
+			break
+		}
+	}
+	if pivot > before {
+		result.HasMoreBefore = true
+		messages = messages[pivot-before:]
+		pivot = before
+	}
+	if len(messages)-pivot-1 > after {
+		result.HasMoreAfter = true
+		messages = messages[:pivot+after+1]
+	}
+
+	s.attachSharedTopics(ctx, messages)
+	resolveMessageMedia(ctx, s.storage, messages...)
+	result.Messages = messages
+	if len(messages) > 0 {
+		result.BeforeCursor = messages[0].ID
+		result.AfterCursor = messages[len(messages)-1].ID
+	}
+
+	return result, nil
+}
+
+// 消息删除方式
+const (
+	// DeleteScopeMe 仅对自己删除，其他成员不受影响
+	DeleteScopeMe = "me"
+	// DeleteScopeEveryone 对所有人删除（撤回），仅发送者可操作，历史中保留墓碑
+	DeleteScopeEveryone = "everyone"
+)
+
+// DeleteMessage 删除消息
+// scope 为 me 时仅对当前用户隐藏；为 everyone 时清空内容并保留墓碑，仅限发送者
+// 群主/管理员删除他人消息属于管理操作，不走此方法
+func (s *ChatService) DeleteMessage(ctx context.Context, userID, roomID, messageID uint64, scope string) error {
+	if !s.isRoomMember(ctx, roomID, userID) {
+		return ErrNotRoomMember
+	}
+
+	message, err := s.chatRepo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if message == nil || message.ChatRoomID != roomID {
+		return ErrMessageNotFound
+	}
+
+	switch scope {
+	case DeleteScopeMe:
+		return s.chatRepo.HideMessage(ctx, userID, messageID)
+	case DeleteScopeEveryone:
+		if message.SenderID != userID {
+			return ErrForbidden
+		}
+		if message.IsDeleted() {
+			return nil
+		}
+		media, err := s.chatRepo.GetMessageMedia(ctx, messageID)
+		if err != nil {
+			return err
+		}
+		if err := s.chatRepo.DeleteMessageForEveryone(ctx, messageID); err != nil {
+			return fmt.Errorf("failed to delete message: %w", err)
+		}
+		for _, m := range media {
+			if err := s.storage.DeleteFile(ctx, m.MediaURL); err != nil {
+				logger.Warn("failed to delete message media",
+					logger.Any("error", err),
+					logger.Uint64("message_id", messageID))
+			}
+		}
+		return nil
+	default:
+		return ErrInvalidRequest
+	}
 }
 
 // MarkMessagesAsRead 标记消息为已读
@@ -316,14 +541,87 @@ func (s *ChatService) RemoveMember(ctx context.Context, operatorID, roomID, user
 	return s.chatRepo.RemoveMember(ctx, roomID, userID)
 }
 
+// LeaveRoom 退出群聊
+// 群主退出时转让给最早加入的管理员，没有管理员则转让给最早加入的成员
+func (s *ChatService) LeaveRoom(ctx context.Context, userID, roomID uint64) error {
+	room, err := s.chatRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		return ErrChatRoomNotFound
+	}
+	// 私聊不支持退出
+	if room.Type != "group" {
+		return ErrInvalidOperation
+	}
+
+	members, err := s.chatRepo.GetRoomMembers(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	var self *model.ChatRoomMember
+	for _, member := range members {
+		if member.UserID == userID {
+			self = member
+			break
+		}
+	}
+	if self == nil {
+		return ErrNotRoomMember
+	}
+
+	var newOwnerID uint64
+	if self.Role == "owner" {
+		newOwnerID = pickNextOwner(members, userID)
+	}
+
+	return s.chatRepo.LeaveRoom(ctx, roomID, userID, newOwnerID)
+}
+
+// LeaveAllGroups 退出用户加入的所有群聊，私聊不受影响
+// 逐个处理，单个聊天室失败不影响其余聊天室
+func (s *ChatService) LeaveAllGroups(ctx context.Context, userID uint64) (*LeaveAllResult, error) {
+	rooms, err := s.chatRepo.ListUserRoomsByType(ctx, userID, "group")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LeaveAllResult{
+		Total:  len(rooms),
+		Left:   make([]uint64, 0, len(rooms)),
+		Failed: make([]LeaveRoomFailure, 0),
+	}
+	for _, room := range rooms {
+		if err := s.LeaveRoom(ctx, userID, room.ID); err != nil {
+			logger.Error("退出群聊失败",
+				logger.Any("error", err),
+				logger.Uint64("room_id", room.ID),
+				logger.Uint64("user_id", userID))
+			code, reason := failureCode(err)
+			result.Failed = append(result.Failed, LeaveRoomFailure{
+				RoomID: room.ID,
+				Code:   code,
+				Reason: reason,
+			})
+			continue
+		}
+		result.Left = append(result.Left, room.ID)
+	}
+
+	return result, nil
+}
+
 // UpdateMemberRole 更新成员角色
+// 群主将其他成员设为 owner 时转让群主，自己降为管理员
 func (s *ChatService) UpdateMemberRole(ctx context.Context, operatorID, roomID, userID uint64, newRole string) error {
 	// 检查操作者权限
 	operatorMember, err := s.getMemberInfo(ctx, roomID, operatorID)
 	if err != nil {
 		return err
 	}
-	if operatorMember == nil || operatorMember.Role != "owner" {
+	if operatorMember == nil {
 		return ErrForbidden
 	}
 
@@ -336,14 +634,72 @@ func (s *ChatService) UpdateMemberRole(ctx context.Context, operatorID, roomID,
 		return ErrNotRoomMember
 	}
 
+	if !canChangeRole(operatorMember, member, newRole) {
+		return ErrForbidden
+	}
+	if member.Role == newRole {
+		return nil
+	}
+
+	// 转让群主
+	if newRole == "owner" {
+		return s.chatRepo.TransferOwnership(ctx, roomID, operatorID, userID)
+	}
+
 	// 更新角色
 	member.Role = newRole
 	return s.chatRepo.UpdateMember(ctx, member)
 }
 
-// GetRoomInfo 获取聊天室信息
-func (s *ChatService) GetRoomInfo(ctx context.Context, roomID uint64) (*model.ChatRoom, error) {
-	return s.chatRepo.GetRoomByID(ctx, roomID)
+// roleRank 成员角色等级
+var roleRank = map[string]int{
+	"member": 1,
+	"admin":  2,
+	"owner":  3,
+}
+
+// canChangeRole 检查操作者能否将目标成员设为指定角色
+// 不能修改自己和群主的角色，不能授予高于自己的角色，owner 只能由群主转让
+func canChangeRole(operator, target *model.ChatRoomMember, newRole string) bool {
+	if operator.UserID == target.UserID || target.Role == "owner" {
+		return false
+	}
+	if roleRank[operator.Role] < roleRank["admin"] {
+		return false
+	}
+	if newRole == "owner" {
+		return operator.Role == "owner"
+	}
+	return roleRank[newRole] > 0 && roleRank[newRole] <= roleRank[operator.Role]
+}
+
+// GetSharedRooms 获取与另一用户共同加入的聊天室，私聊不在其中
+func (s *ChatService) GetSharedRooms(ctx context.Context, userID, otherUserID uint64) ([]*model.ChatRoom, error) {
+	if userID == otherUserID {
+		return nil, ErrInvalidRequest
+	}
+	return s.chatRepo.GetSharedRooms(ctx, userID, otherUserID)
+}
+
+// GetRoomInfo 获取聊天室信息，在线状态可用时附带当前查看人数
+// 只有当前成员可以查看，已退出或被移除的用户返回 ErrNotRoomMember
+func (s *ChatService) GetRoomInfo(ctx context.Context, userID, roomID uint64) (*model.ChatRoom, error) {
+	room, err := s.chatRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrChatRoomNotFound
+	}
+	if !s.isRoomMember(ctx, roomID, userID) {
+		return nil, ErrNotRoomMember
+	}
+
+	if viewers, err := activeMembers(cache.ChatPresenceKey(roomID)); err == nil {
+		count := int64(len(viewers))
+		room.ViewerCount = &count
+	}
+	return room, nil
 }
 
 // ListUserRooms 获取用户的聊天室列表
@@ -354,38 +710,46 @@ func (s *ChatService) ListUserRooms(ctx context.Context, userID uint64, page, pa
 
 // 辅助方法
 
-// findPrivateRoom 查找两个用户之间的私聊房间
-func (s *ChatService) findPrivateRoom(ctx context.Context, userID1, userID2 uint64) (*model.ChatRoom, error) {
-	rooms, _, err := s.chatRepo.ListUserRooms(ctx, userID1, 0, 1000)
+// validateRoomTopic 检查群聊关联的话题是否存在、有效且属于创建者
+func (s *ChatService) validateRoomTopic(ctx context.Context, creatorID, topicID uint64) error {
+	topic, err := s.topicRepo.GetByID(ctx, topicID)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if topic == nil {
+		return ErrTopicNotFound
+	}
+	if topic.UserID != creatorID {
+		return ErrForbidden
+	}
+	if topic.Status != model.TopicStatusActive {
+		return ErrInvalidTopicStatus
+	}
+	if topic.IsExpired(time.Now()) {
+		return ErrTopicExpired
 	}
+	return nil
+}
 
-	for _, room := range rooms {
-		if room.Type != "individual" {
+// pickNextOwner 选出新群主，members 需按加入时间升序排列
+func pickNextOwner(members []*model.ChatRoomMember, leavingUserID uint64) uint64 {
+	var firstMember uint64
+	for _, member := range members {
+		if member.UserID == leavingUserID {
 			continue
 		}
-
-		members, err := s.chatRepo.GetRoomMembers(ctx, room.ID)
-		if err != nil {
-			continue
+		if member.Role == "admin" {
+			return member.UserID
 		}
-
-		if len(members) == 2 {
-			memberIDs := map[uint64]bool{
-				members[0].UserID: true,
-				members[1].UserID: true,
-			}
-			if memberIDs[userID1] && memberIDs[userID2] {
-				return room, nil
-			}
+		if firstMember == 0 {
+			firstMember = member.UserID
 		}
 	}
-
-	return nil, nil
+	return firstMember
 }
 
 // isRoomMember 检查用户是否是房间成员
+// 退出或被移除时成员记录已删除，之后房间内容均不可访问
 func (s *ChatService) isRoomMember(ctx context.Context, roomID, userID uint64) bool {
 	member, _ := s.getMemberInfo(ctx, roomID, userID)
 	return member != nil
@@ -437,6 +801,23 @@ func (s *ChatService) PinRoom(ctx context.Context, userID, roomID uint64) error
 		return ErrNotRoomMember
 	}
 
+	// 检查置顶数量上限，已置顶的房间不重复计数
+	if s.maxPinnedRooms > 0 {
+		pinned, err := s.chatRepo.GetPinnedRooms(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get pinned rooms: %w", err)
+		}
+		count := 0
+		for _, room := range pinned {
+			if room.ID != roomID {
+				count++
+			}
+		}
+		if count >= s.maxPinnedRooms {
+			return ErrPinLimitExceeded
+		}
+	}
+
 	return s.chatRepo.PinRoom(ctx, userID, roomID)
 }
 
@@ -480,34 +861,37 @@ func (s *ChatService) UpdateRoomInfo(ctx context.Context, operatorID uint64, roo
 	return s.chatRepo.UpdateRoom(ctx, existingRoom)
 }
 
-// UpdateRoomAvatar 更新聊天室头像
-func (s *ChatService) UpdateRoomAvatar(ctx context.Context, operatorID uint64, roomID uint64, avatar *model.File) error {
+// UpdateRoomAvatar 更新聊天室头像，返回处理后的头像信息
+func (s *ChatService) UpdateRoomAvatar(ctx context.Context, operatorID uint64, roomID uint64, avatar *model.File) (*AvatarUpload, error) {
 	// 检查操作者权限
 	member, err := s.getMemberInfo(ctx, roomID, operatorID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if member == nil || member.Role == "member" {
-		return ErrForbidden
+		return nil, ErrForbidden
 	}
 
 	// 上传新头像
-	fileURL, err := s.storage.UploadFile(ctx, avatar.File, storage.ChatDirectory)
+	uploaded, err := uploadAvatar(ctx, s.storage, avatar, storage.ChatDirectory, s.upload)
 	if err != nil {
-		return fmt.Errorf("failed to upload avatar: %w", err)
+		return nil, err
 	}
 
 	// 更新房间头像
 	room, err := s.chatRepo.GetRoomByID(ctx, roomID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if room == nil {
-		return ErrChatRoomNotFound
+		return nil, ErrChatRoomNotFound
 	}
 
-	room.AvatarURL = fileURL
-	return s.chatRepo.UpdateRoom(ctx, room)
+	room.AvatarURL = uploaded.URL
+	if err := s.chatRepo.UpdateRoom(ctx, room); err != nil {
+		return nil, err
+	}
+	return uploaded, nil
 }
 
 // MuteMember 将成员禁言
@@ -587,8 +971,8 @@ func (s *ChatService) GetUnreadCount(ctx context.Context, userID, roomID uint64)
 		return 0, ErrNotRoomMember
 	}
 
-	// 获取最新消息ID
-	messages, err := s.chatRepo.GetLatestMessages(ctx, roomID, 1)
+	// 获取当前用户可见的最新消息ID
+	messages, err := s.chatRepo.GetLatestMessages(ctx, roomID, userID, 1)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,535 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+)
+
+func newTestChatService(chatRepo *fakeChatRepo) *ChatService {
+	return NewChatService(chatRepo, newFakeTopicRepo(), newFakeUserRepo(), nil, &fakeStorage{},
+		config.UploadConfig{}, config.ChatConfig{})
+}
+
+func member(userID uint64, role string) *model.ChatRoomMember {
+	return &model.ChatRoomMember{UserID: userID, Role: role}
+}
+
+func TestLeaveAllGroupsIsolatesFailures(t *testing.T) {
+	chatRepo := newFakeChatRepo()
+	// 用户 7 是房间 1 的群主，管理员 9 应接任
+	chatRepo.addRoom(1, "group", member(7, "owner"), member(8, "member"), member(9, "admin"))
+	// 用户 7 是房间 2 的普通成员
+	chatRepo.addRoom(2, "group", member(8, "owner"), member(7, "member"))
+	// 房间 3 读取成员失败，不应影响其余房间
+	chatRepo.addRoom(3, "group", member(7, "member"))
+	chatRepo.failing[3] = errors.New("dial tcp 10.0.0.5:3306: connection refused")
+	// 私聊不受影响
+	chatRepo.addRoom(4, "individual", member(7, "member"), member(8, "member"))
+
+	result, err := newTestChatService(chatRepo).LeaveAllGroups(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("LeaveAllGroups: %v", err)
+	}
+
+	if result.Total != 3 {
+		t.Errorf("Total = %d, want 3", result.Total)
+	}
+	if len(result.Left) != 2 || result.Left[0] != 1 || result.Left[1] != 2 {
+		t.Errorf("Left = %v, want [1 2]", result.Left)
+	}
+	if owner := chatRepo.left[1]; owner != 9 {
+		t.Errorf("room 1 new owner = %d, want 9", owner)
+	}
+	if owner, ok := chatRepo.left[2]; !ok || owner != 0 {
+		t.Errorf("room 2 new owner = %d (left=%v), want 0", owner, ok)
+	}
+	if _, ok := chatRepo.left[4]; ok {
+		t.Error("private room should be untouched")
+	}
+
+	if len(result.Failed) != 1 {
+		t.Fatalf("Failed = %+v, want one failure", result.Failed)
+	}
+	failure := result.Failed[0]
+	if failure.RoomID != 3 || failure.Code != CodeOperationFailed || failure.Reason != ErrOperationFailed.Message {
+		t.Errorf("failure = %+v, want fixed operation-failed code", failure)
+	}
+}
+
+func TestCreateGroupRoomValidatesLinkedTopic(t *testing.T) {
+	creator := &model.User{Nickname: "creator"}
+	creator.ID = 7
+	own := newTestTopic(10, 7, model.TopicStatusActive)
+	foreign := newTestTopic(11, 8, model.TopicStatusActive)
+	closed := newTestTopic(12, 7, model.TopicStatusClosed)
+
+	tests := []struct {
+		name    string
+		topicID uint64
+		wantErr error
+	}{
+		{"unlinked", 0, nil},
+		{"own active topic", 10, nil},
+		{"missing topic", 99, ErrTopicNotFound},
+		{"foreign topic", 11, ErrForbidden},
+		{"closed topic", 12, ErrInvalidTopicStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatRepo := newFakeChatRepo()
+			svc := NewChatService(chatRepo, newFakeTopicRepo(own, foreign, closed), newFakeUserRepo(creator), nil,
+				&fakeStorage{}, config.UploadConfig{}, config.ChatConfig{})
+
+			room, err := svc.CreateGroupRoom(context.Background(), creator.ID, "group", tt.topicID, nil)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(chatRepo.rooms) != 0 {
+					t.Errorf("room created despite error: %+v", chatRepo.rooms)
+				}
+				return
+			}
+			if tt.topicID == 0 && room.TopicID != nil {
+				t.Errorf("unlinked room TopicID = %v", *room.TopicID)
+			}
+			if tt.topicID != 0 && (room.TopicID == nil || *room.TopicID != tt.topicID) {
+				t.Errorf("room TopicID = %v, want %d", room.TopicID, tt.topicID)
+			}
+		})
+	}
+}
+
+func mediaFiles(names ...string) []*model.File {
+	files := make([]*model.File, len(names))
+	for i, name := range names {
+		files[i] = &model.File{Name: name, Type: "image", File: &multipart.FileHeader{Filename: name}}
+	}
+	return files
+}
+
+func TestSendMessagePartialMediaFailure(t *testing.T) {
+	chatRepo := newFakeChatRepo()
+	chatRepo.addRoom(1, "group", member(7, "owner"))
+	store := &fakeStorage{failing: map[string]bool{"b.png": true}}
+	svc := NewChatService(chatRepo, newFakeTopicRepo(), newFakeUserRepo(), nil, store,
+		config.UploadConfig{MediaFailurePolicy: MediaFailurePartial}, config.ChatConfig{})
+
+	msg, err := svc.SendMessage(context.Background(), 7, 1, "image", "", mediaFiles("a.png", "b.png", "c.png"), nil)
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	// 媒体记录随消息一起写入
+	if len(msg.MessageMedia) != 2 || msg.MessageMedia[0].FileName != "a.png" || msg.MessageMedia[1].FileName != "c.png" {
+		t.Errorf("media = %+v, want a.png and c.png", msg.MessageMedia)
+	}
+	if len(msg.FailedMedia) != 1 {
+		t.Fatalf("failed = %+v, want one failure", msg.FailedMedia)
+	}
+	failure := msg.FailedMedia[0]
+	if failure.Index != 1 || failure.Code != CodeUploadFailed || failure.Reason != ErrUploadFailed.Message {
+		t.Errorf("failure = %+v, want fixed upload-failed reason for index 1", failure)
+	}
+}
+
+func TestSendMessageAllMediaFailed(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"media only", "", true},
+		{"with caption", "look", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatRepo := newFakeChatRepo()
+			chatRepo.addRoom(1, "group", member(7, "owner"))
+			store := &fakeStorage{failing: map[string]bool{"a.png": true}}
+			svc := NewChatService(chatRepo, newFakeTopicRepo(), newFakeUserRepo(), nil, store,
+				config.UploadConfig{MediaFailurePolicy: MediaFailurePartial}, config.ChatConfig{})
+
+			// 没有文字的消息全部媒体失败时不保存，有文字时保存文字并返回失败列表
+			msg, err := svc.SendMessage(context.Background(), 7, 1, "image", tt.content, mediaFiles("a.png"), nil)
+			if tt.wantErr {
+				var svcErr *Error
+				if !errors.As(err, &svcErr) || svcErr.Code != CodeUploadFailed {
+					t.Fatalf("err = %v, want upload failed", err)
+				}
+				if len(chatRepo.messages) != 0 {
+					t.Errorf("messages = %d, want none", len(chatRepo.messages))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SendMessage: %v", err)
+			}
+			if msg.Content != tt.content || len(msg.MessageMedia) != 0 || len(msg.FailedMedia) != 1 {
+				t.Errorf("msg = %+v, want caption with one failure", msg)
+			}
+		})
+	}
+}
+
+func TestSendMessageStrictMediaFailure(t *testing.T) {
+	chatRepo := newFakeChatRepo()
+	chatRepo.addRoom(1, "group", member(7, "owner"))
+	store := &fakeStorage{failing: map[string]bool{"b.png": true}}
+	svc := NewChatService(chatRepo, newFakeTopicRepo(), newFakeUserRepo(), nil, store,
+		config.UploadConfig{MediaFailurePolicy: MediaFailureStrict}, config.ChatConfig{})
+
+	_, err := svc.SendMessage(context.Background(), 7, 1, "image", "", mediaFiles("a.png", "b.png", "c.png"), nil)
+	var svcErr *Error
+	if !errors.As(err, &svcErr) || svcErr.Code != CodeUploadFailed {
+		t.Fatalf("err = %v, want upload failed", err)
+	}
+	if len(chatRepo.messages) != 0 {
+		t.Errorf("messages = %d, want none", len(chatRepo.messages))
+	}
+	if len(store.deleted) != 2 {
+		t.Errorf("deleted = %v, want both uploaded files removed", store.deleted)
+	}
+}
+
+func TestSendMessageRemovesMediaWhenSaveFails(t *testing.T) {
+	chatRepo := newFakeChatRepo()
+	chatRepo.addRoom(1, "group", member(7, "owner"))
+	chatRepo.createMsgFail = errors.New("insert message_media: deadlock")
+	store := &fakeStorage{}
+	svc := NewChatService(chatRepo, newFakeTopicRepo(), newFakeUserRepo(), nil, store,
+		config.UploadConfig{MediaFailurePolicy: MediaFailurePartial}, config.ChatConfig{})
+
+	if _, err := svc.SendMessage(context.Background(), 7, 1, "image", "", mediaFiles("a.png"), nil); err == nil {
+		t.Fatal("SendMessage succeeded, want error")
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != "chats/a.png" {
+		t.Errorf("deleted = %v, want the uploaded file removed", store.deleted)
+	}
+}
+
+func TestSendMessageReturnsSignedMediaURL(t *testing.T) {
+	chatRepo := newFakeChatRepo()
+	chatRepo.addRoom(1, "group", member(7, "owner"))
+	store := &fakeStorage{private: "chats"}
+	svc := NewChatService(chatRepo, newFakeTopicRepo(), newFakeUserRepo(), nil, store,
+		config.UploadConfig{}, config.ChatConfig{})
+
+	msg, err := svc.SendMessage(context.Background(), 7, 1, "image", "", mediaFiles("a.png"), nil)
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if got := msg.MessageMedia[0].MediaURL; got != "chats/a.png?signature=test" {
+		t.Errorf("returned url = %q, want signed url", got)
+	}
+	// 数据库中保存原地址，签名地址会过期
+	if got := chatRepo.messages[0].MessageMedia[0].MediaURL; got != "chats/a.png" {
+		t.Errorf("stored url = %q, want original url", got)
+	}
+}
+
+func TestCanChangeRole(t *testing.T) {
+	tests := []struct {
+		name         string
+		operatorRole string
+		targetRole   string
+		self         bool
+		newRole      string
+		want         bool
+	}{
+		{"owner promotes member to admin", "owner", "member", false, "admin", true},
+		{"owner demotes admin", "owner", "admin", false, "member", true},
+		{"owner transfers ownership", "owner", "admin", false, "owner", true},
+		{"admin promotes member", "admin", "member", false, "admin", true},
+		{"admin demotes admin", "admin", "admin", false, "member", true},
+		{"admin grants owner", "admin", "member", false, "owner", false},
+		{"admin changes owner", "admin", "owner", false, "member", false},
+		{"admin promotes self", "admin", "admin", true, "owner", false},
+		{"owner demotes self", "owner", "owner", true, "member", false},
+		{"member promotes member", "member", "member", false, "admin", false},
+		{"member promotes self", "member", "member", true, "admin", false},
+		{"unknown role", "owner", "member", false, "superuser", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operator := member(7, tt.operatorRole)
+			target := member(8, tt.targetRole)
+			if tt.self {
+				target = operator
+			}
+			if got := canChangeRole(operator, target, tt.newRole); got != tt.want {
+				t.Errorf("canChangeRole = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRoomInfoRequiresCurrentMembership(t *testing.T) {
+	resetCache(t)
+	chatRepo := newFakeChatRepo()
+	chatRepo.addRoom(1, "group", member(7, "owner"), member(8, "member"))
+	svc := newTestChatService(chatRepo)
+	ctx := context.Background()
+
+	if _, err := svc.GetRoomInfo(ctx, 8, 1); err != nil {
+		t.Fatalf("member GetRoomInfo: %v", err)
+	}
+
+	// 退出后成员记录被删除
+	if err := svc.LeaveRoom(ctx, 8, 1); err != nil {
+		t.Fatalf("LeaveRoom: %v", err)
+	}
+	if _, err := svc.GetRoomInfo(ctx, 8, 1); err != ErrNotRoomMember {
+		t.Errorf("left user err = %v, want ErrNotRoomMember", err)
+	}
+	if _, err := svc.GetMessages(ctx, 8, 1, 0, 0, 20); err != ErrNotRoomMember {
+		t.Errorf("left user GetMessages err = %v, want ErrNotRoomMember", err)
+	}
+	if _, err := svc.GetRoomInfo(ctx, 8, 99); err != ErrChatRoomNotFound {
+		t.Errorf("unknown room err = %v, want ErrChatRoomNotFound", err)
+	}
+}
+
+func TestCreatePrivateRoomReusesPairRoom(t *testing.T) {
+	alice := &model.User{Nickname: "alice"}
+	alice.ID = 7
+	bob := &model.User{Nickname: "bob"}
+	bob.ID = 8
+	chatRepo := newFakeChatRepo()
+	svc := NewChatService(chatRepo, newFakeTopicRepo(), newFakeUserRepo(alice, bob), nil, &fakeStorage{},
+		config.UploadConfig{}, config.ChatConfig{})
+	ctx := context.Background()
+
+	first, err := svc.CreatePrivateRoom(ctx, 7, 8)
+	if err != nil {
+		t.Fatalf("CreatePrivateRoom: %v", err)
+	}
+	second, err := svc.CreatePrivateRoom(ctx, 8, 7)
+	if err != nil {
+		t.Fatalf("CreatePrivateRoom reversed: %v", err)
+	}
+	if second.ID != first.ID || len(chatRepo.rooms) != 1 {
+		t.Errorf("rooms = %d, second = %d, want the same room %d", len(chatRepo.rooms), second.ID, first.ID)
+	}
+}
+
+func TestCreatePrivateRoomConflictReturnsExisting(t *testing.T) {
+	alice := &model.User{Nickname: "alice"}
+	alice.ID = 7
+	bob := &model.User{Nickname: "bob"}
+	bob.ID = 8
+	chatRepo := newFakeChatRepo()
+	pairKey := model.PrivatePairKey(7, 8)
+	racing := &model.ChatRoom{Type: "individual", PairKey: &pairKey}
+	racing.ID = 42
+	chatRepo.racingRoom = racing
+	svc := NewChatService(chatRepo, newFakeTopicRepo(), newFakeUserRepo(alice, bob), nil, &fakeStorage{},
+		config.UploadConfig{}, config.ChatConfig{})
+
+	room, err := svc.CreatePrivateRoom(context.Background(), 8, 7)
+	if err != nil {
+		t.Fatalf("CreatePrivateRoom: %v", err)
+	}
+	if room.ID != 42 {
+		t.Errorf("room = %d, want the concurrently created room 42", room.ID)
+	}
+}
+
+// visibleContents 返回用户在聊天室中看到的消息内容，墓碑显示为 "<deleted>"
+func visibleContents(t *testing.T, svc *ChatService, userID, roomID uint64) []string {
+	t.Helper()
+	messages, err := svc.GetMessages(context.Background(), userID, roomID, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages(%d): %v", userID, err)
+	}
+	contents := make([]string, len(messages))
+	for i, msg := range messages {
+		contents[i] = msg.Content
+		if msg.IsDeleted() {
+			contents[i] = "<deleted>"
+		}
+	}
+	return contents
+}
+
+func TestDeleteMessageForMeAndForEveryone(t *testing.T) {
+	chatRepo := newFakeChatRepo()
+	chatRepo.addRoom(1, "group", member(7, "owner"), member(8, "member"))
+	for i, content := range []string{"hi", "secret", "oops"} {
+		msg := &model.Message{ChatRoomID: 1, SenderID: 7, ContentType: "text", Content: content}
+		msg.ID = uint64(i + 1)
+		chatRepo.messages = append(chatRepo.messages, msg)
+	}
+	svc := newTestChatService(chatRepo)
+	ctx := context.Background()
+
+	// 仅对自己删除：只影响删除者
+	if err := svc.DeleteMessage(ctx, 8, 1, 2, DeleteScopeMe); err != nil {
+		t.Fatalf("delete for me: %v", err)
+	}
+	// 对所有人删除：双方都看到墓碑
+	if err := svc.DeleteMessage(ctx, 7, 1, 3, DeleteScopeEveryone); err != nil {
+		t.Fatalf("delete for everyone: %v", err)
+	}
+	// 只有发送者可以对所有人删除
+	if err := svc.DeleteMessage(ctx, 8, 1, 1, DeleteScopeEveryone); err != ErrForbidden {
+		t.Fatalf("delete others' message for everyone err = %v, want ErrForbidden", err)
+	}
+
+	if got := visibleContents(t, svc, 7, 1); strings.Join(got, ",") != "hi,secret,<deleted>" {
+		t.Errorf("sender sees %v, want hi,secret,<deleted>", got)
+	}
+	if got := visibleContents(t, svc, 8, 1); strings.Join(got, ",") != "hi,<deleted>" {
+		t.Errorf("member sees %v, want hi,<deleted>", got)
+	}
+}
+
+func TestGetUnreadCountSkipsMessagesHiddenForMe(t *testing.T) {
+	chatRepo := newFakeChatRepo()
+	reader := member(8, "member")
+	reader.LastReadMessageID = 1
+	chatRepo.addRoom(1, "group", member(7, "owner"), reader)
+	for i := uint64(1); i <= 3; i++ {
+		msg := &model.Message{ChatRoomID: 1, SenderID: 7, ContentType: "text", Content: "hi"}
+		msg.ID = i
+		chatRepo.messages = append(chatRepo.messages, msg)
+	}
+	svc := newTestChatService(chatRepo)
+	ctx := context.Background()
+
+	if err := svc.DeleteMessage(ctx, 8, 1, 3, DeleteScopeMe); err != nil {
+		t.Fatalf("delete for me: %v", err)
+	}
+	// 最新的可见消息是 2，隐藏的消息 3 不计入未读
+	if unread, err := svc.GetUnreadCount(ctx, 8, 1); err != nil || unread != 1 {
+		t.Errorf("reader unread = %d, %v; want 1", unread, err)
+	}
+	if unread, err := svc.GetUnreadCount(ctx, 7, 1); err != nil || unread != 3 {
+		t.Errorf("sender unread = %d, %v; want 3", unread, err)
+	}
+}
+
+func TestPinRoomUpToCap(t *testing.T) {
+	chatRepo := newFakeChatRepo()
+	for roomID := uint64(1); roomID <= 3; roomID++ {
+		chatRepo.addRoom(roomID, "group", member(7, "member"))
+	}
+	svc := NewChatService(chatRepo, newFakeTopicRepo(), newFakeUserRepo(), nil, &fakeStorage{},
+		config.UploadConfig{}, config.ChatConfig{MaxPinnedRooms: 2})
+	ctx := context.Background()
+
+	for roomID := uint64(1); roomID <= 2; roomID++ {
+		if err := svc.PinRoom(ctx, 7, roomID); err != nil {
+			t.Fatalf("pin room %d: %v", roomID, err)
+		}
+	}
+	if err := svc.PinRoom(ctx, 7, 3); err != ErrPinLimitExceeded {
+		t.Fatalf("pin beyond cap err = %v, want ErrPinLimitExceeded", err)
+	}
+	// 已置顶的房间重复置顶不占用额外名额
+	if err := svc.PinRoom(ctx, 7, 1); err != nil {
+		t.Fatalf("re-pin at cap: %v", err)
+	}
+
+	// 取消置顶不受限制，并释放名额
+	if err := svc.UnpinRoom(ctx, 7, 1); err != nil {
+		t.Fatalf("unpin: %v", err)
+	}
+	if err := svc.PinRoom(ctx, 7, 3); err != nil {
+		t.Fatalf("pin after unpin: %v", err)
+	}
+	if pinned := chatRepo.pins[7]; len(pinned) != 2 {
+		t.Errorf("pinned = %v, want 2 rooms", pinned)
+	}
+}
+
+func newShareFixture() (*fakeChatRepo, *fakeTopicRepo, *ChatService) {
+	topic := &model.Topic{
+		UserID:  1,
+		Title:   "周末爬山",
+		Content: strings.Repeat("山", 150),
+		Status:  model.TopicStatusActive,
+		User:    model.User{Nickname: "author"},
+	}
+	topic.ID = 100
+	topicRepo := newFakeTopicRepo(topic)
+	chatRepo := newFakeChatRepo()
+	chatRepo.topicRepo = topicRepo
+	chatRepo.addRoom(1, "group", member(7, "owner"), member(8, "member"))
+	svc := NewChatService(chatRepo, topicRepo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.UploadConfig{}, config.ChatConfig{})
+	return chatRepo, topicRepo, svc
+}
+
+func TestShareTopicCountsRepeatSharesOnce(t *testing.T) {
+	resetCache(t)
+	chatRepo, _, svc := newShareFixture()
+	ctx := context.Background()
+
+	shares := []struct {
+		userID uint64
+		want   uint
+	}{
+		{7, 1},
+		{7, 1}, // 同一用户再次分享仍然成功，但不重复计数
+		{8, 2},
+	}
+	for i, share := range shares {
+		msg, err := svc.ShareTopic(ctx, share.userID, 1, 100)
+		if err != nil {
+			t.Fatalf("share %d: %v", i, err)
+		}
+		if msg.SharedTopic == nil {
+			t.Fatalf("share %d returned no preview", i)
+		}
+		if msg.SharedTopic.SharesCount != share.want {
+			t.Errorf("share %d shares_count = %d, want %d", i, msg.SharedTopic.SharesCount, share.want)
+		}
+	}
+	if len(chatRepo.messages) != len(shares) {
+		t.Errorf("messages = %d, want %d", len(chatRepo.messages), len(shares))
+	}
+}
+
+func TestGetMessagesRendersSharedTopicPreview(t *testing.T) {
+	resetCache(t)
+	chatRepo, _, svc := newShareFixture()
+	ctx := context.Background()
+
+	if _, err := svc.ShareTopic(ctx, 7, 1, 100); err != nil {
+		t.Fatalf("share: %v", err)
+	}
+	// 话题已被删除的分享消息不渲染预览
+	gone := &model.Message{ChatRoomID: 1, SenderID: 7, ContentType: model.MessageContentTopic, Content: "404"}
+	if err := chatRepo.CreateMessage(ctx, gone); err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+
+	messages, err := svc.GetMessages(ctx, 8, 1, 0, 0, 20)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	previews := map[string]*model.SharedTopic{}
+	for _, m := range messages {
+		previews[m.Content] = m.SharedTopic
+	}
+
+	preview := previews["100"]
+	if preview == nil {
+		t.Fatalf("shared topic message has no preview: %+v", messages)
+	}
+	if preview.Title != "周末爬山" || preview.AuthorNickname != "author" || preview.SharesCount != 1 {
+		t.Errorf("preview = %+v", preview)
+	}
+	if n := len([]rune(preview.Summary)); n != 100 {
+		t.Errorf("summary length = %d, want 100", n)
+	}
+	if previews["404"] != nil {
+		t.Errorf("deleted topic rendered preview: %+v", previews["404"])
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -27,6 +28,15 @@ func NewError(code int, message string) *Error {
 	}
 }
 
+// failureCode 返回可对外展示的固定错误码和信息，内部错误细节只记录日志
+func failureCode(err error) (int, string) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code, e.Message
+	}
+	return ErrOperationFailed.Code, ErrOperationFailed.Message
+}
+
 // WithDetails 添加错误详情
 func (e *Error) WithDetails(details interface{}) *Error {
 	e.Details = details
@@ -57,6 +67,8 @@ const (
 	CodeInvalidUserStatus   = 20004
 	CodeInvalidPrivacyLevel = 20005
 	CodeBlockedUser         = 20006
+	CodeNicknameTaken       = 20007
+	CodeUserBanned          = 20008
 
 	// 关系相关错误码 (3xxxx)
 	CodeSelfRelation        = 30001
@@ -69,12 +81,18 @@ const (
 	CodeInvalidTopicStatus = 40002
 	CodeTopicExpired       = 40003
 	CodeInvalidInteraction = 40004
+	CodeTopicPinLimit      = 40005
+	CodeRestoreExpired     = 40006
+	CodeDuplicateTopic     = 40007
 
 	// 聊天相关错误码 (5xxxx)
 	CodeChatRoomNotFound   = 50001
 	CodeNotRoomMember      = 50002
 	CodeInvalidMessageType = 50003
 	CodeMessageNotFound    = 50004
+	CodeLinkBlocked        = 50005
+	CodeLinkPreviewFailed  = 50006
+	CodePinLimitExceeded   = 50007
 
 	// 标签相关错误码 (6xxxx)
 	CodeTagNotFound    = 60001
@@ -91,11 +109,18 @@ const (
 	CodeFileTypeNotSupported    = 70006
 	CodeFileTooLarge            = 70007
 	CodeImageDimensionsTooLarge = 70008
+	CodeUploadExpired           = 70009
+	CodeInvalidAspectRatio      = 70010
+	CodeTooManyImages           = 70011
 
 	// 位置相关错误码 (8xxxx)
 	CodeInvalidLocation  = 80001
 	CodeLocationDisabled = 80002
 	CodeLocationNotFound = 80003
+
+	// 举报相关错误码 (9xxxx)
+	CodeInvalidReportReason  = 90001
+	CodeReportDetailRequired = 90002
 )
 
 // 预定义错误
@@ -125,8 +150,12 @@ var (
 				WithStatus(http.StatusBadRequest)
 	ErrInvalidPrivacyLevel = NewError(CodeInvalidPrivacyLevel, "invalid privacy level").
 				WithStatus(http.StatusBadRequest)
+	ErrNicknameTaken = NewError(CodeNicknameTaken, "nickname already in use").
+				WithStatus(http.StatusConflict)
 	ErrBlockedUser = NewError(CodeBlockedUser, "user is blocked").
 			WithStatus(http.StatusForbidden)
+	ErrUserBanned = NewError(CodeUserBanned, "user is banned").
+			WithStatus(http.StatusForbidden)
 
 	// 关系相关错误
 	ErrSelfRelation = NewError(CodeSelfRelation, "cannot follow/block yourself").
@@ -147,6 +176,12 @@ var (
 			WithStatus(http.StatusBadRequest)
 	ErrInvalidInteraction = NewError(CodeInvalidInteraction, "invalid interaction type").
 				WithStatus(http.StatusBadRequest)
+	ErrTopicPinLimitExceeded = NewError(CodeTopicPinLimit, "pinned topic limit exceeded").
+					WithStatus(http.StatusBadRequest)
+	ErrRestoreExpired = NewError(CodeRestoreExpired, "topic can no longer be restored").
+				WithStatus(http.StatusBadRequest)
+	ErrDuplicateTopic = NewError(CodeDuplicateTopic, "similar topic posted recently").
+				WithStatus(http.StatusConflict)
 
 	// 聊天相关错误
 	ErrChatRoomNotFound = NewError(CodeChatRoomNotFound, "chat room not found").
@@ -157,6 +192,12 @@ var (
 				WithStatus(http.StatusBadRequest)
 	ErrMessageNotFound = NewError(CodeMessageNotFound, "message not found").
 				WithStatus(http.StatusNotFound)
+	ErrLinkBlocked = NewError(CodeLinkBlocked, "link points to a blocked address").
+			WithStatus(http.StatusBadRequest)
+	ErrLinkPreviewFailed = NewError(CodeLinkPreviewFailed, "failed to fetch link preview").
+				WithStatus(http.StatusBadGateway)
+	ErrPinLimitExceeded = NewError(CodePinLimitExceeded, "pinned chat room limit exceeded").
+				WithStatus(http.StatusBadRequest)
 
 	// 标签相关错误
 	ErrTagNotFound = NewError(CodeTagNotFound, "tag not found").
@@ -185,6 +226,12 @@ var (
 			WithStatus(http.StatusBadRequest)
 	ErrImageDimensionsTooLarge = NewError(CodeImageDimensionsTooLarge, "image dimensions too large").
 					WithStatus(http.StatusBadRequest)
+	ErrUploadExpired = NewError(CodeUploadExpired, "upload handle not found or expired").
+				WithStatus(http.StatusGone)
+	ErrInvalidAspectRatio = NewError(CodeInvalidAspectRatio, "image aspect ratio not allowed").
+				WithStatus(http.StatusBadRequest)
+	ErrTooManyImages = NewError(CodeTooManyImages, "too many images").
+				WithStatus(http.StatusBadRequest)
 
 	// 位置相关错误
 	ErrInvalidLocation = NewError(CodeInvalidLocation, "invalid location coordinates").
@@ -194,6 +241,12 @@ var (
 	ErrLocationNotFound = NewError(CodeLocationNotFound, "location not found").
 				WithStatus(http.StatusNotFound)
 
+	// 举报相关错误
+	ErrInvalidReportReason = NewError(CodeInvalidReportReason, "invalid report reason").
+				WithStatus(http.StatusBadRequest)
+	ErrReportDetailRequired = NewError(CodeReportDetailRequired, "report detail is required for this reason").
+				WithStatus(http.StatusBadRequest)
+
 	// 业务相关错误
 	ErrInvalidStatus = NewError(CodeInvalidOperation, "invalid status").
 				WithStatus(http.StatusBadRequest)
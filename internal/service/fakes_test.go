@@ -0,0 +1,932 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+)
+
+// fakeUserRepo 内存用户仓储，只实现测试用到的方法
+type fakeUserRepo struct {
+	repository.UserRepository
+	users    map[uint64]*model.User
+	firebase map[string]uint64 // Firebase UID -> 用户ID
+
+	firebaseLookups int // GetByFirebaseUID 调用次数
+	idsQueries      int // GetByIDs 调用次数
+	bans            []*model.UserBan
+	devices         []*model.UserDevice
+}
+
+func newFakeUserRepo(users ...*model.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: map[uint64]*model.User{}, firebase: map[string]uint64{}}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uint64) (*model.User, error) {
+	if u, ok := r.users[id]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetByIDs(ctx context.Context, ids []uint64) ([]*model.User, error) {
+	r.idsQueries++
+	var users []*model.User
+	for _, id := range ids {
+		if u, ok := r.users[id]; ok {
+			copied := *u
+			users = append(users, &copied)
+		}
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *model.User) error {
+	user.ID = uint64(len(r.users) + 1000)
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *model.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) NicknameExists(ctx context.Context, nickname string, excludeID uint64) (bool, error) {
+	for id, u := range r.users {
+		if id != excludeID && u.Nickname == nickname {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeUserRepo) CreateAuthentication(ctx context.Context, auth *model.UserAuthentication) error {
+	r.firebase[auth.FirebaseUID] = auth.UserID
+	return nil
+}
+
+func (r *fakeUserRepo) UpdateAuthentication(ctx context.Context, auth *model.UserAuthentication) error {
+	return nil
+}
+
+func (r *fakeUserRepo) GetByFirebaseUID(ctx context.Context, firebaseUID string) (*model.User, error) {
+	r.firebaseLookups++
+	if id, ok := r.firebase[firebaseUID]; ok {
+		return r.GetByID(ctx, id)
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetAuthentication(ctx context.Context, userID uint64) (*model.UserAuthentication, error) {
+	for uid, id := range r.firebase {
+		if id == userID {
+			return &model.UserAuthentication{UserID: userID, FirebaseUID: uid}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) ListActiveDevices(ctx context.Context, userID uint64) ([]*model.UserDevice, error) {
+	var result []*model.UserDevice
+	for _, d := range r.devices {
+		if d.UserID == userID && d.IsActive {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeUserRepo) DeactivateDevice(ctx context.Context, userID, deviceID uint64) (bool, error) {
+	for _, d := range r.devices {
+		if d.ID == deviceID && d.UserID == userID && d.IsActive {
+			d.IsActive = false
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeUserRepo) DeactivateAccount(ctx context.Context, userID uint64) error {
+	for uid, id := range r.firebase {
+		if id == userID {
+			delete(r.firebase, uid)
+		}
+	}
+	if u, ok := r.users[userID]; ok {
+		u.Status = model.UserStatusInactive
+	}
+	return nil
+}
+
+// GetActiveBan 与 MySQL 实现的过滤条件保持一致
+func (r *fakeUserRepo) GetActiveBan(ctx context.Context, userID uint64, now time.Time) (*model.UserBan, error) {
+	var latest *model.UserBan
+	for _, ban := range r.bans {
+		if ban.UserID != userID || ban.Status != "active" || ban.BanEnd == nil {
+			continue
+		}
+		if ban.BanStart.After(now) || !ban.BanEnd.After(now) {
+			continue
+		}
+		if latest == nil || ban.BanEnd.After(*latest.BanEnd) {
+			latest = ban
+		}
+	}
+	return latest, nil
+}
+
+func (r *fakeUserRepo) CreateBan(ctx context.Context, ban *model.UserBan) error {
+	r.bans = append(r.bans, ban)
+	if u, ok := r.users[ban.UserID]; ok && ban.BanEnd == nil {
+		u.Status = model.UserStatusBanned
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) CancelBans(ctx context.Context, userID uint64) error {
+	for _, ban := range r.bans {
+		if ban.UserID == userID && ban.Status == "active" {
+			ban.Status = "cancelled"
+		}
+	}
+	if u, ok := r.users[userID]; ok && u.Status == model.UserStatusBanned {
+		u.Status = model.UserStatusActive
+	}
+	return nil
+}
+
+// fakeTopicRepo 内存话题仓储，只实现测试用到的方法
+type fakeTopicRepo struct {
+	repository.TopicRepository
+	mu     sync.Mutex
+	topics map[uint64]*model.Topic
+
+	dailyStats         []*model.TopicDailyStat
+	participants       []*model.User   // 关联群聊的成员
+	roomViewers        map[uint64]bool // 关联群聊中的用户
+	interactors        []*model.User
+	participantQueries int
+	likes              []*model.TopicInteraction
+	interactions       []*model.TopicInteraction // 各类型互动，供 ListInteractedByUser 使用
+	audits             []*model.AuditLog
+
+	closedBefore time.Time                   // 最近一次 ListClosedByUser 的截止时间
+	listOpts     repository.TopicListOptions // 最近一次列表查询的选项
+	hardDeleted  []uint64
+	pinned       []uint64 // 主页置顶的话题ID，按置顶时间倒序
+}
+
+func newFakeTopicRepo(topics ...*model.Topic) *fakeTopicRepo {
+	r := &fakeTopicRepo{topics: map[uint64]*model.Topic{}}
+	for _, t := range topics {
+		r.topics[t.ID] = t
+	}
+	return r
+}
+
+func (r *fakeTopicRepo) GetByID(ctx context.Context, id uint64) (*model.Topic, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.topics[id]; ok {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+// Delete 与 MySQL 实现一致，只关闭有效的话题
+func (r *fakeTopicRepo) Delete(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.topics[id]; ok && t.Status == model.TopicStatusActive {
+		t.Status = model.TopicStatusClosed
+		t.ClosedAt = timePtr(time.Now())
+	}
+	return nil
+}
+
+// ListClosedByUser 与 MySQL 实现的过滤条件保持一致
+func (r *fakeTopicRepo) ListClosedByUser(ctx context.Context, userID uint64, closedBefore time.Time) ([]*model.Topic, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closedBefore = closedBefore
+	var result []*model.Topic
+	for _, t := range r.topics {
+		if t.UserID != userID {
+			continue
+		}
+		if t.Status == model.TopicStatusClosed && t.ClosedAt != nil && t.ClosedAt.Before(closedBefore) {
+			copied := *t
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+// ListProfilePinned 与 MySQL 实现一致，只返回仍属于该用户的有效话题
+func (r *fakeTopicRepo) ListProfilePinned(ctx context.Context, userID uint64) ([]*model.Topic, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*model.Topic
+	for _, id := range r.pinned {
+		if t, ok := r.topics[id]; ok && t.UserID == userID && t.Status == model.TopicStatusActive {
+			copied := *t
+			copied.TopicImages = append([]model.TopicImage(nil), t.TopicImages...)
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeTopicRepo) PinToProfile(ctx context.Context, userID, topicID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pinned := []uint64{topicID}
+	for _, id := range r.pinned {
+		if id != topicID {
+			pinned = append(pinned, id)
+		}
+	}
+	r.pinned = pinned
+	return nil
+}
+
+func (r *fakeTopicRepo) TransferOwner(ctx context.Context, topicID, newOwnerID uint64, audit *model.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.topics[topicID]; ok {
+		t.UserID = newOwnerID
+	}
+	r.audits = append(r.audits, audit)
+	return nil
+}
+
+// ListLikers 按ID倒序返回有效点赞，拉黑过滤由 SQL 完成，这里不模拟
+func (r *fakeTopicRepo) ListLikers(ctx context.Context, topicID, viewerID, beforeID uint64, limit int) ([]*model.TopicInteraction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*model.TopicInteraction
+	for _, like := range r.likes {
+		if like.TopicID != topicID || like.InteractionStatus != model.InteractionStatusActive {
+			continue
+		}
+		if beforeID > 0 && like.ID >= beforeID {
+			continue
+		}
+		result = append(result, like)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// ListInteractedByUser 与 MySQL 实现的过滤条件保持一致，按互动ID倒序返回
+func (r *fakeTopicRepo) ListInteractedByUser(ctx context.Context, userID uint64, interactionType string, beforeID uint64, limit int) ([]*model.TopicInteraction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*model.TopicInteraction
+	for _, in := range r.interactions {
+		if in.UserID != userID || in.InteractionType != interactionType || in.InteractionStatus != model.InteractionStatusActive {
+			continue
+		}
+		if beforeID > 0 && in.ID >= beforeID {
+			continue
+		}
+		topic, ok := r.topics[in.TopicID]
+		if !ok || topic.Status != model.TopicStatusActive || topic.IsExpired(time.Now()) {
+			continue
+		}
+		copied := *in
+		copied.Topic = *topic
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *fakeTopicRepo) Create(ctx context.Context, topic *model.Topic) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	topic.ID = uint64(len(r.topics) + 1000)
+	topic.CreatedAt = time.Now()
+	copied := *topic
+	r.topics[topic.ID] = &copied
+	return nil
+}
+
+// ListRecentByUser 与 MySQL 实现一致，只返回仍有效的话题
+func (r *fakeTopicRepo) ListRecentByUser(ctx context.Context, userID uint64, since time.Time, limit int) ([]*model.Topic, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*model.Topic
+	for _, t := range r.topics {
+		if t.UserID == userID && t.Status == model.TopicStatusActive && !t.CreatedAt.Before(since) {
+			copied := *t
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *fakeTopicRepo) List(ctx context.Context, opts repository.TopicListOptions, offset, limit int) ([]*model.Topic, int64, error) {
+	r.listOpts = opts
+	return nil, 0, nil
+}
+
+func (r *fakeTopicRepo) ListByUser(ctx context.Context, userID uint64, opts repository.TopicListOptions, offset, limit int) ([]*model.Topic, int64, error) {
+	r.listOpts = opts
+	return nil, 0, nil
+}
+
+func (r *fakeTopicRepo) GetNearbyTopics(ctx context.Context, lat, lng, radius float64, opts repository.TopicListOptions, offset, limit int) ([]*model.Topic, int64, error) {
+	r.listOpts = opts
+	return nil, 0, nil
+}
+
+func (r *fakeTopicRepo) GetImages(ctx context.Context, topicID uint64) ([]*model.TopicImage, error) {
+	return nil, nil
+}
+
+func (r *fakeTopicRepo) HardDelete(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.topics, id)
+	r.hardDeleted = append(r.hardDeleted, id)
+	return nil
+}
+
+// fakeStorage 记录删除请求的存储实现
+type fakeStorage struct {
+	mu      sync.Mutex
+	deleted []string
+	failing map[string]bool // 上传失败的文件名
+	private string          // 私有目录，其中的文件返回签名地址
+}
+
+func (s *fakeStorage) UploadFile(ctx context.Context, file *multipart.FileHeader, directory string) (string, error) {
+	if s.failing[file.Filename] {
+		return "", errors.New("googleapi: Error 503: backend unavailable, bucket internal-media")
+	}
+	return directory + "/" + file.Filename, nil
+}
+
+func (s *fakeStorage) UploadBytes(ctx context.Context, data []byte, originalName, directory string) (string, error) {
+	return directory + "/" + originalName, nil
+}
+
+func (s *fakeStorage) DeleteFile(ctx context.Context, fileURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = append(s.deleted, fileURL)
+	return nil
+}
+
+func (s *fakeStorage) AccessURL(ctx context.Context, fileURL string) (string, error) {
+	if s.private != "" && strings.HasPrefix(fileURL, s.private+"/") {
+		return fileURL + "?signature=test", nil
+	}
+	return fileURL, nil
+}
+
+// fakeChatRepo 内存聊天仓储，只实现测试用到的方法
+type fakeChatRepo struct {
+	repository.ChatRepository
+	mu      sync.Mutex
+	rooms   map[uint64]*model.ChatRoom
+	members map[uint64][]*model.ChatRoomMember
+	failing map[uint64]error // 指定聊天室读取成员时返回的错误
+
+	left map[uint64]uint64 // 退出的聊天室 -> 新群主
+
+	messages      []*model.Message
+	createMsgFail error
+	hidden        map[[2]uint64]bool  // {用户, 消息} 仅对自己删除的消息
+	pins          map[uint64][]uint64 // 用户 -> 置顶的聊天室
+
+	// racingRoom 不为空时模拟并发请求抢先创建了同一对用户的私聊
+	racingRoom *model.ChatRoom
+
+	privateRoomQueries int
+
+	shares    map[[2]uint64]bool // {话题, 用户} 分享互动
+	topicRepo *fakeTopicRepo     // 分享时同步更新其中话题的分享数
+}
+
+func newFakeChatRepo() *fakeChatRepo {
+	return &fakeChatRepo{
+		rooms:   map[uint64]*model.ChatRoom{},
+		members: map[uint64][]*model.ChatRoomMember{},
+		failing: map[uint64]error{},
+		left:    map[uint64]uint64{},
+		hidden:  map[[2]uint64]bool{},
+		pins:    map[uint64][]uint64{},
+	}
+}
+
+// addRoom 添加聊天室，members 按加入顺序排列
+func (r *fakeChatRepo) addRoom(id uint64, roomType string, members ...*model.ChatRoomMember) {
+	room := &model.ChatRoom{Type: roomType}
+	room.ID = id
+	r.rooms[id] = room
+	for _, m := range members {
+		m.ChatRoomID = id
+	}
+	r.members[id] = members
+}
+
+func (r *fakeChatRepo) GetRoomByID(ctx context.Context, id uint64) (*model.ChatRoom, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if room, ok := r.rooms[id]; ok {
+		copied := *room
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeChatRepo) GetRoomMembers(ctx context.Context, roomID uint64) ([]*model.ChatRoomMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.failing[roomID]; err != nil {
+		return nil, err
+	}
+	return append([]*model.ChatRoomMember(nil), r.members[roomID]...), nil
+}
+
+func (r *fakeChatRepo) ListUserRoomsByType(ctx context.Context, userID uint64, roomType string) ([]*model.ChatRoom, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rooms []*model.ChatRoom
+	for id, room := range r.rooms {
+		if room.Type != roomType {
+			continue
+		}
+		for _, m := range r.members[id] {
+			if m.UserID == userID {
+				rooms = append(rooms, room)
+				break
+			}
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
+	return rooms, nil
+}
+
+func (r *fakeChatRepo) LeaveRoom(ctx context.Context, roomID, userID, newOwnerID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.left[roomID] = newOwnerID
+	// 与仓储一致，退出时删除成员记录
+	var remaining []*model.ChatRoomMember
+	for _, m := range r.members[roomID] {
+		if m.UserID != userID {
+			remaining = append(remaining, m)
+		}
+	}
+	r.members[roomID] = remaining
+	return nil
+}
+
+func (r *fakeChatRepo) CreateRoom(ctx context.Context, room *model.ChatRoom) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	room.ID = uint64(len(r.rooms) + 1)
+	copied := *room
+	r.rooms[room.ID] = &copied
+	return nil
+}
+
+func (r *fakeChatRepo) AddMember(ctx context.Context, member *model.ChatRoomMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.members[member.ChatRoomID] = append(r.members[member.ChatRoomID], member)
+	return nil
+}
+
+// fakeRelationRepo 内存关注关系仓储，键为 {关注者, 被关注者}
+type fakeRelationRepo struct {
+	repository.RelationshipRepository
+	mu        sync.Mutex
+	relations map[[2]uint64]*model.UserRelationship
+
+	betweenQueries int
+}
+
+func newFakeRelationRepo() *fakeRelationRepo {
+	return &fakeRelationRepo{relations: map[[2]uint64]*model.UserRelationship{}}
+}
+
+// set 设置 followerID 对 followingID 的关系状态
+func (r *fakeRelationRepo) set(followerID, followingID uint64, status string) {
+	r.relations[[2]uint64{followerID, followingID}] = &model.UserRelationship{
+		FollowerID:  followerID,
+		FollowingID: followingID,
+		Status:      status,
+	}
+}
+
+func (r *fakeRelationRepo) GetRelationship(ctx context.Context, followerID, followingID uint64) (*model.UserRelationship, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rel, ok := r.relations[[2]uint64{followerID, followingID}]; ok {
+		copied := *rel
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeRelationRepo) ListBetween(ctx context.Context, userID uint64, targetIDs []uint64) ([]*model.UserRelationship, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.betweenQueries++
+	var relationships []*model.UserRelationship
+	for _, targetID := range targetIDs {
+		for _, key := range [][2]uint64{{userID, targetID}, {targetID, userID}} {
+			if rel, ok := r.relations[key]; ok {
+				copied := *rel
+				relationships = append(relationships, &copied)
+			}
+		}
+	}
+	return relationships, nil
+}
+
+func (r *fakeRelationRepo) Create(ctx context.Context, relationship *model.UserRelationship) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *relationship
+	r.relations[[2]uint64{relationship.FollowerID, relationship.FollowingID}] = &copied
+	return nil
+}
+
+func (r *fakeRelationRepo) Update(ctx context.Context, relationship *model.UserRelationship) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *relationship
+	r.relations[[2]uint64{relationship.FollowerID, relationship.FollowingID}] = &copied
+	return nil
+}
+
+func (r *fakeRelationRepo) DeletePending(ctx context.Context, followerID, followingID uint64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := [2]uint64{followerID, followingID}
+	if rel, ok := r.relations[key]; ok && rel.Status == "pending" {
+		delete(r.relations, key)
+		return true, nil
+	}
+	return false, nil
+}
+
+// RejectAndBlock 与 MySQL 实现一致，删除双方的所有关系后创建拉黑关系
+func (r *fakeRelationRepo) RejectAndBlock(ctx context.Context, userID, followerID uint64) error {
+	r.mu.Lock()
+	delete(r.relations, [2]uint64{followerID, userID})
+	delete(r.relations, [2]uint64{userID, followerID})
+	r.mu.Unlock()
+	r.set(userID, followerID, "blocked")
+	return nil
+}
+
+func (r *fakeChatRepo) CreateMessage(ctx context.Context, message *model.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.createMsgFail != nil {
+		return r.createMsgFail
+	}
+	message.ID = uint64(len(r.messages) + 1)
+	// 保存副本，和数据库一样不受调用方之后修改的影响
+	stored := *message
+	stored.MessageMedia = append([]model.MessageMedia(nil), message.MessageMedia...)
+	r.messages = append(r.messages, &stored)
+	return nil
+}
+
+// CreateTopicShareMessage 与 MySQL 实现一致，同一用户重复分享只计一次
+func (r *fakeChatRepo) CreateTopicShareMessage(ctx context.Context, message *model.Message, share *model.TopicInteraction) error {
+	if err := r.CreateMessage(ctx, message); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	if r.shares == nil {
+		r.shares = map[[2]uint64]bool{}
+	}
+	r.shares[[2]uint64{share.TopicID, share.UserID}] = true
+	var count uint
+	for key := range r.shares {
+		if key[0] == share.TopicID {
+			count++
+		}
+	}
+	r.mu.Unlock()
+
+	if r.topicRepo != nil {
+		r.topicRepo.mu.Lock()
+		if t, ok := r.topicRepo.topics[share.TopicID]; ok {
+			t.SharesCount = count
+		}
+		r.topicRepo.mu.Unlock()
+	}
+	return nil
+}
+
+func (r *fakeTopicRepo) ListByIDs(ctx context.Context, ids []uint64) ([]*model.Topic, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var topics []*model.Topic
+	for _, id := range ids {
+		if t, ok := r.topics[id]; ok {
+			copied := *t
+			topics = append(topics, &copied)
+		}
+	}
+	return topics, nil
+}
+
+func (r *fakeTopicRepo) ListRoomParticipants(ctx context.Context, topicID, viewerID uint64, limit int) ([]*model.User, error) {
+	r.participantQueries++
+	if !r.roomViewers[viewerID] {
+		return nil, nil
+	}
+	return r.participants, nil
+}
+
+func (r *fakeTopicRepo) ListRecentInteractors(ctx context.Context, topicID uint64, limit int) ([]*model.User, error) {
+	return r.interactors, nil
+}
+
+func (r *fakeTopicRepo) IncrementViewCount(ctx context.Context, topicID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.topics[topicID]; ok {
+		t.ViewsCount++
+	}
+	return nil
+}
+
+func (r *fakeChatRepo) GetMessageByID(ctx context.Context, id uint64) (*model.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range r.messages {
+		if msg.ID == id {
+			copied := *msg
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetMessagesByRoom 忽略分页，返回 viewerID 可见的全部消息
+func (r *fakeChatRepo) GetMessagesByRoom(ctx context.Context, roomID, viewerID, beforeID uint64, limit int) ([]*model.Message, error) {
+	return r.GetLatestMessages(ctx, roomID, viewerID, limit)
+}
+
+func (r *fakeChatRepo) GetLatestMessages(ctx context.Context, roomID, viewerID uint64, limit int) ([]*model.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var messages []*model.Message
+	for _, msg := range r.messages {
+		if msg.ChatRoomID == roomID && !r.hidden[[2]uint64{viewerID, msg.ID}] {
+			copied := *msg
+			messages = append(messages, &copied)
+		}
+	}
+	if len(messages) > limit {
+		messages = messages[len(messages)-limit:]
+	}
+	return messages, nil
+}
+
+func (r *fakeChatRepo) HideMessage(ctx context.Context, userID, messageID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hidden[[2]uint64{userID, messageID}] = true
+	return nil
+}
+
+func (r *fakeChatRepo) GetMessageMedia(ctx context.Context, messageID uint64) ([]*model.MessageMedia, error) {
+	return nil, nil
+}
+
+func (r *fakeChatRepo) DeleteMessageForEveryone(ctx context.Context, messageID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range r.messages {
+		if msg.ID == messageID && msg.DeletedAt == nil {
+			now := time.Now()
+			msg.Content = ""
+			msg.DeletedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeChatRepo) GetPinnedRooms(ctx context.Context, userID uint64) ([]*model.ChatRoom, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rooms []*model.ChatRoom
+	for _, roomID := range r.pins[userID] {
+		rooms = append(rooms, r.rooms[roomID])
+	}
+	return rooms, nil
+}
+
+func (r *fakeChatRepo) PinRoom(ctx context.Context, userID, roomID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range r.pins[userID] {
+		if id == roomID {
+			return nil
+		}
+	}
+	r.pins[userID] = append(r.pins[userID], roomID)
+	return nil
+}
+
+func (r *fakeChatRepo) UnpinRoom(ctx context.Context, userID, roomID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pins := r.pins[userID][:0]
+	for _, id := range r.pins[userID] {
+		if id != roomID {
+			pins = append(pins, id)
+		}
+	}
+	r.pins[userID] = pins
+	return nil
+}
+
+func (r *fakeChatRepo) GetPrivateRoom(ctx context.Context, pairKey string) (*model.ChatRoom, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, room := range r.rooms {
+		if room.PairKey != nil && *room.PairKey == pairKey {
+			return room, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeChatRepo) ListPrivateRooms(ctx context.Context, pairKeys []string) ([]*model.ChatRoom, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.privateRoomQueries++
+	var rooms []*model.ChatRoom
+	for _, key := range pairKeys {
+		for _, room := range r.rooms {
+			if room.PairKey != nil && *room.PairKey == key {
+				rooms = append(rooms, room)
+			}
+		}
+	}
+	return rooms, nil
+}
+
+func (r *fakeChatRepo) CreateRoomWithMembers(ctx context.Context, room *model.ChatRoom, members []*model.ChatRoomMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.racingRoom != nil {
+		r.rooms[r.racingRoom.ID] = r.racingRoom
+		r.racingRoom = nil
+	}
+	for _, existing := range r.rooms {
+		if room.PairKey != nil && existing.PairKey != nil && *existing.PairKey == *room.PairKey {
+			return errors.New("Error 1062 (23000): Duplicate entry for key 'uk_pair_key'")
+		}
+	}
+	room.ID = uint64(len(r.rooms) + 1)
+	r.rooms[room.ID] = room
+	for _, m := range members {
+		m.ChatRoomID = room.ID
+		r.members[room.ID] = append(r.members[room.ID], m)
+	}
+	return nil
+}
+
+func (r *fakeTopicRepo) GetDailyStats(ctx context.Context, topicID uint64, since time.Time) ([]*model.TopicDailyStat, error) {
+	var stats []*model.TopicDailyStat
+	for _, stat := range r.dailyStats {
+		if stat.TopicID == topicID && !stat.StatDate.Before(since) {
+			stats = append(stats, stat)
+		}
+	}
+	return stats, nil
+}
+
+// fakeAdminRepo 记录下架操作，下架时同步修改话题仓储中的状态
+// 统计查询返回 stats 中的对应值，并记录查询次数和统计起始时间
+type fakeAdminRepo struct {
+	repository.AdminRepository
+	topicRepo *fakeTopicRepo
+	audits    []*model.AuditLog
+
+	stats        DashboardStats
+	countQueries int
+	activeSince  time.Time
+	todaySince   time.Time
+}
+
+func (r *fakeAdminRepo) CountUsers(ctx context.Context) (int64, error) {
+	r.countQueries++
+	return r.stats.TotalUsers, nil
+}
+
+func (r *fakeAdminRepo) CountActiveUsers(ctx context.Context, since time.Time) (int64, error) {
+	r.countQueries++
+	r.activeSince = since
+	return r.stats.ActiveUsers, nil
+}
+
+func (r *fakeAdminRepo) CountTopicsSince(ctx context.Context, since time.Time) (int64, error) {
+	r.countQueries++
+	r.todaySince = since
+	return r.stats.TopicsToday, nil
+}
+
+func (r *fakeAdminRepo) CountMessagesSince(ctx context.Context, since time.Time) (int64, error) {
+	r.countQueries++
+	return r.stats.MessagesToday, nil
+}
+
+func (r *fakeAdminRepo) CountOpenReports(ctx context.Context) (int64, error) {
+	r.countQueries++
+	return r.stats.OpenReports, nil
+}
+
+func (r *fakeAdminRepo) RemoveTopic(ctx context.Context, topicID uint64, audit *model.AuditLog) error {
+	r.topicRepo.mu.Lock()
+	defer r.topicRepo.mu.Unlock()
+	if t, ok := r.topicRepo.topics[topicID]; ok {
+		t.Status = model.TopicStatusCancelled
+		t.ClosedAt = timePtr(time.Now())
+	}
+	r.audits = append(r.audits, audit)
+	return nil
+}
+
+// fakeReportRepo 记录提交的举报
+type fakeReportRepo struct {
+	reports []*model.Report
+}
+
+func (r *fakeReportRepo) Create(ctx context.Context, report *model.Report) error {
+	report.ID = uint64(len(r.reports) + 1)
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+// fakeActivityRepo 按 (发生时间, 类型, ID) 倒序分页，与 MySQL 实现的排序键一致
+type fakeActivityRepo struct {
+	activities []*model.Activity
+}
+
+// activityBefore 判断 a 是否排在 b 之前
+func activityBefore(a, b *model.Activity) bool {
+	if !a.OccurredAt.Equal(b.OccurredAt) {
+		return a.OccurredAt.After(b.OccurredAt)
+	}
+	if a.Type != b.Type {
+		return a.Type > b.Type
+	}
+	return a.ID > b.ID
+}
+
+func (r *fakeActivityRepo) ListByUser(ctx context.Context, userID uint64, cursor repository.ActivityCursor, limit int) ([]*model.Activity, error) {
+	last := &model.Activity{OccurredAt: cursor.OccurredAt, Type: cursor.Type, ID: cursor.ID}
+	var result []*model.Activity
+	for _, activity := range r.activities {
+		if cursor.OccurredAt.IsZero() || activityBefore(last, activity) {
+			copied := *activity
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return activityBefore(result[i], result[j]) })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/linkpreview"
+	"DistanceBack_v1/pkg/logger"
+)
+
+// LinkPreviewExpiration 链接预览缓存时间
+const LinkPreviewExpiration = 10 * time.Minute
+
+// GetLinkPreview 获取链接的 OpenGraph 预览信息，结果按 URL 短期缓存
+// 与消息存储无关，客户端在发送前即可调用
+func (s *ChatService) GetLinkPreview(ctx context.Context, rawURL string) (*linkpreview.Preview, error) {
+	target, err := linkpreview.Normalize(rawURL)
+	if err != nil {
+		return nil, ErrInvalidRequest
+	}
+
+	cacheKey := cache.LinkPreviewKey(target)
+	var cached linkpreview.Preview
+	if err := cache.Get(cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	preview, err := s.linkFetcher.Fetch(ctx, target)
+	if err != nil {
+		switch {
+		case errors.Is(err, linkpreview.ErrBlockedAddress):
+			return nil, ErrLinkBlocked
+		case errors.Is(err, linkpreview.ErrInvalidURL):
+			return nil, ErrInvalidRequest
+		}
+		logger.Warn("failed to fetch link preview",
+			logger.Any("error", err),
+			logger.String("url", target))
+		return nil, ErrLinkPreviewFailed
+	}
+
+	if err := cache.Set(cacheKey, preview, LinkPreviewExpiration); err != nil {
+		logger.Warn("failed to cache link preview", logger.Any("error", err))
+	}
+	return preview, nil
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"os"
+	"testing"
+
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+var testRedis *miniredis.Miniredis
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+
+	var err error
+	testRedis, err = miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	cache.RedisClient = redis.NewClient(&redis.Options{Addr: testRedis.Addr()})
+
+	code := m.Run()
+	testRedis.Close()
+	os.Exit(code)
+}
+
+// resetCache 清空测试用 Redis
+func resetCache(t *testing.T) {
+	t.Helper()
+	testRedis.FlushAll()
+}
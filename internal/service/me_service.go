@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/logger"
+)
+
+// MeOverviewExpiration 首页概览缓存时间
+const MeOverviewExpiration = 15 * time.Second
+
+// UserStats 用户统计数据
+type UserStats struct {
+	TopicsCount    int64 `json:"topics_count"`
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+}
+
+// MeOverview 当前用户首页概览
+type MeOverview struct {
+	Profile               *model.User     `json:"profile"`
+	Stats                 UserStats       `json:"stats"`
+	TotalUnread           int64           `json:"total_unread"`
+	PendingFollowRequests int64           `json:"pending_follow_requests"`
+	Features              map[string]bool `json:"features"`
+}
+
+type MeService struct {
+	userRepo     repository.UserRepository
+	topicRepo    repository.TopicRepository
+	chatRepo     repository.ChatRepository
+	relationRepo repository.RelationshipRepository
+	features     map[string]bool
+}
+
+// NewMeService 创建当前用户概览服务实例
+func NewMeService(
+	userRepo repository.UserRepository,
+	topicRepo repository.TopicRepository,
+	chatRepo repository.ChatRepository,
+	relationRepo repository.RelationshipRepository,
+	features map[string]bool,
+) *MeService {
+	if features == nil {
+		features = make(map[string]bool)
+	}
+	return &MeService{
+		userRepo:     userRepo,
+		topicRepo:    topicRepo,
+		chatRepo:     chatRepo,
+		relationRepo: relationRepo,
+		features:     features,
+	}
+}
+
+// GetOverview 获取当前用户的资料、统计、未读数及功能开关
+func (s *MeService) GetOverview(ctx context.Context, userID uint64) (*MeOverview, error) {
+	// 尝试从缓存获取
+	cacheKey := cache.MeOverviewKey(userID)
+	var cached MeOverview
+	if err := cache.Get(cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	overview := &MeOverview{
+		Profile:  user,
+		Features: s.features,
+	}
+
+	if overview.Stats.TopicsCount, err = s.topicRepo.CountByUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to count topics: %w", err)
+	}
+
+	counts, err := s.relationRepo.GetCounts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count relationships: %w", err)
+	}
+	overview.Stats.FollowersCount = counts.Followers
+	overview.Stats.FollowingCount = counts.Following
+	overview.PendingFollowRequests = counts.Pending
+
+	if overview.TotalUnread, err = s.chatRepo.GetTotalUnread(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
+	// 缓存概览数据
+	if err := cache.Set(cacheKey, overview, MeOverviewExpiration); err != nil {
+		logger.Warn("failed to cache me overview", logger.Any("error", err))
+	}
+
+	return overview, nil
+}
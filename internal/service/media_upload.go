@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"mime/multipart"
+	"net/http"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/pkg/logger"
+	"DistanceBack_v1/pkg/storage"
+)
+
+// 媒体文件上传失败处理策略
+const (
+	// MediaFailureStrict 任一文件上传失败则整个请求失败，已上传的文件会被删除
+	MediaFailureStrict = "strict"
+	// MediaFailurePartial 保留上传成功的文件，并在响应中返回失败的文件列表
+	MediaFailurePartial = "partial"
+)
+
+// uploadedMedia 上传成功的文件
+type uploadedMedia struct {
+	Index int
+	File  *model.File
+	URL   string
+}
+
+// uploadMediaFiles 以最多 concurrency 个并发上传全部媒体文件并按原顺序汇总结果
+// strict 策略下有文件失败时清理已上传的文件并返回错误，错误详情中包含失败列表
+func uploadMediaFiles(ctx context.Context, store storage.Storage, files []*model.File, directory, policy string, concurrency int) ([]uploadedMedia, []model.FileUploadFailure, error) {
+	uploaded := make([]uploadedMedia, 0, len(files))
+	var failures []model.FileUploadFailure
+
+	headers := make([]*multipart.FileHeader, len(files))
+	for i, file := range files {
+		headers[i] = file.File
+	}
+	results := storage.UploadFiles(ctx, store, headers, directory, concurrency)
+
+	for i, file := range files {
+		fileURL, err := results[i].URL, results[i].Err
+		if err != nil {
+			logger.Error("failed to upload media file",
+				logger.Any("error", err),
+				logger.String("directory", directory),
+				logger.Int("index", i))
+			// 内部错误只记录日志，对外返回固定错误码
+			failures = append(failures, model.FileUploadFailure{
+				Index:    i,
+				FileName: file.Name,
+				Code:     ErrUploadFailed.Code,
+				Reason:   ErrUploadFailed.Message,
+			})
+			continue
+		}
+		uploaded = append(uploaded, uploadedMedia{Index: i, File: file, URL: fileURL})
+	}
+
+	if len(failures) > 0 && policy != MediaFailurePartial {
+		deleteUploadedMedia(ctx, store, uploaded)
+		return nil, failures, newMediaUploadError(failures)
+	}
+
+	return uploaded, failures, nil
+}
+
+// deleteUploadedMedia 删除已上传的文件，用于请求失败时回滚
+func deleteUploadedMedia(ctx context.Context, store storage.Storage, uploaded []uploadedMedia) {
+	for _, m := range uploaded {
+		if err := store.DeleteFile(ctx, m.URL); err != nil {
+			logger.Warn("failed to delete uploaded media",
+				logger.Any("error", err),
+				logger.String("url", m.URL))
+		}
+	}
+}
+
+// accessURL 返回客户端可访问的文件地址，私有目录的文件为有时效的签名地址
+// 签名失败时返回空地址，不把无法访问的原地址返回给客户端
+func accessURL(ctx context.Context, store storage.Storage, fileURL string) string {
+	if fileURL == "" {
+		return ""
+	}
+	resolved, err := store.AccessURL(ctx, fileURL)
+	if err != nil {
+		logger.Warn("failed to resolve file access URL",
+			logger.Any("error", err),
+			logger.String("url", fileURL))
+		return ""
+	}
+	return resolved
+}
+
+// resolveMessageMedia 将消息中的媒体地址替换为客户端可访问的地址
+// 只用于返回给客户端的消息，数据库中保存的始终是原地址
+func resolveMessageMedia(ctx context.Context, store storage.Storage, messages ...*model.Message) {
+	for _, msg := range messages {
+		for i := range msg.MessageMedia {
+			msg.MessageMedia[i].MediaURL = accessURL(ctx, store, msg.MessageMedia[i].MediaURL)
+		}
+	}
+}
+
+// resolveTopicImages 将话题中的图片地址替换为客户端可访问的地址
+// 只用于返回给客户端的话题，不要把替换后的话题写回数据库或缓存
+func resolveTopicImages(ctx context.Context, store storage.Storage, topics ...*model.Topic) {
+	for _, topic := range topics {
+		for i := range topic.TopicImages {
+			topic.TopicImages[i].ImageURL = accessURL(ctx, store, topic.TopicImages[i].ImageURL)
+		}
+	}
+}
+
+// newMediaUploadError 创建带失败列表的上传错误
+func newMediaUploadError(failures []model.FileUploadFailure) *Error {
+	return NewError(CodeUploadFailed, "failed to upload media files").
+		WithStatus(http.StatusInternalServerError).
+		WithDetails(failures)
+}
@@ -7,6 +7,7 @@ import (
 
 	"DistanceBack_v1/internal/model"
 	"DistanceBack_v1/internal/repository"
+	"DistanceBack_v1/pkg/cache"
 	"DistanceBack_v1/pkg/logger"
 )
 
@@ -16,6 +17,26 @@ type RelationshipService struct {
 	chatService  *ChatService
 }
 
+// RelationshipCountsExpiration 关系计数缓存时间
+const RelationshipCountsExpiration = 30 * time.Second
+
+// FollowRequestResult 关注及处理关注请求的结果
+type FollowRequestResult struct {
+	Status   string  `json:"status"`            // 处理后的关系状态: pending, accepted, rejected
+	IsFriend bool    `json:"is_friend"`         // 是否已互相关注
+	RoomID   *uint64 `json:"room_id,omitempty"` // 成为好友时的私聊房间
+}
+
+// UserRelationStatus 当前用户与目标用户的关系及私聊状态
+type UserRelationStatus struct {
+	IsFollowing   bool    // 当前用户已关注对方
+	IsFollowed    bool    // 对方已关注当前用户
+	IsFriend      bool    // 互相关注
+	IsBlocked     bool    // 当前用户已拉黑对方
+	IsPending     bool    // 当前用户的关注请求待处理
+	PrivateRoomID *uint64 // 已存在的私聊房间
+}
+
 // NewRelationshipService 创建关系服务实例
 func NewRelationshipService(
 	relationRepo repository.RelationshipRepository,
@@ -30,29 +51,29 @@ func NewRelationshipService(
 }
 
 // Follow 关注用户
-func (s *RelationshipService) Follow(ctx context.Context, followerID, followingID uint64) error {
+func (s *RelationshipService) Follow(ctx context.Context, followerID, followingID uint64) (*FollowRequestResult, error) {
 	// 检查是否自关注
 	if followerID == followingID {
-		return ErrSelfRelation
+		return nil, ErrSelfRelation
 	}
 
 	// 验证用户是否存在
 	follower, err := s.userRepo.GetByID(ctx, followerID)
 	if err != nil || follower == nil {
-		return ErrUserNotFound
+		return nil, ErrUserNotFound
 	}
 	following, err := s.userRepo.GetByID(ctx, followingID)
 	if err != nil || following == nil {
-		return ErrUserNotFound
+		return nil, ErrUserNotFound
 	}
 
 	// 检查是否被对方拉黑
 	isBlocked, err := s.IsBlocked(ctx, followingID, followerID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if isBlocked {
-		return ErrBlockedUser
+		return nil, ErrBlockedUser
 	}
 
 	// 检查目标用户的隐私设置
@@ -71,15 +92,31 @@ func (s *RelationshipService) Follow(ctx context.Context, followerID, followingI
 	}
 
 	if err := s.relationRepo.Create(ctx, relationship); err != nil {
-		return fmt.Errorf("failed to create relationship: %w", err)
+		return nil, fmt.Errorf("failed to create relationship: %w", err)
 	}
 
 	// 如果是直接接受的关注，需要处理互相关注（好友）的情况
+	result := &FollowRequestResult{Status: status}
 	if status == "accepted" {
-		s.handleMutualFollow(ctx, followerID, followingID)
+		s.handleMutualFollow(ctx, result, followerID, followingID)
 	}
 
-	return nil
+	s.notifyFollow(result, followerID, followingID)
+
+	return result, nil
+}
+
+// notifyFollow 根据关注结果通知被关注者
+// 这里应该通过推送通知用户，实际项目中应该通过消息队列处理
+func (s *RelationshipService) notifyFollow(result *FollowRequestResult, followerID, followingID uint64) {
+	event := "new follower notification"
+	if result.Status == "pending" {
+		event = "follow request notification"
+	}
+	logger.Info(event,
+		logger.Uint64("user_id", followingID),
+		logger.Uint64("follower_id", followerID),
+		logger.Bool("is_friend", result.IsFriend))
 }
 
 // Unfollow 取消关注
@@ -95,18 +132,18 @@ func (s *RelationshipService) Unfollow(ctx context.Context, followerID, followin
 	return nil
 }
 
-// AcceptFollow 接受关注请求
-func (s *RelationshipService) AcceptFollow(ctx context.Context, userID, followerID uint64) error {
+// AcceptFollow 接受关注请求，成为好友时返回私聊房间
+func (s *RelationshipService) AcceptFollow(ctx context.Context, userID, followerID uint64) (*FollowRequestResult, error) {
 	// 获取关注请求
 	relationship, err := s.relationRepo.GetRelationship(ctx, followerID, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if relationship == nil {
-		return ErrNotFound
+		return nil, ErrNotFound
 	}
 	if relationship.Status != "pending" {
-		return ErrInvalidRelationType
+		return nil, ErrInvalidRelationType
 	}
 
 	// 更新关系状态
@@ -115,18 +152,63 @@ func (s *RelationshipService) AcceptFollow(ctx context.Context, userID, follower
 	relationship.AcceptedAt = &now
 
 	if err := s.relationRepo.Update(ctx, relationship); err != nil {
-		return fmt.Errorf("failed to update relationship: %w", err)
+		return nil, fmt.Errorf("failed to update relationship: %w", err)
 	}
 
 	// 处理互相关注的情况
-	s.handleMutualFollow(ctx, followerID, userID)
+	result := &FollowRequestResult{Status: relationship.Status}
+	s.handleMutualFollow(ctx, result, followerID, userID)
 
-	return nil
+	return result, nil
+}
+
+// RejectFollow 拒绝关注请求，只能处理待处理的请求
+func (s *RelationshipService) RejectFollow(ctx context.Context, userID, followerID uint64) (*FollowRequestResult, error) {
+	relationship, err := s.relationRepo.GetRelationship(ctx, followerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if relationship == nil {
+		return nil, ErrNotFound
+	}
+	if relationship.Status != "pending" {
+		return nil, ErrInvalidRelationType
+	}
+
+	deleted, err := s.relationRepo.DeletePending(ctx, followerID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject follow request: %w", err)
+	}
+	// 请求已被并发处理
+	if !deleted {
+		return nil, ErrInvalidRelationType
+	}
+	return &FollowRequestResult{Status: "rejected"}, nil
 }
 
-// RejectFollow 拒绝关注请求
-func (s *RelationshipService) RejectFollow(ctx context.Context, userID, followerID uint64) error {
-	return s.relationRepo.Delete(ctx, followerID, userID)
+// RejectAndBlock 拒绝关注请求并拉黑请求者，之后对方无法再关注或私聊
+func (s *RelationshipService) RejectAndBlock(ctx context.Context, userID, followerID uint64) (*FollowRequestResult, error) {
+	if userID == followerID {
+		return nil, ErrSelfRelation
+	}
+
+	// 获取关注请求
+	relationship, err := s.relationRepo.GetRelationship(ctx, followerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if relationship == nil {
+		return nil, ErrNotFound
+	}
+	if relationship.Status != "pending" {
+		return nil, ErrInvalidRelationType
+	}
+
+	if err := s.relationRepo.RejectAndBlock(ctx, userID, followerID); err != nil {
+		return nil, fmt.Errorf("failed to reject and block: %w", err)
+	}
+
+	return &FollowRequestResult{Status: "blocked"}, nil
 }
 
 // GetFollowers 获取粉丝列表
@@ -178,6 +260,82 @@ func (s *RelationshipService) GetFriends(ctx context.Context, userID uint64, pag
 	return friends[start:end], total, nil
 }
 
+// GetRelationStatuses 批量获取当前用户与多个用户的关系和私聊房间
+// 关系和私聊房间各只查询一次，避免逐个用户查询
+func (s *RelationshipService) GetRelationStatuses(ctx context.Context, userID uint64, targetIDs []uint64) (map[uint64]*UserRelationStatus, error) {
+	statuses := make(map[uint64]*UserRelationStatus, len(targetIDs))
+	for _, targetID := range targetIDs {
+		if targetID != userID {
+			statuses[targetID] = &UserRelationStatus{}
+		}
+	}
+	if len(statuses) == 0 {
+		return statuses, nil
+	}
+
+	relationships, err := s.relationRepo.ListBetween(ctx, userID, targetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+	for _, rel := range relationships {
+		if rel.FollowerID == userID {
+			status, ok := statuses[rel.FollowingID]
+			if !ok {
+				continue
+			}
+			switch rel.Status {
+			case "accepted":
+				status.IsFollowing = true
+			case "pending":
+				status.IsPending = true
+			case "blocked":
+				status.IsBlocked = true
+			}
+			continue
+		}
+		if status, ok := statuses[rel.FollowerID]; ok && rel.Status == "accepted" {
+			status.IsFollowed = true
+		}
+	}
+
+	roomIDs, err := s.chatService.GetPrivateRoomIDs(ctx, userID, targetIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for targetID, status := range statuses {
+		status.IsFriend = status.IsFollowing && status.IsFollowed
+		if roomID, ok := roomIDs[targetID]; ok {
+			status.PrivateRoomID = &roomID
+		}
+	}
+	return statuses, nil
+}
+
+// GetBlockedIDs 返回 targetIDs 中与当前用户存在拉黑关系的用户，任一方拉黑都算
+func (s *RelationshipService) GetBlockedIDs(ctx context.Context, userID uint64, targetIDs []uint64) (map[uint64]bool, error) {
+	blocked := make(map[uint64]bool)
+	if len(targetIDs) == 0 {
+		return blocked, nil
+	}
+
+	relationships, err := s.relationRepo.ListBetween(ctx, userID, targetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+	for _, rel := range relationships {
+		if rel.Status != "blocked" {
+			continue
+		}
+		if rel.FollowerID == userID {
+			blocked[rel.FollowingID] = true
+		} else {
+			blocked[rel.FollowerID] = true
+		}
+	}
+	return blocked, nil
+}
+
 // IsFollowing 检查是否正在关注
 func (s *RelationshipService) IsFollowing(ctx context.Context, followerID, followingID uint64) (bool, error) {
 	relationship, err := s.relationRepo.GetRelationship(ctx, followerID, followingID)
@@ -218,8 +376,9 @@ func (s *RelationshipService) IsFriend(ctx context.Context, userID1, userID2 uin
 	return s.IsFollowing(ctx, userID2, userID1)
 }
 
-// 处理互相关注（好友）情况
-func (s *RelationshipService) handleMutualFollow(ctx context.Context, userID1, userID2 uint64) {
+// handleMutualFollow 处理互相关注（好友）情况，在 result 中记录好友状态及私聊房间
+// 私聊房间创建失败不影响好友状态，客户端之后打开私聊时会重新创建
+func (s *RelationshipService) handleMutualFollow(ctx context.Context, result *FollowRequestResult, userID1, userID2 uint64) {
 	isFriend, err := s.IsFriend(ctx, userID1, userID2)
 	if err != nil {
 		logger.Error("failed to check friend status",
@@ -229,14 +388,47 @@ func (s *RelationshipService) handleMutualFollow(ctx context.Context, userID1, u
 		return
 	}
 
-	if isFriend {
-		// 创建私聊房间
-		_, err := s.chatService.CreatePrivateRoom(ctx, userID1, userID2)
-		if err != nil {
-			logger.Error("failed to create private room",
-				logger.Any("error", err),
-				logger.Uint64("user1", userID1),
-				logger.Uint64("user2", userID2))
-		}
+	if !isFriend {
+		return
+	}
+	result.IsFriend = true
+
+	// 创建私聊房间，已存在时直接返回
+	room, err := s.chatService.CreatePrivateRoom(ctx, userID1, userID2)
+	if err != nil {
+		logger.Error("failed to create private room",
+			logger.Any("error", err),
+			logger.Uint64("user1", userID1),
+			logger.Uint64("user2", userID2))
+		return
+	}
+	result.RoomID = &room.ID
+}
+
+// GetCounts 获取用户的粉丝、关注及好友数，短时间缓存
+func (s *RelationshipService) GetCounts(ctx context.Context, userID uint64) (*repository.RelationshipCounts, error) {
+	cacheKey := cache.RelationshipCountsKey(userID)
+	var cached repository.RelationshipCounts
+	if err := cache.Get(cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	counts, err := s.relationRepo.GetCounts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationship counts: %w", err)
+	}
+
+	if err := cache.Set(cacheKey, counts, RelationshipCountsExpiration); err != nil {
+		logger.Warn("failed to cache relationship counts", logger.Any("error", err))
+	}
+	return counts, nil
+}
+
+// GetRelationshipCountsByStatus 获取用户粉丝与关注按状态的计数
+func (s *RelationshipService) GetRelationshipCountsByStatus(ctx context.Context, userID uint64) (*repository.RelationshipCountsByStatus, error) {
+	counts, err := s.relationRepo.GetCountsByStatus(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationship counts by status: %w", err)
 	}
+	return counts, nil
 }
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+)
+
+func TestFollowDependsOnTargetPrivacy(t *testing.T) {
+	tests := []struct {
+		privacy    string
+		wantStatus string
+	}{
+		{model.PrivacyPublic, "accepted"},
+		{model.PrivacyFriends, "pending"},
+		{model.PrivacyPrivate, "pending"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.privacy, func(t *testing.T) {
+			follower := &model.User{Nickname: "follower", Status: model.UserStatusActive}
+			follower.ID = 7
+			target := &model.User{Nickname: "target", Status: model.UserStatusActive, PrivacyLevel: tt.privacy}
+			target.ID = 8
+			relationRepo := newFakeRelationRepo()
+			svc := NewRelationshipService(relationRepo, newFakeUserRepo(follower, target), nil)
+
+			result, err := svc.Follow(context.Background(), 7, 8)
+			if err != nil {
+				t.Fatalf("Follow: %v", err)
+			}
+			if result.Status != tt.wantStatus || result.IsFriend {
+				t.Errorf("result = %+v, want %s and not friend", result, tt.wantStatus)
+			}
+			rel, _ := relationRepo.GetRelationship(context.Background(), 7, 8)
+			if rel == nil || rel.Status != tt.wantStatus {
+				t.Errorf("stored relationship = %+v, want %s", rel, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAcceptFollowOnlyPendingRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string // 空表示没有关系
+		wantErr    error
+		wantStatus string // 处理后保存的状态，空表示没有关系
+	}{
+		{"pending", "pending", nil, "accepted"},
+		{"accepted", "accepted", ErrInvalidRelationType, "accepted"},
+		{"blocked", "blocked", ErrInvalidRelationType, "blocked"},
+		{"missing", "", ErrNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relationRepo := newFakeRelationRepo()
+			if tt.status != "" {
+				relationRepo.set(8, 7, tt.status)
+			}
+			svc := NewRelationshipService(relationRepo, newFakeUserRepo(), nil)
+
+			result, err := svc.AcceptFollow(context.Background(), 7, 8)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && result.Status != "accepted" {
+				t.Errorf("status = %q, want accepted", result.Status)
+			}
+			rel, _ := relationRepo.GetRelationship(context.Background(), 8, 7)
+			status := ""
+			if rel != nil {
+				status = rel.Status
+			}
+			if status != tt.wantStatus {
+				t.Errorf("stored status = %q, want %q", status, tt.wantStatus)
+			}
+			if err != nil && rel != nil && rel.AcceptedAt != nil {
+				t.Error("AcceptedAt set on a request that was not pending")
+			}
+		})
+	}
+}
+
+func TestRejectFollowOnlyPendingRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string // 空表示没有关系
+		wantErr     error
+		wantRemains bool
+	}{
+		{"pending", "pending", nil, false},
+		{"accepted", "accepted", ErrInvalidRelationType, true},
+		{"blocked", "blocked", ErrInvalidRelationType, true},
+		{"missing", "", ErrNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relationRepo := newFakeRelationRepo()
+			if tt.status != "" {
+				relationRepo.set(8, 7, tt.status)
+			}
+			svc := NewRelationshipService(relationRepo, newFakeUserRepo(), nil)
+
+			result, err := svc.RejectFollow(context.Background(), 7, 8)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && result.Status != "rejected" {
+				t.Errorf("status = %q, want rejected", result.Status)
+			}
+			rel, _ := relationRepo.GetRelationship(context.Background(), 8, 7)
+			if (rel != nil) != tt.wantRemains {
+				t.Errorf("relationship remains = %v, want %v", rel != nil, tt.wantRemains)
+			}
+		})
+	}
+}
+
+func TestAcceptFollowReportsFriendWhenRoomCreationFails(t *testing.T) {
+	relationRepo := newFakeRelationRepo()
+	relationRepo.set(8, 7, "pending")
+	relationRepo.set(7, 8, "accepted")
+	// 用户仓储为空，私聊房间创建会失败
+	chatService := NewChatService(newFakeChatRepo(), newFakeTopicRepo(), newFakeUserRepo(), relationRepo,
+		&fakeStorage{}, config.UploadConfig{}, config.ChatConfig{})
+	svc := NewRelationshipService(relationRepo, newFakeUserRepo(), chatService)
+
+	result, err := svc.AcceptFollow(context.Background(), 7, 8)
+	if err != nil {
+		t.Fatalf("AcceptFollow: %v", err)
+	}
+	if result.Status != "accepted" || !result.IsFriend {
+		t.Errorf("result = %+v, want accepted friend", result)
+	}
+	if result.RoomID != nil {
+		t.Errorf("RoomID = %d, want nil when room creation fails", *result.RoomID)
+	}
+}
+
+func TestGetRelationStatusesQueriesOncePerConcern(t *testing.T) {
+	relationRepo := newFakeRelationRepo()
+	relationRepo.set(7, 8, "accepted")
+	relationRepo.set(8, 7, "accepted")
+	relationRepo.set(7, 9, "pending")
+	relationRepo.set(10, 7, "accepted")
+	relationRepo.set(7, 11, "blocked")
+	chatRepo := newFakeChatRepo()
+	chatRepo.addRoom(50, "private", member(7, "member"), member(8, "member"))
+	pairKey := model.PrivatePairKey(7, 8)
+	chatRepo.rooms[50].PairKey = &pairKey
+	chatService := NewChatService(chatRepo, newFakeTopicRepo(), newFakeUserRepo(), relationRepo,
+		&fakeStorage{}, config.UploadConfig{}, config.ChatConfig{})
+	svc := NewRelationshipService(relationRepo, newFakeUserRepo(), chatService)
+
+	statuses, err := svc.GetRelationStatuses(context.Background(), 7, []uint64{7, 8, 9, 10, 11, 12})
+	if err != nil {
+		t.Fatalf("GetRelationStatuses: %v", err)
+	}
+	if relationRepo.betweenQueries != 1 || chatRepo.privateRoomQueries != 1 {
+		t.Errorf("queries = %d relationship, %d room; want one each",
+			relationRepo.betweenQueries, chatRepo.privateRoomQueries)
+	}
+
+	if _, ok := statuses[7]; ok {
+		t.Error("status for self should be omitted")
+	}
+	friend := statuses[8]
+	if !friend.IsFriend || friend.PrivateRoomID == nil || *friend.PrivateRoomID != 50 {
+		t.Errorf("status[8] = %+v, want friend with room 50", friend)
+	}
+	if !statuses[9].IsPending || statuses[9].IsFollowing {
+		t.Errorf("status[9] = %+v, want pending only", statuses[9])
+	}
+	if !statuses[10].IsFollowed || statuses[10].IsFriend {
+		t.Errorf("status[10] = %+v, want followed only", statuses[10])
+	}
+	if !statuses[11].IsBlocked {
+		t.Errorf("status[11] = %+v, want blocked", statuses[11])
+	}
+	if none := statuses[12]; none == nil || *none != (UserRelationStatus{}) {
+		t.Errorf("status[12] = %+v, want empty status", none)
+	}
+}
+
+func TestRejectAndBlockRejectsRequestAndBlocks(t *testing.T) {
+	user := &model.User{Nickname: "user", Status: model.UserStatusActive, PrivacyLevel: model.PrivacyPrivate}
+	user.ID = 7
+	follower := &model.User{Nickname: "follower", Status: model.UserStatusActive}
+	follower.ID = 8
+	relationRepo := newFakeRelationRepo()
+	relationRepo.set(8, 7, "pending")
+	relationRepo.set(7, 8, "accepted")
+	svc := NewRelationshipService(relationRepo, newFakeUserRepo(user, follower), nil)
+	ctx := context.Background()
+
+	result, err := svc.RejectAndBlock(ctx, 7, 8)
+	if err != nil {
+		t.Fatalf("RejectAndBlock: %v", err)
+	}
+	if result.Status != "blocked" {
+		t.Errorf("status = %q, want blocked", result.Status)
+	}
+
+	// 关注请求已被拒绝，原有关注改为拉黑
+	if rel, _ := relationRepo.GetRelationship(ctx, 8, 7); rel != nil {
+		t.Errorf("follow request remains: %+v", rel)
+	}
+	if blocked, err := svc.IsBlocked(ctx, 7, 8); err != nil || !blocked {
+		t.Errorf("IsBlocked = %v, %v; want true", blocked, err)
+	}
+	// 被拉黑后无法再次发送关注请求
+	if _, err := svc.Follow(ctx, 8, 7); err != ErrBlockedUser {
+		t.Errorf("follow after block err = %v, want ErrBlockedUser", err)
+	}
+}
+
+func TestRejectAndBlockRequiresPendingRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string // 空表示没有关系
+		wantErr error
+	}{
+		{"accepted", "accepted", ErrInvalidRelationType},
+		{"missing", "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relationRepo := newFakeRelationRepo()
+			if tt.status != "" {
+				relationRepo.set(8, 7, tt.status)
+			}
+			svc := NewRelationshipService(relationRepo, newFakeUserRepo(), nil)
+
+			if _, err := svc.RejectAndBlock(context.Background(), 7, 8); err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if rel, _ := relationRepo.GetRelationship(context.Background(), 7, 8); rel != nil {
+				t.Errorf("block created without a pending request: %+v", rel)
+			}
+		})
+	}
+}
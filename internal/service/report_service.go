@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/internal/repository"
+)
+
+// ReportReason 可选的举报原因
+type ReportReason struct {
+	Code           string `json:"code"`
+	Label          string `json:"label"`
+	RequiresDetail bool   `json:"requires_detail"` // 是否必须填写详细说明
+}
+
+// reportReasons 举报原因列表，按客户端展示顺序排列
+var reportReasons = []ReportReason{
+	{Code: model.ReportReasonSpam, Label: "垃圾广告"},
+	{Code: model.ReportReasonHarassment, Label: "骚扰或辱骂"},
+	{Code: model.ReportReasonNudity, Label: "色情内容"},
+	{Code: model.ReportReasonOther, Label: "其他", RequiresDetail: true},
+}
+
+// SubmitReportInput 提交举报参数
+type SubmitReportInput struct {
+	TargetType string
+	TargetID   uint64
+	Reason     string
+	Detail     string
+}
+
+type ReportService struct {
+	reportRepo  repository.ReportRepository
+	userRepo    repository.UserRepository
+	topicRepo   repository.TopicRepository
+	chatRepo    repository.ChatRepository
+	chatService *ChatService
+}
+
+// NewReportService 创建举报服务实例
+func NewReportService(
+	reportRepo repository.ReportRepository,
+	userRepo repository.UserRepository,
+	topicRepo repository.TopicRepository,
+	chatRepo repository.ChatRepository,
+	chatService *ChatService,
+) *ReportService {
+	return &ReportService{
+		reportRepo:  reportRepo,
+		userRepo:    userRepo,
+		topicRepo:   topicRepo,
+		chatRepo:    chatRepo,
+		chatService: chatService,
+	}
+}
+
+// ReportReasons 获取可选的举报原因
+func (s *ReportService) ReportReasons() []ReportReason {
+	reasons := make([]ReportReason, len(reportReasons))
+	copy(reasons, reportReasons)
+	return reasons
+}
+
+// findReportReason 查找举报原因，不存在时返回 false
+func findReportReason(code string) (ReportReason, bool) {
+	for _, reason := range reportReasons {
+		if reason.Code == code {
+			return reason, true
+		}
+	}
+	return ReportReason{}, false
+}
+
+// SubmitReport 提交举报，原因必须在举报原因列表中，选择 other 时必须填写说明
+func (s *ReportService) SubmitReport(ctx context.Context, reporterID uint64, input SubmitReportInput) (*model.Report, error) {
+	reason, ok := findReportReason(input.Reason)
+	if !ok {
+		return nil, ErrInvalidReportReason
+	}
+	detail := strings.TrimSpace(input.Detail)
+	if reason.RequiresDetail && detail == "" {
+		return nil, ErrReportDetailRequired
+	}
+
+	if err := s.checkTarget(ctx, reporterID, input.TargetType, input.TargetID); err != nil {
+		return nil, err
+	}
+
+	report := &model.Report{
+		ReporterID:   reporterID,
+		TargetType:   input.TargetType,
+		TargetID:     input.TargetID,
+		ReasonType:   reason.Code,
+		ReasonDetail: detail,
+		Status:       model.ReportStatusPending,
+	}
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to create report: %w", err)
+	}
+	return report, nil
+}
+
+// checkTarget 检查举报对象是否存在，举报消息时举报人必须是消息所在聊天室的成员
+func (s *ReportService) checkTarget(ctx context.Context, reporterID uint64, targetType string, targetID uint64) error {
+	switch targetType {
+	case model.ReportTargetUser:
+		if targetID == reporterID {
+			return ErrInvalidRequest
+		}
+		user, err := s.userRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+	case model.ReportTargetTopic:
+		topic, err := s.topicRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return fmt.Errorf("failed to get topic: %w", err)
+		}
+		if topic == nil {
+			return ErrTopicNotFound
+		}
+	case model.ReportTargetMessage:
+		message, err := s.chatRepo.GetMessageByID(ctx, targetID)
+		if err != nil {
+			return fmt.Errorf("failed to get message: %w", err)
+		}
+		if message == nil {
+			return ErrMessageNotFound
+		}
+		// 只能举报自己所在聊天室的消息
+		if err := s.chatService.CheckRoomMember(ctx, reporterID, message.ChatRoomID); err != nil {
+			return err
+		}
+	default:
+		// 暂不支持评论举报
+		return ErrInvalidRequest
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"DistanceBack_v1/internal/model"
+)
+
+func TestFindReportReason(t *testing.T) {
+	tests := []struct {
+		code           string
+		ok             bool
+		requiresDetail bool
+	}{
+		{model.ReportReasonSpam, true, false},
+		{model.ReportReasonHarassment, true, false},
+		{model.ReportReasonNudity, true, false},
+		{model.ReportReasonOther, true, true},
+		// 旧的话题举报原因已统一到新列表
+		{"abuse", false, false},
+		{"copyright", false, false},
+		{"", false, false},
+		{"SPAM", false, false},
+	}
+	for _, tt := range tests {
+		reason, ok := findReportReason(tt.code)
+		if ok != tt.ok || reason.RequiresDetail != tt.requiresDetail {
+			t.Errorf("findReportReason(%q) = %+v, %v, want ok=%v requiresDetail=%v",
+				tt.code, reason, ok, tt.ok, tt.requiresDetail)
+		}
+	}
+}
+
+func TestSubmitReportRequiresRoomMembershipForMessages(t *testing.T) {
+	chatRepo := newFakeChatRepo()
+	chatRepo.addRoom(1, "group", member(7, "owner"), member(8, "member"))
+	message := &model.Message{ChatRoomID: 1, SenderID: 8, Content: "hi"}
+	message.ID = 50
+	chatRepo.messages = append(chatRepo.messages, message)
+
+	reportRepo := &fakeReportRepo{}
+	svc := NewReportService(reportRepo, newFakeUserRepo(), newFakeTopicRepo(), chatRepo, newTestChatService(chatRepo))
+	input := SubmitReportInput{TargetType: model.ReportTargetMessage, TargetID: 50, Reason: model.ReportReasonSpam}
+
+	if _, err := svc.SubmitReport(context.Background(), 9, input); err != ErrNotRoomMember {
+		t.Errorf("non-member report: err = %v, want ErrNotRoomMember", err)
+	}
+	if _, err := svc.SubmitReport(context.Background(), 7, input); err != nil {
+		t.Fatalf("member report: %v", err)
+	}
+	if len(reportRepo.reports) != 1 || reportRepo.reports[0].ReporterID != 7 {
+		t.Errorf("reports = %+v, want one report by user 7", reportRepo.reports)
+	}
+}
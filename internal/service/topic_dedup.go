@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/pkg/logger"
+	"DistanceBack_v1/pkg/utils"
+)
+
+// MaxDedupCandidates 近似重复检测最多比较的近期话题数量
+const MaxDedupCandidates = 20
+
+// isDuplicateTopic 检查用户近期是否在附近发布过内容相似的话题
+// 尽力而为，查询失败时放行
+func (s *TopicService) isDuplicateTopic(ctx context.Context, userID uint64, topic *model.Topic) bool {
+	cfg := s.config.Dedup
+	if cfg.Window <= 0 {
+		return false
+	}
+
+	recent, err := s.topicRepo.ListRecentByUser(ctx, userID, time.Now().Add(-cfg.Window), MaxDedupCandidates)
+	if err != nil {
+		logger.Error("检查重复话题失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID))
+		return false
+	}
+
+	text := normalizeTopicText(topic.Title + " " + topic.Content)
+	for _, existing := range recent {
+		distance := utils.CalculateDistance(
+			topic.LocationLatitude, topic.LocationLongitude,
+			existing.LocationLatitude, existing.LocationLongitude)
+		if distance > cfg.Radius {
+			continue
+		}
+		if textSimilarity(text, normalizeTopicText(existing.Title+" "+existing.Content)) >= cfg.Similarity {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTopicText 转小写并去掉空白和标点，避免靠改动符号绕过检测
+func normalizeTopicText(text string) []rune {
+	runes := make([]rune, 0, len(text))
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+// textSimilarity 按字符二元组计算 Dice 系数，返回 0~1
+func textSimilarity(a, b []rune) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		if string(a) == string(b) {
+			return 1
+		}
+		return 0
+	}
+
+	bigrams := make(map[[2]rune]int, len(a)-1)
+	for i := 0; i < len(a)-1; i++ {
+		bigrams[[2]rune{a[i], a[i+1]}]++
+	}
+
+	matches := 0
+	for i := 0; i < len(b)-1; i++ {
+		key := [2]rune{b[i], b[i+1]}
+		if bigrams[key] > 0 {
+			bigrams[key]--
+			matches++
+		}
+	}
+	return float64(2*matches) / float64(len(a)+len(b)-2)
+}
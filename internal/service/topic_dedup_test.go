@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/pkg/errors"
+)
+
+func TestNormalizeTopicText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hello, World!", "helloworld"},
+		{"  周末 一起 打球吗？", "周末一起打球吗"},
+		{"Room 42 -- free!!", "room42free"},
+		{"...", ""},
+	}
+	for _, tt := range tests {
+		if got := string(normalizeTopicText(tt.text)); got != tt.want {
+			t.Errorf("normalizeTopicText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTextSimilarity(t *testing.T) {
+	similarity := func(a, b string) float64 {
+		return textSimilarity(normalizeTopicText(a), normalizeTopicText(b))
+	}
+
+	// 只改动标点、空白或大小写视为相同
+	if got := similarity("周末一起打球吗", "周末 一起打球吗！！"); got != 1 {
+		t.Errorf("punctuation change similarity = %v, want 1", got)
+	}
+	if got := similarity("Anyone up for basketball tonight?", "anyone up for BASKETBALL tonight"); got != 1 {
+		t.Errorf("case change similarity = %v, want 1", got)
+	}
+	// 小幅改写仍然高度相似
+	if got := similarity("Anyone up for basketball tonight at the park", "Anyone up for basketball tonight at the big park"); got < 0.8 {
+		t.Errorf("near duplicate similarity = %v, want >= 0.8", got)
+	}
+	// 不同话题相似度很低
+	if got := similarity("Anyone up for basketball tonight", "Lost my cat near the station"); got > 0.3 {
+		t.Errorf("different topics similarity = %v, want <= 0.3", got)
+	}
+	if got := similarity("周末一起打球吗", "附近有好吃的火锅店吗"); got > 0.3 {
+		t.Errorf("different chinese topics similarity = %v, want <= 0.3", got)
+	}
+	// 过短的文本只有完全相同才算相似
+	if got := similarity("a", "a"); got != 1 {
+		t.Errorf("single char similarity = %v, want 1", got)
+	}
+	if got := similarity("a", "b"); got != 0 {
+		t.Errorf("different single char similarity = %v, want 0", got)
+	}
+}
+
+func newDedupTestService(user *model.User, repo *fakeTopicRepo) *TopicService {
+	cfg := config.TopicConfig{
+		CreateLimit: config.TopicLimitConfig{Window: time.Hour, Limit: 2},
+		Dedup:       config.TopicDedupConfig{Window: time.Hour, Radius: 500, Similarity: 0.8},
+	}
+	return NewTopicService(repo, newFakeUserRepo(user), nil, &fakeStorage{}, cfg, config.NearbyConfig{}, config.UploadConfig{})
+}
+
+func TestCreateTopicDuplicateDoesNotConsumeRateLimit(t *testing.T) {
+	resetCache(t)
+	user := &model.User{Status: model.UserStatusActive}
+	user.ID = 7
+	svc := newDedupTestService(user, newFakeTopicRepo())
+	ctx := context.Background()
+
+	if _, err := svc.CreateTopic(ctx, user.ID, &model.Topic{Title: "周末一起打球吗"}, false, nil); err != nil {
+		t.Fatalf("first topic: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := svc.CreateTopic(ctx, user.ID, &model.Topic{Title: "周末一起打球吗？"}, false, nil); err != ErrDuplicateTopic {
+			t.Fatalf("duplicate %d: err = %v, want ErrDuplicateTopic", i+1, err)
+		}
+	}
+	// 重复被拒绝的请求不占用发布次数，第二个不同话题仍可发布
+	if _, err := svc.CreateTopic(ctx, user.ID, &model.Topic{Title: "附近有好吃的火锅店吗"}, false, nil); err != nil {
+		t.Fatalf("second distinct topic: %v", err)
+	}
+	_, err := svc.CreateTopic(ctx, user.ID, &model.Topic{Title: "Lost my cat near the station"}, false, nil)
+	if _, ok := errors.RetryAfterSeconds(err); !ok {
+		t.Errorf("third distinct topic: err = %v, want rate limited", err)
+	}
+}
+
+func TestCreateTopicAllowsRepostAfterClose(t *testing.T) {
+	resetCache(t)
+	user := &model.User{Status: model.UserStatusActive}
+	user.ID = 7
+	closed := newTestTopic(1, 7, model.TopicStatusClosed)
+	closed.Title = "周末一起打球吗"
+	closed.CreatedAt = time.Now().Add(-time.Minute)
+	cancelled := newTestTopic(2, 7, model.TopicStatusCancelled)
+	cancelled.Title = "周末一起打球吗"
+	cancelled.CreatedAt = time.Now().Add(-time.Minute)
+	svc := newDedupTestService(user, newFakeTopicRepo(closed, cancelled))
+
+	// 删除后重新发布不算重复
+	if _, err := svc.CreateTopic(context.Background(), user.ID, &model.Topic{Title: "周末一起打球吗"}, false, nil); err != nil {
+		t.Fatalf("repost after close: %v", err)
+	}
+}
+
+func TestCreateTopicDedupOnlyWithinRadius(t *testing.T) {
+	resetCache(t)
+	user := &model.User{Status: model.UserStatusActive}
+	user.ID = 7
+	svc := newDedupTestService(user, newFakeTopicRepo())
+	ctx := context.Background()
+	at := func(lat, lng float64) *model.Topic {
+		return &model.Topic{Title: "周末一起打球吗", LocationLatitude: lat, LocationLongitude: lng}
+	}
+
+	if _, err := svc.CreateTopic(ctx, user.ID, at(35.6812, 139.7671), false, nil); err != nil {
+		t.Fatalf("first topic: %v", err)
+	}
+	// 约 100 米外，在检测半径内
+	if _, err := svc.CreateTopic(ctx, user.ID, at(35.6821, 139.7671), false, nil); err != ErrDuplicateTopic {
+		t.Fatalf("nearby repost err = %v, want ErrDuplicateTopic", err)
+	}
+	// 约 1.1 公里外，超出检测半径
+	if _, err := svc.CreateTopic(ctx, user.ID, at(35.6912, 139.7671), false, nil); err != nil {
+		t.Fatalf("repost beyond radius: %v", err)
+	}
+}
@@ -2,22 +2,75 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
+	"DistanceBack_v1/config"
 	"DistanceBack_v1/internal/model"
 	"DistanceBack_v1/internal/repository"
 	"DistanceBack_v1/pkg/cache"
 	"DistanceBack_v1/pkg/constants"
+	"DistanceBack_v1/pkg/errors"
 	"DistanceBack_v1/pkg/logger"
+	"DistanceBack_v1/pkg/ratelimit"
 	"DistanceBack_v1/pkg/storage"
 )
 
+const (
+	// TopicExpiryCheckInterval 关闭过期话题的检查间隔
+	TopicExpiryCheckInterval = 5 * time.Minute
+	// MaxTopicParticipants 话题参与者预览的最大数量
+	MaxTopicParticipants = 20
+	// DefaultTopicStatsDays 话题统计默认查询天数
+	DefaultTopicStatsDays = 7
+	// MaxTopicStatsDays 话题统计最多查询天数
+	MaxTopicStatsDays = 90
+	// DefaultLikerLimit 点赞用户列表默认每页数量
+	DefaultLikerLimit = 20
+	// MaxLikerLimit 点赞用户列表每页最大数量
+	MaxLikerLimit = 100
+)
+
+// 话题浏览计数方式
+const (
+	TopicViewModeExplicit = "explicit"
+	TopicViewModeAuto     = "auto"
+)
+
+// TopicPage 话题游标分页结果
+type TopicPage struct {
+	Topics     []*model.Topic
+	NextCursor uint64 // 继续加载时作为 cursor，没有更多时为 0
+	HasMore    bool
+}
+
+// LikerPage 点赞用户分页结果
+type LikerPage struct {
+	Users      []*model.User
+	NextCursor uint64 // 继续加载时作为 cursor，没有更多时为 0
+	HasMore    bool
+}
+
+// TopicListFilter 话题列表过滤条件
+type TopicListFilter struct {
+	SortBy      string
+	CreatedFrom time.Time // 创建时间下限(含)
+	CreatedTo   time.Time // 创建时间上限(不含)
+	// IncludeInactive 包含已关闭和已过期的话题，仅作者本人或管理员可用
+	IncludeInactive bool
+}
+
 type TopicService struct {
 	topicRepo    repository.TopicRepository
 	userRepo     repository.UserRepository
 	relationRepo repository.RelationshipRepository
 	storage      storage.Storage
+	config       config.TopicConfig
+	nearby       config.NearbyConfig
+	mediaPolicy  string // 图片上传失败处理策略
+	concurrency  int    // 图片并发上传数
 }
 
 // NewTopicService 创建话题服务实例
@@ -26,17 +79,25 @@ func NewTopicService(
 	userRepo repository.UserRepository,
 	relationRepo repository.RelationshipRepository,
 	storage storage.Storage,
+	cfg config.TopicConfig,
+	nearby config.NearbyConfig,
+	uploadCfg config.UploadConfig,
 ) *TopicService {
 	return &TopicService{
 		topicRepo:    topicRepo,
 		userRepo:     userRepo,
 		relationRepo: relationRepo,
 		storage:      storage,
+		config:       cfg,
+		nearby:       nearby,
+		mediaPolicy:  uploadCfg.MediaFailurePolicy,
+		concurrency:  uploadCfg.MaxConcurrentUploads,
 	}
 }
 
 // CreateTopic 创建话题
-func (s *TopicService) CreateTopic(ctx context.Context, userID uint64, topic *model.Topic, images []*model.File) (*model.Topic, error) {
+// permanent 为 true 时创建永久话题，仅允许配置中的用户类型使用
+func (s *TopicService) CreateTopic(ctx context.Context, userID uint64, topic *model.Topic, permanent bool, images []*model.File) (*model.Topic, error) {
 	// 验证用户状态
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
@@ -49,49 +110,60 @@ func (s *TopicService) CreateTopic(ctx context.Context, userID uint64, topic *mo
 		return nil, ErrInvalidUserStatus
 	}
 
+	if len(images) > storage.MaxTopicImages {
+		return nil, ErrTooManyImages
+	}
+
+	// 检查近似重复话题
+	if s.isDuplicateTopic(ctx, userID, topic) {
+		return nil, ErrDuplicateTopic
+	}
+
 	// 设置话题基本信息
 	topic.UserID = userID
 	topic.Status = "active"
-	if topic.ExpiresAt.IsZero() {
-		topic.ExpiresAt = time.Now().Add(24 * time.Hour) // 默认24小时后过期
+	if permanent {
+		if !s.canPostPermanent(user) {
+			return nil, ErrForbidden
+		}
+		topic.ExpiresAt = nil
+	} else if topic.ExpiresAt == nil {
+		expiresAt := time.Now().Add(s.defaultExpiration())
+		topic.ExpiresAt = &expiresAt
+	} else if err := s.validateExpiresAt(user, *topic.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	// 其余校验都通过后再检查发布频率，被拒绝的请求不占用发布次数
+	if err := s.checkCreateLimit(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// 先上传图片，按配置的策略处理失败的图片
+	uploaded, failures, err := uploadMediaFiles(ctx, s.storage, images, storage.TopicDirectory, s.mediaPolicy, s.concurrency)
+	if err != nil {
+		return nil, err
 	}
 
 	// 创建话题
 	if err := s.topicRepo.Create(ctx, topic); err != nil {
+		deleteUploadedMedia(ctx, s.storage, uploaded)
 		return nil, fmt.Errorf("failed to create topic: %w", err)
 	}
 
 	// 处理图片
-	if len(images) > 0 {
-		topicImages := make([]*model.TopicImage, 0, len(images))
-		for i, img := range images {
-			// 上传图片
-			fileURL, err := s.storage.UploadFile(ctx, img.File, storage.TopicDirectory)
-			if err != nil {
-				logger.Error("failed to upload topic image",
-					logger.Any("error", err),
-					logger.Uint64("topic_id", topic.ID),
-					logger.Int("image_index", i))
-				continue
-			}
-
-			// 创建图片记录
-			topicImage := &model.TopicImage{
-				TopicID:     topic.ID,
-				ImageURL:    fileURL,
-				SortOrder:   uint(i),
-				ImageWidth:  uint(img.Width),  // 将int转换为uint
-				ImageHeight: uint(img.Height), // 将int转换为uint
-				FileSize:    img.Size,
-			}
-			topicImages = append(topicImages, topicImage)
-		}
+	if len(uploaded) > 0 {
+		topicImages := newTopicImages(topic.ID, uploaded)
 
 		// 保存图片记录
 		if err := s.topicRepo.AddImages(ctx, topic.ID, topicImages); err != nil {
 			logger.Error("failed to save topic images",
 				logger.Any("error", err),
 				logger.Uint64("topic_id", topic.ID))
+		} else {
+			for _, image := range topicImages {
+				topic.TopicImages = append(topic.TopicImages, *image)
+			}
 		}
 	}
 
@@ -101,11 +173,16 @@ func (s *TopicService) CreateTopic(ctx context.Context, userID uint64, topic *mo
 		logger.Warn("failed to cache topic", logger.Any("error", err))
 	}
 
+	// 失败列表与签名地址只随本次响应返回，不写入缓存
+	topic.FailedImages = failures
+	resolveTopicImages(ctx, s.storage, topic)
+
 	return topic, nil
 }
 
 // UpdateTopic 更新话题
-func (s *TopicService) UpdateTopic(ctx context.Context, userID uint64, topic *model.Topic) error {
+// topic.ExpiresAt 为空且 permanent 为 false 时保持原过期时间
+func (s *TopicService) UpdateTopic(ctx context.Context, userID uint64, topic *model.Topic, permanent bool) error {
 	// 获取原话题信息
 	existingTopic, err := s.GetTopicByID(ctx, topic.ID)
 	if err != nil {
@@ -125,10 +202,32 @@ func (s *TopicService) UpdateTopic(ctx context.Context, userID uint64, topic *mo
 		return ErrInvalidTopicStatus
 	}
 
+	// 校验过期时间
+	if permanent || topic.ExpiresAt != nil {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+
+		if permanent {
+			if !s.canPostPermanent(user) {
+				return ErrForbidden
+			}
+			existingTopic.ExpiresAt = nil
+		} else {
+			if err := s.validateExpiresAt(user, *topic.ExpiresAt); err != nil {
+				return err
+			}
+			existingTopic.ExpiresAt = topic.ExpiresAt
+		}
+	}
+
 	// 只更新允许修改的字段
 	existingTopic.Title = topic.Title
 	existingTopic.Content = topic.Content
-	existingTopic.ExpiresAt = topic.ExpiresAt
 
 	// 保存更新
 	if err := s.topicRepo.Update(ctx, existingTopic); err != nil {
@@ -137,7 +236,7 @@ func (s *TopicService) UpdateTopic(ctx context.Context, userID uint64, topic *mo
 
 	// 清除缓存
 	cacheKey := cache.TopicKey(topic.ID)
-	if err := cache.Delete(cacheKey); err != nil {
+	if err := cache.Invalidate(cacheKey); err != nil {
 		logger.Warn("failed to delete topic cache", logger.Any("error", err))
 	}
 
@@ -159,21 +258,192 @@ func (s *TopicService) DeleteTopic(ctx context.Context, userID, topicID uint64)
 	if topic.UserID != userID {
 		return ErrForbidden
 	}
+	// 管理员下架的话题为 cancelled，作者不能再处理
+	if topic.Status != model.TopicStatusActive && topic.Status != model.TopicStatusClosed {
+		return ErrInvalidTopicStatus
+	}
 
-	// 删除话题
+	// 关闭话题，宽限期内可恢复
 	if err := s.topicRepo.Delete(ctx, topicID); err != nil {
 		return fmt.Errorf("failed to delete topic: %w", err)
 	}
 
 	// 清除缓存
 	cacheKey := cache.TopicKey(topicID)
-	if err := cache.Delete(cacheKey); err != nil {
+	if err := cache.Invalidate(cacheKey); err != nil {
 		logger.Warn("failed to delete topic cache", logger.Any("error", err))
 	}
 
 	return nil
 }
 
+// RestoreTopic 作者在宽限期内恢复已删除的话题
+func (s *TopicService) RestoreTopic(ctx context.Context, userID, topicID uint64) (*model.Topic, error) {
+	topic, err := s.topicRepo.GetByID(ctx, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic: %w", err)
+	}
+	if topic == nil {
+		return nil, ErrTopicNotFound
+	}
+
+	// 验证权限
+	if topic.UserID != userID {
+		return nil, ErrForbidden
+	}
+	// 只有作者关闭的话题可以恢复，管理员下架的话题为 cancelled
+	if topic.Status != model.TopicStatusClosed || topic.ClosedAt == nil {
+		return nil, ErrInvalidTopicStatus
+	}
+
+	// 已过期的话题恢复后也无法展示
+	now := time.Now()
+	if topic.IsExpired(now) {
+		return nil, ErrTopicExpired
+	}
+
+	window := s.config.RestoreWindow
+	closedSince := now.Add(-window)
+	if window <= 0 || topic.ClosedAt.Before(closedSince) {
+		return nil, ErrRestoreExpired
+	}
+
+	restored, err := s.topicRepo.Restore(ctx, topicID, closedSince)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore topic: %w", err)
+	}
+	if !restored {
+		return nil, ErrRestoreExpired
+	}
+
+	topic.Status = model.TopicStatusActive
+	topic.ClosedAt = nil
+
+	// 重新写入缓存
+	if err := cache.Set(cache.TopicKey(topicID), topic, cache.DefaultExpiration); err != nil {
+		logger.Warn("failed to cache topic", logger.Any("error", err))
+	}
+
+	return topic, nil
+}
+
+// TransferTopic 管理员将话题转移给其他用户，并记录审计日志
+// 操作人的管理员权限由路由上的 AdminRequired 校验
+func (s *TopicService) TransferTopic(ctx context.Context, operatorID, topicID, newOwnerID uint64) error {
+	// 获取话题信息，绕过缓存读取当前作者
+	topic, err := s.topicRepo.GetByID(ctx, topicID)
+	if err != nil {
+		return fmt.Errorf("failed to get topic: %w", err)
+	}
+	if topic == nil {
+		return ErrTopicNotFound
+	}
+	if topic.UserID == newOwnerID {
+		return ErrInvalidRequest
+	}
+
+	// 验证新作者存在
+	newOwner, err := s.userRepo.GetByID(ctx, newOwnerID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if newOwner == nil {
+		return ErrUserNotFound
+	}
+
+	detail, err := json.Marshal(map[string]uint64{
+		"from_user_id": topic.UserID,
+		"to_user_id":   newOwnerID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit detail: %w", err)
+	}
+	audit := &model.AuditLog{
+		OperatorID: operatorID,
+		Action:     model.AuditActionTopicTransfer,
+		TargetType: model.AuditTargetTopic,
+		TargetID:   topicID,
+		Detail:     string(detail),
+	}
+
+	// 更新作者
+	if err := s.topicRepo.TransferOwner(ctx, topicID, newOwnerID, audit); err != nil {
+		return fmt.Errorf("failed to transfer topic: %w", err)
+	}
+
+	// 清除话题缓存及新旧作者的概览缓存（含话题数）
+	for _, key := range []string{
+		cache.TopicKey(topicID),
+		cache.MeOverviewKey(topic.UserID),
+		cache.MeOverviewKey(newOwnerID),
+	} {
+		if err := cache.Invalidate(key); err != nil {
+			logger.Warn("failed to delete cache after topic transfer",
+				logger.Any("error", err),
+				logger.String("key", key))
+		}
+	}
+
+	return nil
+}
+
+// PurgeMyClosedTopics 彻底删除当前用户关闭时间已超过恢复宽限期的话题，返回删除数量
+// 过期话题由定时任务关闭后同样要等宽限期结束才会被清理，管理员下架的话题保留以便复核
+func (s *TopicService) PurgeMyClosedTopics(ctx context.Context, userID uint64) (int, error) {
+	closedBefore := time.Now()
+	if s.config.RestoreWindow > 0 {
+		closedBefore = closedBefore.Add(-s.config.RestoreWindow)
+	}
+
+	topics, err := s.topicRepo.ListClosedByUser(ctx, userID, closedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list closed topics: %w", err)
+	}
+
+	var lastErr error
+	purged := 0
+	for _, topic := range topics {
+		// 先取出图片地址，数据库删除成功后再清理存储
+		images, err := s.topicRepo.GetImages(ctx, topic.ID)
+		if err != nil {
+			lastErr = err
+			logger.Error("failed to get topic images",
+				logger.Any("error", err),
+				logger.Uint64("topic_id", topic.ID))
+			continue
+		}
+
+		// 每个话题单独事务删除
+		if err := s.topicRepo.HardDelete(ctx, topic.ID); err != nil {
+			lastErr = err
+			logger.Error("failed to purge topic",
+				logger.Any("error", err),
+				logger.Uint64("topic_id", topic.ID))
+			continue
+		}
+		purged++
+
+		for _, image := range images {
+			if err := s.storage.DeleteFile(ctx, image.ImageURL); err != nil {
+				logger.Warn("failed to delete topic image file",
+					logger.Any("error", err),
+					logger.Uint64("topic_id", topic.ID),
+					logger.String("url", image.ImageURL))
+			}
+		}
+
+		if err := cache.RemoveTopicCache(topic.ID); err != nil {
+			logger.Warn("failed to delete topic cache", logger.Any("error", err))
+		}
+	}
+
+	if purged == 0 && lastErr != nil {
+		return 0, fmt.Errorf("failed to purge closed topics: %w", lastErr)
+	}
+
+	return purged, nil
+}
+
 // GetTopicByID 获取话题详情
 func (s *TopicService) GetTopicByID(ctx context.Context, topicID uint64) (*model.Topic, error) {
 	// 尝试从缓存获取
@@ -193,46 +463,109 @@ func (s *TopicService) GetTopicByID(ctx context.Context, topicID uint64) (*model
 		return nil, nil
 	}
 
-	// 缓存话题信息
-	if err := cache.Set(cacheKey, topic, cache.DefaultExpiration); err != nil {
+	// 缓存话题信息，刚失效的话题不回填
+	if _, err := cache.SetIfFresh(cacheKey, topic, cache.DefaultExpiration); err != nil {
 		logger.Warn("failed to cache topic", logger.Any("error", err))
 	}
 
 	return topic, nil
 }
 
-// ViewTopic 查看话题（增加浏览次数）
-func (s *TopicService) ViewTopic(ctx context.Context, topicID uint64) error {
+// ResolveTopicImages 将话题图片替换为客户端可访问的地址，私有目录的图片为签名地址
+// GetTopicByID 返回的话题也用于内部校验，返回给客户端前需调用
+func (s *TopicService) ResolveTopicImages(ctx context.Context, topics ...*model.Topic) {
+	resolveTopicImages(ctx, s.storage, topics...)
+}
+
+// AutoCountViews 获取话题详情时是否自动增加浏览次数
+func (s *TopicService) AutoCountViews() bool {
+	return s.config.ViewMode == TopicViewModeAuto
+}
+
+// ViewTopic 查看话题（增加浏览次数），返回是否实际计数
+// viewerID 非 0 时，同一用户在去重窗口内重复浏览只计一次
+func (s *TopicService) ViewTopic(ctx context.Context, viewerID, topicID uint64) (bool, error) {
+	// 去重检查，缓存不可用时照常计数
+	if viewerID != 0 && s.config.ViewDedupWindow > 0 {
+		first, err := cache.SetNX(cache.TopicViewerKey(topicID, viewerID), 1, s.config.ViewDedupWindow)
+		if err != nil {
+			logger.Warn("failed to check topic view dedup", logger.Any("error", err))
+		} else if !first {
+			return false, nil
+		}
+	}
+
 	// 增加浏览次数
 	if err := s.topicRepo.IncrementViewCount(ctx, topicID); err != nil {
-		return fmt.Errorf("failed to increment view count: %w", err)
+		return false, fmt.Errorf("failed to increment view count: %w", err)
 	}
 
-	// 清除缓存
+	// 浏览是高频操作，直接更新缓存中的浏览数而不是清除缓存，避免热门话题始终无法命中缓存
+	// 并发浏览可能导致缓存计数略少，缓存过期或话题更新后以数据库为准
 	cacheKey := cache.TopicKey(topicID)
-	if err := cache.Delete(cacheKey); err != nil {
-		logger.Warn("failed to delete topic cache", logger.Any("error", err))
+	var cachedTopic model.Topic
+	if err := cache.Get(cacheKey, &cachedTopic); err == nil {
+		cachedTopic.ViewsCount++
+		if _, err := cache.Replace(cacheKey, &cachedTopic); err != nil {
+			logger.Warn("failed to update cached view count", logger.Any("error", err))
+		}
 	}
 
-	return nil
+	return true, nil
 }
 
 // ListTopics 获取话题列表
-func (s *TopicService) ListTopics(ctx context.Context, page, pageSize int) ([]*model.Topic, int64, error) {
+func (s *TopicService) ListTopics(ctx context.Context, filter TopicListFilter, page, pageSize int) ([]*model.Topic, int64, error) {
 	offset := (page - 1) * pageSize
-	return s.topicRepo.List(ctx, offset, pageSize)
+	opts := repository.TopicListOptions{
+		SortBy:      topicSortOrDefault(filter.SortBy, s.config.DefaultSort.List),
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+	}
+	topics, total, err := s.topicRepo.List(ctx, opts, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	resolveTopicImages(ctx, s.storage, topics...)
+	return topics, total, nil
 }
 
 // ListUserTopics 获取用户的话题列表
-func (s *TopicService) ListUserTopics(ctx context.Context, userID uint64, page, pageSize int) ([]*model.Topic, int64, error) {
+func (s *TopicService) ListUserTopics(ctx context.Context, userID uint64, filter TopicListFilter, page, pageSize int) ([]*model.Topic, int64, error) {
+	offset := (page - 1) * pageSize
+	opts := repository.TopicListOptions{
+		SortBy:          topicSortOrDefault(filter.SortBy, s.config.DefaultSort.User),
+		CreatedFrom:     filter.CreatedFrom,
+		CreatedTo:       filter.CreatedTo,
+		IncludeInactive: filter.IncludeInactive,
+	}
+	topics, total, err := s.topicRepo.ListByUser(ctx, userID, opts, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	resolveTopicImages(ctx, s.storage, topics...)
+	return topics, total, nil
+}
+
+// SearchTopics 搜索话题
+func (s *TopicService) SearchTopics(ctx context.Context, keyword, by string, page, pageSize int) ([]*model.Topic, int64, error) {
 	offset := (page - 1) * pageSize
-	return s.topicRepo.ListByUser(ctx, userID, offset, pageSize)
+	return s.topicRepo.Search(ctx, keyword, by, offset, pageSize)
 }
 
 // GetNearbyTopics 获取附近的话题
-func (s *TopicService) GetNearbyTopics(ctx context.Context, lat, lng float64, radius float64, page, pageSize int) ([]*model.Topic, int64, error) {
+func (s *TopicService) GetNearbyTopics(ctx context.Context, lat, lng float64, radius float64, sortBy string, activeWithinDays, page, pageSize int) ([]*model.Topic, int64, error) {
 	offset := (page - 1) * pageSize
-	return s.topicRepo.GetNearbyTopics(ctx, lat, lng, radius, offset, pageSize)
+	opts := repository.TopicListOptions{
+		SortBy:      topicSortOrDefault(sortBy, s.config.DefaultSort.Nearby),
+		ActiveSince: nearbyActiveSince(activeWithinDays, s.nearby.ActiveWithin, time.Now()),
+	}
+	topics, total, err := s.topicRepo.GetNearbyTopics(ctx, lat, lng, radius, opts, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	resolveTopicImages(ctx, s.storage, topics...)
+	return topics, total, nil
 }
 
 // AddInteraction 添加话题互动（点赞、收藏、分享）
@@ -277,15 +610,218 @@ func (s *TopicService) GetInteractions(ctx context.Context, topicID uint64, inte
 	return s.topicRepo.GetInteractions(ctx, topicID, interactionType)
 }
 
-// CleanExpiredTopics 清理过期话题
-func (s *TopicService) CleanExpiredTopics(ctx context.Context) error {
-	// 这个方法可以定期调用，用于清理过期的话题
-	// 实现略...
-	return nil
+// GetLikers 获取话题的点赞用户，按点赞时间倒序游标分页
+// cursor 为上一页返回的 NextCursor，首页传 0；不返回与 viewerID 互相拉黑的用户
+func (s *TopicService) GetLikers(ctx context.Context, viewerID, topicID, cursor uint64, limit int) (*LikerPage, error) {
+	if limit <= 0 {
+		limit = DefaultLikerLimit
+	}
+	if limit > MaxLikerLimit {
+		limit = MaxLikerLimit
+	}
+
+	topic, err := s.GetTopicByID(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	if topic == nil {
+		return nil, ErrTopicNotFound
+	}
+
+	// 多取一条用于判断是否还有下一页
+	interactions, err := s.topicRepo.ListLikers(ctx, topicID, viewerID, cursor, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list likers: %w", err)
+	}
+
+	page := &LikerPage{Users: make([]*model.User, 0, len(interactions))}
+	if len(interactions) > limit {
+		interactions = interactions[:limit]
+		page.HasMore = true
+		page.NextCursor = interactions[len(interactions)-1].ID
+	}
+	for _, interaction := range interactions {
+		user := interaction.User
+		page.Users = append(page.Users, &user)
+	}
+	return page, nil
+}
+
+// PinTopicToProfile 将自己发布的有效话题置顶到个人主页
+func (s *TopicService) PinTopicToProfile(ctx context.Context, userID, topicID uint64) error {
+	topic, err := s.GetTopicByID(ctx, topicID)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		return ErrTopicNotFound
+	}
+	if topic.UserID != userID {
+		return ErrForbidden
+	}
+	if topic.Status != "active" {
+		return ErrInvalidTopicStatus
+	}
+	if topic.IsExpired(time.Now()) {
+		return ErrTopicExpired
+	}
+
+	// 检查置顶数量上限，已置顶的话题不重复计数
+	if limit := s.config.MaxProfilePins; limit > 0 {
+		pinned, err := s.topicRepo.ListProfilePinned(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get pinned topics: %w", err)
+		}
+		count := 0
+		for _, t := range pinned {
+			if t.ID != topicID {
+				count++
+			}
+		}
+		if count >= limit {
+			return ErrTopicPinLimitExceeded
+		}
+	}
+
+	return s.topicRepo.PinToProfile(ctx, userID, topicID)
+}
+
+// UnpinTopicFromProfile 取消个人主页置顶
+func (s *TopicService) UnpinTopicFromProfile(ctx context.Context, userID, topicID uint64) error {
+	return s.topicRepo.UnpinFromProfile(ctx, userID, topicID)
+}
+
+// GetProfilePinnedTopics 获取用户主页置顶的话题
+func (s *TopicService) GetProfilePinnedTopics(ctx context.Context, userID uint64) ([]*model.Topic, error) {
+	topics, err := s.topicRepo.ListProfilePinned(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resolveTopicImages(ctx, s.storage, topics...)
+	return topics, nil
+}
+
+// ListInteractedTopics 获取用户点赞、收藏或分享过的有效话题，按互动时间倒序
+// cursor 为上一页返回的 NextCursor，首页传 0
+func (s *TopicService) ListInteractedTopics(ctx context.Context, userID uint64, interactionType string, cursor uint64, limit int) (*TopicPage, error) {
+	if !isValidInteractionType(interactionType) {
+		return nil, ErrInvalidInteraction
+	}
+	if limit <= 0 {
+		limit = DefaultLikerLimit
+	}
+	if limit > MaxLikerLimit {
+		limit = MaxLikerLimit
+	}
+
+	// 多取一条用于判断是否还有下一页
+	interactions, err := s.topicRepo.ListInteractedByUser(ctx, userID, interactionType, cursor, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interacted topics: %w", err)
+	}
+
+	page := &TopicPage{Topics: make([]*model.Topic, 0, len(interactions))}
+	if len(interactions) > limit {
+		interactions = interactions[:limit]
+		page.HasMore = true
+		page.NextCursor = interactions[len(interactions)-1].ID
+	}
+	for _, interaction := range interactions {
+		topic := interaction.Topic
+		page.Topics = append(page.Topics, &topic)
+	}
+	return page, nil
+}
+
+// GetUserInteractions 获取用户在话题上的互动状态
+func (s *TopicService) GetUserInteractions(ctx context.Context, userID, topicID uint64) ([]*model.TopicInteraction, error) {
+	return s.topicRepo.GetUserInteractions(ctx, topicID, userID)
+}
+
+// CleanExpiredTopics 关闭已过期的话题，永久话题不受影响
+// 该方法应定期调用，返回本次关闭的话题数
+func (s *TopicService) CleanExpiredTopics(ctx context.Context) (int64, error) {
+	return s.topicRepo.CloseExpired(ctx, time.Now())
 }
 
 // 辅助函数
 
+// checkCreateLimit 检查用户发布话题的频率，商家/官方账号上限更高
+// Redis 不可用时有意放行（fail open）：该限制只用于防刷，
+// 若拒绝则 Redis 故障期间所有用户都无法发布，代价高于短时间内漏过少量刷帖；
+// 放行时记录错误日志以便发现故障
+func (s *TopicService) checkCreateLimit(ctx context.Context, user *model.User) error {
+	limitCfg := s.config.CreateLimit
+	if limitCfg.Window <= 0 {
+		return nil
+	}
+
+	limit := limitCfg.Limit
+	switch constants.UserType(user.UserType) {
+	case constants.UserTypeMerchant, constants.UserTypeOfficial, constants.UserTypeAdmin:
+		limit = limitCfg.VerifiedLimit
+	}
+
+	allowed, retryAfter, err := ratelimit.Allow(ctx, cache.TopicCreateLimitKey(user.ID), limit, limitCfg.Window)
+	if err != nil {
+		logger.Error("检查发布频率失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", user.ID))
+		return nil
+	}
+	if !allowed {
+		return errors.NewRateLimited(retryAfter)
+	}
+	return nil
+}
+
+// canPostPermanent 检查用户是否可以发布永久话题
+func (s *TopicService) canPostPermanent(user *model.User) bool {
+	for _, userType := range s.config.PermanentUserTypes {
+		if user.UserType == userType {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultExpiration 获取默认有效期
+func (s *TopicService) defaultExpiration() time.Duration {
+	if s.config.DefaultExpiration > 0 {
+		return s.config.DefaultExpiration
+	}
+	return 24 * time.Hour
+}
+
+// validateExpiresAt 校验过期时间，普通用户不能超过最长有效期
+func (s *TopicService) validateExpiresAt(user *model.User, expiresAt time.Time) error {
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return NewError(CodeInvalidRequest, "expires_at must be in the future").
+			WithStatus(http.StatusBadRequest)
+	}
+	if s.canPostPermanent(user) || s.config.MaxExpiration <= 0 {
+		return nil
+	}
+	if expiresAt.After(now.Add(s.config.MaxExpiration)) {
+		return NewError(CodeInvalidRequest, "expires_at exceeds the maximum allowed duration").
+			WithStatus(http.StatusBadRequest).
+			WithDetails(map[string]string{"max_expiration": s.config.MaxExpiration.String()})
+	}
+	return nil
+}
+
+// topicSortOrDefault 未指定排序时使用接口默认排序，无效值按时间排序
+func topicSortOrDefault(sortBy, defaultSort string) string {
+	if sortBy == "" {
+		sortBy = defaultSort
+	}
+	if sortBy != model.TopicSortPopular {
+		return model.TopicSortRecent
+	}
+	return sortBy
+}
+
 // isValidInteractionType 检查互动类型是否有效
 func isValidInteractionType(interactionType string) bool {
 	validTypes := map[string]bool{
@@ -358,6 +894,63 @@ func (s *TopicService) GetPopularTags(ctx context.Context, limit int) ([]*model.
 	return tags, nil
 }
 
+// GetTopicParticipants 获取话题参与者预览
+// 查看者是话题关联群聊的成员时返回群成员，否则返回最近互动的用户，避免泄露群成员
+func (s *TopicService) GetTopicParticipants(ctx context.Context, topicID, viewerID uint64, limit int) ([]*model.User, error) {
+	if limit <= 0 || limit > MaxTopicParticipants {
+		limit = MaxTopicParticipants
+	}
+
+	if viewerID != 0 {
+		users, err := s.topicRepo.ListRoomParticipants(ctx, topicID, viewerID, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list room participants: %w", err)
+		}
+		if len(users) > 0 {
+			return users, nil
+		}
+	}
+
+	users, err := s.topicRepo.ListRecentInteractors(ctx, topicID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topic interactors: %w", err)
+	}
+	return users, nil
+}
+
+// GetTopicStats 获取话题最近 days 天（含今天）的每日统计
+// 没有记录的日期补零，结果按日期升序
+func (s *TopicService) GetTopicStats(ctx context.Context, topicID uint64, days int) ([]*model.TopicDailyStat, error) {
+	if days <= 0 {
+		days = DefaultTopicStatsDays
+	}
+	if days > MaxTopicStatsDays {
+		days = MaxTopicStatsDays
+	}
+
+	since := model.StatDay(time.Now()).AddDate(0, 0, -(days - 1))
+	stats, err := s.topicRepo.GetDailyStats(ctx, topicID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic daily stats: %w", err)
+	}
+
+	byDate := make(map[string]*model.TopicDailyStat, len(stats))
+	for _, stat := range stats {
+		byDate[stat.StatDate.Format("2006-01-02")] = stat
+	}
+
+	series := make([]*model.TopicDailyStat, 0, days)
+	for i := 0; i < days; i++ {
+		day := since.AddDate(0, 0, i)
+		if stat, ok := byDate[day.Format("2006-01-02")]; ok {
+			series = append(series, stat)
+			continue
+		}
+		series = append(series, &model.TopicDailyStat{TopicID: topicID, StatDate: day})
+	}
+	return series, nil
+}
+
 // AddTopicImage 添加话题图片
 func (s *TopicService) AddTopicImage(ctx context.Context, topicID uint64, images []*model.File) error {
 	if len(images) == 0 {
@@ -373,38 +966,29 @@ func (s *TopicService) AddTopicImage(ctx context.Context, topicID uint64, images
 		return ErrTopicNotFound
 	}
 
-	topicImages := make([]*model.TopicImage, 0, len(images))
-	for i, img := range images {
-		// 上传图片
-		fileURL, err := s.storage.UploadFile(ctx, img.File, storage.TopicDirectory)
-		if err != nil {
-			logger.Error("failed to upload topic image",
-				logger.Any("error", err),
-				logger.Uint64("topic_id", topicID),
-				logger.Int("image_index", i))
-			continue
-		}
+	existing, err := s.topicRepo.GetImages(ctx, topicID)
+	if err != nil {
+		return err
+	}
+	if len(existing)+len(images) > storage.MaxTopicImages {
+		return ErrTooManyImages
+	}
 
-		// 创建图片记录，注意类型转换
-		topicImage := &model.TopicImage{
-			TopicID:     topicID,
-			ImageURL:    fileURL,
-			SortOrder:   uint(i),
-			ImageWidth:  uint(img.Width),  // 将int转换为uint
-			ImageHeight: uint(img.Height), // 将int转换为uint
-			FileSize:    img.Size,
-		}
-		topicImages = append(topicImages, topicImage)
+	// 上传图片，任一失败则整体失败
+	uploaded, _, err := uploadMediaFiles(ctx, s.storage, images, storage.TopicDirectory, MediaFailureStrict, s.concurrency)
+	if err != nil {
+		return err
 	}
 
 	// 保存图片记录
-	if err := s.topicRepo.AddImages(ctx, topicID, topicImages); err != nil {
+	if err := s.topicRepo.AddImages(ctx, topicID, newTopicImages(topicID, uploaded)); err != nil {
+		deleteUploadedMedia(ctx, s.storage, uploaded)
 		return fmt.Errorf("failed to save topic images: %w", err)
 	}
 
 	// 清除缓存
 	cacheKey := cache.TopicKey(topicID)
-	if err := cache.Delete(cacheKey); err != nil {
+	if err := cache.Invalidate(cacheKey); err != nil {
 		logger.Warn("failed to delete topic cache",
 			logger.Any("error", err),
 			logger.Uint64("topic_id", topicID))
@@ -413,6 +997,22 @@ func (s *TopicService) AddTopicImage(ctx context.Context, topicID uint64, images
 	return nil
 }
 
+// newTopicImages 根据上传结果创建图片记录，排序沿用请求中的顺序
+func newTopicImages(topicID uint64, uploaded []uploadedMedia) []*model.TopicImage {
+	topicImages := make([]*model.TopicImage, 0, len(uploaded))
+	for _, m := range uploaded {
+		topicImages = append(topicImages, &model.TopicImage{
+			TopicID:     topicID,
+			ImageURL:    m.URL,
+			SortOrder:   uint(m.Index),
+			ImageWidth:  uint(m.File.Width),  // 将int转换为uint
+			ImageHeight: uint(m.File.Height), // 将int转换为uint
+			FileSize:    m.File.Size,
+		})
+	}
+	return topicImages
+}
+
 // validateImage 验证图片
 func (s *TopicService) validateImage(image *model.File) error {
 	// 验证文件类型
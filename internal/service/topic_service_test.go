@@ -0,0 +1,702 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/constants"
+	"DistanceBack_v1/pkg/errors"
+	"DistanceBack_v1/pkg/storage"
+)
+
+func newTestTopic(id, userID uint64, status string) *model.Topic {
+	t := &model.Topic{UserID: userID, Title: "topic", Status: status}
+	t.ID = id
+	return t
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestPurgeMyClosedTopicsKeepsRestorableTopics(t *testing.T) {
+	resetCache(t)
+	now := time.Now()
+
+	recentlyClosed := newTestTopic(1, 7, model.TopicStatusClosed)
+	recentlyClosed.ClosedAt = timePtr(now.Add(-time.Hour))
+	longClosed := newTestTopic(2, 7, model.TopicStatusClosed)
+	longClosed.ClosedAt = timePtr(now.Add(-48 * time.Hour))
+	// 过期后已被定时任务关闭的话题，关闭时间超过宽限期
+	expiredClosed := newTestTopic(3, 7, model.TopicStatusClosed)
+	expiredClosed.ExpiresAt = timePtr(now.Add(-72 * time.Hour))
+	expiredClosed.ClosedAt = timePtr(now.Add(-48 * time.Hour))
+	// 已过期但尚未被关闭的话题要等关闭后的宽限期结束
+	expiredActive := newTestTopic(4, 7, model.TopicStatusActive)
+	expiredActive.ExpiresAt = timePtr(now.Add(-time.Minute))
+	otherUser := newTestTopic(5, 8, model.TopicStatusClosed)
+	otherUser.ClosedAt = timePtr(now.Add(-48 * time.Hour))
+	// 管理员下架的话题保留以便复核
+	removed := newTestTopic(6, 7, model.TopicStatusCancelled)
+	removed.ClosedAt = timePtr(now.Add(-48 * time.Hour))
+
+	repo := newFakeTopicRepo(recentlyClosed, longClosed, expiredClosed, expiredActive, otherUser, removed)
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{RestoreWindow: 24 * time.Hour}, config.NearbyConfig{}, config.UploadConfig{})
+
+	purged, err := svc.PurgeMyClosedTopics(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("PurgeMyClosedTopics: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("purged = %d, want 2", purged)
+	}
+
+	sort.Slice(repo.hardDeleted, func(i, j int) bool { return repo.hardDeleted[i] < repo.hardDeleted[j] })
+	if len(repo.hardDeleted) != 2 || repo.hardDeleted[0] != 2 || repo.hardDeleted[1] != 3 {
+		t.Errorf("hard deleted = %v, want [2 3]", repo.hardDeleted)
+	}
+
+	wantCutoff := now.Add(-24 * time.Hour)
+	if diff := repo.closedBefore.Sub(wantCutoff); diff < 0 || diff > time.Minute {
+		t.Errorf("closedBefore = %v, want about %v", repo.closedBefore, wantCutoff)
+	}
+}
+
+func TestPurgeMyClosedTopicsWithoutRestoreWindow(t *testing.T) {
+	resetCache(t)
+	closed := newTestTopic(1, 7, model.TopicStatusClosed)
+	closed.ClosedAt = timePtr(time.Now().Add(-time.Second))
+
+	repo := newFakeTopicRepo(closed)
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+
+	purged, err := svc.PurgeMyClosedTopics(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("PurgeMyClosedTopics: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("purged = %d, want 1 when topics cannot be restored", purged)
+	}
+}
+
+func TestListTopicsUsesPerEndpointDefaultSort(t *testing.T) {
+	repo := newFakeTopicRepo()
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{DefaultSort: config.TopicSortConfig{
+			List:   model.TopicSortPopular,
+			User:   model.TopicSortRecent,
+			Nearby: "bogus",
+		}}, config.NearbyConfig{}, config.UploadConfig{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func(sortBy string) error
+		def  string
+	}{
+		{"list", func(sortBy string) error {
+			_, _, err := svc.ListTopics(ctx, TopicListFilter{SortBy: sortBy}, 1, 20)
+			return err
+		}, model.TopicSortPopular},
+		{"user", func(sortBy string) error {
+			_, _, err := svc.ListUserTopics(ctx, 7, TopicListFilter{SortBy: sortBy}, 1, 20)
+			return err
+		}, model.TopicSortRecent},
+		{"nearby", func(sortBy string) error {
+			_, _, err := svc.GetNearbyTopics(ctx, 35, 139, 1000, sortBy, 0, 1, 20)
+			return err
+		}, model.TopicSortRecent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(""); err != nil {
+				t.Fatalf("default sort: %v", err)
+			}
+			if repo.listOpts.SortBy != tt.def {
+				t.Errorf("default SortBy = %q, want %q", repo.listOpts.SortBy, tt.def)
+			}
+			if err := tt.call(model.TopicSortPopular); err != nil {
+				t.Fatalf("explicit sort: %v", err)
+			}
+			if repo.listOpts.SortBy != model.TopicSortPopular {
+				t.Errorf("explicit SortBy = %q, want %q", repo.listOpts.SortBy, model.TopicSortPopular)
+			}
+		})
+	}
+}
+
+func TestCheckCreateLimit(t *testing.T) {
+	cfg := config.TopicConfig{CreateLimit: config.TopicLimitConfig{Window: time.Hour, Limit: 2, VerifiedLimit: 3}}
+	svc := NewTopicService(newFakeTopicRepo(), newFakeUserRepo(), nil, &fakeStorage{}, cfg, config.NearbyConfig{}, config.UploadConfig{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		userType string
+		allowed  int
+	}{
+		{"individual", string(constants.UserTypeIndividual), 2},
+		{"merchant", string(constants.UserTypeMerchant), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCache(t)
+			user := &model.User{UserType: tt.userType}
+			user.ID = 7
+			for i := 0; i < tt.allowed; i++ {
+				if err := svc.checkCreateLimit(ctx, user); err != nil {
+					t.Fatalf("create %d: %v", i+1, err)
+				}
+			}
+			err := svc.checkCreateLimit(ctx, user)
+			if seconds, ok := errors.RetryAfterSeconds(err); !ok || seconds < 1 {
+				t.Fatalf("over limit err = %v, want rate limited with retry-after", err)
+			}
+		})
+	}
+}
+
+func TestCheckCreateLimitFailsOpenWhenRedisDown(t *testing.T) {
+	resetCache(t)
+	testRedis.SetError("LOADING")
+	defer testRedis.SetError("")
+
+	cfg := config.TopicConfig{CreateLimit: config.TopicLimitConfig{Window: time.Hour, Limit: 1}}
+	svc := NewTopicService(newFakeTopicRepo(), newFakeUserRepo(), nil, &fakeStorage{}, cfg, config.NearbyConfig{}, config.UploadConfig{})
+	user := &model.User{}
+	user.ID = 7
+	if err := svc.checkCreateLimit(context.Background(), user); err != nil {
+		t.Fatalf("checkCreateLimit with Redis down = %v, want nil", err)
+	}
+}
+
+func TestCreateTopicExpiration(t *testing.T) {
+	resetCache(t)
+	individual := &model.User{Status: model.UserStatusActive, UserType: string(constants.UserTypeIndividual)}
+	individual.ID = 7
+	merchant := &model.User{Status: model.UserStatusActive, UserType: string(constants.UserTypeMerchant)}
+	merchant.ID = 8
+
+	cfg := config.TopicConfig{
+		DefaultExpiration:  24 * time.Hour,
+		MaxExpiration:      7 * 24 * time.Hour,
+		PermanentUserTypes: []string{string(constants.UserTypeMerchant)},
+	}
+	svc := NewTopicService(newFakeTopicRepo(), newFakeUserRepo(individual, merchant), nil, &fakeStorage{},
+		cfg, config.NearbyConfig{}, config.UploadConfig{})
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		user        *model.User
+		permanent   bool
+		expiresAt   *time.Time
+		wantErr     bool
+		wantExpires time.Duration // 0 表示永久
+	}{
+		{"merchant permanent", merchant, true, nil, false, 0},
+		{"individual permanent", individual, true, nil, true, 0},
+		{"default expiration", individual, false, nil, false, 24 * time.Hour},
+		{"within max", individual, false, timePtr(now.Add(48 * time.Hour)), false, 48 * time.Hour},
+		{"beyond max", individual, false, timePtr(now.Add(30 * 24 * time.Hour)), true, 0},
+		{"merchant beyond max", merchant, false, timePtr(now.Add(30 * 24 * time.Hour)), false, 30 * 24 * time.Hour},
+		{"in the past", individual, false, timePtr(now.Add(-time.Hour)), true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic := &model.Topic{Title: tt.name, ExpiresAt: tt.expiresAt}
+			created, err := svc.CreateTopic(context.Background(), tt.user.ID, topic, tt.permanent, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreateTopic succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateTopic: %v", err)
+			}
+			if tt.wantExpires == 0 {
+				if created.ExpiresAt != nil {
+					t.Errorf("ExpiresAt = %v, want permanent", *created.ExpiresAt)
+				}
+				if created.IsExpired(now.Add(100 * 365 * 24 * time.Hour)) {
+					t.Error("permanent topic reported as expired")
+				}
+				return
+			}
+			if created.ExpiresAt == nil {
+				t.Fatal("ExpiresAt = nil, want expiring topic")
+			}
+			if d := created.ExpiresAt.Sub(now) - tt.wantExpires; d < -time.Minute || d > time.Minute {
+				t.Errorf("ExpiresAt = %v, want about now+%v", *created.ExpiresAt, tt.wantExpires)
+			}
+			if !created.IsExpired(created.ExpiresAt.Add(time.Second)) {
+				t.Error("topic not expired after ExpiresAt")
+			}
+		})
+	}
+}
+
+func TestCreateTopicRejectsTooManyImages(t *testing.T) {
+	resetCache(t)
+	user := &model.User{Status: model.UserStatusActive}
+	user.ID = 7
+	svc := NewTopicService(newFakeTopicRepo(), newFakeUserRepo(user), nil, &fakeStorage{},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+
+	images := make([]*model.File, storage.MaxTopicImages+1)
+	for i := range images {
+		images[i] = &model.File{Type: "image", Name: "a.jpg"}
+	}
+	if _, err := svc.CreateTopic(context.Background(), user.ID, &model.Topic{Title: "t"}, false, images); err != ErrTooManyImages {
+		t.Fatalf("err = %v, want ErrTooManyImages", err)
+	}
+}
+
+func TestGetTopicParticipantsOnlyShowsRoomToMembers(t *testing.T) {
+	roomMember := &model.User{Nickname: "member"}
+	roomMember.ID = 20
+	interactor := &model.User{Nickname: "liker"}
+	interactor.ID = 30
+
+	tests := []struct {
+		name        string
+		viewerID    uint64
+		wantID      uint64
+		wantQueries int
+	}{
+		{"room member sees members", 20, 20, 1},
+		{"non-member falls back to interactors", 8, 30, 1},
+		{"anonymous skips room query", 0, 30, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeTopicRepo(newTestTopic(1, 7, model.TopicStatusActive))
+			repo.participants = []*model.User{roomMember}
+			repo.roomViewers = map[uint64]bool{20: true}
+			repo.interactors = []*model.User{interactor}
+			svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+				config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+
+			users, err := svc.GetTopicParticipants(context.Background(), 1, tt.viewerID, 8)
+			if err != nil {
+				t.Fatalf("GetTopicParticipants: %v", err)
+			}
+			if len(users) != 1 || users[0].ID != tt.wantID {
+				t.Errorf("participants = %+v, want user %d", users, tt.wantID)
+			}
+			if repo.participantQueries != tt.wantQueries {
+				t.Errorf("room queries = %d, want %d", repo.participantQueries, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestViewTopicKeepsTopicCached(t *testing.T) {
+	resetCache(t)
+	repo := newFakeTopicRepo(newTestTopic(1, 7, model.TopicStatusActive))
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+	ctx := context.Background()
+
+	if _, err := svc.GetTopicByID(ctx, 1); err != nil {
+		t.Fatalf("GetTopicByID: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := svc.ViewTopic(ctx, 0, 1); err != nil {
+			t.Fatalf("ViewTopic: %v", err)
+		}
+	}
+
+	var cached model.Topic
+	if err := cache.Get(cache.TopicKey(1), &cached); err != nil {
+		t.Fatalf("topic cache dropped by views: %v", err)
+	}
+	if cached.ViewsCount != 3 {
+		t.Errorf("cached views = %d, want 3", cached.ViewsCount)
+	}
+	if ttl := testRedis.TTL(cache.TopicKey(1)); ttl <= 0 {
+		t.Errorf("cache ttl = %v, want the original expiration kept", ttl)
+	}
+}
+
+func TestViewTopicDoesNotCreateCache(t *testing.T) {
+	resetCache(t)
+	repo := newFakeTopicRepo(newTestTopic(1, 7, model.TopicStatusActive))
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+
+	if _, err := svc.ViewTopic(context.Background(), 0, 1); err != nil {
+		t.Fatalf("ViewTopic: %v", err)
+	}
+	if testRedis.Exists(cache.TopicKey(1)) {
+		t.Error("view created a topic cache entry")
+	}
+}
+
+func TestGetTopicStatsZeroFillsMissingDays(t *testing.T) {
+	today := model.StatDay(time.Now())
+	repo := newFakeTopicRepo(newTestTopic(1, 7, model.TopicStatusActive))
+	repo.dailyStats = []*model.TopicDailyStat{
+		{TopicID: 1, StatDate: today, Views: 5, Likes: 1},
+		{TopicID: 1, StatDate: today.AddDate(0, 0, -2), Views: 3},
+		{TopicID: 1, StatDate: today.AddDate(0, 0, -30), Views: 100}, // 超出范围
+		{TopicID: 2, StatDate: today, Views: 9},                      // 其他话题
+	}
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+
+	series, err := svc.GetTopicStats(context.Background(), 1, 7)
+	if err != nil {
+		t.Fatalf("GetTopicStats: %v", err)
+	}
+	if len(series) != 7 {
+		t.Fatalf("len = %d, want 7", len(series))
+	}
+	wantViews := []uint{0, 0, 0, 0, 3, 0, 5}
+	for i, stat := range series {
+		wantDate := today.AddDate(0, 0, i-6)
+		if !stat.StatDate.Equal(wantDate) {
+			t.Errorf("series[%d] date = %v, want %v", i, stat.StatDate, wantDate)
+		}
+		if stat.TopicID != 1 || stat.Views != wantViews[i] {
+			t.Errorf("series[%d] = %+v, want %d views", i, stat, wantViews[i])
+		}
+	}
+	if series[6].Likes != 1 {
+		t.Errorf("today likes = %d, want 1", series[6].Likes)
+	}
+}
+
+func TestGetTopicStatsClampsDays(t *testing.T) {
+	repo := newFakeTopicRepo(newTestTopic(1, 7, model.TopicStatusActive))
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+
+	tests := []struct {
+		days int
+		want int
+	}{
+		{0, DefaultTopicStatsDays},
+		{-3, DefaultTopicStatsDays},
+		{1, 1},
+		{MaxTopicStatsDays + 10, MaxTopicStatsDays},
+	}
+	for _, tt := range tests {
+		series, err := svc.GetTopicStats(context.Background(), 1, tt.days)
+		if err != nil {
+			t.Fatalf("GetTopicStats(%d): %v", tt.days, err)
+		}
+		if len(series) != tt.want {
+			t.Errorf("GetTopicStats(%d) len = %d, want %d", tt.days, len(series), tt.want)
+		}
+	}
+}
+
+func TestGetLikersPaginatesNewestFirst(t *testing.T) {
+	resetCache(t)
+	repo := newFakeTopicRepo(newTestTopic(1, 7, model.TopicStatusActive))
+	for i := uint64(1); i <= 5; i++ {
+		like := &model.TopicInteraction{
+			TopicID:           1,
+			UserID:            100 + i,
+			InteractionType:   model.InteractionTypeLike,
+			InteractionStatus: model.InteractionStatusActive,
+		}
+		like.ID = i
+		like.User.ID = 100 + i
+		repo.likes = append(repo.likes, like)
+	}
+	// 已取消的点赞不返回
+	repo.likes[2].InteractionStatus = model.InteractionStatusCancelled
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+	ctx := context.Background()
+
+	first, err := svc.GetLikers(ctx, 7, 1, 0, 2)
+	if err != nil {
+		t.Fatalf("GetLikers: %v", err)
+	}
+	if len(first.Users) != 2 || first.Users[0].ID != 105 || first.Users[1].ID != 104 {
+		t.Fatalf("first page = %+v, want users 105, 104", first.Users)
+	}
+	if !first.HasMore || first.NextCursor != 4 {
+		t.Errorf("first page has_more = %v, cursor = %d; want true, 4", first.HasMore, first.NextCursor)
+	}
+
+	second, err := svc.GetLikers(ctx, 7, 1, first.NextCursor, 2)
+	if err != nil {
+		t.Fatalf("GetLikers: %v", err)
+	}
+	if len(second.Users) != 2 || second.Users[0].ID != 102 || second.Users[1].ID != 101 {
+		t.Fatalf("second page = %+v, want users 102, 101", second.Users)
+	}
+	if second.HasMore || second.NextCursor != 0 {
+		t.Errorf("second page has_more = %v, cursor = %d; want last page", second.HasMore, second.NextCursor)
+	}
+
+	if _, err := svc.GetLikers(ctx, 7, 99, 0, 2); err != ErrTopicNotFound {
+		t.Errorf("missing topic err = %v, want ErrTopicNotFound", err)
+	}
+}
+
+func TestTransferTopicInvalidatesOwnerCaches(t *testing.T) {
+	resetCache(t)
+	newOwner := &model.User{Nickname: "ren"}
+	newOwner.ID = 8
+	repo := newFakeTopicRepo(newTestTopic(1, 7, model.TopicStatusActive))
+	svc := NewTopicService(repo, newFakeUserRepo(newOwner), nil, &fakeStorage{},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+	ctx := context.Background()
+
+	// 话题详情及新旧作者的概览均已缓存
+	if _, err := svc.GetTopicByID(ctx, 1); err != nil {
+		t.Fatalf("GetTopicByID: %v", err)
+	}
+	for _, userID := range []uint64{7, 8} {
+		if err := cache.Set(cache.MeOverviewKey(userID), &MeOverview{}, time.Hour); err != nil {
+			t.Fatalf("seed overview: %v", err)
+		}
+	}
+
+	if err := svc.TransferTopic(ctx, 1, 1, 8); err != nil {
+		t.Fatalf("TransferTopic: %v", err)
+	}
+
+	topic, err := svc.GetTopicByID(ctx, 1)
+	if err != nil || topic.UserID != 8 {
+		t.Fatalf("topic after transfer = %+v, %v; want owner 8", topic, err)
+	}
+	for _, userID := range []uint64{7, 8} {
+		if testRedis.Exists(cache.MeOverviewKey(userID)) {
+			t.Errorf("overview cache of user %d not invalidated", userID)
+		}
+	}
+	if len(repo.audits) != 1 || repo.audits[0].OperatorID != 1 || repo.audits[0].Action != model.AuditActionTopicTransfer {
+		t.Errorf("audits = %+v, want one transfer by operator 1", repo.audits)
+	}
+
+	if err := svc.TransferTopic(ctx, 1, 1, 8); err != ErrInvalidRequest {
+		t.Errorf("transfer to current owner err = %v, want ErrInvalidRequest", err)
+	}
+	if err := svc.TransferTopic(ctx, 1, 1, 99); err != ErrUserNotFound {
+		t.Errorf("transfer to missing user err = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestListInteractedTopicsByType(t *testing.T) {
+	resetCache(t)
+	expired := newTestTopic(4, 1, model.TopicStatusActive)
+	expired.ExpiresAt = timePtr(time.Now().Add(-time.Hour))
+	repo := newFakeTopicRepo(
+		newTestTopic(1, 1, model.TopicStatusActive),
+		newTestTopic(2, 1, model.TopicStatusActive),
+		newTestTopic(3, 1, model.TopicStatusClosed),
+		expired,
+	)
+	add := func(id, topicID, userID uint64, interactionType, status string) {
+		in := &model.TopicInteraction{TopicID: topicID, UserID: userID, InteractionType: interactionType, InteractionStatus: status}
+		in.ID = id
+		repo.interactions = append(repo.interactions, in)
+	}
+	active := model.InteractionStatusActive
+	add(1, 1, 7, model.InteractionTypeLike, active)
+	add(2, 2, 7, model.InteractionTypeLike, active)
+	add(3, 3, 7, model.InteractionTypeLike, active) // 已关闭的话题不返回
+	add(4, 4, 7, model.InteractionTypeLike, active) // 已过期的话题不返回
+	add(5, 2, 8, model.InteractionTypeLike, active) // 其他用户的互动不返回
+	add(6, 2, 7, model.InteractionTypeFavorite, active)
+	add(7, 1, 7, model.InteractionTypeFavorite, model.InteractionStatusCancelled)
+	add(8, 1, 7, model.InteractionTypeShare, active)
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+
+	tests := []struct {
+		interactionType string
+		want            []uint64
+	}{
+		// 按互动时间倒序，最近点赞的话题在前
+		{model.InteractionTypeLike, []uint64{2, 1}},
+		{model.InteractionTypeFavorite, []uint64{2}},
+		{model.InteractionTypeShare, []uint64{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.interactionType, func(t *testing.T) {
+			page, err := svc.ListInteractedTopics(context.Background(), 7, tt.interactionType, 0, 10)
+			if err != nil {
+				t.Fatalf("ListInteractedTopics: %v", err)
+			}
+			var got []uint64
+			for _, topic := range page.Topics {
+				got = append(got, topic.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("topics = %v, want %v", got, tt.want)
+			}
+			if page.HasMore {
+				t.Errorf("has_more = true, want false")
+			}
+		})
+	}
+
+	if _, err := svc.ListInteractedTopics(context.Background(), 7, "view", 0, 10); err != ErrInvalidInteraction {
+		t.Errorf("invalid type err = %v, want ErrInvalidInteraction", err)
+	}
+}
+
+func TestListInteractedTopicsPaginates(t *testing.T) {
+	resetCache(t)
+	repo := newFakeTopicRepo()
+	for i := uint64(1); i <= 3; i++ {
+		repo.topics[i] = newTestTopic(i, 1, model.TopicStatusActive)
+		like := &model.TopicInteraction{TopicID: i, UserID: 7, InteractionType: model.InteractionTypeLike, InteractionStatus: model.InteractionStatusActive}
+		like.ID = 10 + i
+		repo.interactions = append(repo.interactions, like)
+	}
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+	ctx := context.Background()
+
+	first, err := svc.ListInteractedTopics(ctx, 7, model.InteractionTypeLike, 0, 2)
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if len(first.Topics) != 2 || !first.HasMore || first.NextCursor != 12 {
+		t.Fatalf("first page = %d topics, has_more %v, cursor %d", len(first.Topics), first.HasMore, first.NextCursor)
+	}
+	second, err := svc.ListInteractedTopics(ctx, 7, model.InteractionTypeLike, first.NextCursor, 2)
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if len(second.Topics) != 1 || second.Topics[0].ID != 1 || second.HasMore {
+		t.Errorf("second page = %+v, has_more %v", second.Topics, second.HasMore)
+	}
+}
+
+func TestPinTopicToProfileEnforcesCap(t *testing.T) {
+	resetCache(t)
+	repo := newFakeTopicRepo(
+		newTestTopic(1, 7, model.TopicStatusActive),
+		newTestTopic(2, 7, model.TopicStatusActive),
+		newTestTopic(3, 7, model.TopicStatusActive),
+	)
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{MaxProfilePins: 2}, config.NearbyConfig{}, config.UploadConfig{})
+	ctx := context.Background()
+
+	for _, id := range []uint64{1, 2} {
+		if err := svc.PinTopicToProfile(ctx, 7, id); err != nil {
+			t.Fatalf("pin topic %d: %v", id, err)
+		}
+	}
+	if err := svc.PinTopicToProfile(ctx, 7, 3); err != ErrTopicPinLimitExceeded {
+		t.Fatalf("pin over cap: err = %v, want ErrTopicPinLimitExceeded", err)
+	}
+	// 重复置顶已置顶的话题不占用名额，只调整顺序
+	if err := svc.PinTopicToProfile(ctx, 7, 1); err != nil {
+		t.Fatalf("re-pin topic 1: %v", err)
+	}
+	if fmt.Sprint(repo.pinned) != "[1 2]" {
+		t.Errorf("pinned = %v, want [1 2]", repo.pinned)
+	}
+}
+
+func TestPinTopicToProfileRequiresOwnActiveTopic(t *testing.T) {
+	resetCache(t)
+	repo := newFakeTopicRepo(
+		newTestTopic(1, 8, model.TopicStatusActive),
+		newTestTopic(2, 7, model.TopicStatusClosed),
+	)
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{MaxProfilePins: 3}, config.NearbyConfig{}, config.UploadConfig{})
+	ctx := context.Background()
+
+	if err := svc.PinTopicToProfile(ctx, 7, 1); err != ErrForbidden {
+		t.Errorf("pin other user's topic: err = %v, want ErrForbidden", err)
+	}
+	if err := svc.PinTopicToProfile(ctx, 7, 2); err != ErrInvalidTopicStatus {
+		t.Errorf("pin closed topic: err = %v, want ErrInvalidTopicStatus", err)
+	}
+	if err := svc.PinTopicToProfile(ctx, 7, 99); err != ErrTopicNotFound {
+		t.Errorf("pin missing topic: err = %v, want ErrTopicNotFound", err)
+	}
+	if len(repo.pinned) != 0 {
+		t.Errorf("pinned = %v, want none", repo.pinned)
+	}
+}
+
+func TestGetProfilePinnedTopicsSignsPrivateImages(t *testing.T) {
+	topic := newTestTopic(1, 7, model.TopicStatusActive)
+	topic.TopicImages = []model.TopicImage{{TopicID: 1, ImageURL: storage.TopicDirectory + "/a.jpg"}}
+	repo := newFakeTopicRepo(topic)
+	repo.pinned = []uint64{1}
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{private: storage.TopicDirectory},
+		config.TopicConfig{}, config.NearbyConfig{}, config.UploadConfig{})
+
+	topics, err := svc.GetProfilePinnedTopics(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetProfilePinnedTopics: %v", err)
+	}
+	if len(topics) != 1 || topics[0].TopicImages[0].ImageURL != storage.TopicDirectory+"/a.jpg?signature=test" {
+		t.Fatalf("topics = %+v, want signed image URL", topics)
+	}
+	// 签名地址只用于响应，不影响仓储中的原地址
+	if got := repo.topics[1].TopicImages[0].ImageURL; got != storage.TopicDirectory+"/a.jpg" {
+		t.Errorf("stored image URL = %q, want original", got)
+	}
+}
+
+func TestDeleteTopicKeepsOriginalClosedAt(t *testing.T) {
+	resetCache(t)
+	closedAt := time.Now().Add(-2 * time.Hour)
+	closed := newTestTopic(1, 7, model.TopicStatusClosed)
+	closed.ClosedAt = timePtr(closedAt)
+	active := newTestTopic(2, 7, model.TopicStatusActive)
+	repo := newFakeTopicRepo(closed, active)
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{RestoreWindow: 24 * time.Hour}, config.NearbyConfig{}, config.UploadConfig{})
+	ctx := context.Background()
+
+	// 重复删除是空操作，不会延长恢复宽限期
+	if err := svc.DeleteTopic(ctx, 7, 1); err != nil {
+		t.Fatalf("delete closed topic: %v", err)
+	}
+	if !repo.topics[1].ClosedAt.Equal(closedAt) {
+		t.Errorf("closed_at = %v, want unchanged %v", repo.topics[1].ClosedAt, closedAt)
+	}
+
+	if err := svc.DeleteTopic(ctx, 7, 2); err != nil {
+		t.Fatalf("delete active topic: %v", err)
+	}
+	if repo.topics[2].Status != model.TopicStatusClosed || repo.topics[2].ClosedAt == nil {
+		t.Errorf("topic 2 = %+v, want closed with closed_at", repo.topics[2])
+	}
+}
+
+func TestRemovedTopicCannotBeDeletedOrRestored(t *testing.T) {
+	resetCache(t)
+	removed := newTestTopic(1, 7, model.TopicStatusCancelled)
+	removed.ClosedAt = timePtr(time.Now().Add(-time.Minute))
+	repo := newFakeTopicRepo(removed)
+	svc := NewTopicService(repo, newFakeUserRepo(), nil, &fakeStorage{},
+		config.TopicConfig{RestoreWindow: 24 * time.Hour}, config.NearbyConfig{}, config.UploadConfig{})
+	ctx := context.Background()
+
+	if err := svc.DeleteTopic(ctx, 7, 1); err != ErrInvalidTopicStatus {
+		t.Errorf("DeleteTopic: err = %v, want ErrInvalidTopicStatus", err)
+	}
+	if _, err := svc.RestoreTopic(ctx, 7, 1); err != ErrInvalidTopicStatus {
+		t.Errorf("RestoreTopic: err = %v, want ErrInvalidTopicStatus", err)
+	}
+	if repo.topics[1].Status != model.TopicStatusCancelled {
+		t.Errorf("status = %q, want cancelled", repo.topics[1].Status)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/logger"
+)
+
+// ShareTopic 将话题以消息形式分享到聊天室，并记录分享互动
+func (s *ChatService) ShareTopic(ctx context.Context, userID, roomID, topicID uint64) (*model.Message, error) {
+	// 检查发送者是否是房间成员
+	if !s.isRoomMember(ctx, roomID, userID) {
+		return nil, ErrNotRoomMember
+	}
+
+	// 检查话题是否存在且有效
+	topic, err := s.topicRepo.GetByID(ctx, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic: %w", err)
+	}
+	if topic == nil {
+		return nil, ErrTopicNotFound
+	}
+	if topic.Status != model.TopicStatusActive {
+		return nil, ErrInvalidTopicStatus
+	}
+	if topic.IsExpired(time.Now()) {
+		return nil, ErrTopicExpired
+	}
+
+	// 创建消息，分享互动和分享数在同一事务中更新，同一用户重复分享只计一次
+	msg := &model.Message{
+		ChatRoomID:  roomID,
+		SenderID:    userID,
+		ContentType: model.MessageContentTopic,
+		Content:     strconv.FormatUint(topicID, 10),
+	}
+	share := &model.TopicInteraction{
+		TopicID:           topicID,
+		UserID:            userID,
+		InteractionType:   model.InteractionTypeShare,
+		InteractionStatus: model.InteractionStatusActive,
+	}
+	if err := s.chatRepo.CreateTopicShareMessage(ctx, msg, share); err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+	if err := cache.Invalidate(cache.TopicKey(topicID)); err != nil {
+		logger.Warn("failed to delete topic cache", logger.Any("error", err))
+	}
+
+	// 重新读取话题，预览中的分享数以事务提交后为准
+	if updated, err := s.topicRepo.GetByID(ctx, topicID); err != nil {
+		logger.Warn("failed to reload shared topic",
+			logger.Any("error", err),
+			logger.Uint64("topic_id", topicID))
+	} else if updated != nil {
+		topic = updated
+	}
+	msg.SharedTopic = model.NewSharedTopic(topic)
+
+	// 更新房间成员的未读消息状态
+	go s.updateMembersUnreadStatus(ctx, roomID, msg.ID)
+
+	return msg, nil
+}
+
+// attachSharedTopics 为分享话题消息填充话题预览
+// 话题已删除或查询失败时不填充，不影响消息列表
+func (s *ChatService) attachSharedTopics(ctx context.Context, messages []*model.Message) {
+	var topicIDs []uint64
+	for _, msg := range messages {
+		if msg.ContentType != model.MessageContentTopic || msg.IsDeleted() {
+			continue
+		}
+		if id, err := strconv.ParseUint(msg.Content, 10, 64); err == nil {
+			topicIDs = append(topicIDs, id)
+		}
+	}
+	if len(topicIDs) == 0 {
+		return
+	}
+
+	topics, err := s.topicRepo.ListByIDs(ctx, topicIDs)
+	if err != nil {
+		logger.Warn("failed to load shared topics", logger.Any("error", err))
+		return
+	}
+	byID := make(map[uint64]*model.Topic, len(topics))
+	for _, topic := range topics {
+		byID[topic.ID] = topic
+	}
+
+	for _, msg := range messages {
+		if msg.ContentType != model.MessageContentTopic || msg.IsDeleted() {
+			continue
+		}
+		id, err := strconv.ParseUint(msg.Content, 10, 64)
+		if err != nil {
+			continue
+		}
+		if topic, ok := byID[id]; ok {
+			msg.SharedTopic = model.NewSharedTopic(topic)
+		}
+	}
+}
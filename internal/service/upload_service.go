@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/logger"
+	"DistanceBack_v1/pkg/storage"
+	"DistanceBack_v1/pkg/utils"
+)
+
+const (
+	// UploadHandleExpiration 上传句柄有效期，过期后不能再被消息引用
+	UploadHandleExpiration = 24 * time.Hour
+	// MaxMessageAttachments 单条消息最多引用的附件数
+	MaxMessageAttachments = 9
+	// UploadSessionExpiration 分片上传会话有效期，超时未完成的上传会被清理
+	UploadSessionExpiration = 24 * time.Hour
+	// UploadCleanupInterval 清理过期分片上传的间隔
+	UploadCleanupInterval = time.Hour
+
+	uploadCleanupBatchSize = 100
+)
+
+// UploadSession 分片上传会话
+type UploadSession struct {
+	ID          string    `json:"id"`
+	UserID      uint64    `json:"user_id"`
+	MediaType   string    `json:"media_type"`
+	FileName    string    `json:"file_name"`
+	TotalSize   int64     `json:"total_size"`
+	ChunkSize   int64     `json:"chunk_size"`
+	TotalChunks int       `json:"total_chunks"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// chunkLength 获取指定分片应有的大小
+func (u *UploadSession) chunkLength(index int) int64 {
+	if index == u.TotalChunks-1 {
+		return u.TotalSize - int64(index)*u.ChunkSize
+	}
+	return u.ChunkSize
+}
+
+// UploadHandle 已上传文件的临时句柄
+type UploadHandle struct {
+	ID        string    `json:"id"`
+	UserID    uint64    `json:"user_id"`
+	MediaType string    `json:"media_type"` // image, file
+	URL       string    `json:"url"`
+	FileName  string    `json:"file_name"`
+	FileSize  uint      `json:"file_size"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type UploadService struct {
+	storage storage.Storage
+}
+
+// NewUploadService 创建上传服务实例
+func NewUploadService(storage storage.Storage) *UploadService {
+	return &UploadService{
+		storage: storage,
+	}
+}
+
+// Upload 上传文件并返回临时句柄
+func (s *UploadService) Upload(ctx context.Context, userID uint64, file *model.File) (*UploadHandle, error) {
+	if file == nil || file.File == nil {
+		return nil, ErrInvalidFile
+	}
+	if file.File.Size > storage.MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+	if file.Type == "image" && !storage.IsImageTypeAllowed(file.Name) {
+		return nil, ErrInvalidFileType
+	}
+
+	fileURL, err := s.storage.UploadFile(ctx, file.File, storage.ChatDirectory)
+	if err != nil {
+		logger.Error("上传文件失败",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID))
+		return nil, ErrUploadFailed
+	}
+
+	return s.createHandle(ctx, userID, file.Type, fileURL, file.Name, file.Size)
+}
+
+// InitChunkedUpload 创建分片上传会话
+func (s *UploadService) InitChunkedUpload(ctx context.Context, userID uint64, mediaType, fileName string, totalSize, chunkSize int64) (*UploadSession, error) {
+	if _, ok := s.storage.(storage.ChunkStorage); !ok {
+		return nil, ErrOperationFailed
+	}
+	if mediaType == "image" && !storage.IsImageTypeAllowed(fileName) {
+		return nil, ErrInvalidFileType
+	}
+	if totalSize <= 0 || chunkSize <= 0 || chunkSize > storage.MaxChunkSize {
+		return nil, ErrInvalidRequest
+	}
+	if totalSize > storage.MaxChunkedFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	now := time.Now()
+	session := &UploadSession{
+		ID:          utils.NewUID(),
+		UserID:      userID,
+		MediaType:   mediaType,
+		FileName:    fileName,
+		TotalSize:   totalSize,
+		ChunkSize:   chunkSize,
+		TotalChunks: int((totalSize + chunkSize - 1) / chunkSize),
+		ExpiresAt:   now.Add(UploadSessionExpiration),
+	}
+
+	if err := cache.Set(cache.UploadSessionKey(session.ID), session, UploadSessionExpiration); err != nil {
+		return nil, err
+	}
+	// 记录会话过期时间，用于清理未完成上传的分片
+	if err := cache.ZAdd(cache.UploadSessionsKey(), float64(session.ExpiresAt.Unix()), session.ID); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// UploadChunk 上传单个分片，同一分片可重复上传
+func (s *UploadService) UploadChunk(ctx context.Context, userID uint64, sessionID string, index int, size int64, r io.Reader) error {
+	chunkStorage, ok := s.storage.(storage.ChunkStorage)
+	if !ok {
+		return ErrOperationFailed
+	}
+
+	session, err := s.getSession(userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if index < 0 || index >= session.TotalChunks {
+		return ErrInvalidRequest
+	}
+	// 除最后一个分片外，分片大小必须与会话声明一致
+	if size != session.chunkLength(index) {
+		return ErrInvalidRequest
+	}
+
+	if err := chunkStorage.UploadChunk(ctx, storage.ChunkObjectPath(session.ID, index), io.LimitReader(r, size)); err != nil {
+		logger.Error("上传分片失败",
+			logger.Any("error", err),
+			logger.String("session_id", session.ID),
+			logger.Int("index", index))
+		return ErrUploadFailed
+	}
+
+	chunksKey := cache.UploadChunksKey(session.ID)
+	if err := cache.SAdd(chunksKey, index); err != nil {
+		return err
+	}
+	return cache.Expire(chunksKey, time.Until(session.ExpiresAt))
+}
+
+// CompleteChunkedUpload 合并全部分片并返回可供消息引用的上传句柄
+func (s *UploadService) CompleteChunkedUpload(ctx context.Context, userID uint64, sessionID string) (*UploadHandle, error) {
+	chunkStorage, ok := s.storage.(storage.ChunkStorage)
+	if !ok {
+		return nil, ErrOperationFailed
+	}
+
+	if !utils.IsValidUID(sessionID) {
+		return nil, ErrInvalidRequest
+	}
+
+	// 防止同一会话被重复合并
+	lock := cache.NewLock(cache.UploadSessionKey(sessionID), time.Minute)
+	locked, err := lock.Lock()
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, ErrConflict
+	}
+	defer lock.Unlock()
+
+	session, err := s.getSession(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	received, err := cache.SCard(cache.UploadChunksKey(session.ID))
+	if err != nil {
+		return nil, err
+	}
+	if received != int64(session.TotalChunks) {
+		return nil, NewError(CodeInvalidRequest, "upload incomplete").
+			WithStatus(http.StatusBadRequest).
+			WithDetails(map[string]int64{
+				"received_chunks": received,
+				"total_chunks":    int64(session.TotalChunks),
+			})
+	}
+
+	srcPaths := make([]string, 0, session.TotalChunks)
+	for i := 0; i < session.TotalChunks; i++ {
+		srcPaths = append(srcPaths, storage.ChunkObjectPath(session.ID, i))
+	}
+
+	fileURL, err := chunkStorage.ComposeObjects(ctx, srcPaths, storage.ChatDirectory, session.FileName)
+	if err != nil {
+		logger.Error("合并分片失败",
+			logger.Any("error", err),
+			logger.String("session_id", session.ID))
+		return nil, ErrUploadFailed
+	}
+
+	handle, err := s.createHandle(ctx, userID, session.MediaType, fileURL, session.FileName, uint(session.TotalSize))
+	if err != nil {
+		return nil, err
+	}
+
+	s.removeSession(ctx, chunkStorage, session.ID)
+	return handle, nil
+}
+
+// CleanupExpiredSessions 清理过期未完成的分片上传
+func (s *UploadService) CleanupExpiredSessions(ctx context.Context) (int, error) {
+	chunkStorage, ok := s.storage.(storage.ChunkStorage)
+	if !ok {
+		return 0, nil
+	}
+
+	sessionIDs, err := cache.ZRangeByMaxScore(cache.UploadSessionsKey(), float64(time.Now().Unix()), uploadCleanupBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, sessionID := range sessionIDs {
+		s.removeSession(ctx, chunkStorage, sessionID)
+	}
+
+	return len(sessionIDs), nil
+}
+
+// ClaimHandles 校验并消费上传句柄
+// 句柄必须属于当前用户且未过期，校验全部通过后才逐个原子地取出，
+// 并发请求引用同一句柄时只有一个能成功
+func (s *UploadService) ClaimHandles(ctx context.Context, userID uint64, handleIDs []string) ([]*UploadHandle, error) {
+	if len(handleIDs) > MaxMessageAttachments {
+		return nil, ErrInvalidRequest
+	}
+
+	ids := make([]string, 0, len(handleIDs))
+	seen := make(map[string]bool, len(handleIDs))
+	for _, id := range handleIDs {
+		if !utils.IsValidUID(id) {
+			return nil, ErrInvalidRequest
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		var handle UploadHandle
+		if err := cache.Get(cache.UploadHandleKey(id), &handle); err != nil {
+			if errors.Is(err, cache.ErrCacheMiss) {
+				return nil, ErrUploadExpired
+			}
+			return nil, err
+		}
+		if err := checkHandle(&handle, userID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	handles := make([]*UploadHandle, 0, len(ids))
+	for _, id := range ids {
+		var handle UploadHandle
+		err := cache.GetDel(cache.UploadHandleKey(id), &handle)
+		if err == nil {
+			err = checkHandle(&handle, userID)
+		}
+		if err != nil {
+			// 句柄已被其他请求消费，归还本次已取出的句柄
+			s.ReleaseHandles(ctx, handles)
+			if errors.Is(err, cache.ErrCacheMiss) {
+				return nil, ErrUploadExpired
+			}
+			return nil, err
+		}
+		handles = append(handles, &handle)
+	}
+
+	return handles, nil
+}
+
+// ReleaseHandles 归还未被使用的上传句柄，用于消息发送失败时让客户端可以重试
+func (s *UploadService) ReleaseHandles(ctx context.Context, handles []*UploadHandle) {
+	for _, handle := range handles {
+		ttl := time.Until(handle.ExpiresAt)
+		if ttl <= 0 {
+			continue
+		}
+		if err := cache.Set(cache.UploadHandleKey(handle.ID), handle, ttl); err != nil {
+			logger.Error("归还上传句柄失败",
+				logger.Any("error", err),
+				logger.String("handle_id", handle.ID))
+		}
+	}
+}
+
+// checkHandle 检查句柄属于当前用户且未过期
+func checkHandle(handle *UploadHandle, userID uint64) error {
+	if handle.UserID != userID {
+		return ErrForbidden
+	}
+	if time.Now().After(handle.ExpiresAt) {
+		return ErrUploadExpired
+	}
+	return nil
+}
+
+// createHandle 为已上传的文件生成句柄
+func (s *UploadService) createHandle(ctx context.Context, userID uint64, mediaType, fileURL, fileName string, fileSize uint) (*UploadHandle, error) {
+	handle := &UploadHandle{
+		ID:        utils.NewUID(),
+		UserID:    userID,
+		MediaType: mediaType,
+		URL:       fileURL,
+		FileName:  fileName,
+		FileSize:  fileSize,
+		ExpiresAt: time.Now().Add(UploadHandleExpiration),
+	}
+
+	if err := cache.Set(cache.UploadHandleKey(handle.ID), handle, UploadHandleExpiration); err != nil {
+		// 句柄无法保存时清理已上传的文件
+		if delErr := s.storage.DeleteFile(ctx, fileURL); delErr != nil {
+			logger.Error("清理上传文件失败",
+				logger.Any("error", delErr),
+				logger.String("url", fileURL))
+		}
+		return nil, err
+	}
+
+	// 缓存中保留原地址供发送消息时引用，返回给客户端的是可访问地址
+	resolved := *handle
+	resolved.URL = accessURL(ctx, s.storage, fileURL)
+	return &resolved, nil
+}
+
+// getSession 获取属于当前用户的分片上传会话
+func (s *UploadService) getSession(userID uint64, sessionID string) (*UploadSession, error) {
+	// 会话ID会拼入存储路径，必须是服务端生成的UUID
+	if !utils.IsValidUID(sessionID) {
+		return nil, ErrInvalidRequest
+	}
+
+	var session UploadSession
+	if err := cache.Get(cache.UploadSessionKey(sessionID), &session); err != nil {
+		if errors.Is(err, cache.ErrCacheMiss) {
+			return nil, ErrUploadExpired
+		}
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return &session, nil
+}
+
+// removeSession 删除分片上传会话及其分片对象
+func (s *UploadService) removeSession(ctx context.Context, chunkStorage storage.ChunkStorage, sessionID string) {
+	if err := chunkStorage.DeletePrefix(ctx, storage.ChunkPrefix(sessionID)); err != nil {
+		logger.Error("删除上传分片失败",
+			logger.Any("error", err),
+			logger.String("session_id", sessionID))
+		// 保留会话记录，下次清理时重试
+		return
+	}
+
+	for _, key := range []string{cache.UploadSessionKey(sessionID), cache.UploadChunksKey(sessionID)} {
+		if err := cache.Delete(key); err != nil {
+			logger.Error("删除上传会话失败",
+				logger.Any("error", err),
+				logger.String("session_id", sessionID))
+		}
+	}
+	if err := cache.ZRem(cache.UploadSessionsKey(), sessionID); err != nil {
+		logger.Error("删除上传会话索引失败",
+			logger.Any("error", err),
+			logger.String("session_id", sessionID))
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"mime/multipart"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/pkg/cache"
+)
+
+const testHandleID = "0b6f6f1e-7d3c-4c3a-9a53-1f2d5c6b7a80"
+
+// storeTestHandle 写入属于 userID 的上传句柄
+func storeTestHandle(t *testing.T, userID uint64) {
+	t.Helper()
+	handle := &UploadHandle{
+		ID:        testHandleID,
+		UserID:    userID,
+		MediaType: "image",
+		URL:       "https://example.com/a.jpg",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := cache.Set(cache.UploadHandleKey(handle.ID), handle, time.Hour); err != nil {
+		t.Fatalf("store handle: %v", err)
+	}
+}
+
+func TestClaimHandlesOnlyOnce(t *testing.T) {
+	resetCache(t)
+	storeTestHandle(t, 7)
+	uploadService := NewUploadService(nil)
+	ctx := context.Background()
+
+	if _, err := uploadService.ClaimHandles(ctx, 8, []string{testHandleID}); err != ErrForbidden {
+		t.Fatalf("claim by other user err = %v, want ErrForbidden", err)
+	}
+	handles, err := uploadService.ClaimHandles(ctx, 7, []string{testHandleID})
+	if err != nil || len(handles) != 1 {
+		t.Fatalf("first claim = %v, %v", handles, err)
+	}
+	if _, err := uploadService.ClaimHandles(ctx, 7, []string{testHandleID}); err != ErrUploadExpired {
+		t.Fatalf("second claim err = %v, want ErrUploadExpired", err)
+	}
+
+	uploadService.ReleaseHandles(ctx, handles)
+	if _, err := uploadService.ClaimHandles(ctx, 7, []string{testHandleID}); err != nil {
+		t.Fatalf("claim after release: %v", err)
+	}
+}
+
+func TestUploadReturnsSignedURLAndKeepsOriginal(t *testing.T) {
+	resetCache(t)
+	uploadService := NewUploadService(&fakeStorage{private: "chats"})
+	ctx := context.Background()
+
+	file := &model.File{Name: "a.png", Type: "image", Size: 10, File: &multipart.FileHeader{Filename: "a.png", Size: 10}}
+	handle, err := uploadService.Upload(ctx, 7, file)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if handle.URL != "chats/a.png?signature=test" {
+		t.Errorf("returned url = %q, want signed url", handle.URL)
+	}
+
+	// 发送消息时引用的是原地址
+	handles, err := uploadService.ClaimHandles(ctx, 7, []string{handle.ID})
+	if err != nil || len(handles) != 1 {
+		t.Fatalf("claim = %v, %v", handles, err)
+	}
+	if handles[0].URL != "chats/a.png" {
+		t.Errorf("claimed url = %q, want original url", handles[0].URL)
+	}
+}
@@ -2,14 +2,17 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
+	"DistanceBack_v1/config"
 	"DistanceBack_v1/internal/model"
 	"DistanceBack_v1/internal/repository"
 	"DistanceBack_v1/pkg/auth"
 	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/constants"
 	"DistanceBack_v1/pkg/logger"
 	"DistanceBack_v1/pkg/storage"
 	"DistanceBack_v1/pkg/utils"
@@ -18,13 +21,24 @@ import (
 type UserService struct {
 	userRepo repository.UserRepository
 	storage  storage.Storage
+	nearby   config.NearbyConfig
+	upload   config.UploadConfig
+	profile  config.ProfileConfig
+
+	// revokeTokens 撤销 Firebase 刷新令牌，测试中可替换
+	revokeTokens func(ctx context.Context, firebaseUID string) error
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService(userRepo repository.UserRepository, storage storage.Storage) *UserService {
+func NewUserService(userRepo repository.UserRepository, storage storage.Storage, nearby config.NearbyConfig, upload config.UploadConfig, profile config.ProfileConfig) *UserService {
 	return &UserService{
 		userRepo: userRepo,
 		storage:  storage,
+		nearby:   nearby,
+		upload:   upload,
+		profile:  profile,
+
+		revokeTokens: auth.RevokeRefreshTokens,
 	}
 }
 
@@ -37,9 +51,13 @@ func (s *UserService) RegisterOrUpdateUser(ctx context.Context, firebaseUser *au
 	}
 
 	if user == nil {
-		// 创建新用户
+		// 创建新用户，Firebase 显示名已被占用时不同步，由用户稍后设置
+		nickname, err := s.syncableNickname(ctx, firebaseUser.DisplayName, 0)
+		if err != nil {
+			return nil, err
+		}
 		user = &model.User{
-			Nickname:            firebaseUser.DisplayName,
+			Nickname:            nickname,
 			AvatarURL:           firebaseUser.PhotoURL,
 			Gender:              "other", // 设置默认性别
 			Status:              model.UserStatusActive,
@@ -69,8 +87,14 @@ func (s *UserService) RegisterOrUpdateUser(ctx context.Context, firebaseUser *au
 		}
 	} else {
 		// 更新现有用户信息
-		if firebaseUser.DisplayName != "" {
-			user.Nickname = firebaseUser.DisplayName
+		if firebaseUser.DisplayName != "" && firebaseUser.DisplayName != user.Nickname {
+			nickname, err := s.syncableNickname(ctx, firebaseUser.DisplayName, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if nickname != "" {
+				user.Nickname = nickname
+			}
 		}
 		if firebaseUser.PhotoURL != "" {
 			user.AvatarURL = firebaseUser.PhotoURL
@@ -100,6 +124,9 @@ func (s *UserService) RegisterOrUpdateUser(ctx context.Context, firebaseUser *au
 	if err := cache.Set(cacheKey, user, cache.DefaultExpiration); err != nil {
 		logger.Warn("failed to cache user info", logger.Any("error", err))
 	}
+	if err := cache.Set(cache.FirebaseUIDKey(firebaseUser.UID), user.ID, cache.DefaultExpiration); err != nil {
+		logger.Warn("failed to cache firebase uid", logger.Any("error", err))
+	}
 
 	return user, nil
 }
@@ -115,6 +142,13 @@ func (s *UserService) UpdateProfile(ctx context.Context, userID uint64, profile
 		return errors.New("user not found")
 	}
 
+	// 检查昵称是否可用
+	if profile.Nickname != user.Nickname {
+		if err := s.checkNicknameAvailable(ctx, profile.Nickname, userID); err != nil {
+			return err
+		}
+	}
+
 	// 更新可修改的字段
 	user.Nickname = profile.Nickname
 	user.BirthDate = profile.BirthDate
@@ -133,43 +167,74 @@ func (s *UserService) UpdateProfile(ctx context.Context, userID uint64, profile
 
 	// 清除缓存
 	cacheKey := cache.UserKey(userID)
-	if err := cache.Delete(cacheKey); err != nil {
+	if err := cache.Invalidate(cacheKey); err != nil {
 		logger.Warn("failed to delete user cache", logger.Any("error", err))
 	}
 
 	return nil
 }
 
-// UpdateAvatar 更新用户头像
-func (s *UserService) UpdateAvatar(ctx context.Context, userID uint64, avatar *model.File) error {
+// checkNicknameAvailable 开启昵称唯一时检查昵称是否已被其他用户使用
+// 检查与写入不在同一事务中，并发修改为同一昵称的极端情况不做处理
+func (s *UserService) checkNicknameAvailable(ctx context.Context, nickname string, userID uint64) error {
+	if !s.profile.UniqueNickname || nickname == "" {
+		return nil
+	}
+	exists, err := s.userRepo.NicknameExists(ctx, nickname, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check nickname: %w", err)
+	}
+	if exists {
+		return ErrNicknameTaken
+	}
+	return nil
+}
+
+// syncableNickname 返回可从 Firebase 同步的昵称
+// 昵称已被占用时返回空字符串，登录流程不因昵称冲突失败
+func (s *UserService) syncableNickname(ctx context.Context, displayName string, userID uint64) (string, error) {
+	err := s.checkNicknameAvailable(ctx, displayName, userID)
+	if err == ErrNicknameTaken {
+		logger.Info("firebase display name already in use, skip nickname sync",
+			logger.Uint64("user_id", userID))
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return displayName, nil
+}
+
+// UpdateAvatar 更新用户头像，返回处理后的头像信息
+func (s *UserService) UpdateAvatar(ctx context.Context, userID uint64, avatar *model.File) (*AvatarUpload, error) {
 	// 获取现有用户信息
 	user, err := s.GetUserByID(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if user == nil {
-		return errors.New("user not found")
+		return nil, errors.New("user not found")
 	}
 
 	// 上传新头像
-	fileURL, err := s.storage.UploadFile(ctx, avatar.File, storage.AvatarDirectory)
+	uploaded, err := uploadAvatar(ctx, s.storage, avatar, storage.AvatarDirectory, s.upload)
 	if err != nil {
-		return fmt.Errorf("failed to upload avatar: %w", err)
+		return nil, err
 	}
 
 	// 更新用户头像URL
-	user.AvatarURL = fileURL
+	user.AvatarURL = uploaded.URL
 	if err := s.userRepo.Update(ctx, user); err != nil {
-		return fmt.Errorf("failed to update user avatar: %w", err)
+		return nil, fmt.Errorf("failed to update user avatar: %w", err)
 	}
 
 	// 清除缓存
 	cacheKey := cache.UserKey(userID)
-	if err := cache.Delete(cacheKey); err != nil {
+	if err := cache.Invalidate(cacheKey); err != nil {
 		logger.Warn("failed to delete user cache", logger.Any("error", err))
 	}
 
-	return nil
+	return uploaded, nil
 }
 
 // UpdateLocation 更新用户位置
@@ -222,14 +287,266 @@ func (s *UserService) GetUserByID(ctx context.Context, userID uint64) (*model.Us
 		return nil, nil
 	}
 
-	// 缓存用户信息
-	if err := cache.Set(cacheKey, user, cache.DefaultExpiration); err != nil {
+	// 缓存用户信息，刚失效的用户不回填
+	if _, err := cache.SetIfFresh(cacheKey, user, cache.DefaultExpiration); err != nil {
 		logger.Warn("failed to cache user info", logger.Any("error", err))
 	}
 
 	return user, nil
 }
 
+// MaxUserBriefBatch 批量获取用户简要信息的最大数量
+const MaxUserBriefBatch = 100
+
+// GetBriefs 批量获取用户，一次 MGET 读取缓存，未命中的用户一次查询数据库
+// 结果按 userIDs 的顺序返回，重复及不存在的用户会被忽略
+func (s *UserService) GetBriefs(ctx context.Context, userIDs []uint64) ([]*model.User, error) {
+	if len(userIDs) > MaxUserBriefBatch {
+		return nil, ErrInvalidRequest
+	}
+
+	found := make(map[uint64]*model.User, len(userIDs))
+	ids := make([]uint64, 0, len(userIDs))
+	for _, id := range userIDs {
+		if _, ok := found[id]; ok {
+			continue
+		}
+		found[id] = nil
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return []*model.User{}, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = cache.UserKey(id)
+	}
+	cached, err := cache.MGet(keys)
+	if err != nil {
+		// 缓存不可用时全部回源数据库
+		logger.Warn("failed to get cached users", logger.Any("error", err))
+		cached = make([][]byte, len(ids))
+	}
+
+	var missing []uint64
+	for i, id := range ids {
+		if cached[i] != nil {
+			var cachedUser model.User
+			if err := json.Unmarshal(cached[i], &cachedUser); err == nil {
+				found[id] = &cachedUser
+				continue
+			}
+		}
+		missing = append(missing, id)
+	}
+
+	if len(missing) > 0 {
+		users, err := s.userRepo.GetByIDs(ctx, missing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get users: %w", err)
+		}
+		for _, user := range users {
+			found[user.ID] = user
+			// 缓存用户信息，刚失效的用户不回填
+			if _, err := cache.SetIfFresh(cache.UserKey(user.ID), user, cache.DefaultExpiration); err != nil {
+				logger.Warn("failed to cache user info", logger.Any("error", err))
+			}
+		}
+	}
+
+	result := make([]*model.User, 0, len(found))
+	for _, id := range userIDs {
+		if user := found[id]; user != nil {
+			result = append(result, user)
+			// 避免重复ID重复返回
+			delete(found, id)
+		}
+	}
+	return result, nil
+}
+
+// GetUserByFirebaseUID 根据Firebase UID获取用户
+func (s *UserService) GetUserByFirebaseUID(ctx context.Context, firebaseUID string) (*model.User, error) {
+	user, err := s.userRepo.GetByFirebaseUID(ctx, firebaseUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by firebase uid: %w", err)
+	}
+	return user, nil
+}
+
+// GetUserIDByFirebaseUID 根据Firebase UID获取用户ID，优先读取缓存
+// 用户不存在时返回0
+func (s *UserService) GetUserIDByFirebaseUID(ctx context.Context, firebaseUID string) (uint64, error) {
+	cacheKey := cache.FirebaseUIDKey(firebaseUID)
+	var userID uint64
+	if err := cache.Get(cacheKey, &userID); err == nil && userID != 0 {
+		return userID, nil
+	}
+
+	user, err := s.GetUserByFirebaseUID(ctx, firebaseUID)
+	if err != nil || user == nil {
+		return 0, err
+	}
+
+	if err := cache.Set(cacheKey, user.ID, cache.DefaultExpiration); err != nil {
+		logger.Warn("failed to cache firebase uid", logger.Any("error", err))
+	}
+	return user.ID, nil
+}
+
+// InvalidateFirebaseUID 清除Firebase UID映射缓存，账号解绑或删除时调用
+func (s *UserService) InvalidateFirebaseUID(firebaseUID string) {
+	if err := cache.Delete(cache.FirebaseUIDKey(firebaseUID)); err != nil {
+		logger.Warn("failed to delete firebase uid cache",
+			logger.Any("error", err),
+			logger.String("firebase_uid", firebaseUID))
+	}
+}
+
+// DeleteAccount 注销账号，解除 Firebase 绑定并清除用户缓存
+// 之后同一 Firebase 账号登录会注册为新用户
+func (s *UserService) DeleteAccount(ctx context.Context, userID uint64) error {
+	auth, err := s.userRepo.GetAuthentication(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user authentication: %w", err)
+	}
+	if auth == nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.userRepo.DeactivateAccount(ctx, userID); err != nil {
+		return fmt.Errorf("failed to deactivate account: %w", err)
+	}
+
+	s.InvalidateFirebaseUID(auth.FirebaseUID)
+	if err := cache.RemoveUserCache(userID); err != nil {
+		logger.Warn("failed to remove user cache",
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID))
+	}
+	return nil
+}
+
+// BanStatusExpiration 封禁状态缓存时间，封禁生效最多延迟该时长
+const BanStatusExpiration = time.Minute
+
+// BanStatus 用户封禁状态
+type BanStatus struct {
+	Banned bool       `json:"banned"`
+	Until  *time.Time `json:"banned_until,omitempty"` // 为空表示永久封禁
+}
+
+// Active 判断封禁在 now 时是否仍然有效
+func (b *BanStatus) Active(now time.Time) bool {
+	return b.Banned && (b.Until == nil || b.Until.After(now))
+}
+
+// GetBanStatus 获取用户封禁状态，结果短期缓存
+// 账号状态为 banned 时视为永久封禁，不受封禁记录影响；否则以正在生效的限期封禁记录为准
+func (s *UserService) GetBanStatus(ctx context.Context, userID uint64) (*BanStatus, error) {
+	cacheKey := cache.UserBanKey(userID)
+	var status BanStatus
+	if err := cache.Get(cacheKey, &status); err == nil {
+		return &status, nil
+	}
+
+	// 直接读库，用户缓存中的状态可能已过期
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if user.Status == model.UserStatusBanned {
+		status.Banned = true
+	} else {
+		ban, err := s.userRepo.GetActiveBan(ctx, userID, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user ban: %w", err)
+		}
+		if ban != nil && ban.BanEnd != nil {
+			status.Banned = true
+			until := *ban.BanEnd
+			status.Until = &until
+		}
+	}
+
+	// 封禁刚变化时不回填，避免覆盖新的状态
+	if _, err := cache.SetIfFresh(cacheKey, &status, BanStatusExpiration); err != nil {
+		logger.Warn("failed to cache ban status", logger.Any("error", err))
+	}
+	return &status, nil
+}
+
+// BanUser 封禁用户，until 为空时永久封禁
+func (s *UserService) BanUser(ctx context.Context, operatorID, userID uint64, reason string, until *time.Time) error {
+	if operatorID == userID {
+		return ErrInvalidRequest
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	ban := &model.UserBan{
+		UserID:     userID,
+		OperatorID: operatorID,
+		Reason:     reason,
+		BanStart:   time.Now(),
+		BanEnd:     until,
+		Status:     "active",
+	}
+	if err := s.userRepo.CreateBan(ctx, ban); err != nil {
+		return fmt.Errorf("failed to create user ban: %w", err)
+	}
+
+	s.invalidateBanStatus(userID)
+	return nil
+}
+
+// UnbanUser 解除用户的全部封禁
+func (s *UserService) UnbanUser(ctx context.Context, userID uint64) error {
+	if err := s.userRepo.CancelBans(ctx, userID); err != nil {
+		return fmt.Errorf("failed to cancel user bans: %w", err)
+	}
+
+	s.invalidateBanStatus(userID)
+	return nil
+}
+
+// invalidateBanStatus 封禁变化后清除封禁状态和用户信息缓存
+func (s *UserService) invalidateBanStatus(userID uint64) {
+	for _, key := range []string{cache.UserBanKey(userID), cache.UserKey(userID)} {
+		if err := cache.Invalidate(key); err != nil {
+			logger.Warn("failed to delete ban status cache",
+				logger.Any("error", err),
+				logger.String("key", key))
+		}
+	}
+}
+
+// IsAdmin 检查用户是否为管理员
+func (s *UserService) IsAdmin(ctx context.Context, userID uint64) (bool, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, nil
+	}
+	if user.UserType == string(constants.UserTypeAdmin) {
+		return true, nil
+	}
+
+	return s.userRepo.HasAdminPermission(ctx, userID)
+}
+
 // SearchUsers 搜索用户
 func (s *UserService) SearchUsers(ctx context.Context, keyword string, page, pageSize int) ([]*model.User, int64, error) {
 	offset := (page - 1) * pageSize
@@ -237,9 +554,88 @@ func (s *UserService) SearchUsers(ctx context.Context, keyword string, page, pag
 }
 
 // GetNearbyUsers 获取附近的用户
-func (s *UserService) GetNearbyUsers(ctx context.Context, lat, lng float64, radius float64, page, pageSize int) ([]*model.User, int64, error) {
+// activeWithinDays 为 0 时使用配置的默认活跃时长
+func (s *UserService) GetNearbyUsers(ctx context.Context, lat, lng float64, radius float64, activeWithinDays, page, pageSize int) ([]*model.User, int64, error) {
 	offset := (page - 1) * pageSize
-	return s.userRepo.GetNearbyUsers(ctx, lat, lng, radius, offset, pageSize)
+	activeSince := nearbyActiveSince(activeWithinDays, s.nearby.ActiveWithin, time.Now())
+	return s.userRepo.GetNearbyUsers(ctx, lat, lng, radius, activeSince, offset, pageSize)
+}
+
+// nearbyActiveSince 计算附近查询的活跃起始时间，返回零值表示不过滤
+func nearbyActiveSince(activeWithinDays int, defaultWithin time.Duration, now time.Time) time.Time {
+	within := defaultWithin
+	if activeWithinDays > 0 {
+		within = time.Duration(activeWithinDays) * 24 * time.Hour
+	}
+	if within <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-within)
+}
+
+// ListSessions 获取用户当前登录的设备
+func (s *UserService) ListSessions(ctx context.Context, userID uint64) ([]*model.UserDevice, error) {
+	devices, err := s.userRepo.ListActiveDevices(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	return devices, nil
+}
+
+// GetLatestDevice 获取用户最近活跃且上报了版本号的已登录设备
+// platform 不为空时只查找该平台的设备，没有时返回 nil
+func (s *UserService) GetLatestDevice(ctx context.Context, userID uint64, platform string) (*model.UserDevice, error) {
+	devices, err := s.userRepo.ListActiveDevices(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	for _, device := range devices {
+		if device.AppVersion == "" {
+			continue
+		}
+		if platform == "" || device.DeviceType == platform {
+			return device, nil
+		}
+	}
+	return nil, nil
+}
+
+// RevokeSession 让指定设备退出登录，该设备不再接收推送
+// Firebase 令牌不带设备标识，只能按用户撤销：所有设备已签发的令牌都会失效并需要重新登录，
+// 其他设备仍保持注册，重新登录后照常使用
+// 先撤销令牌再停用设备，撤销失败时设备保持不变，客户端可以直接重试
+func (s *UserService) RevokeSession(ctx context.Context, userID, deviceID uint64) error {
+	devices, err := s.userRepo.ListActiveDevices(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list devices: %w", err)
+	}
+	found := false
+	for _, device := range devices {
+		if device.ID == deviceID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrNotFound
+	}
+
+	authInfo, err := s.userRepo.GetAuthentication(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get authentication: %w", err)
+	}
+	if authInfo == nil {
+		return ErrNotFound
+	}
+	if err := s.revokeTokens(ctx, authInfo.FirebaseUID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	// 并发退出时设备可能已被停用，令牌已撤销，结果一致
+	if _, err := s.userRepo.DeactivateDevice(ctx, userID, deviceID); err != nil {
+		return fmt.Errorf("failed to deactivate device: %w", err)
+	}
+	return nil
 }
 
 // RegisterDevice 注册用户设备
@@ -282,3 +678,25 @@ func (s *UserService) RegisterDevice(ctx context.Context, userID uint64, device
 func (s *UserService) UpdateLastActive(ctx context.Context, userID uint64) error {
 	return s.userRepo.UpdateLastActive(ctx, userID)
 }
+
+// lastActiveInterval 同一用户两次写入最后活跃时间的最小间隔
+const lastActiveInterval = 5 * time.Minute
+
+// TouchLastActive 记录用户活跃，按用户节流，间隔内的重复调用直接返回
+// 缓存不可用时跳过，避免每个请求都写库；写库失败则删除节流键以便下次重试
+func (s *UserService) TouchLastActive(ctx context.Context, userID uint64) error {
+	key := cache.UserActiveKey(userID)
+	ok, err := cache.SetNX(key, 1, lastActiveInterval)
+	if err != nil {
+		return fmt.Errorf("failed to throttle last active: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	if err := s.userRepo.UpdateLastActive(ctx, userID); err != nil {
+		cache.Delete(key)
+		return fmt.Errorf("failed to update last active: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/config"
+	"DistanceBack_v1/internal/model"
+	"DistanceBack_v1/pkg/auth"
+	"DistanceBack_v1/pkg/cache"
+)
+
+func newTestUserService(userRepo *fakeUserRepo) *UserService {
+	return NewUserService(userRepo, nil, config.NearbyConfig{}, config.UploadConfig{}, config.ProfileConfig{})
+}
+
+func TestGetUserIDByFirebaseUIDUsesCache(t *testing.T) {
+	resetCache(t)
+	user := &model.User{Nickname: "kay", Status: model.UserStatusActive}
+	user.ID = 7
+	userRepo := newFakeUserRepo(user)
+	userRepo.firebase["fb-kay"] = user.ID
+	svc := newTestUserService(userRepo)
+
+	for i := 0; i < 2; i++ {
+		id, err := svc.GetUserIDByFirebaseUID(context.Background(), "fb-kay")
+		if err != nil || id != 7 {
+			t.Fatalf("call %d: id = %d, err = %v, want 7", i, id, err)
+		}
+	}
+	if userRepo.firebaseLookups != 1 {
+		t.Errorf("lookups = %d, want 1 (second call served from cache)", userRepo.firebaseLookups)
+	}
+}
+
+func TestDeleteAccountInvalidatesFirebaseUID(t *testing.T) {
+	resetCache(t)
+	user := &model.User{Nickname: "kay", Status: model.UserStatusActive}
+	user.ID = 7
+	userRepo := newFakeUserRepo(user)
+	userRepo.firebase["fb-kay"] = user.ID
+	svc := newTestUserService(userRepo)
+	ctx := context.Background()
+
+	if id, _ := svc.GetUserIDByFirebaseUID(ctx, "fb-kay"); id != 7 {
+		t.Fatalf("id = %d, want 7 before deletion", id)
+	}
+	if err := svc.DeleteAccount(ctx, 7); err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+
+	id, err := svc.GetUserIDByFirebaseUID(ctx, "fb-kay")
+	if err != nil || id != 0 {
+		t.Errorf("id = %d, err = %v, want 0 after deletion", id, err)
+	}
+	if user.Status != model.UserStatusInactive {
+		t.Errorf("status = %q, want inactive", user.Status)
+	}
+	if err := svc.DeleteAccount(ctx, 7); err != ErrUserNotFound {
+		t.Errorf("second DeleteAccount err = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestUpdateProfileNicknameUniqueness(t *testing.T) {
+	tests := []struct {
+		name     string
+		unique   bool
+		nickname string
+		wantErr  error
+	}{
+		{"enforced clash", true, "ren", ErrNicknameTaken},
+		{"enforced own nickname", true, "kay", nil},
+		{"enforced free nickname", true, "mio", nil},
+		{"not enforced clash", false, "ren", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCache(t)
+			kay := &model.User{Nickname: "kay"}
+			kay.ID = 7
+			ren := &model.User{Nickname: "ren"}
+			ren.ID = 8
+			userRepo := newFakeUserRepo(kay, ren)
+			svc := NewUserService(userRepo, nil, config.NearbyConfig{}, config.UploadConfig{},
+				config.ProfileConfig{UniqueNickname: tt.unique})
+
+			err := svc.UpdateProfile(context.Background(), 7, &model.User{Nickname: tt.nickname})
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			want := tt.nickname
+			if tt.wantErr != nil {
+				want = "kay"
+			}
+			if got := userRepo.users[7].Nickname; got != want {
+				t.Errorf("nickname = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRegisterSkipsTakenDisplayName(t *testing.T) {
+	tests := []struct {
+		name   string
+		unique bool
+		want   string
+	}{
+		{"enforced", true, ""},
+		{"not enforced", false, "ren"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCache(t)
+			ren := &model.User{Nickname: "ren"}
+			ren.ID = 8
+			svc := NewUserService(newFakeUserRepo(ren), nil, config.NearbyConfig{}, config.UploadConfig{},
+				config.ProfileConfig{UniqueNickname: tt.unique})
+
+			// 昵称冲突不影响登录
+			user, err := svc.RegisterOrUpdateUser(context.Background(), &auth.AuthUser{UID: "fb-new", DisplayName: "ren"})
+			if err != nil {
+				t.Fatalf("RegisterOrUpdateUser: %v", err)
+			}
+			if user.Nickname != tt.want {
+				t.Errorf("nickname = %q, want %q", user.Nickname, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBanStatusAccountStatusTakesPrecedence(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		status     string
+		banStart   time.Time
+		banEnd     time.Time
+		wantBanned bool
+		wantUntil  bool
+	}{
+		{"active ban", model.UserStatusActive, now.Add(-time.Hour), now.Add(time.Hour), true, true},
+		{"expired ban", model.UserStatusActive, now.Add(-2 * time.Hour), now.Add(-time.Hour), false, false},
+		{"future ban", model.UserStatusActive, now.Add(time.Hour), now.Add(2 * time.Hour), false, false},
+		{"banned account with expired ban", model.UserStatusBanned, now.Add(-2 * time.Hour), now.Add(-time.Hour), true, false},
+		{"banned account with future ban", model.UserStatusBanned, now.Add(time.Hour), now.Add(2 * time.Hour), true, false},
+		{"banned account with active ban", model.UserStatusBanned, now.Add(-time.Hour), now.Add(time.Hour), true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCache(t)
+			user := &model.User{Nickname: "kay", Status: tt.status}
+			user.ID = 7
+			userRepo := newFakeUserRepo(user)
+			banEnd := tt.banEnd
+			userRepo.bans = []*model.UserBan{{UserID: 7, BanStart: tt.banStart, BanEnd: &banEnd, Status: "active"}}
+			svc := newTestUserService(userRepo)
+
+			status, err := svc.GetBanStatus(context.Background(), 7)
+			if err != nil {
+				t.Fatalf("GetBanStatus: %v", err)
+			}
+			if status.Banned != tt.wantBanned || (status.Until != nil) != tt.wantUntil {
+				t.Errorf("status = %+v, want banned %v with until %v", status, tt.wantBanned, tt.wantUntil)
+			}
+		})
+	}
+}
+
+func TestBanAndUnbanInvalidateCachedStatus(t *testing.T) {
+	resetCache(t)
+	user := &model.User{Nickname: "kay", Status: model.UserStatusActive}
+	user.ID = 7
+	svc := newTestUserService(newFakeUserRepo(user))
+	ctx := context.Background()
+
+	// 先缓存未封禁状态
+	if status, err := svc.GetBanStatus(ctx, 7); err != nil || status.Banned {
+		t.Fatalf("initial status = %+v, %v; want not banned", status, err)
+	}
+
+	until := time.Now().Add(time.Hour)
+	if err := svc.BanUser(ctx, 1, 7, "spam", &until); err != nil {
+		t.Fatalf("BanUser: %v", err)
+	}
+	if status, err := svc.GetBanStatus(ctx, 7); err != nil || !status.Active(time.Now()) {
+		t.Fatalf("status after ban = %+v, %v; want banned", status, err)
+	}
+
+	if err := svc.UnbanUser(ctx, 7); err != nil {
+		t.Fatalf("UnbanUser: %v", err)
+	}
+	if status, err := svc.GetBanStatus(ctx, 7); err != nil || status.Banned {
+		t.Fatalf("status after unban = %+v, %v; want not banned", status, err)
+	}
+
+	// 永久封禁以账号状态为准
+	if err := svc.BanUser(ctx, 1, 7, "abuse", nil); err != nil {
+		t.Fatalf("BanUser: %v", err)
+	}
+	if status, err := svc.GetBanStatus(ctx, 7); err != nil || !status.Banned || status.Until != nil {
+		t.Fatalf("status after permanent ban = %+v, %v; want permanent ban", status, err)
+	}
+}
+
+func TestRevokeSessionRevokesFirebaseTokens(t *testing.T) {
+	userRepo := newFakeUserRepo(&model.User{BaseModel: model.BaseModel{ID: 7}}, &model.User{BaseModel: model.BaseModel{ID: 8}})
+	userRepo.firebase["fb-7"] = 7
+	userRepo.firebase["fb-8"] = 8
+	own := &model.UserDevice{UserID: 7, IsActive: true}
+	own.ID = 1
+	other := &model.UserDevice{UserID: 8, IsActive: true}
+	other.ID = 2
+	ownTablet := &model.UserDevice{UserID: 7, IsActive: true}
+	ownTablet.ID = 3
+	userRepo.devices = []*model.UserDevice{own, other, ownTablet}
+
+	svc := newTestUserService(userRepo)
+	var revoked []string
+	svc.revokeTokens = func(ctx context.Context, firebaseUID string) error {
+		revoked = append(revoked, firebaseUID)
+		return nil
+	}
+	ctx := context.Background()
+
+	// 其他用户的设备
+	if err := svc.RevokeSession(ctx, 7, 2); err != ErrNotFound {
+		t.Fatalf("revoke other user's device err = %v, want ErrNotFound", err)
+	}
+	if len(revoked) != 0 || !other.IsActive {
+		t.Fatalf("other user's session touched: revoked = %v", revoked)
+	}
+
+	if err := svc.RevokeSession(ctx, 7, 1); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if own.IsActive {
+		t.Error("device still active")
+	}
+	// 令牌按用户撤销，所有设备都需重新登录，但只停用指定设备
+	if len(revoked) != 1 || revoked[0] != "fb-7" {
+		t.Errorf("revoked = %v, want [fb-7]", revoked)
+	}
+	if !ownTablet.IsActive {
+		t.Error("other device of the same user deactivated")
+	}
+
+	// 已退出的设备
+	if err := svc.RevokeSession(ctx, 7, 1); err != ErrNotFound {
+		t.Errorf("revoke inactive device err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRevokeSessionKeepsDeviceWhenTokenRevocationFails(t *testing.T) {
+	userRepo := newFakeUserRepo(&model.User{BaseModel: model.BaseModel{ID: 7}})
+	userRepo.firebase["fb-7"] = 7
+	device := &model.UserDevice{UserID: 7, IsActive: true}
+	device.ID = 1
+	userRepo.devices = []*model.UserDevice{device}
+
+	svc := newTestUserService(userRepo)
+	svc.revokeTokens = func(ctx context.Context, firebaseUID string) error {
+		return errors.New("firebase unavailable")
+	}
+	ctx := context.Background()
+	if err := svc.RevokeSession(ctx, 7, 1); err == nil {
+		t.Fatal("RevokeSession succeeded, want revocation error")
+	}
+	if !device.IsActive {
+		t.Fatal("device deactivated although tokens were not revoked")
+	}
+
+	// 重试成功后设备停用
+	svc.revokeTokens = func(ctx context.Context, firebaseUID string) error { return nil }
+	if err := svc.RevokeSession(ctx, 7, 1); err != nil {
+		t.Fatalf("retry RevokeSession: %v", err)
+	}
+	if device.IsActive {
+		t.Error("device still active after retry")
+	}
+}
+
+func TestGetBriefsQueriesMissingUsersOnce(t *testing.T) {
+	resetCache(t)
+	var users []*model.User
+	for _, id := range []uint64{1, 2, 3} {
+		user := &model.User{Nickname: "user", Status: model.UserStatusActive}
+		user.ID = id
+		users = append(users, user)
+	}
+	userRepo := newFakeUserRepo(users...)
+	s := newTestUserService(userRepo)
+
+	// 用户 1 已缓存，其余用户只查询一次数据库
+	if err := cache.Set(cache.UserKey(1), users[0], time.Minute); err != nil {
+		t.Fatalf("cache user: %v", err)
+	}
+	got, err := s.GetBriefs(context.Background(), []uint64{3, 1, 2, 3, 99})
+	if err != nil {
+		t.Fatalf("GetBriefs: %v", err)
+	}
+	if len(got) != 3 || got[0].ID != 3 || got[1].ID != 1 || got[2].ID != 2 {
+		t.Fatalf("got %d users, want users 3, 1, 2 in request order", len(got))
+	}
+	if userRepo.idsQueries != 1 {
+		t.Errorf("GetByIDs calls = %d, want 1", userRepo.idsQueries)
+	}
+
+	// 再次请求全部命中缓存，不查询数据库且只发一条 MGET
+	before := testRedis.CommandCount()
+	if _, err := s.GetBriefs(context.Background(), []uint64{1, 2, 3}); err != nil {
+		t.Fatalf("GetBriefs: %v", err)
+	}
+	if userRepo.idsQueries != 1 {
+		t.Errorf("GetByIDs calls = %d, want 1", userRepo.idsQueries)
+	}
+	if commands := testRedis.CommandCount() - before; commands != 1 {
+		t.Errorf("redis commands = %d, want 1", commands)
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// breakerFailureThreshold 连续失败多少次后熔断
+	breakerFailureThreshold = 5
+	// breakerOpenDuration 熔断持续时间，之后放行一次试探请求
+	breakerOpenDuration = 30 * time.Second
+)
+
+// circuitBreaker Firebase 调用熔断器
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+var firebaseBreaker = &circuitBreaker{}
+
+// allow 判断是否允许调用 Firebase
+// 熔断期结束后只放行一个试探请求，其结果决定是否恢复
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < breakerFailureThreshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// success 记录调用成功
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// failure 记录 Firebase 不可用
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.failures >= breakerFailureThreshold {
+		b.openUntil = time.Now().Add(breakerOpenDuration)
+	}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		prepare  func(b *circuitBreaker)
+		want     []bool // 依次调用 allow 的结果
+	}{
+		{"closed", 0, nil, []bool{true, true}},
+		{"below threshold", breakerFailureThreshold - 1, nil, []bool{true, true}},
+		{"open", breakerFailureThreshold, nil, []bool{false, false}},
+		{
+			"half open allows one probe",
+			breakerFailureThreshold,
+			func(b *circuitBreaker) { b.openUntil = time.Now().Add(-time.Second) },
+			[]bool{true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &circuitBreaker{}
+			for i := 0; i < tt.failures; i++ {
+				b.failure()
+			}
+			if tt.prepare != nil {
+				tt.prepare(b)
+			}
+			for i, want := range tt.want {
+				if got := b.allow(); got != want {
+					t.Errorf("allow() call %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerProbeResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		probeOK   bool
+		wantAllow bool
+	}{
+		{"probe succeeds", true, true},
+		{"probe fails", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &circuitBreaker{}
+			for i := 0; i < breakerFailureThreshold; i++ {
+				b.failure()
+			}
+			b.openUntil = time.Now().Add(-time.Second)
+			if !b.allow() {
+				t.Fatal("probe should be allowed after open period")
+			}
+			if tt.probeOK {
+				b.success()
+			} else {
+				b.failure()
+			}
+			if got := b.allow(); got != tt.wantAllow {
+				t.Errorf("allow() after probe = %v, want %v", got, tt.wantAllow)
+			}
+		})
+	}
+}
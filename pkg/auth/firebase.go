@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"DistanceBack_v1/config"
 	"DistanceBack_v1/pkg/logger"
@@ -166,7 +167,28 @@ func EnableUser(ctx context.Context, uid string) error {
 	return nil
 }
 
-// VerifyIDToken 验证Firebase ID令牌
+// RevokeRefreshTokens 撤销用户的全部刷新令牌
+// 撤销前签发的 ID 令牌在验证时被拒绝，用户的所有设备都需要重新登录
+// 两步都是幂等的，失败后可以直接重试
+func RevokeRefreshTokens(ctx context.Context, uid string) error {
+	if firebaseAuth == nil {
+		return fmt.Errorf("firebase auth client not initialized")
+	}
+
+	if err := firebaseAuth.RevokeRefreshTokens(ctx, uid); err != nil {
+		return fmt.Errorf("error revoking refresh tokens: %v", err)
+	}
+	if err := markRevoked(uid, time.Now()); err != nil {
+		return fmt.Errorf("error recording token revocation: %v", err)
+	}
+
+	// 熔断期间沿用的本地验证结果同样作废
+	verifiedTokens.dropUID(uid)
+	return nil
+}
+
+// VerifyIDToken 验证Firebase ID令牌，已撤销的令牌视为无效
+// 撤销在本地检查，正常情况下只有获取公钥会访问 Firebase
 func VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error) {
 	if firebaseAuth == nil {
 		return nil, fmt.Errorf("firebase auth client not initialized")
@@ -177,10 +199,42 @@ func VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error) {
 		return nil, fmt.Errorf("empty id token")
 	}
 
+	// 熔断期间直接使用本地缓存的验证结果
+	if !firebaseBreaker.allow() {
+		if token, ok := verifiedTokens.get(idToken); ok {
+			return notRevoked(token)
+		}
+		return nil, fmt.Errorf("firebase auth unavailable")
+	}
+
 	token, err := firebaseAuth.VerifyIDToken(ctx, idToken)
 	if err != nil {
+		// 公钥获取失败说明 Firebase 不可用，而不是令牌无效
+		if auth.IsCertificateFetchFailed(err) {
+			firebaseBreaker.failure()
+			if cached, ok := verifiedTokens.get(idToken); ok {
+				logger.Warn("firebase unavailable, using cached token verification",
+					logger.String("uid", cached.UID))
+				return notRevoked(cached)
+			}
+		} else {
+			firebaseBreaker.success()
+		}
 		return nil, fmt.Errorf("error verifying ID token: %v", err)
 	}
 
+	firebaseBreaker.success()
+	if err := checkRevoked(token); err != nil {
+		return nil, err
+	}
+	verifiedTokens.set(idToken, token)
+	return token, nil
+}
+
+// notRevoked 沿用缓存的验证结果前确认令牌没有在其他实例上被撤销
+func notRevoked(token *auth.Token) (*auth.Token, error) {
+	if err := checkRevoked(token); err != nil {
+		return nil, err
+	}
 	return token, nil
 }
@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -30,6 +32,29 @@ func TokenFromHeader(c *gin.Context) (string, error) {
 	return parts[1], nil
 }
 
+// maxUIDLength Firebase UID 的最大长度
+const maxUIDLength = 128
+
+// UnverifiedUID 读取 ID 令牌声明的用户 UID，不校验签名
+// 结果可被伪造，只能用于限流分组等不涉及授权的场景，无法解析时返回空字符串
+func UnverifiedUID(idToken string) string {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || len(claims.Subject) > maxUIDLength {
+		return ""
+	}
+	return claims.Subject
+}
+
 // AuthMiddleware Firebase认证中间件
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestUnverifiedUID(t *testing.T) {
+	encode := func(payload string) string {
+		return "header." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+	}
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"subject", encode(`{"sub":"uid-1","user_id":"uid-1"}`), "uid-1"},
+		{"no subject", encode(`{"iss":"x"}`), ""},
+		{"not json", encode("garbage"), ""},
+		{"bad encoding", "header.!!!.signature", ""},
+		{"not a jwt", "opaque-token", ""},
+		{"too long", encode(`{"sub":"` + strings.Repeat("a", maxUIDLength+1) + `"}`), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnverifiedUID(tt.token); got != tt.want {
+				t.Errorf("UnverifiedUID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/logger"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+// idTokenLifetime Firebase ID 令牌的有效期，撤销之前签发的令牌最晚在此之后过期
+const idTokenLifetime = time.Hour
+
+// RevokedTokenError 令牌签名有效，但签发于用户令牌被撤销之前
+type RevokedTokenError struct {
+	UID string
+}
+
+func (e *RevokedTokenError) Error() string {
+	return "id token has been revoked"
+}
+
+// RevokedTokenUID 错误由已撤销的令牌引起时返回令牌所属的 UID
+func RevokedTokenUID(err error) (string, bool) {
+	var revoked *RevokedTokenError
+	if errors.As(err, &revoked) {
+		return revoked.UID, true
+	}
+	return "", false
+}
+
+// markRevoked 记录撤销时间，该用户在此之前签发的令牌全部作废
+// 记录只需保留到撤销前签发的令牌全部过期
+func markRevoked(uid string, at time.Time) error {
+	return cache.Set(cache.TokenRevokedKey(uid), at.Unix(), idTokenLifetime)
+}
+
+// checkRevoked 在本地检查令牌是否签发于撤销之前，避免每次验证都请求 Firebase
+// 撤销记录读取失败时放行，Redis 故障不应导致全部请求认证失败
+func checkRevoked(token *auth.Token) error {
+	var revokedAt int64
+	err := cache.Get(cache.TokenRevokedKey(token.UID), &revokedAt)
+	if err == cache.ErrCacheMiss {
+		return nil
+	}
+	if err != nil {
+		logger.Warn("failed to check token revocation",
+			logger.Any("error", err),
+			logger.String("uid", token.UID))
+		return nil
+	}
+	if token.IssuedAt < revokedAt {
+		return &RevokedTokenError{UID: token.UID}
+	}
+	return nil
+}
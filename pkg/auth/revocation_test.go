@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"DistanceBack_v1/pkg/cache"
+	"DistanceBack_v1/pkg/logger"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// useTestRedis 将撤销记录指向测试用 Redis
+func useTestRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	logger.Log = zap.NewNop()
+	mr := miniredis.RunT(t)
+	prev := cache.RedisClient
+	cache.RedisClient = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { cache.RedisClient = prev })
+	return mr
+}
+
+func TestCheckRevoked(t *testing.T) {
+	mr := useTestRedis(t)
+	revokedAt := time.Now()
+	if err := markRevoked("uid-1", revokedAt); err != nil {
+		t.Fatalf("markRevoked: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   *auth.Token
+		revoked bool
+	}{
+		{"issued before revocation", &auth.Token{UID: "uid-1", IssuedAt: revokedAt.Add(-time.Minute).Unix()}, true},
+		{"issued after revocation", &auth.Token{UID: "uid-1", IssuedAt: revokedAt.Add(time.Minute).Unix()}, false},
+		{"same second as revocation", &auth.Token{UID: "uid-1", IssuedAt: revokedAt.Unix()}, false},
+		{"other user", &auth.Token{UID: "uid-2", IssuedAt: revokedAt.Add(-time.Minute).Unix()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRevoked(tt.token)
+			uid, revoked := RevokedTokenUID(fmt.Errorf("verify: %w", err))
+			if revoked != tt.revoked {
+				t.Fatalf("checkRevoked() = %v, want revoked %v", err, tt.revoked)
+			}
+			if revoked && uid != tt.token.UID {
+				t.Errorf("revoked uid = %q, want %q", uid, tt.token.UID)
+			}
+		})
+	}
+
+	// 撤销记录在令牌有效期之后过期
+	mr.FastForward(idTokenLifetime)
+	if err := checkRevoked(tests[0].token); err != nil {
+		t.Errorf("checkRevoked after lifetime = %v, want nil", err)
+	}
+}
+
+func TestCheckRevokedAllowsWhenRedisUnavailable(t *testing.T) {
+	mr := useTestRedis(t)
+	if err := markRevoked("uid-1", time.Now()); err != nil {
+		t.Fatalf("markRevoked: %v", err)
+	}
+	mr.SetError("unavailable")
+
+	token := &auth.Token{UID: "uid-1", IssuedAt: time.Now().Add(-time.Minute).Unix()}
+	if err := checkRevoked(token); err != nil {
+		t.Errorf("checkRevoked = %v, want nil when Redis is unavailable", err)
+	}
+}
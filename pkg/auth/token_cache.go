@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+const (
+	// TokenCacheMaxStaleness Firebase 不可用时，本地缓存的验证结果最多可沿用的时间
+	TokenCacheMaxStaleness = 10 * time.Minute
+	// tokenCacheMaxEntries 本地缓存最大条目数
+	tokenCacheMaxEntries = 10000
+)
+
+// cachedToken 已验证的令牌
+type cachedToken struct {
+	token      *auth.Token
+	verifiedAt time.Time
+}
+
+// tokenCache 已验证令牌的本地缓存，仅在 Firebase 不可用时使用
+type tokenCache struct {
+	mu      sync.RWMutex
+	entries map[string]cachedToken
+}
+
+var verifiedTokens = &tokenCache{entries: make(map[string]cachedToken)}
+
+// tokenCacheKey 令牌原文不直接作为键保存
+func tokenCacheKey(idToken string) string {
+	sum := sha256.Sum256([]byte(idToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// set 记录验证通过的令牌
+func (c *tokenCache) set(idToken string, token *auth.Token) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= tokenCacheMaxEntries {
+		c.pruneLocked(now)
+	}
+	if len(c.entries) >= tokenCacheMaxEntries {
+		return
+	}
+	c.entries[tokenCacheKey(idToken)] = cachedToken{token: token, verifiedAt: now}
+}
+
+// get 获取仍可沿用的验证结果：验证时间不超过最大沿用时间且令牌本身未过期
+func (c *tokenCache) get(idToken string) (*auth.Token, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[tokenCacheKey(idToken)]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	now := time.Now()
+	if now.Sub(entry.verifiedAt) > TokenCacheMaxStaleness || now.Unix() >= entry.token.Expires {
+		return nil, false
+	}
+	return entry.token, true
+}
+
+// dropUID 删除指定用户的全部验证结果
+func (c *tokenCache) dropUID(uid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if entry.token.UID == uid {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// pruneLocked 清理不可再沿用的条目，调用方需持有写锁
+func (c *tokenCache) pruneLocked(now time.Time) {
+	for key, entry := range c.entries {
+		if now.Sub(entry.verifiedAt) > TokenCacheMaxStaleness || now.Unix() >= entry.token.Expires {
+			delete(c.entries, key)
+		}
+	}
+}
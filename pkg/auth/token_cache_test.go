@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+func TestTokenCacheStaleness(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		verifiedAt time.Time
+		expires    time.Time
+		want       bool
+	}{
+		{"fresh", now, now.Add(time.Hour), true},
+		{"too stale", now.Add(-TokenCacheMaxStaleness - time.Second), now.Add(time.Hour), false},
+		{"token expired", now, now.Add(-time.Second), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &tokenCache{entries: make(map[string]cachedToken)}
+			c.entries[tokenCacheKey("id-token")] = cachedToken{
+				token:      &auth.Token{UID: "uid-1", Expires: tt.expires.Unix()},
+				verifiedAt: tt.verifiedAt,
+			}
+			token, ok := c.get("id-token")
+			if ok != tt.want {
+				t.Fatalf("get() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && token.UID != "uid-1" {
+				t.Errorf("UID = %q", token.UID)
+			}
+		})
+	}
+
+	c := &tokenCache{entries: make(map[string]cachedToken)}
+	if _, ok := c.get("unknown"); ok {
+		t.Error("get() on unknown token should miss")
+	}
+}
+
+func TestTokenCacheDropUID(t *testing.T) {
+	expires := time.Now().Add(time.Hour).Unix()
+	c := &tokenCache{entries: make(map[string]cachedToken)}
+	c.set("token-a1", &auth.Token{UID: "uid-a", Expires: expires})
+	c.set("token-a2", &auth.Token{UID: "uid-a", Expires: expires})
+	c.set("token-b", &auth.Token{UID: "uid-b", Expires: expires})
+
+	c.dropUID("uid-a")
+	if _, ok := c.get("token-a1"); ok {
+		t.Error("token-a1 still cached after revocation")
+	}
+	if _, ok := c.get("token-a2"); ok {
+		t.Error("token-a2 still cached after revocation")
+	}
+	if _, ok := c.get("token-b"); !ok {
+		t.Error("token-b of another user was dropped")
+	}
+}
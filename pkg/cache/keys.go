@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"time"
 )
@@ -12,20 +14,35 @@ const (
 	LongExpiration    = time.Hour * 24 * 7
 
 	// 用户相关前缀
-	UserKeyPrefix     = "user:"
-	UserTokenPrefix   = "user:token:"
-	UserProfilePrefix = "user:profile:"
-	UserOnlinePrefix  = "user:online:"
+	UserKeyPrefix      = "user:"
+	UserTokenPrefix    = "user:token:"
+	UserProfilePrefix  = "user:profile:"
+	UserOnlinePrefix   = "user:online:"
+	MeOverviewPrefix   = "user:me:"
+	FirebaseUIDPrefix  = "user:firebase:" // Firebase UID 到用户ID的映射
+	UserBanPrefix      = "user:ban:"
+	UserActivePrefix   = "user:active:"  // 最后活跃时间写入节流
+	TokenRevokedPrefix = "user:revoked:" // Firebase UID 的令牌撤销时间
+
+	// 关系相关前缀
+	RelationshipCountsPrefix = "relationship:counts:"
 
 	// 话题相关前缀
 	TopicKeyPrefix  = "topic:"
 	TopicLikePrefix = "topic:like:"
 	TopicViewPrefix = "topic:view:"
 
+	// 限流相关前缀
+	TopicCreateLimitPrefix  = "ratelimit:topic:create:"
+	LoginFailureLimitPrefix = "ratelimit:login:fail:"
+
 	// 聊天相关前缀
 	ChatRoomPrefix     = "chat:room:"
 	ChatMembersPrefix  = "chat:members:"
 	ChatMessagesPrefix = "chat:messages:"
+	ChatPresencePrefix = "chat:presence:"
+	ChatTypingPrefix   = "chat:typing:"
+	LinkPreviewPrefix  = "chat:link:"
 
 	// 位置相关前缀
 	LocationKeyPrefix = "location:"
@@ -35,6 +52,14 @@ const (
 	TagKeyPrefix    = "tag:"
 	TopicTagsPrefix = "topic:tags:"
 	PopularTagsName = "popular:tags" // 改名以避免与函数冲突
+
+	// 上传相关前缀
+	UploadHandlePrefix  = "upload:handle:"
+	UploadSessionPrefix = "upload:session:"
+	UploadSessionsName  = "upload:sessions" // 按过期时间排序的分片上传会话
+
+	// 管理后台相关
+	AdminStatsName = "admin:stats"
 )
 
 // 用户相关键生成函数
@@ -54,6 +79,32 @@ func UserOnlineKey(userID uint64) string {
 	return fmt.Sprintf("%s%d", UserOnlinePrefix, userID)
 }
 
+func MeOverviewKey(userID uint64) string {
+	return fmt.Sprintf("%s%d", MeOverviewPrefix, userID)
+}
+
+func FirebaseUIDKey(firebaseUID string) string {
+	return FirebaseUIDPrefix + firebaseUID
+}
+
+func UserBanKey(userID uint64) string {
+	return fmt.Sprintf("%s%d", UserBanPrefix, userID)
+}
+
+func UserActiveKey(userID uint64) string {
+	return fmt.Sprintf("%s%d", UserActivePrefix, userID)
+}
+
+// TokenRevokedKey 记录 Firebase 用户令牌撤销时间的键
+func TokenRevokedKey(firebaseUID string) string {
+	return TokenRevokedPrefix + firebaseUID
+}
+
+// 关系相关键生成函数
+func RelationshipCountsKey(userID uint64) string {
+	return fmt.Sprintf("%s%d", RelationshipCountsPrefix, userID)
+}
+
 // 话题相关键生成函数
 func TopicKey(topicID uint64) string {
 	return fmt.Sprintf("%s%d", TopicKeyPrefix, topicID)
@@ -67,6 +118,24 @@ func TopicViewKey(topicID uint64) string {
 	return fmt.Sprintf("%s%d", TopicViewPrefix, topicID)
 }
 
+// TopicViewerKey 用户浏览话题的去重键
+func TopicViewerKey(topicID, userID uint64) string {
+	return fmt.Sprintf("%s%d:%d", TopicViewPrefix, topicID, userID)
+}
+
+// 限流相关键生成函数
+func LoginFailureIPKey(ip string) string {
+	return LoginFailureLimitPrefix + "ip:" + ip
+}
+
+func LoginFailureUIDKey(uid string) string {
+	return LoginFailureLimitPrefix + "uid:" + uid
+}
+
+func TopicCreateLimitKey(userID uint64) string {
+	return fmt.Sprintf("%s%d", TopicCreateLimitPrefix, userID)
+}
+
 // 聊天相关键生成函数
 func ChatRoomKey(roomID uint64) string {
 	return fmt.Sprintf("%s%d", ChatRoomPrefix, roomID)
@@ -80,6 +149,20 @@ func ChatMessagesKey(roomID uint64) string {
 	return fmt.Sprintf("%s%d", ChatMessagesPrefix, roomID)
 }
 
+func ChatPresenceKey(roomID uint64) string {
+	return fmt.Sprintf("%s%d", ChatPresencePrefix, roomID)
+}
+
+func ChatTypingKey(roomID uint64) string {
+	return fmt.Sprintf("%s%d", ChatTypingPrefix, roomID)
+}
+
+// LinkPreviewKey 链接预览缓存键，URL 取哈希避免键过长
+func LinkPreviewKey(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return LinkPreviewPrefix + hex.EncodeToString(sum[:])
+}
+
 // 位置相关键生成函数
 func LocationKey(userID uint64) string {
 	return fmt.Sprintf("%s%d", LocationKeyPrefix, userID)
@@ -102,6 +185,28 @@ func PopularTagsKey() string {
 	return PopularTagsName
 }
 
+// 上传相关键生成函数
+func UploadHandleKey(handleID string) string {
+	return UploadHandlePrefix + handleID
+}
+
+func UploadSessionKey(sessionID string) string {
+	return UploadSessionPrefix + sessionID
+}
+
+func UploadChunksKey(sessionID string) string {
+	return UploadSessionPrefix + sessionID + ":chunks"
+}
+
+func UploadSessionsKey() string {
+	return UploadSessionsName
+}
+
+// 管理后台相关键生成函数
+func AdminStatsKey() string {
+	return AdminStatsName
+}
+
 // 缓存删除函数
 func RemoveUserCache(userID uint64) error {
 	keys := []string{
@@ -109,6 +214,7 @@ func RemoveUserCache(userID uint64) error {
 		UserTokenKey(userID),
 		UserProfileKey(userID),
 		UserOnlineKey(userID),
+		MeOverviewKey(userID),
 	}
 
 	for _, key := range keys {
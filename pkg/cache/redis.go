@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,6 +16,9 @@ import (
 var (
 	RedisClient *redis.Client
 	Ctx         = context.Background()
+
+	// ErrCacheMiss 缓存不存在
+	ErrCacheMiss = errors.New("cache miss")
 )
 
 // InitRedis 初始化Redis连接
@@ -30,6 +34,8 @@ func InitRedis(cfg *config.RedisConfig) error {
 		PoolTimeout:  4 * time.Second,
 	})
 
+	SetTombstoneTTL(cfg.TombstoneTTL)
+
 	// 测试连接
 	if err := RedisClient.Ping(Ctx).Err(); err != nil {
 		return fmt.Errorf("redis connection failed: %v", err)
@@ -62,12 +68,29 @@ func Set(key string, value interface{}, expiration time.Duration) error {
 	return nil
 }
 
+// Replace 键存在时覆盖缓存值并保留剩余过期时间，返回是否实际写入
+func Replace(key string, value interface{}) (bool, error) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal cache value: %v", err)
+	}
+
+	err = RedisClient.SetArgs(Ctx, key, bytes, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to replace cache: %v", err)
+	}
+	return true, nil
+}
+
 // Get 获取缓存
 func Get(key string, value interface{}) error {
 	bytes, err := RedisClient.Get(Ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			return nil
+			return ErrCacheMiss
 		}
 		return fmt.Errorf("failed to get cache: %v", err)
 	}
@@ -80,6 +103,40 @@ func Get(key string, value interface{}) error {
 	return nil
 }
 
+// MGet 一次获取多个缓存，返回与 keys 一一对应的原始数据，未命中的位置为 nil
+func MGet(keys []string) ([][]byte, error) {
+	values, err := RedisClient.MGet(Ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache: %v", err)
+	}
+
+	result := make([][]byte, len(values))
+	for i, value := range values {
+		if str, ok := value.(string); ok {
+			result[i] = []byte(str)
+		}
+	}
+	return result, nil
+}
+
+// GetDel 原子地获取并删除缓存，并发调用时只有一个调用方能拿到值
+func GetDel(key string, value interface{}) error {
+	bytes, err := RedisClient.GetDel(Ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return ErrCacheMiss
+		}
+		return fmt.Errorf("failed to getdel cache: %v", err)
+	}
+
+	err = json.Unmarshal(bytes, value)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal cache value: %v", err)
+	}
+
+	return nil
+}
+
 // Delete 删除缓存
 func Delete(key string) error {
 	return RedisClient.Del(Ctx, key).Err()
@@ -139,6 +196,48 @@ func HDel(key string, fields ...string) error {
 	return RedisClient.HDel(Ctx, key, fields...).Err()
 }
 
+// SAdd 添加集合成员
+func SAdd(key string, members ...interface{}) error {
+	return RedisClient.SAdd(Ctx, key, members...).Err()
+}
+
+// SCard 获取集合成员数量
+func SCard(key string) (int64, error) {
+	return RedisClient.SCard(Ctx, key).Result()
+}
+
+// ZAdd 添加有序集合成员
+func ZAdd(key string, score float64, member interface{}) error {
+	return RedisClient.ZAdd(Ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+// ZRangeByMaxScore 获取分数不大于 max 的有序集合成员
+func ZRangeByMaxScore(key string, max float64, limit int64) ([]string, error) {
+	return RedisClient.ZRangeByScore(Ctx, key, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   fmt.Sprintf("%f", max),
+		Count: limit,
+	}).Result()
+}
+
+// ZRangeByMinScore 获取分数大于 min 的有序集合成员
+func ZRangeByMinScore(key string, min float64) ([]string, error) {
+	return RedisClient.ZRangeByScore(Ctx, key, &redis.ZRangeBy{
+		Min: fmt.Sprintf("(%f", min),
+		Max: "+inf",
+	}).Result()
+}
+
+// ZRemRangeByMaxScore 删除分数不大于 max 的有序集合成员
+func ZRemRangeByMaxScore(key string, max float64) error {
+	return RedisClient.ZRemRangeByScore(Ctx, key, "-inf", fmt.Sprintf("%f", max)).Err()
+}
+
+// ZRem 删除有序集合成员
+func ZRem(key string, members ...interface{}) error {
+	return RedisClient.ZRem(Ctx, key, members...).Err()
+}
+
 // Lock 分布式锁
 type Lock struct {
 	key        string
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TombstonePrefix 缓存失效墓碑前缀
+const TombstonePrefix = "tombstone:"
+
+// DefaultTombstoneTTL 默认墓碑有效期
+const DefaultTombstoneTTL = 2 * time.Second
+
+// tombstoneTTL 失效后禁止回填缓存的时长，0 表示不写墓碑
+var tombstoneTTL = DefaultTombstoneTTL
+
+// setIfFreshScript 墓碑不存在时才写入缓存
+// KEYS[1] 缓存键，KEYS[2] 墓碑键；ARGV: 缓存值、过期时间(毫秒)
+var setIfFreshScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[2]) == 1 then
+	return 0
+end
+if tonumber(ARGV[2]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// SetTombstoneTTL 设置墓碑有效期
+func SetTombstoneTTL(ttl time.Duration) {
+	tombstoneTTL = ttl
+}
+
+// TombstoneKey 获取缓存键对应的墓碑键
+func TombstoneKey(key string) string {
+	return TombstonePrefix + key
+}
+
+// Invalidate 删除缓存并写入短期墓碑
+// 墓碑有效期内 SetIfFresh 不会回填，避免并发读取把更新前的数据重新写入缓存
+func Invalidate(key string) error {
+	pipe := RedisClient.TxPipeline()
+	pipe.Del(Ctx, key)
+	if tombstoneTTL > 0 {
+		pipe.Set(Ctx, TombstoneKey(key), 1, tombstoneTTL)
+	}
+	if _, err := pipe.Exec(Ctx); err != nil {
+		return fmt.Errorf("failed to invalidate cache: %v", err)
+	}
+	return nil
+}
+
+// SetIfFresh 写入读路径回填的缓存，键存在墓碑时跳过
+// 返回是否实际写入
+func SetIfFresh(key string, value interface{}, expiration time.Duration) (bool, error) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal cache value: %v", err)
+	}
+
+	written, err := setIfFreshScript.Run(Ctx, RedisClient,
+		[]string{key, TombstoneKey(key)},
+		bytes, expiration.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to set cache: %v", err)
+	}
+	return written == 1, nil
+}
@@ -2,7 +2,9 @@ package errors
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"time"
 )
 
 // AppError 自定义错误结构
@@ -36,6 +38,9 @@ const (
 	CodeUpload         = 10008 // 上传失败
 	CodeDownload       = 10009 // 下载失败
 	CodeOperation      = 10010 // 操作失败
+	CodeRateLimited    = 10011 // 请求过于频繁
+	CodeTooLarge       = 10012 // 请求体过大
+	CodeUpgrade        = 10013 // 客户端版本过低
 
 	// 用户相关错误 (2xxxx)
 	CodeUserNotFound      = 20001 // 用户不存在
@@ -121,18 +126,74 @@ func Is(err error, target *AppError) bool {
 	return false
 }
 
+// RateLimitDetails 限流错误详情
+type RateLimitDetails struct {
+	RetryAfterSeconds int `json:"retry_after_seconds"`
+}
+
+// NewRateLimited 创建带重试等待时间的限流错误
+func NewRateLimited(retryAfter time.Duration) *AppError {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return New(CodeRateLimited, ErrRateLimited.Message).
+		WithStatus(http.StatusTooManyRequests).
+		WithDetails(&RateLimitDetails{RetryAfterSeconds: seconds})
+}
+
+// RetryAfterSeconds 获取限流错误的重试等待秒数
+func RetryAfterSeconds(err error) (int, bool) {
+	appErr, ok := err.(*AppError)
+	if !ok || appErr.Code != CodeRateLimited {
+		return 0, false
+	}
+	if details, ok := appErr.Details.(*RateLimitDetails); ok {
+		return details.RetryAfterSeconds, true
+	}
+	return 1, true
+}
+
+// RequestTooLargeDetails 请求体过大错误详情
+type RequestTooLargeDetails struct {
+	MaxBytes int64 `json:"max_bytes"`
+}
+
+// NewRequestTooLarge 创建带请求体大小上限的错误
+func NewRequestTooLarge(maxBytes int64) *AppError {
+	return New(CodeTooLarge, ErrRequestTooLarge.Message).
+		WithStatus(http.StatusRequestEntityTooLarge).
+		WithDetails(&RequestTooLargeDetails{MaxBytes: maxBytes})
+}
+
+// UpgradeRequiredDetails 版本过低错误详情
+type UpgradeRequiredDetails struct {
+	Platform   string `json:"platform"`
+	MinVersion string `json:"min_version"`
+}
+
+// NewUpgradeRequired 创建带平台最低版本的升级提示错误
+func NewUpgradeRequired(platform, minVersion string) *AppError {
+	return New(CodeUpgrade, ErrUpgradeRequired.Message).
+		WithStatus(http.StatusUpgradeRequired).
+		WithDetails(&UpgradeRequiredDetails{Platform: platform, MinVersion: minVersion})
+}
+
 // 预定义错误实例
 var (
 	// 系统级错误
-	ErrUnknown        = New(CodeUnknown, "未知错误")
-	ErrValidation     = New(CodeValidation, "参数验证错误")
-	ErrDatabase       = New(CodeDatabase, "数据库错误")
-	ErrAuthentication = New(CodeAuthentication, "认证失败")
-	ErrAuthorization  = New(CodeAuthorization, "权限不足")
-	ErrNotFound       = New(CodeNotFound, "资源不存在")
-	ErrDuplicate      = New(CodeDuplicate, "资源已存在")
-	ErrThirdParty     = New(CodeThirdParty, "第三方服务错误")
-	ErrOperation      = New(CodeOperation, "操作失败")
+	ErrUnknown         = New(CodeUnknown, "未知错误")
+	ErrValidation      = New(CodeValidation, "参数验证错误")
+	ErrDatabase        = New(CodeDatabase, "数据库错误")
+	ErrAuthentication  = New(CodeAuthentication, "认证失败")
+	ErrAuthorization   = New(CodeAuthorization, "权限不足")
+	ErrNotFound        = New(CodeNotFound, "资源不存在")
+	ErrDuplicate       = New(CodeDuplicate, "资源已存在")
+	ErrThirdParty      = New(CodeThirdParty, "第三方服务错误")
+	ErrOperation       = New(CodeOperation, "操作失败")
+	ErrRateLimited     = New(CodeRateLimited, "请求过于频繁，请稍后再试").WithStatus(http.StatusTooManyRequests)
+	ErrRequestTooLarge = New(CodeTooLarge, "请求体过大").WithStatus(http.StatusRequestEntityTooLarge)
+	ErrUpgradeRequired = New(CodeUpgrade, "客户端版本过低，请升级后再试").WithStatus(http.StatusUpgradeRequired)
 
 	// 用户相关错误
 	ErrUserNotFound    = New(CodeUserNotFound, "用户不存在")
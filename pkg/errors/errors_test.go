@@ -0,0 +1,55 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimitedRoundsUpRetryAfter(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       int
+	}{
+		{0, 1},
+		{-time.Second, 1},
+		{300 * time.Millisecond, 1},
+		{time.Second, 1},
+		{1500 * time.Millisecond, 2},
+		{time.Minute, 60},
+	}
+	for _, tt := range tests {
+		e := NewRateLimited(tt.retryAfter)
+		if e.HTTPStatus != http.StatusTooManyRequests || e.Code != CodeRateLimited {
+			t.Fatalf("NewRateLimited(%v) = status %d code %d", tt.retryAfter, e.HTTPStatus, e.Code)
+		}
+		got, ok := RetryAfterSeconds(e)
+		if !ok || got != tt.want {
+			t.Errorf("RetryAfterSeconds(NewRateLimited(%v)) = %d, %v; want %d", tt.retryAfter, got, ok, tt.want)
+		}
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   int
+		wantOK bool
+	}{
+		{"rate limited with details", NewRateLimited(5 * time.Second), 5, true},
+		{"rate limited without details", New(CodeRateLimited, "too many"), 1, true},
+		{"other app error", ErrNotFound, 0, false},
+		{"plain error", fmt.Errorf("boom"), 0, false},
+		{"nil", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RetryAfterSeconds(tt.err)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RetryAfterSeconds() = %d, %v; want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// DefaultTimeout 单次抓取的总超时时间
+	DefaultTimeout = 5 * time.Second
+	// DefaultMaxBodySize 最多读取的页面大小，OpenGraph 标签位于 head 中
+	DefaultMaxBodySize = 512 * 1024
+	// DefaultMaxRedirects 最多跟随的重定向次数
+	DefaultMaxRedirects = 3
+
+	// 文本字段的最大长度（按字符）
+	maxTitleLength       = 200
+	maxDescriptionLength = 500
+)
+
+var (
+	// ErrInvalidURL URL 格式错误或协议不受支持
+	ErrInvalidURL = errors.New("invalid url")
+	// ErrBlockedAddress 目标地址为内网、回环等受限地址
+	ErrBlockedAddress = errors.New("blocked address")
+	// ErrUnsupportedContent 目标内容不是 HTML 页面
+	ErrUnsupportedContent = errors.New("unsupported content type")
+)
+
+// Preview 链接预览信息
+type Preview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	SiteName    string `json:"site_name"`
+}
+
+// Fetcher 抓取网页并解析 OpenGraph 元数据
+// 连接建立时校验实际解析出的 IP，防止通过 DNS 或重定向访问内网
+type Fetcher struct {
+	client      *http.Client
+	maxBodySize int64
+}
+
+// NewFetcher 创建使用默认限制的抓取器
+func NewFetcher() *Fetcher {
+	dialer := &net.Dialer{
+		Timeout: DefaultTimeout,
+		Control: blockPrivateAddress,
+	}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   DefaultTimeout,
+		ResponseHeaderTimeout: DefaultTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	return &Fetcher{
+		client: &http.Client{
+			Timeout:   DefaultTimeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= DefaultMaxRedirects {
+					return fmt.Errorf("stopped after %d redirects", DefaultMaxRedirects)
+				}
+				return validateScheme(req.URL)
+			},
+		},
+		maxBodySize: DefaultMaxBodySize,
+	}
+}
+
+// Normalize 校验并规范化 URL，仅允许 http/https
+func Normalize(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+	if err := validateScheme(u); err != nil {
+		return "", err
+	}
+	if u.Hostname() == "" || u.User != nil {
+		return "", ErrInvalidURL
+	}
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+	return u.String(), nil
+}
+
+// Fetch 抓取 URL 并返回预览信息
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	target, err := Normalize(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	req.Header.Set("User-Agent", "DistanceBot/1.0 (+link preview)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlockedAddress) {
+			return nil, ErrBlockedAddress
+		}
+		return nil, fmt.Errorf("failed to fetch url: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrUnsupportedContent
+	}
+
+	preview := parse(io.LimitReader(resp.Body, f.maxBodySize), resp.Request.URL)
+	preview.URL = resp.Request.URL.String()
+	return preview, nil
+}
+
+// parse 解析 head 中的 OpenGraph 标签，缺失时回退到 title 和 description
+func parse(r io.Reader, base *url.URL) *Preview {
+	preview := &Preview{}
+	var fallbackTitle, fallbackDescription string
+
+	z := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return finish(preview, base, fallbackTitle, fallbackDescription)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = tt == html.StartTagToken
+			case "meta":
+				if !hasAttr {
+					continue
+				}
+				key, content := metaAttrs(z)
+				switch key {
+				case "og:title":
+					preview.Title = content
+				case "og:description":
+					preview.Description = content
+				case "og:image", "og:image:url":
+					if preview.Image == "" {
+						preview.Image = content
+					}
+				case "og:site_name":
+					preview.SiteName = content
+				case "description":
+					fallbackDescription = content
+				}
+			case "body":
+				// 元数据只在 head 中，进入 body 即可停止
+				return finish(preview, base, fallbackTitle, fallbackDescription)
+			}
+		case html.TextToken:
+			if inTitle && fallbackTitle == "" {
+				fallbackTitle = strings.TrimSpace(string(z.Text()))
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "title" {
+				inTitle = false
+			} else if string(name) == "head" {
+				return finish(preview, base, fallbackTitle, fallbackDescription)
+			}
+		}
+	}
+}
+
+// metaAttrs 读取 meta 标签的 property/name 与 content
+func metaAttrs(z *html.Tokenizer) (key, content string) {
+	for {
+		name, value, more := z.TagAttr()
+		switch string(name) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(string(value)))
+			}
+		case "content":
+			content = strings.TrimSpace(string(value))
+		}
+		if !more {
+			return key, content
+		}
+	}
+}
+
+// finish 补全回退字段、截断长度并把图片地址转为绝对地址
+func finish(p *Preview, base *url.URL, title, description string) *Preview {
+	if p.Title == "" {
+		p.Title = title
+	}
+	if p.Description == "" {
+		p.Description = description
+	}
+	p.Title = truncate(p.Title, maxTitleLength)
+	p.Description = truncate(p.Description, maxDescriptionLength)
+
+	if p.Image != "" {
+		img, err := base.Parse(p.Image)
+		if err != nil || (img.Scheme != "http" && img.Scheme != "https") {
+			p.Image = ""
+		} else {
+			p.Image = img.String()
+		}
+	}
+	return p
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
+
+func validateScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// blockPrivateAddress 在建立连接前检查目标 IP
+func blockPrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrBlockedAddress
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsBlockedIP(ip) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// blockedNets 标准库判断之外需要禁止的地址段
+var blockedNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),     // 本网络，部分系统上 0.x 会连到本机
+	mustParseCIDR("100.64.0.0/10"), // 运营商级 NAT
+	mustParseCIDR("64:ff9b::/96"),  // NAT64，可映射到任意 IPv4 内网地址
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+// IsBlockedIP 是否为禁止访问的地址：回环、内网、链路本地、组播、未指定地址及 blockedNets 中的地址段
+func IsBlockedIP(ip net.IP) bool {
+	if ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() {
+		return true
+	}
+	for _, ipNet := range blockedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"DistanceBack_v1/pkg/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript 基于有序集合的滑动窗口计数
+// KEYS[1] 计数键；ARGV: 当前时间(毫秒)、窗口(毫秒)、上限、本次请求成员
+// 返回 {是否允许, 需等待毫秒数}
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+if redis.call("ZCARD", KEYS[1]) < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	redis.call("PEXPIRE", KEYS[1], window)
+	return {1, 0}
+end
+
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {0, tonumber(oldest[2]) + window - now}
+`)
+
+// peekScript 只统计滑动窗口内的请求数，不记录本次请求
+// KEYS[1] 计数键；ARGV: 当前时间(毫秒)、窗口(毫秒)、上限
+// 返回 {是否未达上限, 需等待毫秒数}
+var peekScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+if redis.call("ZCARD", KEYS[1]) < limit then
+	return {1, 0}
+end
+
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {0, tonumber(oldest[2]) + window - now}
+`)
+
+// Allow 检查 key 在 window 内的请求次数是否达到 limit，未达到时记录本次请求
+// 被拒绝时返回距离窗口内最早一次请求过期的等待时间
+func Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	if limit <= 0 {
+		return false, window, nil
+	}
+
+	now := time.Now().UnixMilli()
+	member := fmt.Sprintf("%d-%d", now, rand.Int63())
+
+	result, err := slidingWindowScript.Run(ctx, cache.RedisClient,
+		[]string{key}, now, window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to run rate limit script: %v", err)
+	}
+
+	if result[0] == 1 {
+		return true, 0, nil
+	}
+	return false, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// Peek 检查 key 在 window 内的次数是否未达到 limit，但不记录本次请求
+// 用于只统计失败次数的场景：先 Peek，失败后再调用 Allow 记录
+func Peek(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	if limit <= 0 {
+		return false, window, nil
+	}
+
+	now := time.Now().UnixMilli()
+	result, err := peekScript.Run(ctx, cache.RedisClient,
+		[]string{key}, now, window.Milliseconds(), limit).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to run rate limit script: %v", err)
+	}
+
+	if result[0] == 1 {
+		return true, 0, nil
+	}
+	return false, time.Duration(result[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image/gif"
+	"image/png"
+	"io"
+	"mime/multipart"
+)
+
+// webpAnimationFlag VP8X 扩展头中的动画标志位
+const webpAnimationFlag = 0x02
+
+// FlattenedExt 动图提取首帧后重新编码的格式扩展名
+const FlattenedExt = ".png"
+
+var (
+	// ErrCannotFlatten 不支持提取首帧的动图格式
+	ErrCannotFlatten = errors.New("animated image cannot be flattened")
+	// ErrImageTooLarge 图片宽或高超过 MaxImageDimension
+	ErrImageTooLarge = errors.New("image dimensions exceed limit")
+	// errMalformedGIF GIF 数据块结构不完整
+	errMalformedGIF = errors.New("malformed gif")
+)
+
+// IsAnimated 检查图片是否为动图，目前识别 GIF 与 WebP
+func IsAnimated(file *multipart.FileHeader) (bool, error) {
+	data, err := readFile(file)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case isGIF(data):
+		if err := checkGIFDimensions(data); err != nil {
+			return false, err
+		}
+		// 只扫描数据块统计帧数，不解码像素，数到第二帧即可判断
+		frames, err := countGIFFrames(data, 2)
+		if err != nil {
+			return false, fmt.Errorf("failed to decode gif: %v", err)
+		}
+		return frames > 1, nil
+	case isWebP(data):
+		return isAnimatedWebP(data), nil
+	default:
+		return false, nil
+	}
+}
+
+// FlattenAnimated 提取动图的第一帧，返回 PNG 编码的单帧图片数据
+// 上传时文件扩展名需改为 FlattenedExt；WebP 需要额外的解码器，暂不支持，返回 ErrCannotFlatten
+func FlattenAnimated(file *multipart.FileHeader) ([]byte, error) {
+	data, err := readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if !isGIF(data) {
+		return nil, ErrCannotFlatten
+	}
+	if err := checkGIFDimensions(data); err != nil {
+		return nil, err
+	}
+
+	// gif.Decode 解码到第一帧即返回
+	frame, err := gif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode gif: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, frame); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// checkGIFDimensions 解码前检查画布尺寸，避免超大图片占用内存
+func checkGIFDimensions(data []byte) error {
+	cfg, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode gif: %v", err)
+	}
+	if cfg.Width > MaxImageDimension || cfg.Height > MaxImageDimension {
+		return ErrImageTooLarge
+	}
+	return nil
+}
+
+// countGIFFrames 遍历 GIF 数据块统计图像帧数，达到 limit 时提前返回
+func countGIFFrames(data []byte, limit int) (int, error) {
+	// 头部 6 字节 + 逻辑屏幕描述符 7 字节
+	offset := 13
+	if len(data) < offset {
+		return 0, errMalformedGIF
+	}
+	if flags := data[10]; flags&0x80 != 0 {
+		offset += 3 << ((flags & 0x07) + 1) // 全局颜色表
+	}
+
+	frames := 0
+	for offset < len(data) {
+		switch data[offset] {
+		case 0x21: // 扩展块：标签 + 数据子块
+			offset += 2
+		case 0x2C: // 图像描述符
+			frames++
+			if frames >= limit {
+				return frames, nil
+			}
+			if offset+10 > len(data) {
+				return 0, errMalformedGIF
+			}
+			flags := data[offset+9]
+			offset += 10
+			if flags&0x80 != 0 {
+				offset += 3 << ((flags & 0x07) + 1) // 局部颜色表
+			}
+			offset++ // LZW 最小码长
+		case 0x3B: // 结束符
+			return frames, nil
+		default:
+			return 0, errMalformedGIF
+		}
+
+		// 跳过数据子块，以长度为 0 的块结束
+		for {
+			if offset >= len(data) {
+				return 0, errMalformedGIF
+			}
+			size := int(data[offset])
+			offset += 1 + size
+			if size == 0 {
+				break
+			}
+		}
+	}
+	return frames, nil
+}
+
+// readFile 读取上传文件的全部内容
+func readFile(file *multipart.FileHeader) ([]byte, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	return data, nil
+}
+
+func isGIF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("GIF87a")) || bytes.HasPrefix(data, []byte("GIF89a"))
+}
+
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+// isAnimatedWebP 遍历 RIFF 块，VP8X 带动画标志或存在 ANIM 块时视为动图
+func isAnimatedWebP(data []byte) bool {
+	for offset := 12; offset+8 <= len(data); {
+		chunkType := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		payload := offset + 8
+
+		switch chunkType {
+		case "VP8X":
+			if payload < len(data) && data[payload]&webpAnimationFlag != 0 {
+				return true
+			}
+		case "ANIM", "ANMF":
+			return true
+		}
+
+		// 块数据按偶数字节对齐
+		offset = payload + size + size%2
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"mime/multipart"
+	"testing"
+)
+
+// gifData 生成指定帧数的 GIF
+func gifData(t *testing.T, frames int) []byte {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, 4, 4), palette))
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatalf("encode gif: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// fileHeader 构造带内容的上传文件
+func fileHeader(t *testing.T, name string, data []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	part.Write(data)
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("read form: %v", err)
+	}
+	return form.File["file"][0]
+}
+
+func TestIsAnimated(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames int
+		want   bool
+	}{
+		{"static", 1, false},
+		{"two frames", 2, true},
+		{"many frames", 50, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsAnimated(fileHeader(t, "a.gif", gifData(t, tt.frames)))
+			if err != nil {
+				t.Fatalf("IsAnimated: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsAnimated = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAnimatedRejectsOversizedCanvas(t *testing.T) {
+	data := gifData(t, 2)
+	// 逻辑屏幕宽度位于第 6、7 字节（小端）
+	data[6], data[7] = 0x88, 0x13 // 5000
+
+	if _, err := IsAnimated(fileHeader(t, "a.gif", data)); err != ErrImageTooLarge {
+		t.Errorf("IsAnimated err = %v, want ErrImageTooLarge", err)
+	}
+	if _, err := FlattenAnimated(fileHeader(t, "a.gif", data)); err != ErrImageTooLarge {
+		t.Errorf("FlattenAnimated err = %v, want ErrImageTooLarge", err)
+	}
+}
+
+func TestCountGIFFramesRejectsTruncatedData(t *testing.T) {
+	data := gifData(t, 2)
+	if _, err := countGIFFrames(data[:20], 2); err == nil {
+		t.Error("countGIFFrames succeeded on truncated data")
+	}
+}
+
+func TestFlattenAnimatedEncodesPNG(t *testing.T) {
+	frame, err := FlattenAnimated(fileHeader(t, "a.gif", gifData(t, 3)))
+	if err != nil {
+		t.Fatalf("FlattenAnimated: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(frame))
+	if err != nil {
+		t.Fatalf("flattened frame is not png: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 4 || b.Dy() != 4 {
+		t.Errorf("bounds = %v, want 4x4", b)
+	}
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+
+	"github.com/nfnt/resize"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器
+)
+
+// avatarJPEGQuality 重新编码 JPEG 头像的质量
+const avatarJPEGQuality = 90
+
+var (
+	// ErrUnsupportedImage 无法解码的图片格式
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	// ErrAspectRatio 不裁剪时图片长宽比超出限制
+	ErrAspectRatio = errors.New("image aspect ratio exceeds limit")
+)
+
+// AvatarOptions 头像处理参数
+type AvatarOptions struct {
+	// Crop 居中裁剪为正方形，为 false 时长宽比超过 MaxAspectRatio 返回 ErrAspectRatio
+	Crop           bool
+	MaxAspectRatio float64
+	// MaxDimension 处理后的最大边长，0 表示不缩放
+	MaxDimension uint
+}
+
+// AvatarImage 处理后的头像
+type AvatarImage struct {
+	Data   []byte
+	Width  int
+	Height int
+	// Ext 重新编码改变了格式时的新扩展名，为空表示沿用原格式
+	Ext string
+}
+
+// ProcessAvatarFile 读取上传的头像文件并按参数处理
+func ProcessAvatarFile(file *multipart.FileHeader, opts AvatarOptions) (*AvatarImage, error) {
+	data, err := readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ProcessAvatar(data, opts)
+}
+
+// ProcessAvatar 校验头像长宽比，按需居中裁剪为正方形并限制最大边长
+// 无需裁剪和缩放时原样返回数据，避免重复压缩
+func ProcessAvatar(data []byte, opts AvatarOptions) (*AvatarImage, error) {
+	// 解码前只读取头部检查尺寸，避免超大图片占用内存
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width > MaxImageDimension || cfg.Height > MaxImageDimension {
+		return nil, ErrImageTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, ErrUnsupportedImage
+	}
+
+	changed := false
+	if opts.Crop {
+		if width != height {
+			img = cropSquare(img)
+			changed = true
+		}
+	} else if aspectRatio(width, height) > opts.MaxAspectRatio {
+		return nil, ErrAspectRatio
+	}
+
+	bounds = img.Bounds()
+	if opts.MaxDimension > 0 && (bounds.Dx() > int(opts.MaxDimension) || bounds.Dy() > int(opts.MaxDimension)) {
+		img = resize.Thumbnail(opts.MaxDimension, opts.MaxDimension, img, resize.Lanczos3)
+		changed = true
+	}
+
+	bounds = img.Bounds()
+	result := &AvatarImage{Data: data, Width: bounds.Dx(), Height: bounds.Dy()}
+	if !changed {
+		return result, nil
+	}
+
+	// 没有 WebP 编码器，裁剪或缩放后的 WebP 改为 PNG
+	if format == "webp" {
+		format = "png"
+		result.Ext = ".png"
+	}
+	if result.Data, err = encodeImage(img, format); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// cropSquare 以中心为基准裁剪出最大的正方形
+func cropSquare(img image.Image) image.Image {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	rect := image.Rect(x0, y0, x0+side, y0+side)
+
+	if sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst
+}
+
+func aspectRatio(width, height int) float64 {
+	if width > height {
+		return float64(width) / float64(height)
+	}
+	return float64(height) / float64(width)
+}
+
+// encodeImage 按原格式重新编码，保持文件扩展名与内容一致
+func encodeImage(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: avatarJPEGQuality})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	default:
+		return nil, ErrUnsupportedImage
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %v", format, err)
+	}
+	return buf.Bytes(), nil
+}
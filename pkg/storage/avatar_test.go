@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+)
+
+// pngData 生成指定尺寸的 PNG
+func pngData(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessAvatarWideImage(t *testing.T) {
+	data := pngData(t, 300, 100)
+	tests := []struct {
+		name       string
+		opts       AvatarOptions
+		wantWidth  int
+		wantHeight int
+		wantErr    error
+	}{
+		{"crop", AvatarOptions{Crop: true}, 100, 100, nil},
+		{"crop and shrink", AvatarOptions{Crop: true, MaxDimension: 50}, 50, 50, nil},
+		{"reject", AvatarOptions{MaxAspectRatio: 2}, 0, 0, ErrAspectRatio},
+		{"within ratio", AvatarOptions{MaxAspectRatio: 3}, 300, 100, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ProcessAvatar(data, tt.opts)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if result.Width != tt.wantWidth || result.Height != tt.wantHeight {
+				t.Errorf("size = %dx%d, want %dx%d", result.Width, result.Height, tt.wantWidth, tt.wantHeight)
+			}
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(result.Data))
+			if err != nil || format != "png" || cfg.Width != tt.wantWidth || cfg.Height != tt.wantHeight {
+				t.Errorf("encoded = %s %dx%d, %v", format, cfg.Width, cfg.Height, err)
+			}
+		})
+	}
+}
+
+func TestProcessAvatarRejectsOversizedDimensions(t *testing.T) {
+	data := pngData(t, MaxImageDimension+1, 1)
+	if _, err := ProcessAvatar(data, AvatarOptions{Crop: true}); err != ErrImageTooLarge {
+		t.Errorf("err = %v, want ErrImageTooLarge", err)
+	}
+}
+
+func TestProcessAvatarWebP(t *testing.T) {
+	data, err := os.ReadFile("testdata/gopher.webp") // 75x100
+	if err != nil {
+		t.Fatalf("read webp: %v", err)
+	}
+
+	// 长宽比在限制内时原样保留
+	kept, err := ProcessAvatar(data, AvatarOptions{MaxAspectRatio: 2})
+	if err != nil {
+		t.Fatalf("keep: %v", err)
+	}
+	if !bytes.Equal(kept.Data, data) || kept.Ext != "" {
+		t.Errorf("webp within ratio should pass through, ext = %q", kept.Ext)
+	}
+
+	// 裁剪后无法编码 WebP，改为 PNG
+	cropped, err := ProcessAvatar(data, AvatarOptions{Crop: true})
+	if err != nil {
+		t.Fatalf("crop: %v", err)
+	}
+	if cropped.Ext != ".png" || cropped.Width != 75 || cropped.Height != 75 {
+		t.Errorf("cropped = %dx%d ext %q, want 75x75 .png", cropped.Width, cropped.Height, cropped.Ext)
+	}
+	if _, format, err := image.DecodeConfig(bytes.NewReader(cropped.Data)); err != nil || format != "png" {
+		t.Errorf("cropped format = %q, %v", format, err)
+	}
+}
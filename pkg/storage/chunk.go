@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"DistanceBack_v1/pkg/logger"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// 分片上传相关
+	MaxChunkSize       = 8 * 1024 * 1024   // 单个分片最大 8MB
+	MaxChunkedFileSize = 500 * 1024 * 1024 // 分片上传文件最大 500MB
+	ChunkDirectory     = "temp/chunks"     // 分片临时目录
+
+	// GCS 单次合并最多 32 个源对象
+	maxComposeSources = 32
+)
+
+// ChunkStorage 分片上传存储接口
+type ChunkStorage interface {
+	// UploadChunk 写入分片对象
+	UploadChunk(ctx context.Context, objectPath string, r io.Reader) error
+	// ComposeObjects 按顺序合并分片为最终对象，返回访问URL
+	ComposeObjects(ctx context.Context, srcPaths []string, directory, filename string) (string, error)
+	// DeletePrefix 删除指定前缀下的所有对象
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// ChunkObjectPath 生成分片对象路径
+func ChunkObjectPath(sessionID string, index int) string {
+	return path.Join(ChunkPrefix(sessionID), fmt.Sprintf("%06d", index))
+}
+
+// ChunkPrefix 获取上传会话的分片目录
+func ChunkPrefix(sessionID string) string {
+	return path.Join(ChunkDirectory, sessionID) + "/"
+}
+
+// UploadChunk 写入分片对象
+func (s *FirebaseStorage) UploadChunk(ctx context.Context, objectPath string, r io.Reader) error {
+	// 写入失败时取消上下文放弃上传，直接 Close 会提交不完整的分片
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := s.bucket.Object(objectPath).NewWriter(ctx)
+	writer.ContentType = "application/octet-stream"
+
+	if _, err := io.Copy(writer, r); err != nil {
+		cancel()
+		writer.Close()
+		return fmt.Errorf("failed to write chunk: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close chunk writer: %v", err)
+	}
+	return nil
+}
+
+// ComposeObjects 合并分片为最终对象
+// 分片数超过单次合并上限时先分批合并为中间对象，合并成功后删除分片和中间对象
+func (s *FirebaseStorage) ComposeObjects(ctx context.Context, srcPaths []string, directory, filename string) (string, error) {
+	if len(srcPaths) == 0 {
+		return "", fmt.Errorf("no source objects to compose")
+	}
+
+	objectPath := path.Join(directory, generateFileName(filename))
+	var intermediates []string
+	sources := srcPaths
+	for round := 0; len(sources) > maxComposeSources; round++ {
+		next := make([]string, 0, len(sources)/maxComposeSources+1)
+		for i := 0; i < len(sources); i += maxComposeSources {
+			end := i + maxComposeSources
+			if end > len(sources) {
+				end = len(sources)
+			}
+			intermediate := fmt.Sprintf("%s.compose-%d-%d", srcPaths[0], round, i/maxComposeSources)
+			if err := s.compose(ctx, sources[i:end], intermediate, ""); err != nil {
+				return "", err
+			}
+			next = append(next, intermediate)
+			intermediates = append(intermediates, intermediate)
+		}
+		sources = next
+	}
+
+	if err := s.compose(ctx, sources, objectPath, getContentType(filename)); err != nil {
+		return "", err
+	}
+
+	// 合并结果已独立存在，清理失败不影响上传，剩余对象由过期会话清理兜底
+	if err := s.deleteObjects(ctx, append(intermediates, srcPaths...)); err != nil {
+		logger.Warn("删除已合并的分片失败",
+			logger.Any("error", err),
+			logger.String("object", objectPath))
+	}
+
+	return fmt.Sprintf("%s/%s", s.baseURL, objectPath), nil
+}
+
+// deleteObjects 删除指定对象，对象不存在时忽略
+func (s *FirebaseStorage) deleteObjects(ctx context.Context, objectPaths []string) error {
+	for _, p := range objectPaths {
+		if err := s.bucket.Object(p).Delete(ctx); err != nil && err != storage.ErrObjectNotExist {
+			return fmt.Errorf("failed to delete object %s: %v", p, err)
+		}
+	}
+	return nil
+}
+
+// DeletePrefix 删除指定前缀下的所有对象
+func (s *FirebaseStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	it := s.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list objects: %v", err)
+		}
+		if err := s.bucket.Object(attrs.Name).Delete(ctx); err != nil && err != storage.ErrObjectNotExist {
+			return fmt.Errorf("failed to delete object %s: %v", attrs.Name, err)
+		}
+	}
+}
+
+// compose 将源对象合并到目标对象
+func (s *FirebaseStorage) compose(ctx context.Context, srcPaths []string, dstPath, contentType string) error {
+	srcs := make([]*storage.ObjectHandle, 0, len(srcPaths))
+	for _, p := range srcPaths {
+		srcs = append(srcs, s.bucket.Object(p))
+	}
+
+	composer := s.bucket.Object(dstPath).ComposerFrom(srcs...)
+	if contentType != "" {
+		composer.ContentType = contentType
+		composer.CacheControl = s.visibility.cacheControl(dstPath)
+		composer.PredefinedACL = s.visibility.predefinedACL(dstPath)
+	}
+	if _, err := composer.Run(ctx); err != nil {
+		return fmt.Errorf("failed to compose objects: %v", err)
+	}
+	return nil
+}
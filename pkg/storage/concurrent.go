@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"mime/multipart"
+	"sync"
+)
+
+// DefaultUploadConcurrency 未配置时的默认上传并发数
+const DefaultUploadConcurrency = 4
+
+// UploadResult 单个文件的上传结果
+type UploadResult struct {
+	URL string
+	Err error
+}
+
+// UploadFiles 以最多 limit 个并发上传文件，结果与 files 一一对应、顺序一致
+// 单个文件失败不影响其他文件，limit <= 0 时使用 DefaultUploadConcurrency
+func UploadFiles(ctx context.Context, store Storage, files []*multipart.FileHeader, directory string, limit int) []UploadResult {
+	results := make([]UploadResult, len(files))
+	if limit <= 0 {
+		limit = DefaultUploadConcurrency
+	}
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, file *multipart.FileHeader) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := ctx.Err(); err != nil {
+				results[i].Err = err
+				return
+			}
+			results[i].URL, results[i].Err = store.UploadFile(ctx, file, directory)
+		}(i, file)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -13,6 +13,7 @@ import (
 	"DistanceBack_v1/config"
 	"DistanceBack_v1/pkg/auth" // 导入 auth 包
 	"DistanceBack_v1/pkg/logger"
+	"DistanceBack_v1/pkg/utils"
 
 	"cloud.google.com/go/storage"
 )
@@ -20,7 +21,9 @@ import (
 // Storage 定义存储接口
 type Storage interface {
 	UploadFile(ctx context.Context, file *multipart.FileHeader, directory string) (string, error)
+	UploadBytes(ctx context.Context, data []byte, originalName, directory string) (string, error)
 	DeleteFile(ctx context.Context, fileURL string) error
+	AccessURL(ctx context.Context, fileURL string) (string, error)
 }
 
 // FirebaseStorage Firebase存储实现
@@ -28,6 +31,7 @@ type FirebaseStorage struct {
 	bucket     *storage.BucketHandle
 	bucketName string
 	baseURL    string
+	visibility DirectoryVisibility
 }
 
 var defaultStorage Storage
@@ -51,6 +55,7 @@ func InitStorage(cfg *config.FirebaseConfig) error {
 		bucket:     bucket,
 		bucketName: cfg.StorageBucket,
 		baseURL:    fmt.Sprintf("https://storage.googleapis.com/%s", cfg.StorageBucket),
+		visibility: DirectoryVisibility(cfg.DirectoryVisibility),
 	}
 
 	logger.Info("Firebase Storage initialized successfully")
@@ -77,31 +82,31 @@ func (s *FirebaseStorage) UploadFile(ctx context.Context, file *multipart.FileHe
 		return "", fmt.Errorf("failed to read file: %v", err)
 	}
 
+	return s.UploadBytes(ctx, buffer, file.Filename, directory)
+}
+
+// UploadBytes 上传内存中的文件内容，originalName 用于确定扩展名和 Content-Type
+func (s *FirebaseStorage) UploadBytes(ctx context.Context, data []byte, originalName, directory string) (string, error) {
 	// 生成文件路径
-	filename := generateFileName(file.Filename)
+	filename := generateFileName(originalName)
 	objectPath := path.Join(directory, filename)
 
 	// 创建对象句柄
 	obj := s.bucket.Object(objectPath)
 
-	// 设置文件访问权限为公开
-	objectACL := obj.ACL()
-	if err := objectACL.Set(ctx, storage.AllUsers, storage.RoleReader); err != nil {
-		return "", fmt.Errorf("failed to set file ACL: %v", err)
-	}
-
-	// 创建写入器
+	// 创建写入器，按目录可见性设置访问权限
 	writer := obj.NewWriter(ctx)
+	writer.PredefinedACL = s.visibility.predefinedACL(objectPath)
 
 	// 设置Content-Type
-	contentType := getContentType(file.Filename)
+	contentType := getContentType(originalName)
 	writer.ContentType = contentType
 
 	// 设置缓存控制
-	writer.CacheControl = "public, max-age=86400" // 24小时缓存
+	writer.CacheControl = s.visibility.cacheControl(objectPath)
 
 	// 写入文件内容
-	if _, err := io.Copy(writer, bytes.NewReader(buffer)); err != nil {
+	if _, err := io.Copy(writer, bytes.NewReader(data)); err != nil {
 		return "", fmt.Errorf("failed to copy file to storage: %v", err)
 	}
 
@@ -116,10 +121,10 @@ func (s *FirebaseStorage) UploadFile(ctx context.Context, file *multipart.FileHe
 
 // DeleteFile 删除文件
 func (s *FirebaseStorage) DeleteFile(ctx context.Context, fileURL string) error {
-	// 从URL中提取对象路径
-	objectPath := strings.TrimPrefix(fileURL, fmt.Sprintf("%s/", s.baseURL))
-	if objectPath == fileURL {
-		return fmt.Errorf("invalid file URL")
+	// 从URL中提取对象路径，公开与私有文件的 URL 格式相同
+	objectPath, err := s.objectPath(fileURL)
+	if err != nil {
+		return err
 	}
 
 	// 删除对象
@@ -134,11 +139,21 @@ func (s *FirebaseStorage) DeleteFile(ctx context.Context, fileURL string) error
 	return nil
 }
 
+// objectPath 从文件URL中提取对象路径
+func (s *FirebaseStorage) objectPath(fileURL string) (string, error) {
+	objectPath := strings.TrimPrefix(fileURL, fmt.Sprintf("%s/", s.baseURL))
+	if objectPath == fileURL {
+		return "", fmt.Errorf("invalid file URL")
+	}
+	return objectPath, nil
+}
+
 // 生成唯一文件名
+// 并发上传时时间戳可能相同，附加随机UID避免覆盖
 func generateFileName(originalName string) string {
 	ext := path.Ext(originalName)
 	timestamp := time.Now().UnixNano()
-	return fmt.Sprintf("%d%s", timestamp, ext)
+	return fmt.Sprintf("%d_%s%s", timestamp, utils.NewUID(), ext)
 }
 
 // 获取文件Content-Type
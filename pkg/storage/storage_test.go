@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"mime/multipart"
+	"path"
+	"sync"
+	"testing"
+)
+
+func TestGenerateFileNameUniqueUnderConcurrency(t *testing.T) {
+	const workers, perWorker = 16, 200
+
+	var mu sync.Mutex
+	seen := make(map[string]bool, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			names := make([]string, 0, perWorker)
+			for i := 0; i < perWorker; i++ {
+				names = append(names, generateFileName("photo.jpg"))
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, name := range names {
+				if seen[name] {
+					t.Errorf("duplicate file name %q", name)
+				}
+				seen[name] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	for name := range seen {
+		if path.Ext(name) != ".jpg" {
+			t.Fatalf("file name %q lost its extension", name)
+		}
+		break
+	}
+}
+
+// namingStore 按 FirebaseStorage 的规则生成对象名，不做实际上传
+type namingStore struct {
+	Storage
+}
+
+func (namingStore) UploadFile(ctx context.Context, file *multipart.FileHeader, directory string) (string, error) {
+	return path.Join(directory, generateFileName(file.Filename)), nil
+}
+
+func TestUploadFilesConcurrentNamesDoNotCollide(t *testing.T) {
+	files := make([]*multipart.FileHeader, 64)
+	for i := range files {
+		files[i] = fileHeader(t, "same.png", []byte("x"))
+	}
+
+	results := UploadFiles(context.Background(), namingStore{}, files, "topics", len(files))
+	seen := make(map[string]bool, len(results))
+	for i, r := range results {
+		if r.Err != nil {
+			t.Fatalf("file %d: %v", i, r.Err)
+		}
+		if seen[r.URL] {
+			t.Fatalf("file %d overwrote %q", i, r.URL)
+		}
+		seen[r.URL] = true
+	}
+}
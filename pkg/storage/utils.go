@@ -18,6 +18,10 @@ const (
 	MaxFileSize  = 10 * 1024 * 1024  // 单个文件最大 10MB
 	MaxTotalSize = 100 * 1024 * 1024 // 每个用户的总存储限制 100MB
 
+	// 请求体限制相关
+	MaxTopicImages    = 9               // 单个话题最多上传图片数
+	MultipartOverhead = 1 * 1024 * 1024 // multipart 表单字段及边界的预留空间
+
 	// 图片处理相关
 	MaxImageDimension = 4096     // 最大图片尺寸
 	ThumbnailSize     = 300      // 缩略图尺寸
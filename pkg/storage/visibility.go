@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+)
+
+// 目录可见性
+const (
+	// VisibilityPublic 所有人可读，返回的 URL 可直接访问
+	VisibilityPublic = "public"
+	// VisibilityPrivate 仅项目成员可读，需通过签名 URL 访问
+	VisibilityPrivate = "private"
+)
+
+// SignedURLExpiration 私有文件签名 URL 的有效期
+const SignedURLExpiration = 15 * time.Minute
+
+// DirectoryVisibility 按目录配置的可见性，未配置的目录视为公开
+type DirectoryVisibility map[string]string
+
+// IsPublic 判断对象路径是否公开，按最长的目录前缀匹配
+func (v DirectoryVisibility) IsPublic(objectPath string) bool {
+	matched, visibility := "", VisibilityPublic
+	for dir, vis := range v {
+		dir = strings.Trim(dir, "/")
+		if dir == "" || len(dir) <= len(matched) {
+			continue
+		}
+		if objectPath == dir || strings.HasPrefix(objectPath, dir+"/") {
+			matched, visibility = dir, vis
+		}
+	}
+	return !strings.EqualFold(visibility, VisibilityPrivate)
+}
+
+// predefinedACL 根据对象路径返回上传时使用的预定义 ACL
+func (v DirectoryVisibility) predefinedACL(objectPath string) string {
+	if v.IsPublic(objectPath) {
+		return "publicRead"
+	}
+	return "projectPrivate"
+}
+
+// AccessURL 获取文件的访问地址
+// 公开文件直接返回原 URL，私有文件返回有时效的签名 URL
+func (s *FirebaseStorage) AccessURL(ctx context.Context, fileURL string) (string, error) {
+	objectPath, err := s.objectPath(fileURL)
+	if err != nil {
+		return "", err
+	}
+	if s.visibility.IsPublic(objectPath) {
+		return fileURL, nil
+	}
+
+	signed, err := s.bucket.SignedURL(objectPath, &storage.SignedURLOptions{
+		Method:  "GET",
+		Expires: time.Now().Add(SignedURLExpiration),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign file URL: %v", err)
+	}
+	return signed, nil
+}
+
+// cacheControl 私有文件不允许共享缓存
+func (v DirectoryVisibility) cacheControl(objectPath string) string {
+	if v.IsPublic(objectPath) {
+		return "public, max-age=86400" // 24小时缓存
+	}
+	return "private, max-age=0"
+}
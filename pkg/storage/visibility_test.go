@@ -0,0 +1,54 @@
+package storage
+
+import "testing"
+
+func TestDirectoryVisibility(t *testing.T) {
+	visibility := DirectoryVisibility{
+		"avatars":       VisibilityPublic,
+		"chats":         VisibilityPrivate,
+		"/topics/":      "Private",
+		"topics/public": VisibilityPublic,
+	}
+	tests := []struct {
+		name       string
+		objectPath string
+		public     bool
+	}{
+		{"public directory", "avatars/1/a.jpg", true},
+		{"private directory", "chats/10/file.pdf", false},
+		{"directory itself", "chats", false},
+		{"prefix of another name", "chatsroom/a.jpg", true},
+		{"trimmed and case insensitive", "topics/2/a.jpg", false},
+		{"longest prefix wins", "topics/public/a.jpg", true},
+		{"unknown directory", "misc/a.jpg", true},
+		{"root object", "a.jpg", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := visibility.IsPublic(tt.objectPath); got != tt.public {
+				t.Errorf("IsPublic(%q) = %v, want %v", tt.objectPath, got, tt.public)
+			}
+
+			wantACL, wantCache := "publicRead", "public, max-age=86400"
+			if !tt.public {
+				wantACL, wantCache = "projectPrivate", "private, max-age=0"
+			}
+			if got := visibility.predefinedACL(tt.objectPath); got != wantACL {
+				t.Errorf("predefinedACL(%q) = %q, want %q", tt.objectPath, got, wantACL)
+			}
+			if got := visibility.cacheControl(tt.objectPath); got != wantCache {
+				t.Errorf("cacheControl(%q) = %q, want %q", tt.objectPath, got, wantCache)
+			}
+		})
+	}
+}
+
+func TestDirectoryVisibilityUnconfigured(t *testing.T) {
+	var visibility DirectoryVisibility
+	if !visibility.IsPublic("chats/10/file.pdf") {
+		t.Error("unconfigured directory should be public")
+	}
+	if got := visibility.predefinedACL("chats/10/file.pdf"); got != "publicRead" {
+		t.Errorf("predefinedACL = %q, want publicRead", got)
+	}
+}
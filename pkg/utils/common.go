@@ -12,6 +12,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // StringToJSON 将字符串解析为JSON对象
@@ -156,3 +158,14 @@ func ExtractFileExt(filename string) string {
 func TimePtr(t time.Time) *time.Time {
 	return &t
 }
+
+// NewUID 生成全局唯一标识(UUID v4)，所有需要字符串ID的场景统一使用
+func NewUID() string {
+	return uuid.NewString()
+}
+
+// IsValidUID 检查客户端传入的标识是否为合法的UUID
+func IsValidUID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil && len(s) == 36
+}
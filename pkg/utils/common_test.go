@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestIsValidUID(t *testing.T) {
+	tests := []struct {
+		name string
+		uid  string
+		want bool
+	}{
+		{"generated", NewUID(), true},
+		{"canonical", "0b6f6f1e-7d3c-4c3a-9a53-1f2d5c6b7a80", true},
+		{"empty", "", false},
+		{"no hyphens", "0b6f6f1e7d3c4c3a9a531f2d5c6b7a80", false},
+		{"braces", "{0b6f6f1e-7d3c-4c3a-9a53-1f2d5c6b7a80}", false},
+		{"urn prefix", "urn:uuid:0b6f6f1e-7d3c-4c3a-9a53-1f2d5c6b7a80", false},
+		{"non-hex", "zb6f6f1e-7d3c-4c3a-9a53-1f2d5c6b7a80", false},
+		{"path traversal", "../../0b6f6f1e-7d3c-4c3a-9a53-1f2d5c6b", false},
+		{"trailing slash", "0b6f6f1e-7d3c-4c3a-9a53-1f2d5c6b7a8/", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValidUID(tt.uid); got != tt.want {
+				t.Errorf("IsValidUID(%q) = %v, want %v", tt.uid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewUIDIsUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		uid := NewUID()
+		if seen[uid] {
+			t.Fatalf("duplicate uid %s", uid)
+		}
+		seen[uid] = true
+	}
+}
@@ -68,6 +68,27 @@ func GetEndOfDay(t time.Time) time.Time {
 	return time.Date(year, month, day, 23, 59, 59, 999999999, t.Location())
 }
 
+// ParseDateRange 解析 YYYY-MM-DD 格式的日期范围，两端日期均包含在内
+// 返回 [from, to)，to 为结束日期的次日零点；参数为空时对应返回零值
+func ParseDateRange(startDate, endDate string) (from, to time.Time, err error) {
+	if startDate != "" {
+		if from, err = time.ParseInLocation(TimeLayoutDate, startDate, time.Local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %v", err)
+		}
+	}
+	if endDate != "" {
+		end, err := time.ParseInLocation(TimeLayoutDate, endDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %v", err)
+		}
+		to = end.AddDate(0, 0, 1)
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end date is before start date")
+	}
+	return from, to, nil
+}
+
 // GetStartOfWeek 获取本周的开始时间（周一为第一天）
 func GetStartOfWeek(t time.Time) time.Time {
 	weekday := int(t.Weekday())
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateRange(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name     string
+		start    string
+		end      string
+		wantFrom time.Time
+		wantTo   time.Time
+		wantErr  bool
+	}{
+		{"both bounds, end inclusive", "2024-03-01", "2024-03-31", day(2024, 3, 1), day(2024, 4, 1), false},
+		{"same day", "2024-03-05", "2024-03-05", day(2024, 3, 5), day(2024, 3, 6), false},
+		{"start only", "2024-03-05", "", day(2024, 3, 5), time.Time{}, false},
+		{"end only", "", "2024-12-31", time.Time{}, day(2025, 1, 1), false},
+		{"no bounds", "", "", time.Time{}, time.Time{}, false},
+		{"end before start", "2024-03-05", "2024-03-04", time.Time{}, time.Time{}, true},
+		{"invalid start", "2024/03/05", "", time.Time{}, time.Time{}, true},
+		{"invalid end", "", "2024-02-30", time.Time{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := ParseDateRange(tt.start, tt.end)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("range = [%v, %v), want [%v, %v)", from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
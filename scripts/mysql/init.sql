@@ -56,6 +56,23 @@ CREATE TABLE admin_permissions (
     UNIQUE KEY uk_admin_permission (user_id, permission_type)
 )ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '管理员权限表';
 
+-- 管理操作审计日志表
+CREATE TABLE audit_logs (
+    id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+    operator_id BIGINT UNSIGNED NOT NULL COMMENT '操作人ID',
+    action VARCHAR(50) NOT NULL COMMENT '操作类型',
+    target_type VARCHAR(20) NOT NULL COMMENT '操作对象类型',
+    target_id BIGINT UNSIGNED NOT NULL COMMENT '操作对象ID',
+    detail TEXT COMMENT '操作详情(JSON)',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (operator_id) REFERENCES users(id),
+    INDEX idx_operator_id (operator_id),
+    INDEX idx_action (action),
+    INDEX idx_audit_target (target_type, target_id),
+    INDEX idx_created_at (created_at)
+)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '管理操作审计日志表';
+
 -- 被办用户
 CREATE TABLE user_bans (
     id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
@@ -63,9 +80,10 @@ CREATE TABLE user_bans (
     operator_id BIGINT UNSIGNED NOT NULL COMMENT '操作人ID', 
     reason TEXT NOT NULL COMMENT '封禁原因',
     ban_start TIMESTAMP NOT NULL COMMENT '封禁开始时间',
-    ban_end TIMESTAMP COMMENT '封禁结束时间',
+    ban_end TIMESTAMP NULL DEFAULT NULL COMMENT '封禁结束时间，为空表示永久封禁',
     status ENUM('active', 'expired', 'cancelled') DEFAULT 'active',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id),
     FOREIGN KEY (operator_id) REFERENCES users(id),
     INDEX idx_user_status (user_id, status)
@@ -162,6 +180,7 @@ CREATE TABLE topics (
     shares_count INT UNSIGNED DEFAULT 0 COMMENT '分享次数',
     expires_at TIMESTAMP COMMENT '过期时间',
     status ENUM('active', 'closed', 'cancelled') DEFAULT 'active'  COMMENT '话题状态：active-进行中, closed-已结束, cancelled-已取消',
+    closed_at TIMESTAMP NULL DEFAULT NULL COMMENT '关闭时间',
 	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     -- 外键约束
@@ -239,6 +258,26 @@ CREATE TABLE topic_interactions (
     INDEX idx_user_type (user_id, interaction_type, created_at) COMMENT '用户互动类型索引，用于查询用户的互动历史'
 )ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '话题互动表';
 
+-- 话题每日统计表
+CREATE TABLE topic_daily_stats (
+    topic_id BIGINT UNSIGNED NOT NULL COMMENT '话题ID',
+    stat_date DATE NOT NULL COMMENT '统计日期',
+    views INT UNSIGNED DEFAULT 0 COMMENT '当日浏览数',
+    likes INT UNSIGNED DEFAULT 0 COMMENT '当日点赞数',
+    PRIMARY KEY (topic_id, stat_date),
+    FOREIGN KEY (topic_id) REFERENCES topics(id) ON DELETE CASCADE
+)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '话题每日统计表，按日累加浏览和点赞';
+
+-- 个人主页置顶话题表
+CREATE TABLE profile_pinned_topics (
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    topic_id BIGINT UNSIGNED NOT NULL COMMENT '话题ID',
+    pinned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '置顶时间',
+    PRIMARY KEY (user_id, topic_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (topic_id) REFERENCES topics(id) ON DELETE CASCADE
+)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '个人主页置顶话题表';
+
 
 
 -- 聊天室表
@@ -247,10 +286,13 @@ CREATE TABLE chat_rooms (
     name VARCHAR(100) NOT NULL COMMENT '聊天室名称',
     type ENUM('individual', 'group', 'merchant', 'official') NOT NULL COMMENT '聊天室类型：individual-个人聊天, group-群聊, merchant-店家, official-官方',
     topic_id BIGINT UNSIGNED COMMENT '关联的话题ID（如果是话题聊天室）',
+    pair_key VARCHAR(41) COMMENT '私聊双方用户ID（较小ID:较大ID），群聊为空',
     avatar_url VARCHAR(255) COMMENT '聊天室头像URL',
     announcement TEXT COMMENT '聊天室公告',
+    status ENUM('active', 'closed') NOT NULL DEFAULT 'active' COMMENT '聊天室状态：active-正常, closed-已关闭',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '聊天室创建时间',
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '聊天室更新时间',
+    UNIQUE KEY uk_pair_key (pair_key),
     FOREIGN KEY (topic_id) REFERENCES topics(id)
 )ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '聊天室表，用于记录聊天室的基本信息';
 
@@ -274,8 +316,9 @@ CREATE TABLE messages (
     id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT COMMENT '消息ID',
     chat_room_id BIGINT UNSIGNED COMMENT '所属聊天室ID',
     sender_id BIGINT UNSIGNED COMMENT '发送者用户ID',
-    content_type ENUM('text', 'image', 'file', 'system') DEFAULT 'text' NOT NULL COMMENT '消息类型：text-文本, image-图片, file-文件, system-系统消息',
+    content_type ENUM('text', 'image', 'file', 'system', 'topic') DEFAULT 'text' NOT NULL COMMENT '消息类型：text-文本, image-图片, file-文件, system-系统消息, topic-分享话题',
     content TEXT COMMENT '消息内容',
+    deleted_at TIMESTAMP NULL COMMENT '对所有人删除(撤回)的时间，删除后内容清空',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '消息发送时间',
     
     -- 外键约束
@@ -289,6 +332,16 @@ CREATE TABLE messages (
   COLLATE=utf8mb4_unicode_ci 
   COMMENT '消息表，用于记录聊天室中的消息内容';
 
+-- 用户仅对自己删除的消息
+CREATE TABLE hidden_messages (
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    message_id BIGINT UNSIGNED NOT NULL COMMENT '消息ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '删除时间',
+    PRIMARY KEY (user_id, message_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT '隐藏消息表，记录用户仅对自己删除的消息';
+
 -- 消息媒体表
 CREATE TABLE message_media (
     id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT COMMENT '媒体记录ID',
@@ -405,7 +458,7 @@ CREATE TABLE reports (
     reporter_id BIGINT UNSIGNED NOT NULL COMMENT '举报人ID',
     target_type ENUM('user', 'topic', 'comment', 'message') NOT NULL COMMENT '举报对象类型',
     target_id BIGINT UNSIGNED NOT NULL COMMENT '举报对象ID',
-    reason_type VARCHAR(50) NOT NULL COMMENT '举报原因类型',
+    reason_type ENUM('spam', 'harassment', 'nudity', 'other') NOT NULL COMMENT '举报原因类型',
     reason_detail TEXT COMMENT '举报详细原因',
     status ENUM('pending', 'processing', 'resolved', 'rejected') DEFAULT 'pending' COMMENT '处理状态',
     handler_id BIGINT UNSIGNED COMMENT '处理人ID',